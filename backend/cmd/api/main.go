@@ -38,6 +38,8 @@ import (
 	"github.com/shester1kov/testgen-backend/internal/infrastructure/parser"
 	"github.com/shester1kov/testgen-backend/internal/infrastructure/persistence"
 	"github.com/shester1kov/testgen-backend/internal/infrastructure/persistence/postgres"
+	"github.com/shester1kov/testgen-backend/internal/infrastructure/storage"
+	"github.com/shester1kov/testgen-backend/internal/infrastructure/worker"
 	"github.com/shester1kov/testgen-backend/internal/interfaces/http/handler"
 	"github.com/shester1kov/testgen-backend/internal/interfaces/http/router"
 	"github.com/shester1kov/testgen-backend/pkg/config"
@@ -90,9 +92,15 @@ func main() {
 	userRepo := postgres.NewUserRepository(db)
 	roleRepo := postgres.NewRoleRepository(db)
 	documentRepo := postgres.NewDocumentRepository(db)
+	documentTextRevisionRepo := postgres.NewDocumentTextRevisionRepository(db)
+	uploadSessionRepo := postgres.NewUploadSessionRepository(db)
+	searchRepo := postgres.NewSearchRepository(db)
 	testRepo := postgres.NewTestRepository(db)
 	questionRepo := postgres.NewQuestionRepository(db)
 	answerRepo := postgres.NewAnswerRepository(db)
+	questionCategoryRepo := postgres.NewQuestionCategoryRepository(db)
+	bankQuestionRepo := postgres.NewBankQuestionRepository(db)
+	unitOfWork := postgres.NewUnitOfWork(db)
 
 	// Run database seeders
 	seeder := persistence.NewSeeder(userRepo, roleRepo, cfg, appLogger)
@@ -108,6 +116,43 @@ func main() {
 
 	// Initialize document parser factory (Factory Pattern)
 	parserFactory := parser.NewDocumentParserFactory()
+	parserFactory.SetArchiveLimits(parser.ArchiveLimits{
+		MaxEntries:          cfg.File.MaxArchiveEntries,
+		MaxUncompressedSize: cfg.File.MaxUncompressedSize,
+		MaxCompressionRatio: float64(cfg.File.MaxCompressionRatio),
+	})
+	parserFactory.SetNotebookOutputs(cfg.Parser.NotebookOutputs)
+
+	// Initialize file storage (Strategy Pattern: local disk or S3-compatible)
+	fileStorage, err := storage.New(context.Background(), storageConfig(cfg))
+	if err != nil {
+		appLogger.Fatal("Failed to initialize file storage", zap.Error(err))
+	}
+	appLogger.Info("File storage initialized", zap.String("backend", cfg.Storage.Backend))
+
+	// Initialize background parse queue (Worker Pool)
+	parseQueue := worker.NewParseQueue(documentRepo, parserFactory, fileStorage, worker.ParseQueueConfig{
+		Workers:      cfg.Parser.Workers,
+		QueueSize:    cfg.Parser.QueueSize,
+		JobTimeout:   cfg.Parser.JobTimeout,
+		MaxRetries:   cfg.Parser.MaxRetries,
+		RetryBackoff: cfg.Parser.RetryBackoff,
+		PollInterval: cfg.Parser.PollInterval,
+		StaleAfter:   cfg.Parser.StaleAfter,
+	}, appLogger)
+	parseCtx, cancelParse := context.WithCancel(context.Background())
+	defer cancelParse()
+	parseQueue.Start(parseCtx)
+
+	// Initialize storage janitor (removes files nobody references anymore)
+	janitor := worker.NewJanitor(documentRepo, uploadSessionRepo, fileStorage, worker.JanitorConfig{
+		Interval:          cfg.Cleanup.Interval,
+		Retention:         cfg.Cleanup.Retention,
+		OrphanGracePeriod: cfg.Cleanup.OrphanGracePeriod,
+		UploadSessionTTL:  cfg.File.UploadSessionTTL,
+		DryRun:            cfg.Cleanup.DryRun,
+	}, appLogger)
+	janitor.Start(parseCtx)
 
 	// Initialize LLM factory (Factory Pattern + Strategy Pattern)
 	llmFactory := llm.NewLLMFactory(
@@ -120,6 +165,7 @@ func main() {
 
 	// Initialize Moodle components
 	xmlExporter := moodle.NewMoodleXMLExporter()
+	xmlExporter.SetNegativeMarking(cfg.Moodle.NegativeMarking)
 	var moodleClient *moodle.Client
 	if cfg.Moodle.URL != "" && cfg.Moodle.Token != "" {
 		moodleClient = moodle.NewClient(cfg.Moodle.URL, cfg.Moodle.Token, cfg.Moodle.ImportToken)
@@ -142,19 +188,26 @@ func main() {
 	documentHandler := handler.NewDocumentHandler(
 		documentRepo,
 		userRepo,
+		documentTextRevisionRepo,
+		uploadSessionRepo,
 		parserFactory,
-		cfg.File.UploadDir,
+		fileStorage,
 		cfg.File.MaxFileSize,
+		parseQueue,
 	)
-	testHandler := handler.NewTestHandler(testRepo, documentRepo, questionRepo, answerRepo, userRepo, llmFactory, xmlExporter)
+	documentHandler.SetUploadLimits(cfg.File.UploadChunkMaxSize, cfg.File.UploadSessionTTL)
+	documentHandler.SetBulkUploadLimit(cfg.File.BulkUploadMaxFiles)
+	testHandler := handler.NewTestHandler(testRepo, documentRepo, questionRepo, answerRepo, userRepo, unitOfWork, llmFactory, xmlExporter)
 	moodleHandler := handler.NewMoodleHandler(
 		testRepo,
 		questionRepo,
-		answerRepo,
 		xmlExporter,
 		moodleClient,
 	)
 	statsHandler := handler.NewStatsHandler(testRepo, documentRepo, questionRepo, userRepo)
+	searchHandler := handler.NewSearchHandler(searchRepo, userRepo)
+	cleanupHandler := handler.NewCleanupHandler(janitor)
+	questionBankHandler := handler.NewQuestionBankHandler(bankQuestionRepo, questionCategoryRepo, testRepo, questionRepo, documentRepo, userRepo, llmFactory)
 
 	// Initialize Fiber app
 	app := fiber.New(fiber.Config{
@@ -174,9 +227,10 @@ func main() {
 	app.Use(logger.HTTPMiddleware(appLogger))
 	app.Use(cors.New(cors.Config{
 		AllowOrigins:     "http://localhost:3000,http://localhost:5173,http://localhost",
-		AllowHeaders:     "Origin, Content-Type, Accept, Authorization",
-		AllowMethods:     "GET, POST, PUT, DELETE, OPTIONS",
+		AllowHeaders:     "Origin, Content-Type, Accept, Authorization, Range, Upload-Offset",
+		AllowMethods:     "GET, POST, PUT, PATCH, DELETE, OPTIONS",
 		AllowCredentials: true,
+		ExposeHeaders:    "Content-Disposition, Content-Range, Accept-Ranges, Upload-Offset",
 	}))
 
 	// Health check endpoint
@@ -197,7 +251,7 @@ func main() {
 	app.Get("/swagger/*", swagger.HandlerDefault)
 
 	// Setup routes
-	router.SetupRoutes(app, authHandler, userHandler, documentHandler, testHandler, moodleHandler, statsHandler, jwtManager, cfg.Cookie.Name)
+	router.SetupRoutes(app, authHandler, userHandler, documentHandler, testHandler, moodleHandler, statsHandler, searchHandler, cleanupHandler, questionBankHandler, jwtManager, cfg.Cookie.Name)
 
 	// Root endpoint
 	// @Summary API version information
@@ -215,6 +269,7 @@ func main() {
 				"tests":     "/api/v1/tests",
 				"moodle":    "/api/v1/moodle",
 				"stats":     "/api/v1/stats",
+				"admin":     "/api/v1/admin",
 			},
 		})
 	})
@@ -244,9 +299,30 @@ func main() {
 		appLogger.Fatal("Server forced to shutdown", zap.Error(err))
 	}
 
+	// Let in-flight parsing jobs observe cancellation; unfinished ones are recovered on next start
+	parseQueue.Stop()
+	janitor.Stop()
+
 	appLogger.Info("Server exited successfully")
 }
 
+// storageConfig maps application config to file storage settings
+func storageConfig(cfg *config.Config) storage.Config {
+	return storage.Config{
+		Backend:  cfg.Storage.Backend,
+		LocalDir: cfg.File.UploadDir,
+		S3: storage.S3Config{
+			Endpoint:     cfg.Storage.S3Endpoint,
+			AccessKey:    cfg.Storage.S3AccessKey,
+			SecretKey:    cfg.Storage.S3SecretKey,
+			Bucket:       cfg.Storage.S3Bucket,
+			Region:       cfg.Storage.S3Region,
+			UseSSL:       cfg.Storage.S3UseSSL,
+			CreateBucket: cfg.Storage.S3CreateBucket,
+		},
+	}
+}
+
 func customErrorHandler(c *fiber.Ctx, err error) error {
 	code := fiber.StatusInternalServerError
 
@@ -0,0 +1,153 @@
+// Command storage-migrate copies uploaded document files between storage
+// backends and rewrites documents.file_path to the new storage keys.
+//
+// Usage:
+//
+//	go run ./cmd/storage-migrate -from local -to s3 [-dry-run] [-delete-source]
+//
+// Source and destination are configured with the same environment variables
+// as the API (UPLOAD_DIR for local, S3_* for s3). The tool is idempotent:
+// files already present at the destination with the same size are not copied
+// again, so an interrupted run can simply be restarted.
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+
+	"github.com/joho/godotenv"
+	"go.uber.org/zap"
+
+	"github.com/shester1kov/testgen-backend/internal/domain/entity"
+	"github.com/shester1kov/testgen-backend/internal/infrastructure/persistence/postgres"
+	"github.com/shester1kov/testgen-backend/internal/infrastructure/storage"
+	"github.com/shester1kov/testgen-backend/pkg/config"
+	"github.com/shester1kov/testgen-backend/pkg/logger"
+)
+
+func main() {
+	from := flag.String("from", storage.BackendLocal, "source storage backend (local or s3)")
+	to := flag.String("to", storage.BackendS3, "destination storage backend (local or s3)")
+	dryRun := flag.Bool("dry-run", false, "report what would be migrated without copying or updating rows")
+	deleteSource := flag.Bool("delete-source", false, "delete source files after a successful copy")
+	flag.Parse()
+
+	_ = godotenv.Load()
+	cfg := config.Load()
+	log := logger.NewDefault()
+	defer log.Sync()
+
+	if *from == *to {
+		log.Fatal("Source and destination backends must differ", zap.String("backend", *from))
+	}
+
+	ctx := context.Background()
+
+	src, err := storage.New(ctx, storageConfig(cfg, *from))
+	if err != nil {
+		log.Fatal("Failed to initialize source storage", zap.Error(err))
+	}
+	dst, err := storage.New(ctx, storageConfig(cfg, *to))
+	if err != nil {
+		log.Fatal("Failed to initialize destination storage", zap.Error(err))
+	}
+
+	db, err := postgres.NewDatabase(&postgres.DatabaseConfig{
+		Host:     cfg.Database.Host,
+		Port:     cfg.Database.Port,
+		User:     cfg.Database.User,
+		Password: cfg.Database.Password,
+		DBName:   cfg.Database.DBName,
+		SSLMode:  cfg.Database.SSLMode,
+		Logger:   log,
+	})
+	if err != nil {
+		log.Fatal("Failed to connect to database", zap.Error(err))
+	}
+
+	var copied, skipped, failed int
+
+	// Deduplicated uploads share one file between several rows, so files are
+	// migrated per path: copied once, every row moved to the new key, and only
+	// then is the source deleted. Soft-deleted documents are migrated too:
+	// their files are purged by the cleanup job, which must find them in the
+	// new backend. Rows whose file was already purged have nothing to copy
+	var filePaths []string
+	if err := db.WithContext(ctx).Model(&entity.Document{}).
+		Where("file_purged_at IS NULL").
+		Distinct("file_path").
+		Order("file_path").
+		Pluck("file_path", &filePaths).Error; err != nil {
+		log.Fatal("Failed to read documents", zap.Error(err))
+	}
+
+	for _, srcKey := range filePaths {
+		dstKey := storage.NormalizeDocumentKey(srcKey)
+		fileLog := log.With(
+			zap.String("from", srcKey),
+			zap.String("to", dstKey),
+		)
+
+		if *dryRun {
+			fileLog.Info("Would migrate file")
+			continue
+		}
+
+		didCopy, err := storage.Copy(ctx, src, dst, srcKey, dstKey)
+		if err != nil {
+			fileLog.Error("Failed to copy file", zap.Error(err))
+			failed++
+			continue
+		}
+
+		result := db.WithContext(ctx).Model(&entity.Document{}).
+			Where("file_path = ? AND file_purged_at IS NULL", srcKey).
+			Update("file_path", dstKey)
+		if result.Error != nil {
+			fileLog.Error("Failed to update file path", zap.Error(result.Error))
+			failed++
+			continue
+		}
+
+		if didCopy {
+			copied++
+			fileLog.Info("File migrated", zap.Int64("documents", result.RowsAffected))
+		} else {
+			skipped++
+		}
+
+		if *deleteSource {
+			if err := src.Delete(ctx, srcKey); err != nil {
+				fileLog.Warn("Failed to delete source file", zap.Error(err))
+			}
+		}
+	}
+
+	log.Info("Storage migration finished",
+		zap.Int("copied", copied),
+		zap.Int("already_present", skipped),
+		zap.Int("failed", failed),
+		zap.Bool("dry_run", *dryRun),
+	)
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// storageConfig builds settings for one backend from the API configuration
+func storageConfig(cfg *config.Config, backend string) storage.Config {
+	return storage.Config{
+		Backend:  backend,
+		LocalDir: cfg.File.UploadDir,
+		S3: storage.S3Config{
+			Endpoint:     cfg.Storage.S3Endpoint,
+			AccessKey:    cfg.Storage.S3AccessKey,
+			SecretKey:    cfg.Storage.S3SecretKey,
+			Bucket:       cfg.Storage.S3Bucket,
+			Region:       cfg.Storage.S3Region,
+			UseSSL:       cfg.Storage.S3UseSSL,
+			CreateBucket: cfg.Storage.S3CreateBucket,
+		},
+	}
+}
@@ -0,0 +1,26 @@
+package dto
+
+// CleanupReportResponse represents the outcome of a storage cleanup run
+type CleanupReportResponse struct {
+	DryRun          bool     `json:"dry_run"` // Nothing was removed; counts show what would be
+	StartedAt       string   `json:"started_at"`
+	FinishedAt      string   `json:"finished_at"`
+	PurgedDocuments int      `json:"purged_documents"`
+	PurgedFiles     int      `json:"purged_files"`
+	SharedFilesKept int      `json:"shared_files_kept"`
+	OrphanedFiles   int      `json:"orphaned_files"`
+	ExpiredUploads  int      `json:"expired_uploads"`
+	ReclaimedBytes  int64    `json:"reclaimed_bytes"`
+	Errors          []string `json:"errors,omitempty"`
+}
+
+// CleanupStatusResponse represents storage cleanup settings and the latest run
+type CleanupStatusResponse struct {
+	Enabled           bool                   `json:"enabled"` // Scheduled runs are on
+	Interval          string                 `json:"interval"`
+	Retention         string                 `json:"retention"`
+	OrphanGracePeriod string                 `json:"orphan_grace_period"`
+	DryRun            bool                   `json:"dry_run"` // Scheduled runs only report
+	Running           bool                   `json:"running"`
+	LastReport        *CleanupReportResponse `json:"last_report"` // null until the first run finishes
+}
@@ -14,6 +14,27 @@ type DocumentUploadResponse struct {
 	Status     string  `json:"status"`
 	ErrorMsg   *string `json:"error_msg,omitempty"` // Pointer to omit if null
 	CreatedAt  string  `json:"created_at"`
+
+	ParseProgress int    `json:"parse_progress"` // 0-100, background parsing progress
+	ParseAttempts int    `json:"parse_attempts"`
+	Encoding      string `json:"encoding,omitempty"` // Detected charset of TXT/MD uploads
+	ContentHash   string `json:"content_hash,omitempty"` // SHA-256 of the uploaded file
+	Duplicate     bool   `json:"duplicate,omitempty"`    // Existing document returned for a re-upload
+
+	CorrectedText *string `json:"corrected_text,omitempty"` // Latest teacher correction of parsed_text
+	TextRevision  int     `json:"text_revision"`            // 0 while parsed_text is uncorrected
+
+	Stats *DocumentStatsResponse `json:"stats,omitempty"` // Only for parsed documents
+}
+
+// DocumentStatsResponse represents statistics of the text used for generation
+type DocumentStatsResponse struct {
+	WordCount        int            `json:"word_count"`
+	CharCount        int            `json:"char_count"`
+	SectionCount     int            `json:"section_count"`
+	Language         string         `json:"language"`          // ru, en, mixed or unknown
+	ReadabilityScore float64        `json:"readability_score"` // Flesch reading ease, 0-100, higher is easier
+	TokenEstimates   map[string]int `json:"token_estimates"`   // Estimated prompt tokens per LLM provider
 }
 
 // DocumentListResponse represents list of documents
@@ -24,6 +45,64 @@ type DocumentListResponse struct {
 	PageSize  int                      `json:"page_size"`
 }
 
+// Bulk upload entry statuses
+const (
+	BulkEntryCreated   = "created"
+	BulkEntryDuplicate = "duplicate" // Same file already uploaded; the existing document is returned
+	BulkEntrySkipped   = "skipped"   // Not a document: folder, unsupported type, system file
+	BulkEntryFailed    = "failed"
+)
+
+// BulkUploadEntryResponse represents the outcome for one file of a ZIP bundle
+type BulkUploadEntryResponse struct {
+	Path      string                  `json:"path"` // Path inside the archive
+	Status    string                  `json:"status"`
+	Reason    string                  `json:"reason,omitempty"`     // Why the file was skipped or failed
+	ErrorCode string                  `json:"error_code,omitempty"` // Only for failed files
+	Document  *DocumentUploadResponse `json:"document,omitempty"`
+}
+
+// BulkUploadResponse represents the result of a ZIP bundle upload
+type BulkUploadResponse struct {
+	Created    int                       `json:"created"`
+	Duplicates int                       `json:"duplicates"`
+	Skipped    int                       `json:"skipped"`
+	Failed     int                       `json:"failed"`
+	Entries    []BulkUploadEntryResponse `json:"entries"`
+}
+
+// CreateUploadSessionRequest starts a resumable upload
+type CreateUploadSessionRequest struct {
+	FileName    string `json:"file_name" validate:"required"`
+	Title       string `json:"title"`
+	Size        int64  `json:"size" validate:"required,gt=0"`
+	ChunkSize   int64  `json:"chunk_size"`   // Defaults to the largest allowed chunk
+	Checksum    string `json:"checksum"`     // SHA-256 of the whole file, hex; may be sent on completion instead
+	ContentType string `json:"content_type"`
+}
+
+// CompleteUploadSessionRequest finishes a resumable upload
+type CompleteUploadSessionRequest struct {
+	Checksum    string `json:"checksum"`     // Required unless given when the session was created
+	OnDuplicate string `json:"on_duplicate"` // "reject" fails with 409 when the same file was already uploaded
+}
+
+// UploadSessionResponse represents the state of a resumable upload
+type UploadSessionResponse struct {
+	ID             string  `json:"id"`
+	FileName       string  `json:"file_name"`
+	Title          string  `json:"title,omitempty"`
+	Size           int64   `json:"size"`
+	ChunkSize      int64   `json:"chunk_size"`
+	Offset         int64   `json:"offset"` // Bytes received; the next chunk starts here
+	ChunkCount     int     `json:"chunk_count"`
+	ReceivedChunks int     `json:"received_chunks"`
+	Status         string  `json:"status"`
+	DocumentID     *string `json:"document_id,omitempty"` // Set once completed
+	ExpiresAt      string  `json:"expires_at"`
+	CreatedAt      string  `json:"created_at"`
+}
+
 // ParseDocumentRequest represents document parsing request
 type ParseDocumentRequest struct {
 	DocumentID string `json:"document_id" validate:"required,uuid"`
@@ -36,3 +115,43 @@ type ParseDocumentResponse struct {
 	Status      string `json:"status"`
 	TextPreview string `json:"text_preview"`
 }
+
+// ParseJobResponse represents a queued background parsing job
+type ParseJobResponse struct {
+	ID            string `json:"id"`
+	Status        string `json:"status"`
+	ParseProgress int    `json:"parse_progress"`
+	Message       string `json:"message"`
+}
+
+// UpdateDocumentTextRequest represents a corrected version of the parsed text
+type UpdateDocumentTextRequest struct {
+	Text string `json:"text" validate:"required"`
+}
+
+// DocumentTextRevisionResponse represents one saved correction of document text
+type DocumentTextRevisionResponse struct {
+	ID         string `json:"id"`
+	DocumentID string `json:"document_id"`
+	UserID     string `json:"user_id"`
+	Revision   int    `json:"revision"`
+	Text       string `json:"text"`
+	CreatedAt  string `json:"created_at"`
+}
+
+// DocumentTextRevisionListResponse represents correction history, newest first
+type DocumentTextRevisionListResponse struct {
+	Revisions []DocumentTextRevisionResponse `json:"revisions"`
+	Total     int                            `json:"total"`
+}
+
+// DocumentTextPageResponse represents a page of document text; offsets count characters, not bytes
+type DocumentTextPageResponse struct {
+	ID           string `json:"id"`
+	TextRevision int    `json:"text_revision"` // 0 when the page comes from the original parsed text
+	Offset       int    `json:"offset"`
+	Limit        int    `json:"limit"`
+	Total        int    `json:"total"`
+	Text         string `json:"text"`
+	HasMore      bool   `json:"has_more"`
+}
@@ -0,0 +1,120 @@
+package dto
+
+// CreateCategoryRequest represents question bank category creation request
+type CreateCategoryRequest struct {
+	Name     string  `json:"name" validate:"required,max=255"`
+	ParentID *string `json:"parent_id" validate:"omitempty,uuid"` // Omit for a top-level category
+}
+
+// UpdateCategoryRequest represents question bank category update request
+type UpdateCategoryRequest struct {
+	Name     string  `json:"name" validate:"omitempty,max=255"`
+	ParentID *string `json:"parent_id"` // Omit to keep the parent, empty string moves the category to the top level
+}
+
+// CategoryResponse represents a question bank category with its subcategories
+type CategoryResponse struct {
+	ID        string             `json:"id"`
+	ParentID  *string            `json:"parent_id,omitempty"`
+	Name      string             `json:"name"`
+	CreatedAt string             `json:"created_at"`
+	Children  []CategoryResponse `json:"children"`
+}
+
+// CategoryListResponse represents the category tree of the question bank
+type CategoryListResponse struct {
+	Categories []CategoryResponse `json:"categories"`
+}
+
+// BankQuestionRequest represents question bank question creation and update request
+type BankQuestionRequest struct {
+	QuestionText  string                `json:"question_text" validate:"required,min=3"`
+	QuestionType  string                `json:"question_type" validate:"required,oneof=single_choice multiple_choice true_false short_answer matching ordering numerical cloze essay"`
+	Difficulty    string                `json:"difficulty" validate:"omitempty,oneof=easy medium hard"`
+	Points        *float64              `json:"points" validate:"omitempty,gt=0"`
+	CaseSensitive bool                  `json:"case_sensitive"`                        // Short answer questions only
+	CategoryID    *string               `json:"category_id" validate:"omitempty,uuid"` // Omit for an uncategorized question
+	Tags          []string              `json:"tags"`
+	Answers       []CreateAnswerRequest `json:"answers"` // Omit for cloze questions
+}
+
+// BankQuestionResponse represents a question bank question
+type BankQuestionResponse struct {
+	ID            string      `json:"id"`
+	CategoryID    *string     `json:"category_id,omitempty"`
+	QuestionText  string      `json:"question_text"`
+	QuestionType  string      `json:"question_type"`
+	Difficulty    string      `json:"difficulty"`
+	Points        float64     `json:"points"`
+	CaseSensitive bool        `json:"case_sensitive,omitempty"` // Short answer questions only
+	Tags          []string    `json:"tags"`
+	Answers       []AnswerDTO `json:"answers"`
+	TestIDs       []string    `json:"test_ids,omitempty"` // Tests the question was added to; only for a single question
+	CreatedAt     string      `json:"created_at"`
+	UpdatedAt     string      `json:"updated_at"`
+}
+
+// BankQuestionListResponse represents a page of question bank questions
+type BankQuestionListResponse struct {
+	Questions []BankQuestionResponse `json:"questions"`
+	Total     int64                  `json:"total"`
+	Page      int                    `json:"page"`
+	PageSize  int                    `json:"page_size"`
+}
+
+// SaveToBankRequest represents a request to copy test questions into the question bank
+type SaveToBankRequest struct {
+	TestID      string   `json:"test_id" validate:"required,uuid"`
+	QuestionIDs []string `json:"question_ids" validate:"omitempty,dive,uuid"` // Omit to save every question of the test
+	CategoryID  *string  `json:"category_id" validate:"omitempty,uuid"`
+	Tags        []string `json:"tags"`
+}
+
+// AddBankQuestionsRequest represents a request to add bank questions to a test
+type AddBankQuestionsRequest struct {
+	BankQuestionIDs []string `json:"bank_question_ids" validate:"required,min=1,dive,uuid"` // Appended to the test in this order
+}
+
+// TagListResponse represents the tags of the question bank
+type TagListResponse struct {
+	Tags []string `json:"tags"`
+}
+
+// BlueprintRuleRequest represents one rule of a test blueprint
+type BlueprintRuleRequest struct {
+	Count        int      `json:"count" validate:"required,min=1"`
+	Difficulty   string   `json:"difficulty" validate:"omitempty,oneof=easy medium hard"`
+	QuestionType string   `json:"question_type" validate:"omitempty,oneof=single_choice multiple_choice true_false short_answer matching ordering numerical cloze essay"`
+	CategoryID   *string  `json:"category_id" validate:"omitempty,uuid"` // Overrides the blueprint category
+	Tags         []string `json:"tags"`                                  // Required in addition to the blueprint tags
+}
+
+// AssembleTestRequest represents a request to assemble a test from the question bank by a blueprint
+type AssembleTestRequest struct {
+	Title                string                 `json:"title" validate:"required,min=3"`
+	Description          string                 `json:"description"`
+	CategoryID           *string                `json:"category_id" validate:"omitempty,uuid"`
+	IncludeSubcategories *bool                  `json:"include_subcategories"` // Defaults to true
+	Tags                 []string               `json:"tags"`
+	Rules                []BlueprintRuleRequest `json:"rules" validate:"required,min=1,max=20,dive"`
+	MaxPerType           map[string]int         `json:"max_per_type"`                          // e.g. {"true_false": 3}
+	DocumentID           *string                `json:"document_id" validate:"omitempty,uuid"` // Linked to the test; required for top_up
+	TopUp                bool                   `json:"top_up"`                                // Generate the missing questions with LLM
+	LLMProvider          string                 `json:"llm_provider" validate:"omitempty,oneof=perplexity openai yandexgpt"`
+}
+
+// BlueprintRuleReport tells how a blueprint rule was filled
+type BlueprintRuleReport struct {
+	Rule      int `json:"rule"` // 1-based position of the rule in the request
+	Requested int `json:"requested"`
+	Drawn     int `json:"drawn"`     // Taken from the question bank
+	Generated int `json:"generated"` // Generated with LLM
+	Shortfall int `json:"shortfall"` // Still missing
+}
+
+// AssembleTestResponse represents an assembled test with the report per blueprint rule
+type AssembleTestResponse struct {
+	Test      TestResponse          `json:"test"`
+	Rules     []BlueprintRuleReport `json:"rules"`
+	Shortfall int                   `json:"shortfall"` // Missing questions over all rules
+}
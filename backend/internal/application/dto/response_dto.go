@@ -66,6 +66,14 @@ const (
 	ErrCodeDocumentInUse     = "DOCUMENT_IN_USE"
 	ErrCodeInvalidDocumentID = "INVALID_DOCUMENT_ID"
 	ErrCodeDocumentNotParsed = "DOCUMENT_NOT_PARSED"
+	ErrCodeParseQueueFull    = "PARSE_QUEUE_FULL"
+	ErrCodeUnsafeArchive     = "UNSAFE_ARCHIVE"
+
+	// Resumable upload errors
+	ErrCodeUploadExpired        = "UPLOAD_SESSION_EXPIRED"
+	ErrCodeUploadOffsetMismatch = "UPLOAD_OFFSET_MISMATCH"
+	ErrCodeUploadIncomplete     = "UPLOAD_INCOMPLETE"
+	ErrCodeChecksumMismatch     = "CHECKSUM_MISMATCH"
 
 	// Test errors
 	ErrCodeTestNotFound        = "TEST_NOT_FOUND"
@@ -77,6 +85,13 @@ const (
 	ErrCodeMoodleSyncFailed    = "MOODLE_SYNC_FAILED"
 	ErrCodeMoodleUploadFailed  = "MOODLE_UPLOAD_FAILED"
 	ErrCodeMoodleNotConnected  = "MOODLE_NOT_CONNECTED"
+	ErrCodeTestLocked          = "TEST_LOCKED"
+	ErrCodeTestNotPublishable  = "TEST_NOT_PUBLISHABLE"
+	ErrCodeInvalidTestStatus   = "INVALID_TEST_STATUS"
+
+	// Question bank errors
+	ErrCodeCategoryNotFound     = "CATEGORY_NOT_FOUND"
+	ErrCodeBankQuestionNotFound = "BANK_QUESTION_NOT_FOUND"
 
 	// Pagination errors
 	ErrCodeInvalidLimit  = "INVALID_LIMIT"
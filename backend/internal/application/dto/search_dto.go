@@ -0,0 +1,22 @@
+package dto
+
+// SearchResultResponse represents a single full-text search hit
+type SearchResultResponse struct {
+	Type      string  `json:"type"` // document, test or question
+	ID        string  `json:"id"`
+	UserID    string  `json:"user_id"`
+	TestID    *string `json:"test_id,omitempty"` // Parent test of a question
+	Title     string  `json:"title"`             // For questions, the title of the parent test
+	Snippet   string  `json:"snippet"`           // HTML-escaped fragment with matches wrapped in <mark>
+	Rank      float64 `json:"rank"`
+	CreatedAt string  `json:"created_at"`
+}
+
+// SearchResponse represents paginated search results ordered by relevance
+type SearchResponse struct {
+	Query    string                 `json:"query"`
+	Results  []SearchResultResponse `json:"results"`
+	Total    int64                  `json:"total"`
+	Page     int                    `json:"page"`
+	PageSize int                    `json:"page_size"`
+}
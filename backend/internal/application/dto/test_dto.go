@@ -15,19 +15,60 @@ type UpdateTestRequest struct {
 
 // UpdateQuestionRequest represents question update request
 type UpdateQuestionRequest struct {
-	QuestionText string              `json:"question_text" validate:"omitempty,min=3"`
-	QuestionType string              `json:"question_type" validate:"omitempty,oneof=single_choice multiple_choice true_false short_answer"`
-	Difficulty   string              `json:"difficulty" validate:"omitempty,oneof=easy medium hard"`
-	Points       *float64            `json:"points" validate:"omitempty,gt=0"`
-	Answers      []UpdateAnswerRequest `json:"answers"`
+	QuestionText  string                `json:"question_text" validate:"omitempty,min=3"`
+	QuestionType  string                `json:"question_type" validate:"omitempty,oneof=single_choice multiple_choice true_false short_answer matching ordering numerical cloze essay"`
+	Difficulty    string                `json:"difficulty" validate:"omitempty,oneof=easy medium hard"`
+	Points        *float64              `json:"points" validate:"omitempty,gt=0"`
+	CaseSensitive *bool                 `json:"case_sensitive"` // Short answer questions only
+	Answers       []UpdateAnswerRequest `json:"answers"`
 }
 
 // UpdateAnswerRequest represents answer update request
 type UpdateAnswerRequest struct {
-	ID         *string `json:"id"`          // If nil, create new answer
-	AnswerText string  `json:"answer_text" validate:"required"`
-	IsCorrect  bool    `json:"is_correct"`
-	OrderNum   int     `json:"order_num"`
+	ID         *string  `json:"id"` // If nil, create new answer
+	AnswerText string   `json:"answer_text" validate:"required"`
+	IsCorrect  bool     `json:"is_correct"`
+	MatchText  string   `json:"match_text"`                           // Matching questions only
+	Tolerance  *float64 `json:"tolerance" validate:"omitempty,gte=0"` // Numerical questions only
+	Weight     *float64 `json:"weight" validate:"omitempty,gte=-100,lte=100"` // Choice and short answer questions only
+	OrderNum   int      `json:"order_num"`
+}
+
+// CreateQuestionRequest represents manual question creation request
+type CreateQuestionRequest struct {
+	QuestionText  string                `json:"question_text" validate:"required,min=3"`
+	QuestionType  string                `json:"question_type" validate:"required,oneof=single_choice multiple_choice true_false short_answer matching ordering numerical cloze essay"`
+	Difficulty    string                `json:"difficulty" validate:"omitempty,oneof=easy medium hard"`
+	Points        *float64              `json:"points" validate:"omitempty,gt=0"`
+	OrderNum      int                   `json:"order_num"`      // Position in the test; 0 or out of range appends
+	CaseSensitive bool                  `json:"case_sensitive"` // Short answer questions: variants must match letter case
+	Answers       []CreateAnswerRequest `json:"answers"`        // Omit for cloze questions, whose answers are embedded in the text
+}
+
+// CreateAnswerRequest represents an answer of a new question
+type CreateAnswerRequest struct {
+	AnswerText string   `json:"answer_text" validate:"required"`
+	IsCorrect  bool     `json:"is_correct"`
+	MatchText  string   `json:"match_text"`                           // Matching questions: the item AnswerText is paired with
+	Tolerance  *float64 `json:"tolerance" validate:"omitempty,gte=0"` // Numerical questions: accepted absolute error
+	Weight     *float64 `json:"weight" validate:"omitempty,gte=-100,lte=100"` // Choice and short answer questions: grade percentage overriding the default
+}
+
+// ReorderQuestionsRequest represents question reordering request
+type ReorderQuestionsRequest struct {
+	QuestionIDs []string `json:"question_ids" validate:"required,min=1,dive,uuid"` // Every question of the test in the new order
+}
+
+// CloneTestRequest represents test cloning request
+type CloneTestRequest struct {
+	Title string `json:"title" validate:"omitempty,min=3"` // Defaults to the source title with a " (copy)" suffix
+}
+
+// ImportTestRequest represents test import request in the JSON export format
+type ImportTestRequest struct {
+	Title       string        `json:"title" validate:"required,min=3"`
+	Description string        `json:"description"`
+	Questions   []QuestionDTO `json:"questions" validate:"required,min=1"` // IDs are ignored; order follows the array
 }
 
 // GenerateTestRequest represents test generation request
@@ -35,43 +76,50 @@ type GenerateTestRequest struct {
 	DocumentID    string   `json:"document_id" validate:"required,uuid"`
 	Title         string   `json:"title" validate:"required,min=3"`
 	NumQuestions  int      `json:"num_questions" validate:"required,min=1,max=50"`
-	QuestionTypes []string `json:"question_types"`
+	QuestionTypes []string `json:"question_types"` // Defaults to single_choice
 	Difficulty    string   `json:"difficulty" validate:"required,oneof=easy medium hard"`
 	LLMProvider   string   `json:"llm_provider" validate:"omitempty,oneof=perplexity openai yandexgpt"`
 }
 
 // TestResponse represents test response
 type TestResponse struct {
-	ID             string          `json:"id"`
-	UserID         string          `json:"user_id"`
-	UserName       *string         `json:"user_name,omitempty"`  // Only for admin
-	UserEmail      *string         `json:"user_email,omitempty"` // Only for admin
-	Title          string          `json:"title"`
-	Description    string          `json:"description"`
-	TotalQuestions int             `json:"total_questions"`
-	Status         string          `json:"status"`
-	MoodleSynced   bool            `json:"moodle_synced"`
-	CreatedAt      string          `json:"created_at"`
-	Questions      []QuestionDTO   `json:"questions,omitempty"`
+	ID                string        `json:"id"`
+	UserID            string        `json:"user_id"`
+	UserName          *string       `json:"user_name,omitempty"`  // Only for admin
+	UserEmail         *string       `json:"user_email,omitempty"` // Only for admin
+	Title             string        `json:"title"`
+	Description       string        `json:"description"`
+	TotalQuestions    int           `json:"total_questions"`
+	Status            string        `json:"status"`
+	MoodleSynced      bool          `json:"moodle_synced"`
+	Version           int           `json:"version"`
+	PreviousVersionID *string       `json:"previous_version_id,omitempty"` // Test this version was created from
+	CreatedAt         string        `json:"created_at"`
+	Questions         []QuestionDTO `json:"questions,omitempty"`
+	Warnings          []string      `json:"warnings,omitempty"` // Only for generated tests
 }
 
 // QuestionDTO represents question data
 type QuestionDTO struct {
-	ID           string      `json:"id"`
-	QuestionText string      `json:"question_text"`
-	QuestionType string      `json:"question_type"`
-	Difficulty   string      `json:"difficulty"`
-	Points       float64     `json:"points"`
-	OrderNum     int         `json:"order_num"`
-	Answers      []AnswerDTO `json:"answers"`
+	ID            string      `json:"id"`
+	QuestionText  string      `json:"question_text"`
+	QuestionType  string      `json:"question_type"`
+	Difficulty    string      `json:"difficulty"`
+	Points        float64     `json:"points"`
+	CaseSensitive bool        `json:"case_sensitive,omitempty"` // Short answer questions only
+	OrderNum      int         `json:"order_num"`
+	Answers       []AnswerDTO `json:"answers"`
 }
 
 // AnswerDTO represents answer data
 type AnswerDTO struct {
-	ID         string `json:"id"`
-	AnswerText string `json:"answer_text"`
-	IsCorrect  bool   `json:"is_correct"`
-	OrderNum   int    `json:"order_num"`
+	ID         string   `json:"id"`
+	AnswerText string   `json:"answer_text"`
+	IsCorrect  bool     `json:"is_correct"`
+	MatchText  string   `json:"match_text,omitempty"` // Matching questions only
+	Tolerance  *float64 `json:"tolerance,omitempty"`  // Numerical questions only
+	Weight     *float64 `json:"weight,omitempty"`     // Choice and short answer questions only
+	OrderNum   int      `json:"order_num"`
 }
 
 // TestListResponse represents list of tests
@@ -89,6 +137,37 @@ type GenerateQuestionsResponse struct {
 	Questions []QuestionDTO `json:"questions"`
 }
 
+// AssignStudentsRequest represents test assignment request
+type AssignStudentsRequest struct {
+	StudentIDs []string `json:"student_ids" validate:"required,min=1,dive,uuid"` // Users with the student role
+}
+
+// TestAssignmentResponse represents a student a test is assigned to
+type TestAssignmentResponse struct {
+	StudentID   string `json:"student_id"`
+	StudentName string `json:"student_name"`
+	Email       string `json:"email"`
+	AssignedAt  string `json:"assigned_at"`
+}
+
+// TestAssignmentListResponse represents the students a test is assigned to
+type TestAssignmentListResponse struct {
+	Assignments []TestAssignmentResponse `json:"assignments"`
+}
+
+// PublishProblemDTO represents a problem that keeps a test from being published
+type PublishProblemDTO struct {
+	QuestionID string `json:"question_id,omitempty"` // Empty for problems of the whole test
+	OrderNum   int    `json:"order_num,omitempty"`
+	Message    string `json:"message"`
+}
+
+// PublishCheckResponse represents a publish request rejected by the pre-publish checks
+type PublishCheckResponse struct {
+	ErrorResponse
+	Problems []PublishProblemDTO `json:"problems"`
+}
+
 // SyncMoodleRequest represents Moodle sync request
 type SyncMoodleRequest struct {
 	CourseName string `json:"course_name" validate:"required"`
@@ -46,7 +46,9 @@ func (uc *UploadUseCase) Execute(ctx context.Context, params UploadParams) (*ent
 	}
 
 	// Validate file type
-	validTypes := map[string]bool{"pdf": true, "docx": true, "pptx": true, "txt": true, "md": true}
+	validTypes := map[string]bool{"pdf": true, "docx": true, "pptx": true, "txt": true, "md": true, "srt": true, "vtt": true,
+		"ipynb": true, "go": true, "py": true, "java": true,
+		"odt": true, "odp": true, "rtf": true, "html": true, "epub": true}
 	if !validTypes[params.FileType] {
 		return nil, fmt.Errorf("unsupported file type: %s", params.FileType)
 	}
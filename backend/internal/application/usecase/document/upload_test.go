@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -68,6 +69,62 @@ func (m *MockDocumentRepository) CountAll(ctx context.Context) (int64, error) {
 	return args.Get(0).(int64), args.Error(1)
 }
 
+func (m *MockDocumentRepository) FindByStatus(ctx context.Context, status entity.DocumentStatus, limit int) ([]*entity.Document, error) {
+	args := m.Called(ctx, status, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.Document), args.Error(1)
+}
+
+func (m *MockDocumentRepository) ClaimForParsing(ctx context.Context, id uuid.UUID) (bool, error) {
+	args := m.Called(ctx, id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockDocumentRepository) UpdateParseState(ctx context.Context, document *entity.Document) error {
+	args := m.Called(ctx, document)
+	return args.Error(0)
+}
+
+func (m *MockDocumentRepository) ResetStaleParsing(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockDocumentRepository) FindByContentHash(ctx context.Context, contentHash string) ([]*entity.Document, error) {
+	args := m.Called(ctx, contentHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.Document), args.Error(1)
+}
+
+func (m *MockDocumentRepository) CountByFilePath(ctx context.Context, filePath string) (int64, error) {
+	args := m.Called(ctx, filePath)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockDocumentRepository) FindDeletedBefore(ctx context.Context, before time.Time, limit, offset int) ([]*entity.Document, error) {
+	args := m.Called(ctx, before, limit, offset)
+	return args.Get(0).([]*entity.Document), args.Error(1)
+}
+
+func (m *MockDocumentRepository) CountRetainedByFilePath(ctx context.Context, filePath string, deletedBefore time.Time) (int64, error) {
+	args := m.Called(ctx, filePath, deletedBefore)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockDocumentRepository) MarkFilePurged(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockDocumentRepository) FindFilePaths(ctx context.Context) ([]string, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]string), args.Error(1)
+}
+
 func TestUploadUseCase_Execute(t *testing.T) {
 	mockRepo := new(MockDocumentRepository)
 	tempDir := filepath.Join(os.TempDir(), "test-uploads")
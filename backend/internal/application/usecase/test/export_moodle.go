@@ -5,7 +5,6 @@ import (
 	"fmt"
 
 	"github.com/google/uuid"
-	"github.com/shester1kov/testgen-backend/internal/domain/entity"
 	"github.com/shester1kov/testgen-backend/internal/domain/repository"
 	"github.com/shester1kov/testgen-backend/internal/infrastructure/moodle"
 )
@@ -14,7 +13,6 @@ import (
 type ExportMoodleUseCase struct {
 	testRepo     repository.TestRepository
 	questionRepo repository.QuestionRepository
-	answerRepo   repository.AnswerRepository
 	xmlExporter  *moodle.MoodleXMLExporter
 }
 
@@ -22,13 +20,11 @@ type ExportMoodleUseCase struct {
 func NewExportMoodleUseCase(
 	testRepo repository.TestRepository,
 	questionRepo repository.QuestionRepository,
-	answerRepo repository.AnswerRepository,
 	xmlExporter *moodle.MoodleXMLExporter,
 ) *ExportMoodleUseCase {
 	return &ExportMoodleUseCase{
 		testRepo:     testRepo,
 		questionRepo: questionRepo,
-		answerRepo:   answerRepo,
 		xmlExporter:  xmlExporter,
 	}
 }
@@ -47,7 +43,7 @@ func (uc *ExportMoodleUseCase) Execute(ctx context.Context, testID uuid.UUID, us
 	}
 
 	// Get questions
-	questions, err := uc.questionRepo.FindByTestID(ctx, testID)
+	questions, err := uc.questionRepo.FindByTestIDWithAnswers(ctx, testID)
 	if err != nil {
 		return "", fmt.Errorf("failed to retrieve questions: %w", err)
 	}
@@ -56,18 +52,8 @@ func (uc *ExportMoodleUseCase) Execute(ctx context.Context, testID uuid.UUID, us
 		return "", fmt.Errorf("test has no questions")
 	}
 
-	// Get answers for each question
-	answersMap := make(map[string][]*entity.Answer)
-	for _, q := range questions {
-		answers, err := uc.answerRepo.FindByQuestionID(ctx, q.ID)
-		if err != nil {
-			return "", fmt.Errorf("failed to retrieve answers: %w", err)
-		}
-		answersMap[q.ID.String()] = answers
-	}
-
 	// Export to XML
-	xmlContent, err := uc.xmlExporter.Export(test, questions, answersMap)
+	xmlContent, err := uc.xmlExporter.Export(test, questions, moodle.AnswersByQuestion(questions))
 	if err != nil {
 		return "", fmt.Errorf("failed to export XML: %w", err)
 	}
@@ -23,20 +23,11 @@ func (m *mockTestRepository) FindByID(ctx context.Context, id uuid.UUID) (*entit
 
 type mockQuestionRepository struct {
 	repository.QuestionRepository
-	findByTestID func(ctx context.Context, testID uuid.UUID) ([]*entity.Question, error)
+	findByTestIDWithAnswers func(ctx context.Context, testID uuid.UUID) ([]*entity.Question, error)
 }
 
-func (m *mockQuestionRepository) FindByTestID(ctx context.Context, testID uuid.UUID) ([]*entity.Question, error) {
-	return m.findByTestID(ctx, testID)
-}
-
-type mockAnswerRepository struct {
-	repository.AnswerRepository
-	findByQuestionID func(ctx context.Context, questionID uuid.UUID) ([]*entity.Answer, error)
-}
-
-func (m *mockAnswerRepository) FindByQuestionID(ctx context.Context, questionID uuid.UUID) ([]*entity.Answer, error) {
-	return m.findByQuestionID(ctx, questionID)
+func (m *mockQuestionRepository) FindByTestIDWithAnswers(ctx context.Context, testID uuid.UUID) ([]*entity.Question, error) {
+	return m.findByTestIDWithAnswers(ctx, testID)
 }
 
 func TestExportMoodleUseCase(t *testing.T) {
@@ -45,8 +36,8 @@ func TestExportMoodleUseCase(t *testing.T) {
 	questionID := uuid.New()
 
 	sampleTest := &entity.Test{ID: testID, UserID: userID}
-	questions := []*entity.Question{{ID: questionID, QuestionText: "Q1", QuestionType: entity.QuestionTypeSingleChoice, Points: 1, OrderNum: 1}}
-	answers := []*entity.Answer{{ID: uuid.New(), QuestionID: questionID, AnswerText: "A1", IsCorrect: true, OrderNum: 1}}
+	answers := []entity.Answer{{ID: uuid.New(), QuestionID: questionID, AnswerText: "A1", IsCorrect: true, OrderNum: 1}}
+	questions := []*entity.Question{{ID: questionID, QuestionText: "Q1", QuestionType: entity.QuestionTypeSingleChoice, Points: 1, OrderNum: 1, Answers: answers}}
 
 	testCases := []struct {
 		name        string
@@ -60,12 +51,9 @@ func TestExportMoodleUseCase(t *testing.T) {
 					testRepo: &mockTestRepository{findByID: func(ctx context.Context, id uuid.UUID) (*entity.Test, error) {
 						return sampleTest, nil
 					}},
-					questionRepo: &mockQuestionRepository{findByTestID: func(ctx context.Context, testID uuid.UUID) ([]*entity.Question, error) {
+					questionRepo: &mockQuestionRepository{findByTestIDWithAnswers: func(ctx context.Context, testID uuid.UUID) ([]*entity.Question, error) {
 						return questions, nil
 					}},
-					answerRepo: &mockAnswerRepository{findByQuestionID: func(ctx context.Context, questionID uuid.UUID) ([]*entity.Answer, error) {
-						return answers, nil
-					}},
 					xmlExporter: moodle.NewMoodleXMLExporter(),
 				}
 			},
@@ -77,8 +65,7 @@ func TestExportMoodleUseCase(t *testing.T) {
 					testRepo: &mockTestRepository{findByID: func(ctx context.Context, id uuid.UUID) (*entity.Test, error) {
 						return nil, errors.New("missing")
 					}},
-					questionRepo: &mockQuestionRepository{findByTestID: func(context.Context, uuid.UUID) ([]*entity.Question, error) { return nil, nil }},
-					answerRepo:   &mockAnswerRepository{findByQuestionID: func(context.Context, uuid.UUID) ([]*entity.Answer, error) { return nil, nil }},
+					questionRepo: &mockQuestionRepository{findByTestIDWithAnswers: func(context.Context, uuid.UUID) ([]*entity.Question, error) { return nil, nil }},
 					xmlExporter:  moodle.NewMoodleXMLExporter(),
 				}
 			},
@@ -93,8 +80,7 @@ func TestExportMoodleUseCase(t *testing.T) {
 					testRepo: &mockTestRepository{findByID: func(context.Context, uuid.UUID) (*entity.Test, error) {
 						return &wrongOwner, nil
 					}},
-					questionRepo: &mockQuestionRepository{findByTestID: func(context.Context, uuid.UUID) ([]*entity.Question, error) { return questions, nil }},
-					answerRepo:   &mockAnswerRepository{findByQuestionID: func(context.Context, uuid.UUID) ([]*entity.Answer, error) { return answers, nil }},
+					questionRepo: &mockQuestionRepository{findByTestIDWithAnswers: func(context.Context, uuid.UUID) ([]*entity.Question, error) { return questions, nil }},
 					xmlExporter:  moodle.NewMoodleXMLExporter(),
 				}
 			},
@@ -105,10 +91,9 @@ func TestExportMoodleUseCase(t *testing.T) {
 			setup: func() *ExportMoodleUseCase {
 				return &ExportMoodleUseCase{
 					testRepo: &mockTestRepository{findByID: func(context.Context, uuid.UUID) (*entity.Test, error) { return sampleTest, nil }},
-					questionRepo: &mockQuestionRepository{findByTestID: func(context.Context, uuid.UUID) ([]*entity.Question, error) {
+					questionRepo: &mockQuestionRepository{findByTestIDWithAnswers: func(context.Context, uuid.UUID) ([]*entity.Question, error) {
 						return nil, errors.New("boom")
 					}},
-					answerRepo:  &mockAnswerRepository{findByQuestionID: func(context.Context, uuid.UUID) ([]*entity.Answer, error) { return nil, nil }},
 					xmlExporter: moodle.NewMoodleXMLExporter(),
 				}
 			},
@@ -119,37 +104,21 @@ func TestExportMoodleUseCase(t *testing.T) {
 			setup: func() *ExportMoodleUseCase {
 				return &ExportMoodleUseCase{
 					testRepo: &mockTestRepository{findByID: func(context.Context, uuid.UUID) (*entity.Test, error) { return sampleTest, nil }},
-					questionRepo: &mockQuestionRepository{findByTestID: func(context.Context, uuid.UUID) ([]*entity.Question, error) {
+					questionRepo: &mockQuestionRepository{findByTestIDWithAnswers: func(context.Context, uuid.UUID) ([]*entity.Question, error) {
 						return []*entity.Question{}, nil
 					}},
-					answerRepo:  &mockAnswerRepository{findByQuestionID: func(context.Context, uuid.UUID) ([]*entity.Answer, error) { return nil, nil }},
 					xmlExporter: moodle.NewMoodleXMLExporter(),
 				}
 			},
 			expectedErr: "test has no questions",
 		},
-		{
-			name: "errors when answer retrieval fails",
-			setup: func() *ExportMoodleUseCase {
-				return &ExportMoodleUseCase{
-					testRepo:     &mockTestRepository{findByID: func(context.Context, uuid.UUID) (*entity.Test, error) { return sampleTest, nil }},
-					questionRepo: &mockQuestionRepository{findByTestID: func(context.Context, uuid.UUID) ([]*entity.Question, error) { return questions, nil }},
-					answerRepo: &mockAnswerRepository{findByQuestionID: func(context.Context, uuid.UUID) ([]*entity.Answer, error) {
-						return nil, errors.New("answers error")
-					}},
-					xmlExporter: moodle.NewMoodleXMLExporter(),
-				}
-			},
-			expectedErr: "failed to retrieve answers",
-		},
 		{
 			name: "errors when exporter fails",
 			setup: func() *ExportMoodleUseCase {
-				badQuestion := []*entity.Question{{ID: questionID, QuestionText: "Q1", QuestionType: entity.QuestionType(""), Points: 1}}
+				badQuestion := []*entity.Question{{ID: questionID, QuestionText: "Q1", QuestionType: entity.QuestionType(""), Points: 1, Answers: answers}}
 				return &ExportMoodleUseCase{
 					testRepo:     &mockTestRepository{findByID: func(context.Context, uuid.UUID) (*entity.Test, error) { return sampleTest, nil }},
-					questionRepo: &mockQuestionRepository{findByTestID: func(context.Context, uuid.UUID) ([]*entity.Question, error) { return badQuestion, nil }},
-					answerRepo:   &mockAnswerRepository{findByQuestionID: func(context.Context, uuid.UUID) ([]*entity.Answer, error) { return answers, nil }},
+					questionRepo: &mockQuestionRepository{findByTestIDWithAnswers: func(context.Context, uuid.UUID) ([]*entity.Question, error) { return badQuestion, nil }},
 					xmlExporter:  moodle.NewMoodleXMLExporter(),
 				}
 			},
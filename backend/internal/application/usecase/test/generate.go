@@ -65,7 +65,7 @@ func (uc *GenerateUseCase) Execute(ctx context.Context, params GenerateParams) (
 	// Create LLM context and generate questions
 	llmContext := llm.NewLLMContext(strategy)
 	questions, err := llmContext.GenerateQuestions(ctx, llm.GenerationParams{
-		Text:         document.ParsedText,
+		Text:         document.EffectiveText(),
 		NumQuestions: params.NumQuestions,
 		Difficulty:   params.Difficulty,
 	})
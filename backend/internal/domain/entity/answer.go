@@ -11,6 +11,9 @@ type Answer struct {
 	QuestionID uuid.UUID `json:"question_id" gorm:"type:uuid;not null;index"`
 	AnswerText string    `json:"answer_text" gorm:"type:text;not null"`
 	IsCorrect  bool      `json:"is_correct" gorm:"default:false"`
+	MatchText  string    `json:"match_text,omitempty" gorm:"type:text"`            // Matching questions: the item AnswerText is paired with
+	Tolerance  *float64  `json:"tolerance,omitempty" gorm:"type:double precision"` // Numerical questions: accepted absolute error
+	Weight     *float64  `json:"weight,omitempty" gorm:"type:double precision"`    // Choice and short answer questions: grade percentage overriding the default
 	OrderNum   int       `json:"order_num" gorm:"not null"`
 	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime"`
 
@@ -0,0 +1,98 @@
+package entity
+
+import (
+	"fmt"
+	"math"
+)
+
+// AnswerWeights are the grade percentages an answer may carry, in descending
+// order. They are the fractions Moodle accepts, so weighted questions export
+// without being rounded; negative weights use the same magnitudes.
+var AnswerWeights = []float64{
+	100, 90, 83.33333, 80, 75, 70, 66.66667, 60, 50, 40, 33.33333,
+	30, 25, 20, 16.66667, 14.28571, 12.5, 11.11111, 10, 5, 0,
+}
+
+// weightEpsilon is how far a weight may be from an allowed value, so that
+// clients may send 33.33 instead of 33.33333
+const weightEpsilon = 0.01
+
+// NearestAnswerWeight returns the allowed weight closest to w, keeping its sign
+func NearestAnswerWeight(w float64) float64 {
+	best := AnswerWeights[0]
+	for _, allowed := range AnswerWeights[1:] {
+		if math.Abs(math.Abs(w)-allowed) < math.Abs(math.Abs(w)-best) {
+			best = allowed
+		}
+	}
+	if w < 0 {
+		return -best
+	}
+	return best
+}
+
+// IsAllowedAnswerWeight reports whether w is one of AnswerWeights or its negative
+func IsAllowedAnswerWeight(w float64) bool {
+	return math.Abs(math.Abs(w)-math.Abs(NearestAnswerWeight(w))) <= weightEpsilon
+}
+
+// validateWeights checks teacher-set answer weights of choice and short
+// answer questions. A single choice question keeps 100 for its correct answer
+// and may give partial credit or a penalty for the others; the correct
+// answers of a multiple choice question are weighted all or none and must add
+// up to 100, its wrong answers may only take points away. A short answer
+// variant may give partial credit.
+func (q *Question) validateWeights() error {
+	weighted, correct, correctWeighted := false, 0, 0
+	sum := 0.0
+	for _, a := range q.Answers {
+		if a.IsCorrect {
+			correct++
+		}
+		if a.Weight == nil {
+			continue
+		}
+		weighted = true
+		w := *a.Weight
+		if !IsAllowedAnswerWeight(w) {
+			return fmt.Errorf("%w: weight %v is not an allowed grade percentage", ErrInvalidAnswers, w)
+		}
+
+		switch q.QuestionType {
+		case QuestionTypeSingleChoice:
+			if a.IsCorrect && NearestAnswerWeight(w) != 100 {
+				return fmt.Errorf("%w: the correct answer of a single choice question is worth 100", ErrInvalidAnswers)
+			}
+			if !a.IsCorrect && NearestAnswerWeight(w) == 100 {
+				return fmt.Errorf("%w: a wrong answer must be worth less than 100", ErrInvalidAnswers)
+			}
+		case QuestionTypeMultipleChoice:
+			if a.IsCorrect {
+				if w <= 0 {
+					return fmt.Errorf("%w: a correct answer must have a positive weight", ErrInvalidAnswers)
+				}
+				correctWeighted++
+				sum += NearestAnswerWeight(w)
+			} else if w > 0 {
+				return fmt.Errorf("%w: a wrong answer must not have a positive weight", ErrInvalidAnswers)
+			}
+		case QuestionTypeShortAnswer:
+			if w <= 0 {
+				return fmt.Errorf("%w: a short answer variant must be worth more than 0", ErrInvalidAnswers)
+			}
+		default:
+			return fmt.Errorf("%w: weight is only used by choice and short answer questions", ErrInvalidAnswers)
+		}
+	}
+
+	if !weighted || q.QuestionType != QuestionTypeMultipleChoice || correctWeighted == 0 {
+		return nil
+	}
+	if correctWeighted != correct {
+		return fmt.Errorf("%w: weight all correct answers or none of them", ErrInvalidAnswers)
+	}
+	if math.Abs(sum-100) > weightEpsilon*float64(correct) {
+		return fmt.Errorf("%w: weights of correct answers add up to %v instead of 100", ErrInvalidAnswers, sum)
+	}
+	return nil
+}
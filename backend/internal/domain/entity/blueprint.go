@@ -0,0 +1,123 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// MaxBlueprintRules limits how many rules one blueprint may have
+	MaxBlueprintRules = 20
+	// MaxBlueprintQuestions limits how many questions one blueprint may request
+	MaxBlueprintQuestions = 200
+)
+
+// BlueprintRule asks for Count bank questions matching its filters; empty
+// filters match any question. CategoryID overrides the blueprint's category,
+// Tags are required in addition to the blueprint's tags.
+type BlueprintRule struct {
+	Count        int
+	Difficulty   Difficulty
+	QuestionType QuestionType
+	CategoryID   *uuid.UUID
+	Tags         []string
+}
+
+// Blueprint describes how to assemble a test from the question bank, e.g.
+// "5 easy + 10 medium + 5 hard from Networking, at most 3 true_false".
+// It is an input model and has no table.
+type Blueprint struct {
+	CategoryID           *uuid.UUID
+	IncludeSubcategories bool
+	Tags                 []string
+	Rules                []BlueprintRule
+	MaxPerType           map[QuestionType]int // Limits questions of a type across all rules
+}
+
+// TotalRequested returns how many questions the rules ask for
+func (b *Blueprint) TotalRequested() int {
+	total := 0
+	for _, rule := range b.Rules {
+		total += rule.Count
+	}
+	return total
+}
+
+// Validate checks the rules and type limits of the blueprint
+func (b *Blueprint) Validate() error {
+	if len(b.Rules) == 0 {
+		return errors.New("blueprint needs at least one rule")
+	}
+	if len(b.Rules) > MaxBlueprintRules {
+		return fmt.Errorf("blueprint must not have more than %d rules", MaxBlueprintRules)
+	}
+	for i, rule := range b.Rules {
+		if rule.Count < 1 {
+			return fmt.Errorf("rule %d: count must be positive", i+1)
+		}
+		if rule.Difficulty != "" && !(&Question{Difficulty: rule.Difficulty}).IsValidDifficulty() {
+			return fmt.Errorf("rule %d: invalid difficulty", i+1)
+		}
+		if rule.QuestionType != "" && !(&Question{QuestionType: rule.QuestionType}).IsValidType() {
+			return fmt.Errorf("rule %d: invalid question type", i+1)
+		}
+	}
+	if b.TotalRequested() > MaxBlueprintQuestions {
+		return fmt.Errorf("blueprint must not request more than %d questions", MaxBlueprintQuestions)
+	}
+	for questionType, limit := range b.MaxPerType {
+		if !(&Question{QuestionType: questionType}).IsValidType() {
+			return fmt.Errorf("max_per_type: invalid question type %q", questionType)
+		}
+		if limit < 0 {
+			return fmt.Errorf("max_per_type: limit for %s must not be negative", questionType)
+		}
+	}
+	return nil
+}
+
+// TypeAllowed reports whether one more question of the type fits MaxPerType,
+// given how many questions of each type were already picked
+func (b *Blueprint) TypeAllowed(questionType QuestionType, picked map[QuestionType]int) bool {
+	limit, ok := b.MaxPerType[questionType]
+	return !ok || picked[questionType] < limit
+}
+
+// Draw picks questions at random for each rule from that rule's candidates,
+// never picking a question twice and keeping within MaxPerType. Rules with
+// fewer candidates pick first, so broad rules do not take the only questions
+// a narrow rule can use. The result is indexed like Rules; a rule that got
+// fewer questions than its Count has a shortfall.
+func (b *Blueprint) Draw(candidates [][]*BankQuestion, shuffle func(n int, swap func(i, j int))) [][]*BankQuestion {
+	order := make([]int, len(b.Rules))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return len(candidates[order[i]]) < len(candidates[order[j]])
+	})
+
+	picked := make(map[uuid.UUID]bool)
+	pickedTypes := make(map[QuestionType]int)
+	drawn := make([][]*BankQuestion, len(b.Rules))
+	for _, ruleIndex := range order {
+		pool := append([]*BankQuestion(nil), candidates[ruleIndex]...)
+		shuffle(len(pool), func(i, j int) { pool[i], pool[j] = pool[j], pool[i] })
+
+		for _, question := range pool {
+			if len(drawn[ruleIndex]) == b.Rules[ruleIndex].Count {
+				break
+			}
+			if picked[question.ID] || !b.TypeAllowed(question.QuestionType, pickedTypes) {
+				continue
+			}
+			picked[question.ID] = true
+			pickedTypes[question.QuestionType]++
+			drawn[ruleIndex] = append(drawn[ruleIndex], question)
+		}
+	}
+	return drawn
+}
@@ -0,0 +1,98 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// keepOrder is a shuffle that leaves candidates in place, making draws predictable
+func keepOrder(n int, swap func(i, j int)) {}
+
+func bankQuestions(questionType QuestionType, n int) []*BankQuestion {
+	questions := make([]*BankQuestion, n)
+	for i := range questions {
+		questions[i] = &BankQuestion{ID: uuid.New(), QuestionType: questionType}
+	}
+	return questions
+}
+
+func TestBlueprint_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		blueprint Blueprint
+		wantErr   bool
+	}{
+		{"valid", Blueprint{Rules: []BlueprintRule{{Count: 5, Difficulty: DifficultyEasy}}, MaxPerType: map[QuestionType]int{QuestionTypeTrueFalse: 0}}, false},
+		{"no rules", Blueprint{}, true},
+		{"zero count", Blueprint{Rules: []BlueprintRule{{Count: 0}}}, true},
+		{"invalid difficulty", Blueprint{Rules: []BlueprintRule{{Count: 1, Difficulty: "extreme"}}}, true},
+		{"invalid type", Blueprint{Rules: []BlueprintRule{{Count: 1, QuestionType: "essay_like"}}}, true},
+		{"too many questions", Blueprint{Rules: []BlueprintRule{{Count: MaxBlueprintQuestions}, {Count: 1}}}, true},
+		{"negative limit", Blueprint{Rules: []BlueprintRule{{Count: 1}}, MaxPerType: map[QuestionType]int{QuestionTypeTrueFalse: -1}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.blueprint.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestBlueprint_DrawNarrowRulesFirst(t *testing.T) {
+	shared := bankQuestions(QuestionTypeSingleChoice, 2)
+	broad := append(bankQuestions(QuestionTypeSingleChoice, 1), shared...)
+	blueprint := Blueprint{Rules: []BlueprintRule{{Count: 3}, {Count: 2}}}
+
+	drawn := blueprint.Draw([][]*BankQuestion{broad, shared}, keepOrder)
+
+	// The narrow rule takes both shared questions, the broad rule gets the rest
+	assert.Equal(t, shared, drawn[1])
+	assert.Equal(t, broad[:1], drawn[0])
+}
+
+func TestBlueprint_DrawRespectsTypeLimit(t *testing.T) {
+	trueFalse := bankQuestions(QuestionTypeTrueFalse, 4)
+	single := bankQuestions(QuestionTypeSingleChoice, 4)
+	mixed := append(append([]*BankQuestion{}, trueFalse...), single...)
+	blueprint := Blueprint{
+		Rules:      []BlueprintRule{{Count: 6}},
+		MaxPerType: map[QuestionType]int{QuestionTypeTrueFalse: 3},
+	}
+
+	drawn := blueprint.Draw([][]*BankQuestion{mixed}, keepOrder)
+
+	assert.Len(t, drawn[0], 6)
+	count := 0
+	for _, q := range drawn[0] {
+		if q.QuestionType == QuestionTypeTrueFalse {
+			count++
+		}
+	}
+	assert.Equal(t, 3, count)
+}
+
+func TestBlueprint_DrawNeverRepeats(t *testing.T) {
+	pool := bankQuestions(QuestionTypeSingleChoice, 3)
+	blueprint := Blueprint{Rules: []BlueprintRule{{Count: 2}, {Count: 2}}}
+
+	drawn := blueprint.Draw([][]*BankQuestion{pool, pool}, func(n int, swap func(i, j int)) {
+		swap(0, n-1)
+	})
+
+	seen := make(map[uuid.UUID]bool)
+	total := 0
+	for _, questions := range drawn {
+		for _, q := range questions {
+			assert.False(t, seen[q.ID])
+			seen[q.ID] = true
+			total++
+		}
+	}
+	assert.Equal(t, 3, total, "the second rule falls one question short")
+}
@@ -0,0 +1,82 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// clozeFieldRegex matches an embedded answer of a cloze question in Moodle
+// syntax, e.g. {1:SHORTANSWER:=Paris~%50%Lutetia} or {:MULTICHOICE:=4~5~6}
+var clozeFieldRegex = regexp.MustCompile(`\{(\d*):([A-Z_]+):((?:\\.|[^\\}])*)\}`)
+
+// clozeFieldTypes lists the Moodle cloze field types with their short forms
+var clozeFieldTypes = map[string]bool{
+	"SHORTANSWER": true, "SA": true, "MW": true, "SHORTANSWER_C": true, "SAC": true, "MWC": true,
+	"NUMERICAL": true, "NM": true,
+	"MULTICHOICE": true, "MC": true, "MULTICHOICE_V": true, "MCV": true, "MULTICHOICE_H": true, "MCH": true,
+	"MULTICHOICE_S": true, "MCS": true, "MULTICHOICE_VS": true, "MCVS": true, "MULTICHOICE_HS": true, "MCHS": true,
+	"MULTIRESPONSE": true, "MR": true, "MULTIRESPONSE_H": true, "MRH": true,
+	"MULTIRESPONSE_S": true, "MRS": true, "MULTIRESPONSE_HS": true, "MRHS": true,
+}
+
+// ClozeField is an answer embedded in the text of a cloze question
+type ClozeField struct {
+	Weight  string // Empty means 1
+	Type    string // Moodle field type, e.g. SHORTANSWER or MULTICHOICE
+	Options []string
+}
+
+// ParseClozeFields extracts the embedded answers of a cloze question text and
+// checks that each has a known type and at least one fully correct option,
+// marked with "=" or "%100%"
+func ParseClozeFields(text string) ([]ClozeField, error) {
+	matches := clozeFieldRegex.FindAllStringSubmatch(text, -1)
+	if len(matches) == 0 {
+		return nil, errors.New("cloze question text needs at least 1 embedded answer like {1:SHORTANSWER:=answer}")
+	}
+
+	fields := make([]ClozeField, 0, len(matches))
+	for i, m := range matches {
+		field := ClozeField{Weight: m[1], Type: m[2], Options: splitClozeOptions(m[3])}
+		if !clozeFieldTypes[field.Type] {
+			return nil, fmt.Errorf("embedded answer %d has unknown type %s", i+1, field.Type)
+		}
+		correct := false
+		for _, option := range field.Options {
+			if strings.HasPrefix(option, "=") || strings.HasPrefix(option, "%100%") {
+				correct = true
+				break
+			}
+		}
+		if !correct {
+			return nil, fmt.Errorf("embedded answer %d needs a correct option marked with =", i+1)
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// splitClozeOptions splits the options of an embedded answer on unescaped "~"
+func splitClozeOptions(raw string) []string {
+	var options []string
+	var current strings.Builder
+	escaped := false
+	for _, r := range raw {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			current.WriteRune(r)
+			escaped = true
+		case r == '~':
+			options = append(options, strings.TrimSpace(current.String()))
+			current.Reset()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	return append(options, strings.TrimSpace(current.String()))
+}
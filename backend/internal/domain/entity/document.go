@@ -9,11 +9,22 @@ import (
 type FileType string
 
 const (
-	FileTypePDF  FileType = "pdf"
-	FileTypeDOCX FileType = "docx"
-	FileTypePPTX FileType = "pptx"
-	FileTypeTXT  FileType = "txt"
-	FileTypeMD   FileType = "md"
+	FileTypePDF   FileType = "pdf"
+	FileTypeDOCX  FileType = "docx"
+	FileTypePPTX  FileType = "pptx"
+	FileTypeTXT   FileType = "txt"
+	FileTypeMD    FileType = "md"
+	FileTypeSRT   FileType = "srt"
+	FileTypeVTT   FileType = "vtt"
+	FileTypeIPYNB FileType = "ipynb"
+	FileTypeGo    FileType = "go"
+	FileTypePy    FileType = "py"
+	FileTypeJava  FileType = "java"
+	FileTypeODT   FileType = "odt"
+	FileTypeODP   FileType = "odp"
+	FileTypeRTF   FileType = "rtf"
+	FileTypeHTML  FileType = "html"
+	FileTypeEPUB  FileType = "epub"
 )
 
 type DocumentStatus string
@@ -36,10 +47,27 @@ type Document struct {
 	ParsedText string          `json:"parsed_text,omitempty" gorm:"type:text"`
 	Status     DocumentStatus  `json:"status" gorm:"type:varchar(50);default:'uploaded';index"`
 	ErrorMsg   string          `json:"error_msg,omitempty" gorm:"type:text"`
+	Encoding   string          `json:"encoding,omitempty" gorm:"type:varchar(32)"` // Source charset of text uploads
+	ContentHash string         `json:"content_hash,omitempty" gorm:"type:varchar(64);index"` // SHA-256 of the uploaded file
+
+	// Teacher corrections of ParsedText; ParsedText itself keeps the parser output
+	CorrectedText string `json:"corrected_text,omitempty" gorm:"type:text"`
+	TextRevision  int    `json:"text_revision" gorm:"default:0"` // Latest revision number, 0 = uncorrected
+
+	// Statistics of EffectiveText, refreshed on parsing and on every correction
+	TextStats `gorm:"embedded"`
+
+	// Background parsing progress
+	ParseProgress int `json:"parse_progress" gorm:"default:0"`
+	ParseAttempts int `json:"parse_attempts" gorm:"default:0"`
+
 	CreatedAt  time.Time       `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt  time.Time       `json:"updated_at" gorm:"autoUpdateTime"`
 	DeletedAt  *time.Time      `json:"deleted_at,omitempty" gorm:"index"`
 
+	// Set by the storage janitor once the file of a deleted document is removed
+	FilePurgedAt *time.Time `json:"-"`
+
 	// Relations
 	User       User            `json:"user,omitempty" gorm:"foreignKey:UserID"`
 }
@@ -54,15 +82,61 @@ func (d *Document) IsParsed() bool {
 	return d.Status == StatusParsed
 }
 
+// IsParsing checks if document is currently being parsed
+func (d *Document) IsParsing() bool {
+	return d.Status == StatusParsing
+}
+
+// EffectiveText returns the text used for generation: the latest correction if any, otherwise the parsed text
+func (d *Document) EffectiveText() string {
+	if d.TextRevision > 0 {
+		return d.CorrectedText
+	}
+	return d.ParsedText
+}
+
+// CopyParsedTextFrom reuses the parsing result of a document with identical content
+func (d *Document) CopyParsedTextFrom(source *Document) {
+	d.Encoding = source.Encoding
+	d.MarkAsParsed(source.ParsedText)
+}
+
+// RefreshTextStats recomputes statistics of the effective text
+func (d *Document) RefreshTextStats() {
+	d.TextStats = NewTextStats(d.EffectiveText())
+}
+
+// MarkAsQueued resets document to uploaded so the parse queue picks it up again
+func (d *Document) MarkAsQueued() {
+	d.Status = StatusUploaded
+	d.ErrorMsg = ""
+	d.ParseProgress = 0
+	d.ParseAttempts = 0
+}
+
 // MarkAsParsing sets document status to parsing
 func (d *Document) MarkAsParsing() {
 	d.Status = StatusParsing
 }
 
+// SetParseProgress updates parsing progress, clamped to 0..100
+func (d *Document) SetParseProgress(progress int) {
+	if progress < 0 {
+		progress = 0
+	}
+	if progress > 100 {
+		progress = 100
+	}
+	d.ParseProgress = progress
+}
+
 // MarkAsParsed sets document status to parsed
 func (d *Document) MarkAsParsed(parsedText string) {
 	d.Status = StatusParsed
 	d.ParsedText = parsedText
+	d.ErrorMsg = ""
+	d.ParseProgress = 100
+	d.RefreshTextStats()
 }
 
 // MarkAsError sets document status to error
@@ -74,7 +148,9 @@ func (d *Document) MarkAsError(errMsg string) {
 // IsValidType checks if the document type is supported
 func (d *Document) IsValidType() bool {
 	switch d.FileType {
-	case FileTypePDF, FileTypeDOCX, FileTypePPTX, FileTypeTXT, FileTypeMD:
+	case FileTypePDF, FileTypeDOCX, FileTypePPTX, FileTypeTXT, FileTypeMD, FileTypeSRT, FileTypeVTT,
+		FileTypeIPYNB, FileTypeGo, FileTypePy, FileTypeJava,
+		FileTypeODT, FileTypeODP, FileTypeRTF, FileTypeHTML, FileTypeEPUB:
 		return true
 	default:
 		return false
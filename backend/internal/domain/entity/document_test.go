@@ -49,3 +49,30 @@ func TestDocument_TableName(t *testing.T) {
 	doc := Document{}
 	assert.Equal(t, "documents", doc.TableName())
 }
+
+func TestDocument_EffectiveText(t *testing.T) {
+	doc := &Document{ParsedText: "parsed"}
+	assert.Equal(t, "parsed", doc.EffectiveText())
+
+	doc.CorrectedText = "corrected"
+	doc.TextRevision = 1
+	assert.Equal(t, "corrected", doc.EffectiveText())
+
+	// Re-parsing keeps the teacher's correction
+	doc.MarkAsParsed("parsed again")
+	assert.Equal(t, "corrected", doc.EffectiveText())
+}
+
+func TestDocument_TextStatsFollowEffectiveText(t *testing.T) {
+	doc := &Document{}
+	assert.False(t, doc.TextStats.IsComputed())
+
+	doc.MarkAsParsed("one two three")
+	assert.True(t, doc.TextStats.IsComputed())
+	assert.Equal(t, 3, doc.WordCount)
+
+	doc.CorrectedText = "one two three four five"
+	doc.TextRevision = 1
+	doc.MarkAsParsed("parsed again")
+	assert.Equal(t, 5, doc.WordCount)
+}
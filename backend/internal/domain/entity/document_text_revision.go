@@ -0,0 +1,26 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DocumentTextRevision is a teacher-corrected version of a document's parsed text.
+// Revisions are numbered per document starting at 1; the original parsed text is revision 0.
+type DocumentTextRevision struct {
+	ID         uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	DocumentID uuid.UUID `json:"document_id" gorm:"type:uuid;not null;index"`
+	UserID     uuid.UUID `json:"user_id" gorm:"type:uuid;not null"`
+	Revision   int       `json:"revision" gorm:"not null"`
+	Text       string    `json:"text" gorm:"type:text;not null"`
+	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime"`
+
+	// Statistics of Text, written to the document together with the correction
+	Stats TextStats `json:"-" gorm:"-"`
+}
+
+// TableName specifies the table name for GORM
+func (DocumentTextRevision) TableName() string {
+	return "document_text_revisions"
+}
@@ -1,6 +1,10 @@
 package entity
 
 import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,6 +17,11 @@ const (
 	QuestionTypeMultipleChoice QuestionType = "multiple_choice"
 	QuestionTypeTrueFalse      QuestionType = "true_false"
 	QuestionTypeShortAnswer    QuestionType = "short_answer"
+	QuestionTypeMatching       QuestionType = "matching"
+	QuestionTypeOrdering       QuestionType = "ordering"
+	QuestionTypeNumerical      QuestionType = "numerical"
+	QuestionTypeCloze          QuestionType = "cloze"
+	QuestionTypeEssay          QuestionType = "essay"
 )
 
 type Difficulty string
@@ -23,16 +32,20 @@ const (
 	DifficultyHard   Difficulty = "hard"
 )
 
+// ErrInvalidAnswers is returned when a question's answers do not fit its type
+var ErrInvalidAnswers = errors.New("invalid answers")
+
 type Question struct {
-	ID           uuid.UUID    `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
-	TestID       uuid.UUID    `json:"test_id" gorm:"type:uuid;not null;index"`
-	QuestionText string       `json:"question_text" gorm:"type:text;not null"`
-	QuestionType QuestionType `json:"question_type" gorm:"type:varchar(50);default:'single_choice'"`
-	Difficulty   Difficulty   `json:"difficulty" gorm:"type:varchar(50);default:'medium'"`
-	Points       float64      `json:"points" gorm:"type:decimal(5,2);default:1.0"`
-	OrderNum     int          `json:"order_num" gorm:"not null"`
-	CreatedAt    time.Time    `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt    time.Time    `json:"updated_at" gorm:"autoUpdateTime"`
+	ID            uuid.UUID    `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	TestID        uuid.UUID    `json:"test_id" gorm:"type:uuid;not null;index"`
+	QuestionText  string       `json:"question_text" gorm:"type:text;not null"`
+	QuestionType  QuestionType `json:"question_type" gorm:"type:varchar(50);default:'single_choice'"`
+	Difficulty    Difficulty   `json:"difficulty" gorm:"type:varchar(50);default:'medium'"`
+	Points        float64      `json:"points" gorm:"type:decimal(5,2);default:1.0"`
+	CaseSensitive bool         `json:"case_sensitive,omitempty" gorm:"default:false"` // Short answer questions: variants must match letter case
+	OrderNum      int          `json:"order_num" gorm:"not null"`
+	CreatedAt     time.Time    `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt     time.Time    `json:"updated_at" gorm:"autoUpdateTime"`
 
 	// Relations
 	Test    Test     `json:"test,omitempty" gorm:"foreignKey:TestID"`
@@ -63,3 +76,162 @@ func (q *Question) IsTrueFalse() bool {
 func (q *Question) IsShortAnswer() bool {
 	return q.QuestionType == QuestionTypeShortAnswer
 }
+
+// IsMatching checks if question is matching type
+func (q *Question) IsMatching() bool {
+	return q.QuestionType == QuestionTypeMatching
+}
+
+// IsOrdering checks if question is ordering type
+func (q *Question) IsOrdering() bool {
+	return q.QuestionType == QuestionTypeOrdering
+}
+
+// IsNumerical checks if question is numerical type
+func (q *Question) IsNumerical() bool {
+	return q.QuestionType == QuestionTypeNumerical
+}
+
+// IsCloze checks if question is cloze (embedded answers) type
+func (q *Question) IsCloze() bool {
+	return q.QuestionType == QuestionTypeCloze
+}
+
+// IsEssay checks if question is essay type
+func (q *Question) IsEssay() bool {
+	return q.QuestionType == QuestionTypeEssay
+}
+
+// IsValidType checks if the question type is supported
+func (q *Question) IsValidType() bool {
+	switch q.QuestionType {
+	case QuestionTypeSingleChoice, QuestionTypeMultipleChoice, QuestionTypeTrueFalse, QuestionTypeShortAnswer,
+		QuestionTypeMatching, QuestionTypeOrdering, QuestionTypeNumerical, QuestionTypeCloze, QuestionTypeEssay:
+		return true
+	}
+	return false
+}
+
+// IsValidDifficulty checks if the difficulty level is supported
+func (q *Question) IsValidDifficulty() bool {
+	switch q.Difficulty {
+	case DifficultyEasy, DifficultyMedium, DifficultyHard:
+		return true
+	}
+	return false
+}
+
+// ValidateAnswers checks that the answers fit the question type: choice
+// questions need at least two options, single choice and true/false exactly
+// one correct option, and short answers list only accepted (correct) variants.
+// Matching answers are pairs of AnswerText and MatchText, ordering answers are
+// items in the correct order, numerical answers are numbers with an optional
+// Tolerance, cloze questions embed their answers in the question text and
+// essays may have one reference answer for graders.
+func (q *Question) ValidateAnswers() error {
+	correct := 0
+	for _, a := range q.Answers {
+		if strings.TrimSpace(a.AnswerText) == "" {
+			return fmt.Errorf("%w: answer text must not be empty", ErrInvalidAnswers)
+		}
+		if a.MatchText != "" && q.QuestionType != QuestionTypeMatching {
+			return fmt.Errorf("%w: match text is only used by matching questions", ErrInvalidAnswers)
+		}
+		if a.Tolerance != nil && q.QuestionType != QuestionTypeNumerical {
+			return fmt.Errorf("%w: tolerance is only used by numerical questions", ErrInvalidAnswers)
+		}
+		if a.IsCorrect {
+			correct++
+		}
+	}
+	if q.CaseSensitive && q.QuestionType != QuestionTypeShortAnswer {
+		return fmt.Errorf("%w: case sensitivity is only used by short answer questions", ErrInvalidAnswers)
+	}
+	if err := q.validateWeights(); err != nil {
+		return err
+	}
+
+	switch q.QuestionType {
+	case QuestionTypeSingleChoice:
+		if len(q.Answers) < 2 {
+			return fmt.Errorf("%w: single choice question needs at least 2 answers", ErrInvalidAnswers)
+		}
+		if correct != 1 {
+			return fmt.Errorf("%w: single choice question needs exactly 1 correct answer", ErrInvalidAnswers)
+		}
+	case QuestionTypeMultipleChoice:
+		if len(q.Answers) < 2 {
+			return fmt.Errorf("%w: multiple choice question needs at least 2 answers", ErrInvalidAnswers)
+		}
+		if correct == 0 {
+			return fmt.Errorf("%w: multiple choice question needs at least 1 correct answer", ErrInvalidAnswers)
+		}
+	case QuestionTypeTrueFalse:
+		if len(q.Answers) != 2 {
+			return fmt.Errorf("%w: true/false question needs exactly 2 answers", ErrInvalidAnswers)
+		}
+		if correct != 1 {
+			return fmt.Errorf("%w: true/false question needs exactly 1 correct answer", ErrInvalidAnswers)
+		}
+		seen := map[bool]bool{}
+		for _, a := range q.Answers {
+			value, ok := ParseTrueFalse(a.AnswerText)
+			if !ok {
+				return fmt.Errorf("%w: true/false answer %q is neither true nor false", ErrInvalidAnswers, a.AnswerText)
+			}
+			seen[value] = true
+		}
+		if len(seen) != 2 {
+			return fmt.Errorf("%w: true/false question needs a true and a false answer", ErrInvalidAnswers)
+		}
+	case QuestionTypeShortAnswer:
+		if len(q.Answers) == 0 {
+			return fmt.Errorf("%w: short answer question needs at least 1 accepted answer", ErrInvalidAnswers)
+		}
+		if correct != len(q.Answers) {
+			return fmt.Errorf("%w: short answer variants must all be marked correct", ErrInvalidAnswers)
+		}
+		if err := q.validateShortAnswerVariants(); err != nil {
+			return err
+		}
+	case QuestionTypeMatching:
+		if len(q.Answers) < 2 {
+			return fmt.Errorf("%w: matching question needs at least 2 pairs", ErrInvalidAnswers)
+		}
+		for _, a := range q.Answers {
+			if strings.TrimSpace(a.MatchText) == "" {
+				return fmt.Errorf("%w: every matching pair needs a match text", ErrInvalidAnswers)
+			}
+		}
+	case QuestionTypeOrdering:
+		if len(q.Answers) < 2 {
+			return fmt.Errorf("%w: ordering question needs at least 2 items", ErrInvalidAnswers)
+		}
+	case QuestionTypeNumerical:
+		if correct == 0 {
+			return fmt.Errorf("%w: numerical question needs at least 1 correct answer", ErrInvalidAnswers)
+		}
+		for _, a := range q.Answers {
+			if _, err := strconv.ParseFloat(strings.TrimSpace(a.AnswerText), 64); err != nil {
+				return fmt.Errorf("%w: numerical answer %q is not a number", ErrInvalidAnswers, a.AnswerText)
+			}
+			if a.Tolerance != nil && *a.Tolerance < 0 {
+				return fmt.Errorf("%w: tolerance must not be negative", ErrInvalidAnswers)
+			}
+		}
+	case QuestionTypeCloze:
+		if len(q.Answers) != 0 {
+			return fmt.Errorf("%w: cloze answers are embedded in the question text", ErrInvalidAnswers)
+		}
+		if _, err := ParseClozeFields(q.QuestionText); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidAnswers, err)
+		}
+	case QuestionTypeEssay:
+		if len(q.Answers) > 1 {
+			return fmt.Errorf("%w: essay question takes at most 1 reference answer", ErrInvalidAnswers)
+		}
+	default:
+		return fmt.Errorf("%w: unsupported question type %q", ErrInvalidAnswers, q.QuestionType)
+	}
+	return nil
+}
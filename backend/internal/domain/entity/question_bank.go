@@ -0,0 +1,173 @@
+package entity
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// MaxTagLength limits a question bank tag, in characters
+const MaxTagLength = 50
+
+// QuestionCategory groups bank questions of one teacher; categories nest through ParentID
+type QuestionCategory struct {
+	ID        uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	UserID    uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	ParentID  *uuid.UUID `json:"parent_id,omitempty" gorm:"type:uuid;index"` // Nil for top-level categories
+	Name      string     `json:"name" gorm:"type:varchar(255);not null"`
+	CreatedAt time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName specifies the table name for GORM
+func (QuestionCategory) TableName() string {
+	return "question_categories"
+}
+
+// QuestionTag is a free-form label of bank questions, unique per teacher
+type QuestionTag struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	UserID    uuid.UUID `json:"user_id" gorm:"type:uuid;not null;index"`
+	Name      string    `json:"name" gorm:"type:varchar(50);not null"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName specifies the table name for GORM
+func (QuestionTag) TableName() string {
+	return "question_tags"
+}
+
+// BankQuestion is a reusable question owned by a teacher. Adding it to a test
+// copies it into the test, so later bank edits do not change assembled tests;
+// TestBankQuestion keeps track of the tests it was added to.
+type BankQuestion struct {
+	ID            uuid.UUID    `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	UserID        uuid.UUID    `json:"user_id" gorm:"type:uuid;not null;index"`
+	CategoryID    *uuid.UUID   `json:"category_id,omitempty" gorm:"type:uuid;index"` // Nil for uncategorized questions
+	QuestionText  string       `json:"question_text" gorm:"type:text;not null"`
+	QuestionType  QuestionType `json:"question_type" gorm:"type:varchar(50);default:'single_choice'"`
+	Difficulty    Difficulty   `json:"difficulty" gorm:"type:varchar(50);default:'medium'"`
+	Points        float64      `json:"points" gorm:"type:decimal(5,2);default:1.0"`
+	CaseSensitive bool         `json:"case_sensitive,omitempty" gorm:"default:false"`
+	CreatedAt     time.Time    `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt     time.Time    `json:"updated_at" gorm:"autoUpdateTime"`
+
+	// Relations
+	Answers []BankAnswer  `json:"answers,omitempty" gorm:"foreignKey:BankQuestionID"`
+	Tags    []QuestionTag `json:"tags,omitempty" gorm:"many2many:bank_question_tags"`
+}
+
+// TableName specifies the table name for GORM
+func (BankQuestion) TableName() string {
+	return "bank_questions"
+}
+
+// BankAnswer is an answer option of a bank question
+type BankAnswer struct {
+	ID             uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	BankQuestionID uuid.UUID `json:"bank_question_id" gorm:"type:uuid;not null;index"`
+	AnswerText     string    `json:"answer_text" gorm:"type:text;not null"`
+	IsCorrect      bool      `json:"is_correct" gorm:"default:false"`
+	MatchText      string    `json:"match_text,omitempty" gorm:"type:text"`
+	Tolerance      *float64  `json:"tolerance,omitempty" gorm:"type:double precision"`
+	Weight         *float64  `json:"weight,omitempty" gorm:"type:double precision"`
+	OrderNum       int       `json:"order_num" gorm:"not null"`
+	CreatedAt      time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName specifies the table name for GORM
+func (BankAnswer) TableName() string {
+	return "bank_answers"
+}
+
+// TestBankQuestion links a bank question to a test and the question copied into it
+type TestBankQuestion struct {
+	TestID         uuid.UUID `json:"test_id" gorm:"type:uuid;primaryKey"`
+	BankQuestionID uuid.UUID `json:"bank_question_id" gorm:"type:uuid;primaryKey"`
+	QuestionID     uuid.UUID `json:"question_id" gorm:"type:uuid;not null"`
+	CreatedAt      time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName specifies the table name for GORM
+func (TestBankQuestion) TableName() string {
+	return "test_bank_questions"
+}
+
+// TagNames returns the names of the question's tags
+func (q *BankQuestion) TagNames() []string {
+	names := make([]string, len(q.Tags))
+	for i, tag := range q.Tags {
+		names[i] = tag.Name
+	}
+	return names
+}
+
+// ToQuestion copies the bank question into a test question at the given
+// position; the copy and its answers get IDs when they are saved
+func (q *BankQuestion) ToQuestion(testID uuid.UUID, orderNum int) *Question {
+	question := &Question{
+		TestID:        testID,
+		QuestionText:  q.QuestionText,
+		QuestionType:  q.QuestionType,
+		Difficulty:    q.Difficulty,
+		Points:        q.Points,
+		CaseSensitive: q.CaseSensitive,
+		OrderNum:      orderNum,
+	}
+	for _, a := range q.Answers {
+		question.Answers = append(question.Answers, Answer{
+			AnswerText: a.AnswerText,
+			IsCorrect:  a.IsCorrect,
+			MatchText:  a.MatchText,
+			Tolerance:  a.Tolerance,
+			Weight:     a.Weight,
+			OrderNum:   a.OrderNum,
+		})
+	}
+	return question
+}
+
+// NewBankQuestionFromQuestion copies a test question into the bank of a teacher
+func NewBankQuestionFromQuestion(userID uuid.UUID, question *Question) *BankQuestion {
+	bankQuestion := &BankQuestion{
+		UserID:        userID,
+		QuestionText:  question.QuestionText,
+		QuestionType:  question.QuestionType,
+		Difficulty:    question.Difficulty,
+		Points:        question.Points,
+		CaseSensitive: question.CaseSensitive,
+	}
+	for _, a := range question.Answers {
+		bankQuestion.Answers = append(bankQuestion.Answers, BankAnswer{
+			AnswerText: a.AnswerText,
+			IsCorrect:  a.IsCorrect,
+			MatchText:  a.MatchText,
+			Tolerance:  a.Tolerance,
+			Weight:     a.Weight,
+			OrderNum:   a.OrderNum,
+		})
+	}
+	return bankQuestion
+}
+
+// NormalizeTagNames trims and lowercases tag names, dropping empty ones and
+// duplicates while keeping the original order. Returns false when a tag is
+// longer than MaxTagLength.
+func NormalizeTagNames(names []string) ([]string, bool) {
+	seen := make(map[string]bool, len(names))
+	normalized := make([]string, 0, len(names))
+	for _, name := range names {
+		name = strings.ToLower(strings.Join(strings.Fields(name), " "))
+		if name == "" || seen[name] {
+			continue
+		}
+		if utf8.RuneCountInString(name) > MaxTagLength {
+			return nil, false
+		}
+		seen[name] = true
+		normalized = append(normalized, name)
+	}
+	return normalized, true
+}
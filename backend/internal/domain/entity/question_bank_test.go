@@ -0,0 +1,104 @@
+package entity
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeTagNames(t *testing.T) {
+	names, ok := NormalizeTagNames([]string{"  Go ", "go", "", "Data   Structures", "   "})
+	assert.True(t, ok)
+	assert.Equal(t, []string{"go", "data structures"}, names)
+
+	names, ok = NormalizeTagNames(nil)
+	assert.True(t, ok)
+	assert.Empty(t, names)
+
+	_, ok = NormalizeTagNames([]string{strings.Repeat("я", MaxTagLength)})
+	assert.True(t, ok, "length is counted in characters")
+
+	_, ok = NormalizeTagNames([]string{strings.Repeat("a", MaxTagLength+1)})
+	assert.False(t, ok)
+}
+
+func TestBankQuestion_ToQuestion(t *testing.T) {
+	bankQuestion := &BankQuestion{
+		ID:           uuid.New(),
+		QuestionText: "2 + 2 = 4",
+		QuestionType: QuestionTypeTrueFalse,
+		Difficulty:   DifficultyEasy,
+		Points:       0.5,
+		Answers: []BankAnswer{
+			{ID: uuid.New(), AnswerText: "True", IsCorrect: true, OrderNum: 1},
+			{ID: uuid.New(), AnswerText: "False", OrderNum: 2},
+		},
+	}
+	testID := uuid.New()
+
+	question := bankQuestion.ToQuestion(testID, 3)
+
+	assert.Equal(t, uuid.Nil, question.ID)
+	assert.Equal(t, testID, question.TestID)
+	assert.Equal(t, 3, question.OrderNum)
+	assert.Equal(t, bankQuestion.QuestionText, question.QuestionText)
+	assert.Equal(t, 0.5, question.Points)
+	assert.Len(t, question.Answers, 2)
+	assert.Equal(t, uuid.Nil, question.Answers[0].ID)
+	assert.True(t, question.Answers[0].IsCorrect)
+	assert.NoError(t, question.ValidateAnswers())
+}
+
+func TestBankQuestion_ToQuestionKeepsTypeSpecificFields(t *testing.T) {
+	tolerance := 0.1
+	bankQuestion := &BankQuestion{
+		QuestionText: "Match",
+		QuestionType: QuestionTypeMatching,
+		Answers:      []BankAnswer{{AnswerText: "Go", MatchText: "Google"}, {AnswerText: "C#", MatchText: "Microsoft"}},
+	}
+
+	question := bankQuestion.ToQuestion(uuid.New(), 1)
+	assert.Equal(t, "Google", question.Answers[0].MatchText)
+	assert.NoError(t, question.ValidateAnswers())
+
+	question.Answers = []Answer{{AnswerText: "1", IsCorrect: true, Tolerance: &tolerance}}
+	question.QuestionType = QuestionTypeNumerical
+	back := NewBankQuestionFromQuestion(uuid.New(), question)
+	assert.Equal(t, &tolerance, back.Answers[0].Tolerance)
+
+	half := 50.0
+	question.QuestionType, question.CaseSensitive = QuestionTypeShortAnswer, true
+	question.Answers = []Answer{{AnswerText: "Go", IsCorrect: true}, {AnswerText: "Golang", IsCorrect: true, Weight: &half}}
+	back = NewBankQuestionFromQuestion(uuid.New(), question)
+	assert.True(t, back.CaseSensitive)
+	assert.Equal(t, &half, back.Answers[1].Weight)
+	assert.True(t, back.ToQuestion(uuid.New(), 1).CaseSensitive)
+}
+
+func TestNewBankQuestionFromQuestion(t *testing.T) {
+	userID := uuid.New()
+	question := &Question{
+		ID:           uuid.New(),
+		TestID:       uuid.New(),
+		QuestionText: "Pick the prime",
+		QuestionType: QuestionTypeSingleChoice,
+		Difficulty:   DifficultyMedium,
+		Points:       2,
+		Answers: []Answer{
+			{ID: uuid.New(), AnswerText: "4", OrderNum: 1},
+			{ID: uuid.New(), AnswerText: "7", IsCorrect: true, OrderNum: 2},
+		},
+	}
+
+	bankQuestion := NewBankQuestionFromQuestion(userID, question)
+
+	assert.Equal(t, userID, bankQuestion.UserID)
+	assert.Nil(t, bankQuestion.CategoryID)
+	assert.Equal(t, question.QuestionText, bankQuestion.QuestionText)
+	assert.Equal(t, 2.0, bankQuestion.Points)
+	assert.Len(t, bankQuestion.Answers, 2)
+	assert.Equal(t, "7", bankQuestion.Answers[1].AnswerText)
+	assert.True(t, bankQuestion.Answers[1].IsCorrect)
+}
@@ -50,3 +50,278 @@ func TestQuestion_TableName(t *testing.T) {
 	q := Question{}
 	assert.Equal(t, "questions", q.TableName())
 }
+
+func TestQuestion_ValidateAnswers(t *testing.T) {
+	answers := func(correct ...bool) []Answer {
+		result := make([]Answer, len(correct))
+		for i, c := range correct {
+			result[i] = Answer{AnswerText: "option " + string(rune('a'+i)), IsCorrect: c}
+		}
+		return result
+	}
+	pairs := func(texts ...string) []Answer {
+		result := make([]Answer, 0, len(texts)/2)
+		for i := 0; i+1 < len(texts); i += 2 {
+			result = append(result, Answer{AnswerText: texts[i], MatchText: texts[i+1]})
+		}
+		return result
+	}
+	tolerance, negative := 0.01, -1.0
+
+	tests := []struct {
+		name         string
+		questionType QuestionType
+		answers      []Answer
+		valid        bool
+	}{
+		{"single choice", QuestionTypeSingleChoice, answers(true, false, false), true},
+		{"single choice with two correct", QuestionTypeSingleChoice, answers(true, true), false},
+		{"single choice with one option", QuestionTypeSingleChoice, answers(true), false},
+		{"multiple choice", QuestionTypeMultipleChoice, answers(true, true, false), true},
+		{"multiple choice without correct", QuestionTypeMultipleChoice, answers(false, false), false},
+		{"true false", QuestionTypeTrueFalse, []Answer{{AnswerText: "Неверно"}, {AnswerText: "Верно", IsCorrect: true}}, true},
+		{"true false with canonical keys", QuestionTypeTrueFalse, []Answer{{AnswerText: "true", IsCorrect: true}, {AnswerText: "false"}}, true},
+		{"true false with other answers", QuestionTypeTrueFalse, answers(false, true), false},
+		{"true false with two true answers", QuestionTypeTrueFalse, []Answer{{AnswerText: "Верно", IsCorrect: true}, {AnswerText: "True"}}, false},
+		{"true false with three options", QuestionTypeTrueFalse, answers(true, false, false), false},
+		{"short answer", QuestionTypeShortAnswer, answers(true, true), true},
+		{"short answer with wrong variant", QuestionTypeShortAnswer, answers(true, false), false},
+		{"short answer without variants", QuestionTypeShortAnswer, nil, false},
+		{"empty answer text", QuestionTypeMultipleChoice, []Answer{{AnswerText: " ", IsCorrect: true}, {AnswerText: "b"}}, false},
+		{"unknown type", QuestionType("drag_and_drop"), answers(true), false},
+		{"matching", QuestionTypeMatching, pairs("Go", "Google", "Rust", "Mozilla"), true},
+		{"matching with one pair", QuestionTypeMatching, pairs("Go", "Google"), false},
+		{"matching without match text", QuestionTypeMatching, answers(true, true), false},
+		{"match text outside matching", QuestionTypeSingleChoice, pairs("Go", "Google", "Rust", "Mozilla"), false},
+		{"ordering", QuestionTypeOrdering, answers(false, false, false), true},
+		{"ordering with one item", QuestionTypeOrdering, answers(false), false},
+		{"numerical", QuestionTypeNumerical, []Answer{{AnswerText: "3.14", IsCorrect: true, Tolerance: &tolerance}}, true},
+		{"numerical with text", QuestionTypeNumerical, []Answer{{AnswerText: "pi", IsCorrect: true}}, false},
+		{"numerical without correct", QuestionTypeNumerical, []Answer{{AnswerText: "3"}}, false},
+		{"numerical with negative tolerance", QuestionTypeNumerical, []Answer{{AnswerText: "3", IsCorrect: true, Tolerance: &negative}}, false},
+		{"tolerance outside numerical", QuestionTypeShortAnswer, []Answer{{AnswerText: "3", IsCorrect: true, Tolerance: &tolerance}}, false},
+		{"cloze with answers", QuestionTypeCloze, answers(true), false},
+		{"essay", QuestionTypeEssay, nil, true},
+		{"essay with reference answer", QuestionTypeEssay, answers(false), true},
+		{"essay with two reference answers", QuestionTypeEssay, answers(false, false), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &Question{QuestionType: tt.questionType, Answers: tt.answers}
+			err := q.ValidateAnswers()
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrInvalidAnswers)
+			}
+		})
+	}
+}
+
+func TestQuestion_IsValidTypeAndDifficulty(t *testing.T) {
+	q := &Question{QuestionType: QuestionTypeTrueFalse, Difficulty: DifficultyHard}
+	assert.True(t, q.IsValidType())
+	assert.True(t, q.IsValidDifficulty())
+
+	q = &Question{QuestionType: "drag_and_drop", Difficulty: "extreme"}
+	assert.False(t, q.IsValidType())
+	assert.False(t, q.IsValidDifficulty())
+}
+
+func TestQuestion_ValidateAnswersCloze(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		valid bool
+	}{
+		{"short answer and choice", "The capital of France is {1:SHORTANSWER:=Paris~%50%Lutetia}, 2+2 = {2:MC:3~=4~5}", true},
+		{"default weight", "Water boils at {:NUMERICAL:=100:0.5} degrees", true},
+		{"percent marked correct", "Go was made at {1:SA:%100%Google}", true},
+		{"escaped brace", "Braces look like {1:SA:=\\}} in text", true},
+		{"no embedded answers", "The capital of France is Paris", false},
+		{"unknown field type", "The capital of France is {1:DROPDOWN:=Paris}", false},
+		{"no correct option", "The capital of France is {1:MC:Paris~Berlin}", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &Question{QuestionType: QuestionTypeCloze, QuestionText: tt.text}
+			err := q.ValidateAnswers()
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrInvalidAnswers)
+			}
+		})
+	}
+}
+
+func TestQuestion_ValidateAnswersWeights(t *testing.T) {
+	w := func(v float64) *float64 { return &v }
+	tests := []struct {
+		name         string
+		questionType QuestionType
+		answers      []Answer
+		valid        bool
+	}{
+		{"multiple choice split", QuestionTypeMultipleChoice, []Answer{
+			{AnswerText: "a", IsCorrect: true, Weight: w(66.66667)}, {AnswerText: "b", IsCorrect: true, Weight: w(33.33)}, {AnswerText: "c", Weight: w(-50)},
+		}, true},
+		{"multiple choice wrong answers only", QuestionTypeMultipleChoice, []Answer{
+			{AnswerText: "a", IsCorrect: true}, {AnswerText: "b", Weight: w(-100)},
+		}, true},
+		{"single choice partial credit", QuestionTypeSingleChoice, []Answer{
+			{AnswerText: "a", IsCorrect: true, Weight: w(100)}, {AnswerText: "b", Weight: w(50)}, {AnswerText: "c", Weight: w(-25)},
+		}, true},
+		{"not a Moodle fraction", QuestionTypeMultipleChoice, []Answer{
+			{AnswerText: "a", IsCorrect: true, Weight: w(65)}, {AnswerText: "b", IsCorrect: true, Weight: w(35)},
+		}, false},
+		{"correct weights below 100", QuestionTypeMultipleChoice, []Answer{
+			{AnswerText: "a", IsCorrect: true, Weight: w(50)}, {AnswerText: "b", IsCorrect: true, Weight: w(40)},
+		}, false},
+		{"some correct answers weighted", QuestionTypeMultipleChoice, []Answer{
+			{AnswerText: "a", IsCorrect: true, Weight: w(100)}, {AnswerText: "b", IsCorrect: true},
+		}, false},
+		{"positive wrong answer", QuestionTypeMultipleChoice, []Answer{
+			{AnswerText: "a", IsCorrect: true}, {AnswerText: "b", Weight: w(10)},
+		}, false},
+		{"single choice correct below 100", QuestionTypeSingleChoice, []Answer{
+			{AnswerText: "a", IsCorrect: true, Weight: w(90)}, {AnswerText: "b"},
+		}, false},
+		{"single choice wrong worth 100", QuestionTypeSingleChoice, []Answer{
+			{AnswerText: "a", IsCorrect: true}, {AnswerText: "b", Weight: w(100)},
+		}, false},
+		{"short answer partial credit", QuestionTypeShortAnswer, []Answer{
+			{AnswerText: "Moscow", IsCorrect: true}, {AnswerText: "Moskva", IsCorrect: true, Weight: w(50)},
+		}, true},
+		{"short answer variant worth nothing", QuestionTypeShortAnswer, []Answer{
+			{AnswerText: "Moscow", IsCorrect: true}, {AnswerText: "Moskva", IsCorrect: true, Weight: w(0)},
+		}, false},
+		{"not a choice or short answer question", QuestionTypeNumerical, []Answer{
+			{AnswerText: "3", IsCorrect: true, Weight: w(100)},
+		}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &Question{QuestionType: tt.questionType, Answers: tt.answers}
+			err := q.ValidateAnswers()
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrInvalidAnswers)
+			}
+		})
+	}
+}
+
+func TestQuestion_ValidateAnswersShortAnswerVariants(t *testing.T) {
+	half := 50.0
+	tests := []struct {
+		name          string
+		variants      []string
+		caseSensitive bool
+		valid         bool
+	}{
+		{"spellings", []string{"Москва", "Moskva", "Moscow"}, false, true},
+		{"wildcard after literal", []string{"Moscow", "Mosc*"}, false, true},
+		{"escaped asterisk", []string{"a\\*b", "a*b"}, false, true},
+		{"duplicate ignoring case", []string{"Moscow", "moscow"}, false, false},
+		{"differs in case", []string{"Moscow", "moscow"}, true, true},
+		{"shadowed by wildcard", []string{"Mosc*", "Moscow"}, false, false},
+		{"accepts anything", []string{"Moscow", "*"}, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &Question{QuestionType: QuestionTypeShortAnswer, CaseSensitive: tt.caseSensitive}
+			for _, v := range tt.variants {
+				q.Answers = append(q.Answers, Answer{AnswerText: v, IsCorrect: true})
+			}
+			err := q.ValidateAnswers()
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrInvalidAnswers)
+			}
+		})
+	}
+
+	t.Run("no full credit variant", func(t *testing.T) {
+		q := &Question{QuestionType: QuestionTypeShortAnswer, Answers: []Answer{{AnswerText: "Moskva", IsCorrect: true, Weight: &half}}}
+		assert.ErrorIs(t, q.ValidateAnswers(), ErrInvalidAnswers)
+	})
+
+	t.Run("case sensitivity outside short answer", func(t *testing.T) {
+		q := &Question{QuestionType: QuestionTypeSingleChoice, CaseSensitive: true,
+			Answers: []Answer{{AnswerText: "a", IsCorrect: true}, {AnswerText: "b"}}}
+		assert.ErrorIs(t, q.ValidateAnswers(), ErrInvalidAnswers)
+	})
+}
+
+func TestQuestion_ShortAnswerGrade(t *testing.T) {
+	half := 50.0
+	q := &Question{QuestionType: QuestionTypeShortAnswer, Answers: []Answer{
+		{AnswerText: "Moscow", IsCorrect: true},
+		{AnswerText: "Mosc*", IsCorrect: true, Weight: &half},
+		{AnswerText: "2\\*3", IsCorrect: true},
+	}}
+
+	assert.Equal(t, 100.0, q.ShortAnswerGrade("  moscow "))
+	assert.Equal(t, 50.0, q.ShortAnswerGrade("Moscow city"))
+	assert.Equal(t, 100.0, q.ShortAnswerGrade("2*3"))
+	assert.Equal(t, 0.0, q.ShortAnswerGrade("23"))
+	assert.Equal(t, 0.0, q.ShortAnswerGrade("Москва"))
+
+	q.CaseSensitive = true
+	assert.Equal(t, 50.0, q.ShortAnswerGrade("MoscOW"))
+	assert.Equal(t, 0.0, q.ShortAnswerGrade("moscow"))
+}
+
+func TestParseTrueFalse(t *testing.T) {
+	for text, want := range map[string]bool{
+		"Верно": true, " ВЕРНО. ": true, "Правда": true, "true": true, "True!": true, "Да": true,
+		"Неверно": false, "не  верно": false, "Ложь": false, "FALSE": false, "нет": false, "wrong": false,
+	} {
+		value, ok := ParseTrueFalse(text)
+		assert.True(t, ok, text)
+		assert.Equal(t, want, value, text)
+	}
+
+	_, ok := ParseTrueFalse("Возможно")
+	assert.False(t, ok)
+}
+
+func TestQuestion_NormalizeTrueFalseAnswers(t *testing.T) {
+	q := &Question{QuestionType: QuestionTypeTrueFalse, Answers: []Answer{{AnswerText: "Верно", IsCorrect: true}, {AnswerText: "Неверно"}}}
+	q.NormalizeTrueFalseAnswers()
+	assert.Equal(t, TrueFalseKeyTrue, q.Answers[0].AnswerText)
+	assert.Equal(t, TrueFalseKeyFalse, q.Answers[1].AnswerText)
+
+	assert.Equal(t, "Верно", AnswerLabel(QuestionTypeTrueFalse, q.Answers[0].AnswerText, "ru"))
+	assert.Equal(t, "False", AnswerLabel(QuestionTypeTrueFalse, q.Answers[1].AnswerText, "en"))
+	assert.Equal(t, "Неверно", AnswerLabel(QuestionTypeTrueFalse, q.Answers[1].AnswerText, "de"))
+	assert.Equal(t, "true", AnswerLabel(QuestionTypeShortAnswer, "true", "ru"))
+
+	choice := &Question{QuestionType: QuestionTypeSingleChoice, Answers: []Answer{{AnswerText: "Да", IsCorrect: true}, {AnswerText: "Нет"}}}
+	choice.NormalizeTrueFalseAnswers()
+	assert.Equal(t, "Да", choice.Answers[0].AnswerText)
+}
+
+func TestNearestAnswerWeight(t *testing.T) {
+	assert.Equal(t, 33.33333, NearestAnswerWeight(33.33))
+	assert.Equal(t, -12.5, NearestAnswerWeight(-12.4))
+	assert.True(t, IsAllowedAnswerWeight(-66.667))
+	assert.False(t, IsAllowedAnswerWeight(65))
+}
+
+func TestParseClozeFields(t *testing.T) {
+	fields, err := ParseClozeFields("A {2:MULTICHOICE:=cat~dog\\~wolf~bird} and {:SA:=x}")
+	assert.NoError(t, err)
+	assert.Len(t, fields, 2)
+	assert.Equal(t, "2", fields[0].Weight)
+	assert.Equal(t, "MULTICHOICE", fields[0].Type)
+	assert.Equal(t, []string{"=cat", "dog\\~wolf", "bird"}, fields[0].Options)
+	assert.Equal(t, "", fields[1].Weight)
+}
@@ -0,0 +1,37 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SearchResultType identifies what kind of object a search hit refers to
+type SearchResultType string
+
+const (
+	SearchResultDocument SearchResultType = "document"
+	SearchResultTest     SearchResultType = "test"
+	SearchResultQuestion SearchResultType = "question"
+)
+
+// IsValid checks if the search result type is supported
+func (t SearchResultType) IsValid() bool {
+	switch t {
+	case SearchResultDocument, SearchResultTest, SearchResultQuestion:
+		return true
+	}
+	return false
+}
+
+// SearchResult is a single full-text search hit. It is a read model and has no table.
+type SearchResult struct {
+	Type      SearchResultType
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	TestID    *uuid.UUID // Parent test of a question
+	Title     string     // Document or test title; for questions, the title of the parent test
+	Snippet   string     // Matching fragment, HTML-escaped, with matches wrapped in <mark>
+	Rank      float64
+	CreatedAt time.Time
+}
@@ -0,0 +1,93 @@
+package entity
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ShortAnswerPattern compiles an accepted variant of a short answer question
+// the way Moodle reads it: * matches any run of characters, \* is a literal
+// asterisk and surrounding whitespace is ignored
+func ShortAnswerPattern(variant string, caseSensitive bool) *regexp.Regexp {
+	var expr strings.Builder
+	if !caseSensitive {
+		expr.WriteString("(?i)")
+	}
+	expr.WriteString(`(?s)^`)
+
+	var literal strings.Builder
+	flush := func() {
+		expr.WriteString(regexp.QuoteMeta(literal.String()))
+		literal.Reset()
+	}
+	runes := []rune(strings.TrimSpace(variant))
+	for i := 0; i < len(runes); i++ {
+		switch {
+		case runes[i] == '\\' && i+1 < len(runes) && runes[i+1] == '*':
+			literal.WriteRune('*')
+			i++
+		case runes[i] == '*':
+			flush()
+			expr.WriteString(".*")
+		default:
+			literal.WriteRune(runes[i])
+		}
+	}
+	flush()
+	expr.WriteString("$")
+
+	return regexp.MustCompile(expr.String())
+}
+
+// ShortAnswerShadows reports whether the earlier variant already accepts
+// every response the later one does. Only wildcard-free later variants are
+// checked, since comparing two patterns is beyond a simple match.
+func ShortAnswerShadows(earlier, later string, caseSensitive bool) bool {
+	if strings.Contains(strings.ReplaceAll(later, `\*`, ""), "*") {
+		return false
+	}
+	literal := strings.ReplaceAll(strings.TrimSpace(later), `\*`, "*")
+	return ShortAnswerPattern(earlier, caseSensitive).MatchString(literal)
+}
+
+// ShortAnswerGrade returns the grade percentage a response earns: the weight
+// of the first variant it matches, 100 for variants without a weight and 0
+// when none matches
+func (q *Question) ShortAnswerGrade(response string) float64 {
+	response = strings.TrimSpace(response)
+	for _, a := range q.Answers {
+		if !ShortAnswerPattern(a.AnswerText, q.CaseSensitive).MatchString(response) {
+			continue
+		}
+		if a.Weight != nil {
+			return NearestAnswerWeight(*a.Weight)
+		}
+		return 100
+	}
+	return 0
+}
+
+// validateShortAnswerVariants rejects variants that accept any response and
+// variants an earlier one already matches, since Moodle grades a response by
+// the first variant it fits and the later one would never be used
+func (q *Question) validateShortAnswerVariants() error {
+	full := false
+	for i, a := range q.Answers {
+		if strings.Trim(a.AnswerText, "* \t\n") == "" {
+			return fmt.Errorf("%w: variant %q accepts any response", ErrInvalidAnswers, a.AnswerText)
+		}
+		if a.Weight == nil || NearestAnswerWeight(*a.Weight) == 100 {
+			full = true
+		}
+		for _, earlier := range q.Answers[:i] {
+			if ShortAnswerShadows(earlier.AnswerText, a.AnswerText, q.CaseSensitive) {
+				return fmt.Errorf("%w: variant %q is never used because %q comes first", ErrInvalidAnswers, a.AnswerText, earlier.AnswerText)
+			}
+		}
+	}
+	if !full {
+		return fmt.Errorf("%w: at least 1 short answer variant must be worth 100", ErrInvalidAnswers)
+	}
+	return nil
+}
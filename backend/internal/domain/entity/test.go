@@ -1,6 +1,9 @@
 package entity
 
 import (
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,19 +17,36 @@ const (
 	TestStatusArchived  TestStatus = "archived"
 )
 
+// ErrInvalidStatusTransition is returned when a test cannot move from its
+// status to the requested one
+var ErrInvalidStatusTransition = errors.New("invalid test status transition")
+
+// testTransitions lists the statuses a test may move to: a draft is published
+// or archived, a published test goes back to draft or is archived, and an
+// archived test only lives on through a new version. Republishing a draft that
+// was published before bumps its version, see Publish.
+var testTransitions = map[TestStatus][]TestStatus{
+	TestStatusDraft:     {TestStatusPublished, TestStatusArchived},
+	TestStatusPublished: {TestStatusDraft, TestStatusArchived},
+	TestStatusArchived:  {},
+}
+
 type Test struct {
-	ID             uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
-	UserID         uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
-	DocumentID     *uuid.UUID `json:"document_id,omitempty" gorm:"type:uuid;index"`
-	Title          string     `json:"title" gorm:"type:varchar(500);not null"`
-	Description    string     `json:"description,omitempty" gorm:"type:text"`
-	TotalQuestions int        `json:"total_questions" gorm:"default:0"`
-	Status         TestStatus `json:"status" gorm:"type:varchar(50);default:'draft';index"`
-	MoodleSynced   bool       `json:"moodle_synced" gorm:"default:false"`
-	MoodleTestID   string     `json:"moodle_test_id,omitempty" gorm:"type:varchar(255)"`
-	CreatedAt      time.Time  `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt      time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
-	DeletedAt      *time.Time `json:"deleted_at,omitempty" gorm:"index"`
+	ID                uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	UserID            uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	DocumentID        *uuid.UUID `json:"document_id,omitempty" gorm:"type:uuid;index"`
+	Title             string     `json:"title" gorm:"type:varchar(500);not null"`
+	Description       string     `json:"description,omitempty" gorm:"type:text"`
+	TotalQuestions    int        `json:"total_questions" gorm:"default:0"`
+	Status            TestStatus `json:"status" gorm:"type:varchar(50);default:'draft';index"`
+	MoodleSynced      bool       `json:"moodle_synced" gorm:"default:false"`
+	MoodleTestID      string     `json:"moodle_test_id,omitempty" gorm:"type:varchar(255)"`
+	Version           int        `json:"version" gorm:"default:1"`
+	PreviousVersionID *uuid.UUID `json:"previous_version_id,omitempty" gorm:"type:uuid;index"` // Test this version was created from
+	PublishedAt       *time.Time `json:"published_at,omitempty"`                               // Last time the test was published
+	CreatedAt         time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt         time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt         *time.Time `json:"deleted_at,omitempty" gorm:"index"`
 
 	// Relations
 	User      User       `json:"user,omitempty" gorm:"foreignKey:UserID"`
@@ -44,14 +64,107 @@ func (t *Test) IsPublished() bool {
 	return t.Status == TestStatusPublished
 }
 
-// Publish marks test as published
-func (t *Test) Publish() {
-	t.Status = TestStatusPublished
+// IsEditable checks if the test and its questions may be changed; only drafts
+// are, a published or archived test is changed through a new version
+func (t *Test) IsEditable() bool {
+	return t.currentStatus() == TestStatusDraft
+}
+
+// CanTransitionTo checks if the test may move to the given status
+func (t *Test) CanTransitionTo(status TestStatus) bool {
+	for _, next := range testTransitions[t.currentStatus()] {
+		if next == status {
+			return true
+		}
+	}
+	return false
+}
+
+// Publish marks test as published. A test that was published before and
+// taken back to draft may have changed since, so it gets the next version.
+func (t *Test) Publish() error {
+	if err := t.transitionTo(TestStatusPublished); err != nil {
+		return err
+	}
+	if t.PublishedAt != nil {
+		t.Version = t.NextVersion()
+	}
+	now := time.Now()
+	t.PublishedAt = &now
+	return nil
+}
+
+// Unpublish takes a published test back to draft
+func (t *Test) Unpublish() error {
+	return t.transitionTo(TestStatusDraft)
 }
 
 // Archive marks test as archived
-func (t *Test) Archive() {
-	t.Status = TestStatusArchived
+func (t *Test) Archive() error {
+	return t.transitionTo(TestStatusArchived)
+}
+
+func (t *Test) transitionTo(status TestStatus) error {
+	if !t.CanTransitionTo(status) {
+		return fmt.Errorf("%w: %s to %s", ErrInvalidStatusTransition, t.currentStatus(), status)
+	}
+	t.Status = status
+	return nil
+}
+
+// currentStatus treats a test without a status as a draft, the column default
+func (t *Test) currentStatus() TestStatus {
+	if t.Status == "" {
+		return TestStatusDraft
+	}
+	return t.Status
+}
+
+// NextVersion returns the version number of a new version of the test
+func (t *Test) NextVersion() int {
+	if t.Version < 1 {
+		return 2
+	}
+	return t.Version + 1
+}
+
+// PublishProblem is a reason a test cannot be published. QuestionID is nil for
+// problems of the test as a whole.
+type PublishProblem struct {
+	QuestionID *uuid.UUID
+	OrderNum   int
+	Message    string
+}
+
+// PublishProblems runs the checks a test must pass before students see it: it
+// has questions, and every question has text, points above zero and answers
+// that fit its type
+func PublishProblems(questions []*Question) []PublishProblem {
+	if len(questions) == 0 {
+		return []PublishProblem{{Message: "test has no questions"}}
+	}
+
+	var problems []PublishProblem
+	for _, q := range questions {
+		add := func(message string) {
+			id := q.ID
+			problems = append(problems, PublishProblem{QuestionID: &id, OrderNum: q.OrderNum, Message: message})
+		}
+		if strings.TrimSpace(q.QuestionText) == "" {
+			add("question text is empty")
+		}
+		if q.Points <= 0 {
+			add("points must be greater than 0")
+		}
+		if !q.IsValidType() {
+			add(fmt.Sprintf("unsupported question type %q", q.QuestionType))
+			continue
+		}
+		if err := q.ValidateAnswers(); err != nil {
+			add(err.Error())
+		}
+	}
+	return problems
 }
 
 // UpdateQuestionsCount updates total questions count
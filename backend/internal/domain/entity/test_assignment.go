@@ -0,0 +1,23 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TestAssignment gives a student access to a test once it is published
+type TestAssignment struct {
+	TestID     uuid.UUID `json:"test_id" gorm:"type:uuid;primaryKey"`
+	StudentID  uuid.UUID `json:"student_id" gorm:"type:uuid;primaryKey;index"`
+	AssignedBy uuid.UUID `json:"assigned_by" gorm:"type:uuid;not null"`
+	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime"`
+
+	// Relations
+	Student User `json:"student,omitempty" gorm:"foreignKey:StudentID"`
+}
+
+// TableName specifies the table name for GORM
+func (TestAssignment) TableName() string {
+	return "test_assignments"
+}
@@ -21,16 +21,97 @@ func TestTest_IsPublished(t *testing.T) {
 
 func TestTest_Publish(t *testing.T) {
 	test := &Test{Status: TestStatusDraft}
-	test.Publish()
+	assert.NoError(t, test.Publish())
 	assert.Equal(t, TestStatusPublished, test.Status)
 }
 
 func TestTest_Archive(t *testing.T) {
 	test := &Test{Status: TestStatusPublished}
-	test.Archive()
+	assert.NoError(t, test.Archive())
 	assert.Equal(t, TestStatusArchived, test.Status)
 }
 
+func TestTest_StatusTransitions(t *testing.T) {
+	tests := []struct {
+		name       string
+		from       TestStatus
+		transition func(*Test) error
+		allowed    bool
+	}{
+		{"publish draft", TestStatusDraft, (*Test).Publish, true},
+		{"publish unset status", "", (*Test).Publish, true},
+		{"publish published", TestStatusPublished, (*Test).Publish, false},
+		{"publish archived", TestStatusArchived, (*Test).Publish, false},
+		{"unpublish published", TestStatusPublished, (*Test).Unpublish, true},
+		{"unpublish draft", TestStatusDraft, (*Test).Unpublish, false},
+		{"unpublish archived", TestStatusArchived, (*Test).Unpublish, false},
+		{"archive draft", TestStatusDraft, (*Test).Archive, true},
+		{"archive archived", TestStatusArchived, (*Test).Archive, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			test := &Test{Status: tt.from}
+			err := tt.transition(test)
+			if tt.allowed {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, ErrInvalidStatusTransition)
+			assert.Equal(t, tt.from, test.Status)
+		})
+	}
+}
+
+func TestTest_RepublishBumpsVersion(t *testing.T) {
+	test := &Test{Status: TestStatusDraft, Version: 1}
+	assert.NoError(t, test.Publish())
+	assert.Equal(t, 1, test.Version, "first publication keeps the version")
+	assert.NotNil(t, test.PublishedAt)
+
+	assert.NoError(t, test.Unpublish())
+	assert.NoError(t, test.Publish())
+	assert.Equal(t, 2, test.Version)
+}
+
+func TestTest_IsEditable(t *testing.T) {
+	assert.True(t, (&Test{Status: TestStatusDraft}).IsEditable())
+	assert.True(t, (&Test{}).IsEditable())
+	assert.False(t, (&Test{Status: TestStatusPublished}).IsEditable())
+	assert.False(t, (&Test{Status: TestStatusArchived}).IsEditable())
+}
+
+func TestTest_NextVersion(t *testing.T) {
+	assert.Equal(t, 2, (&Test{}).NextVersion())
+	assert.Equal(t, 2, (&Test{Version: 1}).NextVersion())
+	assert.Equal(t, 4, (&Test{Version: 3}).NextVersion())
+}
+
+func TestPublishProblems(t *testing.T) {
+	assert.Len(t, PublishProblems(nil), 1)
+
+	valid := &Question{
+		QuestionText: "Pick one",
+		QuestionType: QuestionTypeSingleChoice,
+		Points:       1,
+		OrderNum:     1,
+		Answers:      []Answer{{AnswerText: "a", IsCorrect: true}, {AnswerText: "b"}},
+	}
+	assert.Empty(t, PublishProblems([]*Question{valid}))
+
+	broken := &Question{
+		QuestionText: " ",
+		QuestionType: QuestionTypeSingleChoice,
+		OrderNum:     2,
+		Answers:      []Answer{{AnswerText: "a"}, {AnswerText: "b"}},
+	}
+	problems := PublishProblems([]*Question{valid, broken})
+	assert.Len(t, problems, 3)
+	for _, p := range problems {
+		assert.Equal(t, 2, p.OrderNum)
+	}
+}
+
 func TestTest_UpdateQuestionsCount(t *testing.T) {
 	test := &Test{TotalQuestions: 0}
 	test.UpdateQuestionsCount(10)
@@ -0,0 +1,31 @@
+package entity
+
+import "github.com/shester1kov/testgen-backend/pkg/textstats"
+
+// TextStats holds statistics of a document's effective text
+type TextStats struct {
+	WordCount        int            `json:"word_count" gorm:"default:0"`
+	CharCount        int            `json:"char_count" gorm:"default:0"`
+	SectionCount     int            `json:"section_count" gorm:"default:0"`
+	Language         string         `json:"language,omitempty" gorm:"type:varchar(16)"`
+	ReadabilityScore float64        `json:"readability_score" gorm:"default:0"`
+	TokenEstimates   map[string]int `json:"token_estimates,omitempty" gorm:"type:jsonb;serializer:json"` // Provider name -> estimated prompt tokens
+}
+
+// NewTextStats analyzes text
+func NewTextStats(text string) TextStats {
+	stats := textstats.Analyze(text)
+	return TextStats{
+		WordCount:        stats.Words,
+		CharCount:        stats.Characters,
+		SectionCount:     stats.Sections,
+		Language:         stats.Language,
+		ReadabilityScore: stats.Readability,
+		TokenEstimates:   stats.Tokens,
+	}
+}
+
+// IsComputed reports whether statistics were computed; documents parsed before they existed have none
+func (s TextStats) IsComputed() bool {
+	return s.TokenEstimates != nil
+}
@@ -0,0 +1,111 @@
+package entity
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Answers of true/false questions are stored under canonical keys, whatever
+// language they were written in; clients see them as localized labels
+const (
+	TrueFalseKeyTrue  = "true"
+	TrueFalseKeyFalse = "false"
+)
+
+// DefaultLabelLanguage is the language of true/false labels when the client
+// does not ask for one of LabelLanguages
+const DefaultLabelLanguage = "ru"
+
+// LabelLanguages lists the languages true/false labels are rendered in
+var LabelLanguages = []string{"ru", "en"}
+
+var trueFalseLabels = map[string][2]string{
+	"ru": {"Неверно", "Верно"},
+	"en": {"False", "True"},
+}
+
+// trueFalseWords maps the ways an answer of a true/false question is written
+// to the statement it stands for
+var trueFalseWords = map[string]bool{
+	"true": true, "t": true, "yes": true, "y": true, "correct": true, "right": true, "1": true,
+	"верно": true, "правда": true, "истина": true, "истинно": true, "да": true, "правильно": true, "верное утверждение": true,
+
+	"false": false, "f": false, "no": false, "n": false, "incorrect": false, "wrong": false, "0": false,
+	"неверно": false, "не верно": false, "ложь": false, "ложно": false, "нет": false, "неправильно": false,
+	"неверное утверждение": false,
+}
+
+// ParseTrueFalse reads an answer of a true/false question in any supported
+// language, ignoring case, surrounding punctuation and repeated spaces.
+// Returns false as the second value for text it does not recognize.
+func ParseTrueFalse(text string) (value bool, ok bool) {
+	text = strings.TrimFunc(strings.ToLower(text), func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsPunct(r)
+	})
+	value, ok = trueFalseWords[strings.Join(strings.Fields(text), " ")]
+	return value, ok
+}
+
+// TrueFalseKey returns the canonical key an answer of the given value is stored under
+func TrueFalseKey(value bool) string {
+	if value {
+		return TrueFalseKeyTrue
+	}
+	return TrueFalseKeyFalse
+}
+
+// TrueFalseLabel renders a true/false answer in the given language, falling
+// back to DefaultLabelLanguage
+func TrueFalseLabel(value bool, language string) string {
+	labels, ok := trueFalseLabels[language]
+	if !ok {
+		labels = trueFalseLabels[DefaultLabelLanguage]
+	}
+	if value {
+		return labels[1]
+	}
+	return labels[0]
+}
+
+// NormalizeAnswerText returns the text an answer of a question of the given
+// type is stored as: the canonical key of a recognized true/false answer, the
+// text itself otherwise
+func NormalizeAnswerText(questionType QuestionType, text string) string {
+	if questionType != QuestionTypeTrueFalse {
+		return text
+	}
+	if value, ok := ParseTrueFalse(text); ok {
+		return TrueFalseKey(value)
+	}
+	return text
+}
+
+// NormalizeTrueFalseAnswers replaces recognized answers of a true/false
+// question with their canonical keys. Unrecognized answers are kept for
+// ValidateAnswers to reject; other question types are left unchanged.
+func (q *Question) NormalizeTrueFalseAnswers() {
+	for i := range q.Answers {
+		q.Answers[i].AnswerText = NormalizeAnswerText(q.QuestionType, q.Answers[i].AnswerText)
+	}
+}
+
+// NormalizeTrueFalseAnswers replaces recognized answers of a true/false bank
+// question with their canonical keys, like Question.NormalizeTrueFalseAnswers
+func (q *BankQuestion) NormalizeTrueFalseAnswers() {
+	for i := range q.Answers {
+		q.Answers[i].AnswerText = NormalizeAnswerText(q.QuestionType, q.Answers[i].AnswerText)
+	}
+}
+
+// AnswerLabel returns how an answer of a question of the given type is shown
+// in the given language: true/false answers are rendered as labels, others
+// as stored
+func AnswerLabel(questionType QuestionType, text, language string) string {
+	if questionType != QuestionTypeTrueFalse {
+		return text
+	}
+	if value, ok := ParseTrueFalse(text); ok {
+		return TrueFalseLabel(value, language)
+	}
+	return text
+}
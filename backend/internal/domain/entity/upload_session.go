@@ -0,0 +1,84 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type UploadSessionStatus string
+
+const (
+	UploadSessionActive    UploadSessionStatus = "active"
+	UploadSessionCompleted UploadSessionStatus = "completed"
+)
+
+// UploadSession tracks a resumable upload sent in fixed-size chunks.
+// Chunks are staged in file storage and assembled into a document on completion.
+type UploadSession struct {
+	ID           uuid.UUID           `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	UserID       uuid.UUID           `json:"user_id" gorm:"type:uuid;not null;index"`
+	FileName     string              `json:"file_name" gorm:"type:varchar(500);not null"`
+	Title        string              `json:"title" gorm:"type:varchar(500)"`
+	ContentType  string              `json:"content_type" gorm:"type:varchar(255)"`
+	TotalSize    int64               `json:"total_size" gorm:"not null"`
+	ChunkSize    int64               `json:"chunk_size" gorm:"not null"`
+	ReceivedSize int64               `json:"received_size" gorm:"not null;default:0"`    // Offset the next chunk must start at
+	Checksum     string              `json:"checksum,omitempty" gorm:"type:varchar(64)"` // Expected SHA-256 of the whole file, hex
+	Status       UploadSessionStatus `json:"status" gorm:"type:varchar(50);default:'active'"`
+	DocumentID   *uuid.UUID          `json:"document_id,omitempty" gorm:"type:uuid"` // Set on completion
+	ExpiresAt    time.Time           `json:"expires_at" gorm:"not null;index"`
+	CreatedAt    time.Time           `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt    time.Time           `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName specifies the table name for GORM
+func (UploadSession) TableName() string {
+	return "upload_sessions"
+}
+
+// ChunkCount returns how many chunks make up the file; the last one may be shorter
+func (s *UploadSession) ChunkCount() int {
+	if s.ChunkSize <= 0 {
+		return 0
+	}
+	return int((s.TotalSize + s.ChunkSize - 1) / s.ChunkSize)
+}
+
+// ReceivedChunks returns how many chunks have been stored
+func (s *UploadSession) ReceivedChunks() int {
+	if s.IsFullyReceived() {
+		return s.ChunkCount()
+	}
+	return int(s.ReceivedSize / s.ChunkSize)
+}
+
+// ExpectedChunkSize returns the size the chunk at the current offset must have
+func (s *UploadSession) ExpectedChunkSize() int64 {
+	remaining := s.TotalSize - s.ReceivedSize
+	if remaining < s.ChunkSize {
+		return remaining
+	}
+	return s.ChunkSize
+}
+
+// IsFullyReceived checks whether all chunks have been stored
+func (s *UploadSession) IsFullyReceived() bool {
+	return s.ReceivedSize >= s.TotalSize
+}
+
+// IsActive checks whether the session still accepts chunks or completion
+func (s *UploadSession) IsActive() bool {
+	return s.Status == UploadSessionActive
+}
+
+// IsExpired checks whether the session outlived its expiry time
+func (s *UploadSession) IsExpired(now time.Time) bool {
+	return now.After(s.ExpiresAt)
+}
+
+// MarkAsCompleted records the document created from the upload
+func (s *UploadSession) MarkAsCompleted(documentID uuid.UUID) {
+	s.Status = UploadSessionCompleted
+	s.DocumentID = &documentID
+}
@@ -0,0 +1,40 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUploadSession_Chunks(t *testing.T) {
+	session := &UploadSession{TotalSize: 10, ChunkSize: 4, Status: UploadSessionActive}
+
+	assert.Equal(t, 3, session.ChunkCount())
+	assert.Equal(t, 0, session.ReceivedChunks())
+	assert.Equal(t, int64(4), session.ExpectedChunkSize())
+
+	session.ReceivedSize = 8
+	assert.Equal(t, 2, session.ReceivedChunks())
+	assert.Equal(t, int64(2), session.ExpectedChunkSize())
+	assert.False(t, session.IsFullyReceived())
+
+	session.ReceivedSize = 10
+	assert.Equal(t, 3, session.ReceivedChunks())
+	assert.True(t, session.IsFullyReceived())
+}
+
+func TestUploadSession_Lifecycle(t *testing.T) {
+	now := time.Now()
+	session := &UploadSession{Status: UploadSessionActive, ExpiresAt: now.Add(time.Hour)}
+
+	assert.True(t, session.IsActive())
+	assert.False(t, session.IsExpired(now))
+	assert.True(t, session.IsExpired(now.Add(2*time.Hour)))
+
+	documentID := uuid.New()
+	session.MarkAsCompleted(documentID)
+	assert.False(t, session.IsActive())
+	assert.Equal(t, &documentID, session.DocumentID)
+}
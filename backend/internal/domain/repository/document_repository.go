@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/shester1kov/testgen-backend/internal/domain/entity"
@@ -17,4 +18,41 @@ type DocumentRepository interface {
 	Delete(ctx context.Context, id uuid.UUID) error
 	CountByUserID(ctx context.Context, userID uuid.UUID) (int64, error)
 	CountAll(ctx context.Context) (int64, error)
+
+	// FindByStatus retrieves documents in the given status, oldest first
+	FindByStatus(ctx context.Context, status entity.DocumentStatus, limit int) ([]*entity.Document, error)
+
+	// ClaimForParsing atomically moves an uploaded document to parsing.
+	// Returns false when the document is missing or was claimed by someone else.
+	ClaimForParsing(ctx context.Context, id uuid.UUID) (bool, error)
+
+	// UpdateParseState writes only the parsing columns of a document (status, progress,
+	// attempts, error and, once parsed, the parsed text and its statistics).
+	// Other columns and soft-deleted documents are left untouched, and so are
+	// the statistics of a document that has a text correction.
+	UpdateParseState(ctx context.Context, document *entity.Document) error
+
+	// ResetStaleParsing moves documents stuck in parsing since before the given time back to uploaded
+	ResetStaleParsing(ctx context.Context, before time.Time) (int64, error)
+
+	// FindByContentHash retrieves non-deleted documents with the given SHA-256, oldest first
+	FindByContentHash(ctx context.Context, contentHash string) ([]*entity.Document, error)
+
+	// CountByFilePath counts non-deleted documents referencing the stored file
+	CountByFilePath(ctx context.Context, filePath string) (int64, error)
+
+	// FindDeletedBefore retrieves documents soft-deleted before the given time whose files
+	// are not purged yet, oldest deletion first
+	FindDeletedBefore(ctx context.Context, before time.Time, limit, offset int) ([]*entity.Document, error)
+
+	// CountRetainedByFilePath counts documents that still need the stored file:
+	// not deleted, or deleted at or after the given time and not purged
+	CountRetainedByFilePath(ctx context.Context, filePath string, deletedBefore time.Time) (int64, error)
+
+	// MarkFilePurged records that the file of a deleted document was removed from storage
+	MarkFilePurged(ctx context.Context, id uuid.UUID) error
+
+	// FindFilePaths returns distinct file paths of all documents whose files are not purged,
+	// including deleted documents still within retention
+	FindFilePaths(ctx context.Context) ([]string, error)
 }
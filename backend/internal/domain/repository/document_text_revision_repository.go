@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/shester1kov/testgen-backend/internal/domain/entity"
+)
+
+// DocumentTextRevisionRepository defines the interface for corrected document text history
+type DocumentTextRevisionRepository interface {
+	// Create stores a new revision and makes it the document's current text.
+	// The revision number is assigned atomically and written back to revision.
+	Create(ctx context.Context, revision *entity.DocumentTextRevision) error
+
+	// FindByDocumentID retrieves all revisions of a document, newest first
+	FindByDocumentID(ctx context.Context, documentID uuid.UUID) ([]*entity.DocumentTextRevision, error)
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/shester1kov/testgen-backend/internal/domain/entity"
+)
+
+// ErrBankQuestionInTest is returned when a bank question is added to a test it is already part of
+var ErrBankQuestionInTest = errors.New("bank question is already in the test")
+
+// QuestionCategoryRepository defines the interface for question bank categories
+type QuestionCategoryRepository interface {
+	Create(ctx context.Context, category *entity.QuestionCategory) error
+	FindByID(ctx context.Context, id uuid.UUID) (*entity.QuestionCategory, error)
+
+	// FindByUserID retrieves all categories of a user ordered by name
+	FindByUserID(ctx context.Context, userID uuid.UUID) ([]*entity.QuestionCategory, error)
+
+	Update(ctx context.Context, category *entity.QuestionCategory) error
+
+	// Delete deletes a category with its subcategories; their questions become uncategorized
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// BankQuestionFilter describes which bank questions to list
+type BankQuestionFilter struct {
+	UserID               uuid.UUID
+	CategoryID           *uuid.UUID
+	IncludeSubcategories bool     // Also match questions of nested categories of CategoryID
+	Tags                 []string // Questions must carry every tag
+	QuestionType         entity.QuestionType
+	Difficulty           entity.Difficulty
+	Text                 string // Case-insensitive substring of the question text
+	Limit                int
+	Offset               int
+}
+
+// BankQuestionRepository defines the interface for question bank operations
+type BankQuestionRepository interface {
+	// Create saves bank questions with their answers and tags in one transaction;
+	// tags are matched by name and created when missing
+	Create(ctx context.Context, questions ...*entity.BankQuestion) error
+
+	// FindByID retrieves a bank question with its answers and tags
+	FindByID(ctx context.Context, id uuid.UUID) (*entity.BankQuestion, error)
+
+	// FindByIDs retrieves bank questions with their answers and tags in no particular order
+	FindByIDs(ctx context.Context, ids []uuid.UUID) ([]*entity.BankQuestion, error)
+
+	// List retrieves matching bank questions with their answers and tags,
+	// newest first, and the total number of matches
+	List(ctx context.Context, filter BankQuestionFilter) ([]*entity.BankQuestion, int64, error)
+
+	// FindMatching retrieves every matching bank question without answers and
+	// tags, ignoring Limit and Offset
+	FindMatching(ctx context.Context, filter BankQuestionFilter) ([]*entity.BankQuestion, error)
+
+	// Update saves a bank question, replacing its answers and tags
+	Update(ctx context.Context, question *entity.BankQuestion) error
+
+	// Delete deletes a bank question; copies already added to tests are kept
+	Delete(ctx context.Context, id uuid.UUID) error
+
+	// FindTagsByUserID retrieves the tags of a user ordered by name
+	FindTagsByUserID(ctx context.Context, userID uuid.UUID) ([]*entity.QuestionTag, error)
+
+	// FindTestIDs retrieves the tests a bank question was added to
+	FindTestIDs(ctx context.Context, id uuid.UUID) ([]uuid.UUID, error)
+
+	// AddToTest copies bank questions to the end of a test in the given order,
+	// links them to the test and refreshes its total_questions in one transaction.
+	// Returns ErrBankQuestionInTest when one of them is already in the test.
+	AddToTest(ctx context.Context, testID uuid.UUID, questions []*entity.BankQuestion) ([]*entity.Question, error)
+
+	// CreateTest saves a new test with its questions and answers like
+	// TestRepository.CreateWithQuestions, together with the links of the
+	// questions copied from the bank, in one transaction
+	CreateTest(ctx context.Context, test *entity.Test, links []entity.TestBankQuestion) error
+}
@@ -2,11 +2,16 @@ package repository
 
 import (
 	"context"
+	"errors"
 
 	"github.com/google/uuid"
 	"github.com/shester1kov/testgen-backend/internal/domain/entity"
 )
 
+// ErrQuestionSetMismatch is returned when a reorder request does not list
+// every question of the test exactly once
+var ErrQuestionSetMismatch = errors.New("question IDs do not match the test's questions")
+
 // QuestionRepository defines the interface for question data operations
 type QuestionRepository interface {
 	// Create creates a new question
@@ -18,6 +23,10 @@ type QuestionRepository interface {
 	// FindByTestID retrieves all questions for a specific test
 	FindByTestID(ctx context.Context, testID uuid.UUID) ([]*entity.Question, error)
 
+	// FindByTestIDWithAnswers retrieves all questions for a specific test with
+	// their answers loaded, using a constant number of queries
+	FindByTestIDWithAnswers(ctx context.Context, testID uuid.UUID) ([]*entity.Question, error)
+
 	// Update updates an existing question
 	Update(ctx context.Context, question *entity.Question) error
 
@@ -27,7 +36,17 @@ type QuestionRepository interface {
 	// CountByTestID counts questions for a specific test
 	CountByTestID(ctx context.Context, testID uuid.UUID) (int, error)
 
-	// ReorderQuestions updates the order of questions
+	// CreateInTest creates a question with its answers at question.OrderNum
+	// (appending when it is out of range), shifting later questions and
+	// refreshing the test's total_questions in one transaction
+	CreateInTest(ctx context.Context, question *entity.Question) error
+
+	// DeleteFromTest deletes a question with its answers, closes the gap in
+	// order_num and refreshes the test's total_questions in one transaction
+	DeleteFromTest(ctx context.Context, testID, questionID uuid.UUID) error
+
+	// ReorderQuestions updates the order of questions; questionIDs must list
+	// every question of the test exactly once
 	ReorderQuestions(ctx context.Context, testID uuid.UUID, questionIDs []uuid.UUID) error
 
 	// CountByUserID counts questions for a specific user
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/shester1kov/testgen-backend/internal/domain/entity"
+)
+
+// SearchQuery describes a full-text search request
+type SearchQuery struct {
+	Text   string
+	UserID *uuid.UUID                // Restrict results to objects owned by this user; nil searches everything
+	Types  []entity.SearchResultType // Empty means all types
+	Limit  int
+	Offset int
+}
+
+// SearchRepository defines full-text search across documents, tests and questions
+type SearchRepository interface {
+	// Search returns hits ordered by relevance and the total number of hits
+	Search(ctx context.Context, query SearchQuery) ([]*entity.SearchResult, int64, error)
+}
@@ -2,19 +2,40 @@ package repository
 
 import (
 	"context"
+	"errors"
 
 	"github.com/google/uuid"
 	"github.com/shester1kov/testgen-backend/internal/domain/entity"
 )
 
+// ErrTestLocked is returned when a change targets a test that is no longer a draft
+var ErrTestLocked = errors.New("test is not editable")
+
 // TestRepository defines the interface for test data operations
 type TestRepository interface {
 	Create(ctx context.Context, test *entity.Test) error
+	// CreateWithQuestions saves a test with its questions and answers in one
+	// transaction and sets TotalQuestions to the number of questions
+	CreateWithQuestions(ctx context.Context, test *entity.Test) error
 	FindByID(ctx context.Context, id uuid.UUID) (*entity.Test, error)
+	// FindByIDForUpdate retrieves a test without relations and row-locks it
+	// until the surrounding transaction ends
+	FindByIDForUpdate(ctx context.Context, id uuid.UUID) (*entity.Test, error)
 	FindByUserID(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*entity.Test, error)
 	FindAll(ctx context.Context, limit, offset int) ([]*entity.Test, error)
 	Update(ctx context.Context, test *entity.Test) error
+	// UpdateStatus writes only the publication state of a test: status,
+	// version, published_at and updated_at
+	UpdateStatus(ctx context.Context, test *entity.Test) error
 	Delete(ctx context.Context, id uuid.UUID) error
 	CountByUserID(ctx context.Context, userID uuid.UUID) (int64, error)
 	CountAll(ctx context.Context) (int64, error)
+	// FindPublishedByStudentID lists published tests assigned to the student
+	FindPublishedByStudentID(ctx context.Context, studentID uuid.UUID, limit, offset int) ([]*entity.Test, error)
+	CountPublishedByStudentID(ctx context.Context, studentID uuid.UUID) (int64, error)
+	// AssignStudents adds assignments, keeping the ones that already exist
+	AssignStudents(ctx context.Context, assignments []entity.TestAssignment) error
+	UnassignStudent(ctx context.Context, testID, studentID uuid.UUID) error
+	// FindAssignments lists the assignments of a test with their students
+	FindAssignments(ctx context.Context, testID uuid.UUID) ([]*entity.TestAssignment, error)
 }
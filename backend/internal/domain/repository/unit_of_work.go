@@ -0,0 +1,17 @@
+package repository
+
+import "context"
+
+// TxRepositories holds repositories bound to one unit of work
+type TxRepositories struct {
+	Tests     TestRepository
+	Questions QuestionRepository
+	Answers   AnswerRepository
+}
+
+// UnitOfWork runs several repository operations atomically
+type UnitOfWork interface {
+	// Do runs fn in a single transaction; every change made through the
+	// given repositories is rolled back when fn returns an error
+	Do(ctx context.Context, fn func(repos TxRepositories) error) error
+}
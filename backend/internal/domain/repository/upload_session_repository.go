@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shester1kov/testgen-backend/internal/domain/entity"
+)
+
+// UploadSessionRepository defines the interface for resumable upload sessions
+type UploadSessionRepository interface {
+	Create(ctx context.Context, session *entity.UploadSession) error
+	FindByID(ctx context.Context, id uuid.UUID) (*entity.UploadSession, error)
+	Update(ctx context.Context, session *entity.UploadSession) error
+	Delete(ctx context.Context, id uuid.UUID) error
+
+	// AdvanceOffset moves an active session's received size from one offset to the next.
+	// Returns false when the session is no longer at the expected offset.
+	AdvanceOffset(ctx context.Context, id uuid.UUID, from, to int64) (bool, error)
+
+	// FindExpired retrieves sessions that expired before the given time, oldest first
+	FindExpired(ctx context.Context, before time.Time, limit, offset int) ([]*entity.UploadSession, error)
+}
@@ -13,6 +13,11 @@ const (
 	MultipleChoice QuestionType = "multiple_choice"
 	TrueFalse      QuestionType = "true_false"
 	ShortAnswer    QuestionType = "short_answer"
+	Matching       QuestionType = "matching"
+	Ordering       QuestionType = "ordering"
+	Numerical      QuestionType = "numerical"
+	Cloze          QuestionType = "cloze"
+	Essay          QuestionType = "essay"
 )
 
 // GenerationParams holds parameters for question generation
@@ -26,17 +31,34 @@ type GenerationParams struct {
 
 // GeneratedQuestion represents a generated question with answers
 type GeneratedQuestion struct {
-	QuestionText string
-	QuestionType QuestionType
-	Difficulty   string
-	Answers      []GeneratedAnswer
-	Explanation  string
+	QuestionText  string
+	QuestionType  QuestionType
+	Difficulty    string
+	Answers       []GeneratedAnswer
+	Explanation   string
+	CaseSensitive bool // Short answer questions: variants must match letter case
 }
 
 // GeneratedAnswer represents a possible answer
 type GeneratedAnswer struct {
 	Text      string
 	IsCorrect bool
+	Match     string   // Matching questions: the item Text is paired with
+	Tolerance *float64 // Numerical questions: accepted absolute error
+	Weight    *float64 // Short answer questions: grade percentage of a partially correct variant
+}
+
+// inputTokenLimits holds how many prompt tokens of document text each provider's model accepts,
+// leaving room for instructions and the generated questions
+var inputTokenLimits = map[string]int{
+	"perplexity": 120000,
+	"openai":     120000,
+	"yandexgpt":  28000,
+}
+
+// InputTokenLimit returns the document size in tokens a provider accepts, 0 if unknown
+func InputTokenLimit(provider string) int {
+	return inputTokenLimits[provider]
 }
 
 // LLMStrategy defines the interface for LLM providers (Strategy Pattern)
@@ -73,13 +73,27 @@ type QuestionResponse struct {
 		Type       string `json:"type"`
 		Difficulty string `json:"difficulty"`
 		Answers    []struct {
-			Text      string `json:"text"`
-			IsCorrect bool   `json:"is_correct"`
+			Text      string   `json:"text"`
+			IsCorrect bool     `json:"is_correct"`
+			Match     string   `json:"match,omitempty"`
+			Tolerance *float64 `json:"tolerance,omitempty"`
+			Weight    *float64 `json:"weight,omitempty"`
 		} `json:"answers"`
-		Explanation string `json:"explanation,omitempty"`
+		Explanation   string `json:"explanation,omitempty"`
+		CaseSensitive bool   `json:"case_sensitive,omitempty"`
 	} `json:"questions"`
 }
 
+// questionTypeRules explains the answer format of question types the base prompt does not cover
+var questionTypeRules = map[QuestionType]string{
+	ShortAnswer: `Для short_answer перечисли 2-5 допустимых вариантов ответа, все с "is_correct": true: разные написания (кириллицей и латиницей, например "Ньютон" и "Newton"), синонимы, сокращения; частично верным вариантам укажи долю оценки в "weight" (например 50), символ * заменяет любые символы (например "фотосинтез*"); если важен регистр букв, укажи у вопроса "case_sensitive": true`,
+	Matching:    `Для matching создай 3-5 пар: в "text" элемент, в "match" соответствующий ему элемент, все с "is_correct": true`,
+	Ordering:    `Для ordering перечисли 3-6 элементов в "answers" в правильном порядке`,
+	Numerical:   `Для numerical укажи правильный ответ числом в "text" (точка как десятичный разделитель), "is_correct": true и допустимую погрешность в "tolerance", например {"text": "3.14", "tolerance": 0.01, "is_correct": true}`,
+	Cloze:       `Для cloze встрой ответы в текст вопроса в формате Moodle, например {1:SHORTANSWER:=Париж} или {1:MULTICHOICE:=верный~неверный~неверный}, а "answers" оставь пустым`,
+	Essay:       `Для essay сформулируй открытый вопрос и дай в "answers" 1 эталонный ответ для проверяющего`,
+}
+
 // NewYandexGPTStrategy creates a new YandexGPT strategy
 func NewYandexGPTStrategy(apiKey, folderID, model string) *YandexGPTStrategy {
 	if model == "" {
@@ -217,6 +231,14 @@ func (s *YandexGPTStrategy) buildPrompt(params GenerationParams) string {
 		difficulty = "medium"
 	}
 
+	// The answer format of other types is explained only when they are requested
+	var typeRules strings.Builder
+	for _, qt := range params.QuestionTypes {
+		if rule, ok := questionTypeRules[qt]; ok {
+			typeRules.WriteString("\n- " + rule)
+		}
+	}
+
 	language := params.Language
 	if language == "" {
 		language = "ru"
@@ -233,7 +255,7 @@ func (s *YandexGPTStrategy) buildPrompt(params GenerationParams) string {
 - Язык: %s
 - Для каждого вопроса типа single_choice создай 4 варианта ответа (1 правильный, 3 неправильных)
 - Для каждого вопроса типа multiple_choice создай 5-6 вариантов (2-3 правильных, 2-3 неправильных)
-- Для true_false создай только 2 варианта: "Верно" и "Неверно"
+- Для true_false создай только 2 варианта: "Верно" и "Неверно"%s
 
 ВАЖНО - ПРАВИЛА ФОРМУЛИРОВКИ ВОПРОСОВ:
 1. Каждый вопрос должен быть САМОДОСТАТОЧНЫМ и понятным без ссылок на текст
@@ -273,6 +295,7 @@ func (s *YandexGPTStrategy) buildPrompt(params GenerationParams) string {
 		questionTypesStr,
 		difficulty,
 		language,
+		typeRules.String(),
 		difficulty,
 	)
 
@@ -310,15 +333,19 @@ func (s *YandexGPTStrategy) parseQuestions(text string, params GenerationParams)
 			answers[i] = GeneratedAnswer{
 				Text:      a.Text,
 				IsCorrect: a.IsCorrect,
+				Match:     a.Match,
+				Tolerance: a.Tolerance,
+				Weight:    a.Weight,
 			}
 		}
 
 		result = append(result, GeneratedQuestion{
-			QuestionText: q.Question,
-			QuestionType: QuestionType(q.Type),
-			Difficulty:   q.Difficulty,
-			Answers:      answers,
-			Explanation:  q.Explanation,
+			QuestionText:  q.Question,
+			QuestionType:  QuestionType(q.Type),
+			Difficulty:    q.Difficulty,
+			Answers:       answers,
+			Explanation:   q.Explanation,
+			CaseSensitive: q.CaseSensitive,
 		})
 	}
 
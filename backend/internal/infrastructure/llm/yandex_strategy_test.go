@@ -55,12 +55,89 @@ func TestYandexGPTStrategy_BuildPrompt(t *testing.T) {
 		prompt := strategy.buildPrompt(params)
 
 		require.Contains(t, prompt, "true_false, short_answer")
+		require.Contains(t, prompt, "кириллицей и латиницей")
+		require.Contains(t, prompt, `"case_sensitive"`)
+	})
+
+	t.Run("explains the answer format of requested types only", func(t *testing.T) {
+		params := GenerationParams{
+			Text:          "Test text",
+			NumQuestions:  2,
+			QuestionTypes: []QuestionType{Matching, Numerical},
+		}
+
+		prompt := strategy.buildPrompt(params)
+
+		require.Contains(t, prompt, "Для matching")
+		require.Contains(t, prompt, `"tolerance"`)
+		require.NotContains(t, prompt, "Для cloze")
+		require.NotContains(t, strategy.buildPrompt(GenerationParams{Text: "Test text", NumQuestions: 1}), "Для matching")
 	})
 }
 
 func TestYandexGPTStrategy_ParseQuestions(t *testing.T) {
 	strategy := NewYandexGPTStrategy("test-key", "test-folder", "yandexgpt-lite")
 
+	t.Run("parses matching pairs and numerical tolerance", func(t *testing.T) {
+		jsonResponse := `{
+			"questions": [
+				{
+					"question": "Match languages and their authors",
+					"type": "matching",
+					"difficulty": "easy",
+					"answers": [
+						{"text": "Go", "match": "Google", "is_correct": true},
+						{"text": "C#", "match": "Microsoft", "is_correct": true}
+					]
+				},
+				{
+					"question": "What is pi to two decimals?",
+					"type": "numerical",
+					"difficulty": "easy",
+					"answers": [{"text": "3.14", "tolerance": 0.01, "is_correct": true}]
+				}
+			]
+		}`
+
+		questions, err := strategy.parseQuestions(jsonResponse, GenerationParams{})
+
+		require.NoError(t, err)
+		require.Len(t, questions, 2)
+		require.Equal(t, Matching, questions[0].QuestionType)
+		require.Equal(t, "Google", questions[0].Answers[0].Match)
+		require.Equal(t, Numerical, questions[1].QuestionType)
+		require.NotNil(t, questions[1].Answers[0].Tolerance)
+		require.Equal(t, 0.01, *questions[1].Answers[0].Tolerance)
+	})
+
+	t.Run("parses short answer variants", func(t *testing.T) {
+		jsonResponse := `{
+			"questions": [
+				{
+					"question": "Who formulated the law of universal gravitation?",
+					"type": "short_answer",
+					"difficulty": "easy",
+					"case_sensitive": true,
+					"answers": [
+						{"text": "Ньютон", "is_correct": true},
+						{"text": "Newton", "is_correct": true},
+						{"text": "Исаак*", "weight": 50, "is_correct": true}
+					]
+				}
+			]
+		}`
+
+		questions, err := strategy.parseQuestions(jsonResponse, GenerationParams{})
+
+		require.NoError(t, err)
+		require.Len(t, questions, 1)
+		require.True(t, questions[0].CaseSensitive)
+		require.Len(t, questions[0].Answers, 3)
+		require.Nil(t, questions[0].Answers[0].Weight)
+		require.NotNil(t, questions[0].Answers[2].Weight)
+		require.Equal(t, 50.0, *questions[0].Answers[2].Weight)
+	})
+
 	t.Run("parses valid JSON response", func(t *testing.T) {
 		jsonResponse := `{
 			"questions": [
@@ -16,19 +16,42 @@ type Quiz struct {
 
 // Question represents a Moodle question
 type Question struct {
-	Type              string   `xml:"type,attr"`
-	Name              Name     `xml:"name"`
-	QuestionText      Text     `xml:"questiontext"`
-	GeneralFeedback   Text     `xml:"generalfeedback"`
-	DefaultGrade      float64  `xml:"defaultgrade"`
-	Penalty           float64  `xml:"penalty"`
-	Hidden            int      `xml:"hidden"`
-	Single            *bool    `xml:"single,omitempty"`            // For multiple choice
-	ShuffleAnswers    *bool    `xml:"shuffleanswers,omitempty"`    // For multiple choice
-	AnswerNumbering   *string  `xml:"answernumbering,omitempty"`   // For multiple choice
-	CorrectFeedback   *Text    `xml:"correctfeedback,omitempty"`   // For multiple choice
-	IncorrectFeedback *Text    `xml:"incorrectfeedback,omitempty"` // For multiple choice
-	Answers           []Answer `xml:"answer,omitempty"`
+	Type              string        `xml:"type,attr"`
+	Name              Name          `xml:"name"`
+	QuestionText      Text          `xml:"questiontext"`
+	GeneralFeedback   Text          `xml:"generalfeedback"`
+	DefaultGrade      float64       `xml:"defaultgrade"`
+	Penalty           float64       `xml:"penalty"`
+	Hidden            int           `xml:"hidden"`
+	Single            *bool         `xml:"single,omitempty"`             // For multiple choice
+	ShuffleAnswers    *bool         `xml:"shuffleanswers,omitempty"`     // For multiple choice
+	AnswerNumbering   *string       `xml:"answernumbering,omitempty"`    // For multiple choice
+	CorrectFeedback   *Text         `xml:"correctfeedback,omitempty"`    // For multiple choice
+	IncorrectFeedback *Text         `xml:"incorrectfeedback,omitempty"`  // For multiple choice
+	UseCase           *int          `xml:"usecase,omitempty"`            // For short answer
+	Subquestions      []Subquestion `xml:"subquestion,omitempty"`        // For matching
+	LayoutType        *string       `xml:"layouttype,omitempty"`         // For ordering
+	SelectType        *string       `xml:"selecttype,omitempty"`         // For ordering
+	SelectCount       *int          `xml:"selectcount,omitempty"`        // For ordering
+	GradingType       *string       `xml:"gradingtype,omitempty"`        // For ordering
+	ResponseFormat    *string       `xml:"responseformat,omitempty"`     // For essay
+	ResponseRequired  *int          `xml:"responserequired,omitempty"`   // For essay
+	ResponseLines     *int          `xml:"responsefieldlines,omitempty"` // For essay
+	Attachments       *int          `xml:"attachments,omitempty"`        // For essay
+	GraderInfo        *Text         `xml:"graderinfo,omitempty"`         // For essay
+	Answers           []Answer      `xml:"answer,omitempty"`
+}
+
+// Subquestion represents a pair of a matching question
+type Subquestion struct {
+	Format string            `xml:"format,attr"`
+	Text   string            `xml:"text"`
+	Answer SubquestionAnswer `xml:"answer"`
+}
+
+// SubquestionAnswer represents the item a matching subquestion is paired with
+type SubquestionAnswer struct {
+	Text string `xml:"text"`
 }
 
 // Name represents question name
@@ -44,20 +67,29 @@ type Text struct {
 
 // Answer represents a question answer
 type Answer struct {
-	Fraction float64 `xml:"fraction,attr"`
-	Format   string  `xml:"format,attr"`
-	Text     string  `xml:"text"`
-	Feedback Text    `xml:"feedback"`
+	Fraction  float64  `xml:"fraction,attr"`
+	Format    string   `xml:"format,attr"`
+	Text      string   `xml:"text"`
+	Feedback  Text     `xml:"feedback"`
+	Tolerance *float64 `xml:"tolerance,omitempty"` // For numerical
 }
 
 // MoodleXMLExporter exports tests to Moodle XML format
-type MoodleXMLExporter struct{}
+type MoodleXMLExporter struct {
+	negativeMarking bool
+}
 
 // NewMoodleXMLExporter creates a new Moodle XML exporter
 func NewMoodleXMLExporter() *MoodleXMLExporter {
 	return &MoodleXMLExporter{}
 }
 
+// SetNegativeMarking makes wrong choices of multiple choice questions take
+// points away, so that ticking every option scores nothing
+func (e *MoodleXMLExporter) SetNegativeMarking(enabled bool) {
+	e.negativeMarking = enabled
+}
+
 // Export converts a test with questions and answers to Moodle XML
 func (e *MoodleXMLExporter) Export(test *entity.Test, questions []*entity.Question, answers map[string][]*entity.Answer) (string, error) {
 	quiz := Quiz{
@@ -83,6 +115,20 @@ func (e *MoodleXMLExporter) Export(test *entity.Test, questions []*entity.Questi
 	return xmlContent, nil
 }
 
+// AnswersByQuestion indexes the preloaded answers of questions by question ID
+// in the form Export expects
+func AnswersByQuestion(questions []*entity.Question) map[string][]*entity.Answer {
+	answers := make(map[string][]*entity.Answer, len(questions))
+	for _, q := range questions {
+		list := make([]*entity.Answer, len(q.Answers))
+		for i := range q.Answers {
+			list[i] = &q.Answers[i]
+		}
+		answers[q.ID.String()] = list
+	}
+	return answers
+}
+
 // convertQuestion converts domain question to Moodle question
 func (e *MoodleXMLExporter) convertQuestion(q *entity.Question, answers []*entity.Answer) (Question, error) {
 	moodleQuestion := Question{
@@ -113,7 +159,7 @@ func (e *MoodleXMLExporter) convertQuestion(q *entity.Question, answers []*entit
 		moodleQuestion.AnswerNumbering = &numbering
 		moodleQuestion.CorrectFeedback = &Text{Text: "Correct!", Format: "html"}
 		moodleQuestion.IncorrectFeedback = &Text{Text: "Incorrect.", Format: "html"}
-		moodleQuestion.Answers = e.convertAnswers(answers)
+		moodleQuestion.Answers = e.convertWeightedAnswers(answers)
 
 	case entity.QuestionTypeMultipleChoice:
 		moodleQuestion.Type = "multichoice"
@@ -125,40 +171,111 @@ func (e *MoodleXMLExporter) convertQuestion(q *entity.Question, answers []*entit
 		moodleQuestion.AnswerNumbering = &numbering
 		moodleQuestion.CorrectFeedback = &Text{Text: "Correct!", Format: "html"}
 		moodleQuestion.IncorrectFeedback = &Text{Text: "Incorrect.", Format: "html"}
-		moodleQuestion.Answers = e.convertAnswers(answers)
+		multipleAnswers, err := e.convertMultipleChoiceAnswers(answers)
+		if err != nil {
+			return moodleQuestion, err
+		}
+		moodleQuestion.Answers = multipleAnswers
 
 	case entity.QuestionTypeTrueFalse:
 		moodleQuestion.Type = "truefalse"
-		// For true/false, we need exactly 2 answers
+		// For true/false, we need exactly 2 answers. Moodle recognizes them by
+		// the lowercase keys and shows its own localized labels
 		trueAnswer := Answer{
 			Fraction: 0,
 			Format:   "moodle_auto_format",
-			Text:     "True",
+			Text:     entity.TrueFalseKeyTrue,
 			Feedback: Text{Text: "", Format: "html"},
 		}
 		falseAnswer := Answer{
 			Fraction: 0,
 			Format:   "moodle_auto_format",
-			Text:     "False",
+			Text:     entity.TrueFalseKeyFalse,
 			Feedback: Text{Text: "", Format: "html"},
 		}
 
 		// Determine which is correct
 		for _, ans := range answers {
-			if ans.IsCorrect {
-				if strings.ToLower(strings.TrimSpace(ans.AnswerText)) == "true" {
-					trueAnswer.Fraction = 100
-				} else {
-					falseAnswer.Fraction = 100
-				}
+			if !ans.IsCorrect {
+				continue
+			}
+			// Answers saved before normalization may still hold labels
+			if value, ok := entity.ParseTrueFalse(ans.AnswerText); ok && value {
+				trueAnswer.Fraction = 100
+			} else {
+				falseAnswer.Fraction = 100
 			}
 		}
 
 		moodleQuestion.Answers = []Answer{trueAnswer, falseAnswer}
 
 	case entity.QuestionTypeShortAnswer:
+		// Variants keep their * wildcards, which Moodle reads the same way
 		moodleQuestion.Type = "shortanswer"
+		useCase := 0
+		if q.CaseSensitive {
+			useCase = 1
+		}
+		moodleQuestion.UseCase = &useCase
+		moodleQuestion.Answers = e.convertWeightedAnswers(answers)
+
+	case entity.QuestionTypeMatching:
+		moodleQuestion.Type = "matching"
+		shuffle := true
+		moodleQuestion.ShuffleAnswers = &shuffle
+		for _, ans := range answers {
+			moodleQuestion.Subquestions = append(moodleQuestion.Subquestions, Subquestion{
+				Format: "html",
+				Text:   e.sanitizeText(ans.AnswerText),
+				Answer: SubquestionAnswer{Text: e.sanitizeText(ans.MatchText)},
+			})
+		}
+
+	case entity.QuestionTypeOrdering:
+		// Items are listed in the correct order; the fraction holds the position
+		moodleQuestion.Type = "ordering"
+		layout, selectType, gradingType := "VERTICAL", "ALL", "ABSOLUTE_POSITION"
+		selectCount := 0
+		moodleQuestion.LayoutType = &layout
+		moodleQuestion.SelectType = &selectType
+		moodleQuestion.SelectCount = &selectCount
+		moodleQuestion.GradingType = &gradingType
+		for i, ans := range answers {
+			moodleQuestion.Answers = append(moodleQuestion.Answers, Answer{
+				Fraction: float64(i + 1),
+				Format:   "moodle_auto_format",
+				Text:     e.sanitizeText(ans.AnswerText),
+				Feedback: Text{Text: "", Format: "html"},
+			})
+		}
+
+	case entity.QuestionTypeNumerical:
+		moodleQuestion.Type = "numerical"
 		moodleQuestion.Answers = e.convertAnswers(answers)
+		for i, ans := range answers {
+			moodleQuestion.Answers[i].Format = "moodle_auto_format"
+			moodleQuestion.Answers[i].Tolerance = ans.Tolerance
+		}
+
+	case entity.QuestionTypeCloze:
+		// Embedded answers live in the question text, which must not be truncated
+		moodleQuestion.Type = "cloze"
+		moodleQuestion.QuestionText.Text = strings.TrimSpace(q.QuestionText)
+
+	case entity.QuestionTypeEssay:
+		moodleQuestion.Type = "essay"
+		moodleQuestion.Penalty = 0
+		format := "editor"
+		required, lines, attachments := 1, 15, 0
+		moodleQuestion.ResponseFormat = &format
+		moodleQuestion.ResponseRequired = &required
+		moodleQuestion.ResponseLines = &lines
+		moodleQuestion.Attachments = &attachments
+		graderInfo := ""
+		if len(answers) > 0 {
+			graderInfo = strings.TrimSpace(answers[0].AnswerText)
+		}
+		moodleQuestion.GraderInfo = &Text{Text: graderInfo, Format: "html"}
 
 	default:
 		return moodleQuestion, fmt.Errorf("unsupported question type: %s", q.QuestionType)
@@ -191,6 +308,100 @@ func (e *MoodleXMLExporter) convertAnswers(answers []*entity.Answer) []Answer {
 	return moodleAnswers
 }
 
+// convertWeightedAnswers converts answers keeping teacher-set weights of
+// partially correct or penalized answers
+func (e *MoodleXMLExporter) convertWeightedAnswers(answers []*entity.Answer) []Answer {
+	moodleAnswers := e.convertAnswers(answers)
+	for i, ans := range answers {
+		if ans.Weight != nil {
+			moodleAnswers[i].Fraction = entity.NearestAnswerWeight(*ans.Weight)
+		}
+	}
+	return moodleAnswers
+}
+
+// convertMultipleChoiceAnswers converts the answers of a multiple choice
+// question. Moodle expects the fractions of its correct answers to add up to
+// 100, so answers without a teacher-set weight share the grade; with negative
+// marking the wrong ones share -100 the same way.
+func (e *MoodleXMLExporter) convertMultipleChoiceAnswers(answers []*entity.Answer) ([]Answer, error) {
+	moodleAnswers := e.convertWeightedAnswers(answers)
+
+	var correct, wrong []int
+	wrongTotal := 0
+	for i, ans := range answers {
+		if !ans.IsCorrect {
+			wrongTotal++
+		}
+		switch {
+		case ans.Weight != nil:
+		case ans.IsCorrect:
+			correct = append(correct, i)
+		default:
+			wrong = append(wrong, i)
+		}
+	}
+
+	shares, err := splitFractions(len(correct))
+	if err != nil {
+		return nil, err
+	}
+	for j, i := range correct {
+		moodleAnswers[i].Fraction = shares[j]
+	}
+
+	if e.negativeMarking && wrongTotal > 0 {
+		penalty := penaltyFraction(wrongTotal)
+		for _, i := range wrong {
+			moodleAnswers[i].Fraction = -penalty
+		}
+	}
+
+	return moodleAnswers, nil
+}
+
+// splitFractions divides 100 between n correct answers using fractions Moodle
+// accepts: evenly where 100/n is one of them, otherwise as tens and fives
+func splitFractions(n int) ([]float64, error) {
+	shares := make([]float64, n)
+	if n == 0 {
+		return shares, nil
+	}
+
+	even := 100 / float64(n)
+	if entity.IsAllowedAnswerWeight(even) {
+		for i := range shares {
+			shares[i] = entity.NearestAnswerWeight(even)
+		}
+		return shares, nil
+	}
+	if n > 20 {
+		return nil, fmt.Errorf("cannot split the grade between %d correct answers, set answer weights instead", n)
+	}
+
+	// 20-n tens and 2n-20 fives add up to 100
+	for i := range shares {
+		if i < 20-n {
+			shares[i] = 10
+		} else {
+			shares[i] = 5
+		}
+	}
+	return shares, nil
+}
+
+// penaltyFraction returns the smallest fraction Moodle accepts that is at
+// least 100/n, so that ticking all n wrong answers costs the whole grade
+func penaltyFraction(n int) float64 {
+	share := 100 / float64(n)
+	for i := len(entity.AnswerWeights) - 1; i >= 0; i-- {
+		if entity.AnswerWeights[i] >= share-0.0001 {
+			return entity.AnswerWeights[i]
+		}
+	}
+	return 100
+}
+
 // sanitizeText cleans text for XML export
 func (e *MoodleXMLExporter) sanitizeText(text string) string {
 	// Remove leading/trailing whitespace
@@ -116,6 +116,208 @@ func TestConvertQuestionVariants(t *testing.T) {
 	}
 }
 
+func TestConvertQuestionNewTypes(t *testing.T) {
+	exporter := NewMoodleXMLExporter()
+	tolerance := 0.01
+
+	matching, err := exporter.convertQuestion(
+		&entity.Question{QuestionText: "match", QuestionType: entity.QuestionTypeMatching},
+		[]*entity.Answer{{AnswerText: "Go", MatchText: "Google"}, {AnswerText: "C#", MatchText: "Microsoft"}},
+	)
+	if err != nil || matching.Type != "matching" || len(matching.Subquestions) != 2 || len(matching.Answers) != 0 {
+		t.Fatalf("unexpected matching conversion: %+v, %v", matching, err)
+	}
+	if matching.Subquestions[1].Text != "C#" || matching.Subquestions[1].Answer.Text != "Microsoft" {
+		t.Fatalf("expected matching pairs to be kept, got %+v", matching.Subquestions)
+	}
+
+	ordering, err := exporter.convertQuestion(
+		&entity.Question{QuestionText: "order", QuestionType: entity.QuestionTypeOrdering},
+		[]*entity.Answer{{AnswerText: "first"}, {AnswerText: "second"}},
+	)
+	if err != nil || ordering.Type != "ordering" || len(ordering.Answers) != 2 || ordering.Answers[1].Fraction != 2 {
+		t.Fatalf("unexpected ordering conversion: %+v, %v", ordering, err)
+	}
+
+	numerical, err := exporter.convertQuestion(
+		&entity.Question{QuestionText: "pi", QuestionType: entity.QuestionTypeNumerical},
+		[]*entity.Answer{{AnswerText: "3.14", IsCorrect: true, Tolerance: &tolerance}},
+	)
+	if err != nil || numerical.Type != "numerical" || numerical.Answers[0].Fraction != 100 ||
+		numerical.Answers[0].Tolerance == nil || *numerical.Answers[0].Tolerance != tolerance {
+		t.Fatalf("unexpected numerical conversion: %+v, %v", numerical, err)
+	}
+
+	clozeText := strings.Repeat("long text ", 30) + "{1:SHORTANSWER:=Paris}"
+	cloze, err := exporter.convertQuestion(&entity.Question{QuestionText: clozeText, QuestionType: entity.QuestionTypeCloze}, nil)
+	if err != nil || cloze.Type != "cloze" || cloze.QuestionText.Text != clozeText {
+		t.Fatalf("expected cloze text to be exported untruncated: %+v, %v", cloze, err)
+	}
+
+	essay, err := exporter.convertQuestion(
+		&entity.Question{QuestionText: "essay", QuestionType: entity.QuestionTypeEssay},
+		[]*entity.Answer{{AnswerText: "reference"}},
+	)
+	if err != nil || essay.Type != "essay" || essay.GraderInfo == nil || essay.GraderInfo.Text != "reference" || len(essay.Answers) != 0 {
+		t.Fatalf("unexpected essay conversion: %+v, %v", essay, err)
+	}
+}
+
+func TestExportNewTypesXML(t *testing.T) {
+	exporter := NewMoodleXMLExporter()
+	matchingID := uuid.New()
+	questions := []*entity.Question{
+		{ID: matchingID, QuestionText: "match", QuestionType: entity.QuestionTypeMatching, Points: 1},
+		{ID: uuid.New(), QuestionText: "essay", QuestionType: entity.QuestionTypeEssay, Points: 1},
+	}
+	answers := map[string][]*entity.Answer{
+		matchingID.String(): {{AnswerText: "Go", MatchText: "Google"}, {AnswerText: "C#", MatchText: "Microsoft"}},
+	}
+
+	xmlContent, err := exporter.Export(&entity.Test{}, questions, answers)
+	if err != nil {
+		t.Fatalf("expected export to succeed: %v", err)
+	}
+	for _, fragment := range []string{
+		`<question type="matching">`,
+		`<subquestion format="html">`,
+		`<answer>`,
+		`<question type="essay">`,
+		`<responseformat>editor</responseformat>`,
+	} {
+		if !strings.Contains(xmlContent, fragment) {
+			t.Fatalf("expected XML to contain %s, got:\n%s", fragment, xmlContent)
+		}
+	}
+}
+
+func TestConvertMultipleChoiceFractions(t *testing.T) {
+	exporter := NewMoodleXMLExporter()
+	question := &entity.Question{QuestionText: "multi", QuestionType: entity.QuestionTypeMultipleChoice}
+	answers := []*entity.Answer{
+		{AnswerText: "a", IsCorrect: true}, {AnswerText: "b", IsCorrect: true}, {AnswerText: "c", IsCorrect: true},
+		{AnswerText: "d"}, {AnswerText: "e"},
+	}
+
+	converted, err := exporter.convertQuestion(question, answers)
+	if err != nil {
+		t.Fatalf("expected conversion to succeed: %v", err)
+	}
+	for i, want := range []float64{33.33333, 33.33333, 33.33333, 0, 0} {
+		if converted.Answers[i].Fraction != want {
+			t.Fatalf("expected fraction %v for answer %d, got %+v", want, i, converted.Answers)
+		}
+	}
+
+	exporter.SetNegativeMarking(true)
+	converted, err = exporter.convertQuestion(question, answers)
+	if err != nil {
+		t.Fatalf("expected conversion to succeed: %v", err)
+	}
+	if converted.Answers[3].Fraction != -50 || converted.Answers[4].Fraction != -50 {
+		t.Fatalf("expected wrong answers to share -100, got %+v", converted.Answers)
+	}
+
+	// Teacher-set weights are kept
+	seventy, thirty, penalty := 70.0, 30.0, -25.0
+	answers[0].Weight, answers[1].Weight, answers[2].Weight = &seventy, &thirty, nil
+	answers[2].IsCorrect = false
+	answers[3].Weight = &penalty
+	converted, err = exporter.convertQuestion(question, answers)
+	if err != nil {
+		t.Fatalf("expected conversion to succeed: %v", err)
+	}
+	for i, want := range []float64{70, 30, -33.33333, -25, -33.33333} {
+		if converted.Answers[i].Fraction != want {
+			t.Fatalf("expected fraction %v for answer %d, got %+v", want, i, converted.Answers)
+		}
+	}
+}
+
+func TestConvertTrueFalseKeys(t *testing.T) {
+	exporter := NewMoodleXMLExporter()
+	question := &entity.Question{QuestionText: "statement", QuestionType: entity.QuestionTypeTrueFalse}
+
+	for _, answers := range [][]*entity.Answer{
+		{{AnswerText: "true", IsCorrect: true}, {AnswerText: "false"}},
+		{{AnswerText: "Верно", IsCorrect: true}, {AnswerText: "Неверно"}},
+	} {
+		converted, err := exporter.convertQuestion(question, answers)
+		if err != nil {
+			t.Fatalf("expected true/false conversion to succeed: %v", err)
+		}
+		if converted.Answers[0].Text != "true" || converted.Answers[1].Text != "false" {
+			t.Fatalf("expected lowercase keys Moodle imports, got %+v", converted.Answers)
+		}
+		if converted.Answers[0].Fraction != 100 || converted.Answers[1].Fraction != 0 {
+			t.Fatalf("expected the true answer to be correct for %q, got %+v", answers[0].AnswerText, converted.Answers)
+		}
+	}
+}
+
+func TestConvertShortAnswerVariants(t *testing.T) {
+	exporter := NewMoodleXMLExporter()
+	half := 50.0
+	question := &entity.Question{QuestionText: "capital", QuestionType: entity.QuestionTypeShortAnswer}
+	answers := []*entity.Answer{
+		{AnswerText: "Moscow", IsCorrect: true},
+		{AnswerText: "Mosc*", IsCorrect: true, Weight: &half},
+	}
+
+	converted, err := exporter.convertQuestion(question, answers)
+	if err != nil {
+		t.Fatalf("expected conversion to succeed: %v", err)
+	}
+	if converted.UseCase == nil || *converted.UseCase != 0 {
+		t.Fatalf("expected case-insensitive usecase, got %+v", converted.UseCase)
+	}
+	if converted.Answers[0].Fraction != 100 || converted.Answers[1].Fraction != 50 || converted.Answers[1].Text != "Mosc*" {
+		t.Fatalf("expected variants with their grades and wildcards, got %+v", converted.Answers)
+	}
+
+	question.CaseSensitive = true
+	xmlContent, err := exporter.Export(&entity.Test{}, []*entity.Question{question}, map[string][]*entity.Answer{question.ID.String(): answers})
+	if err != nil {
+		t.Fatalf("expected export to succeed: %v", err)
+	}
+	for _, fragment := range []string{`<usecase>1</usecase>`, `<answer fraction="50" format="html">`} {
+		if !strings.Contains(xmlContent, fragment) {
+			t.Fatalf("expected XML to contain %s, got:\n%s", fragment, xmlContent)
+		}
+	}
+}
+
+func TestSplitFractions(t *testing.T) {
+	for n := 1; n <= 20; n++ {
+		shares, err := splitFractions(n)
+		if err != nil || len(shares) != n {
+			t.Fatalf("expected %d shares, got %v, %v", n, shares, err)
+		}
+		sum := 0.0
+		for _, share := range shares {
+			if !entity.IsAllowedAnswerWeight(share) {
+				t.Fatalf("share %v of %d answers is not a Moodle fraction", share, n)
+			}
+			sum += share
+		}
+		if sum < 99.999 || sum > 100.001 {
+			t.Fatalf("expected %d shares to add up to 100, got %v", n, sum)
+		}
+	}
+
+	if _, err := splitFractions(21); err == nil {
+		t.Fatalf("expected too many correct answers to fail")
+	}
+}
+
+func TestPenaltyFraction(t *testing.T) {
+	for n, want := range map[int]float64{1: 100, 2: 50, 3: 33.33333, 7: 14.28571, 11: 10, 30: 5} {
+		if got := penaltyFraction(n); got != want {
+			t.Fatalf("expected penalty %v for %d wrong answers, got %v", want, n, got)
+		}
+	}
+}
+
 func TestSanitizeText(t *testing.T) {
 	exporter := NewMoodleXMLExporter()
 	longText := strings.Repeat("a", 300)
@@ -0,0 +1,285 @@
+package parser
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+var (
+	// ErrContentMismatch is returned when file content does not match its claimed type
+	ErrContentMismatch = errors.New("file content does not match its extension")
+
+	// ErrUnsafeArchive is returned when a ZIP-based document exceeds archive limits
+	ErrUnsafeArchive = errors.New("archive exceeds safety limits")
+)
+
+const (
+	// sniffLength is how many leading bytes are inspected for signatures
+	sniffLength = 8192
+
+	// ratioCheckThreshold skips the compression ratio check for small entries,
+	// which legitimately compress well (XML boilerplate)
+	ratioCheckThreshold = 1 << 20
+)
+
+// ArchiveLimits bounds ZIP-based documents (DOCX, PPTX, ODT, ODP, EPUB) to prevent archive bombs
+type ArchiveLimits struct {
+	MaxEntries          int     // Maximum number of files in the archive
+	MaxUncompressedSize int64   // Maximum total uncompressed size in bytes
+	MaxCompressionRatio float64 // Maximum uncompressed/compressed ratio per entry
+}
+
+// DefaultArchiveLimits returns limits that comfortably fit real office documents
+func DefaultArchiveLimits() ArchiveLimits {
+	return ArchiveLimits{
+		MaxEntries:          1000,
+		MaxUncompressedSize: 200 << 20, // 200MB
+		MaxCompressionRatio: 100,
+	}
+}
+
+// contentSniffer validates file content for one file type
+type contentSniffer func(r io.ReaderAt, size int64, limits ArchiveLimits) error
+
+// contentSniffers maps file types to their content validators.
+// Types without an entry are not sniffed.
+var contentSniffers = map[string]contentSniffer{
+	"pdf":   sniffPDF,
+	"docx":  zipSniffer("[Content_Types].xml", "word/document.xml"),
+	"pptx":  zipSniffer("[Content_Types].xml", "ppt/presentation.xml"),
+	"odt":   zipSniffer("mimetype", "content.xml"),
+	"odp":   zipSniffer("mimetype", "content.xml"),
+	"epub":  zipSniffer("mimetype", "META-INF/container.xml"),
+	"rtf":   sniffRTF,
+	"html":  sniffText,
+	"txt":   sniffText,
+	"md":    sniffText,
+	"srt":   sniffText,
+	"vtt":   sniffText,
+	"ipynb": sniffNotebook,
+	"go":    sniffText,
+	"py":    sniffText,
+	"java":  sniffText,
+}
+
+// binarySignatures are magic bytes of formats that must never be accepted as text
+var binarySignatures = [][]byte{
+	[]byte("%PDF-"),
+	[]byte("PK\x03\x04"),
+	[]byte("MZ"),                               // Windows executable
+	[]byte("\x7fELF"),                          // Linux executable
+	[]byte("\xcf\xfa\xed\xfe"),                 // Mach-O
+	[]byte("\xd0\xcf\x11\xe0\xa1\xb1\x1a\xe1"), // Legacy MS Office (OLE2)
+	[]byte("\x1f\x8b"),                         // gzip
+	[]byte("Rar!"),
+	[]byte("7z\xbc\xaf\x27\x1c"),
+	[]byte("\x89PNG"),
+	[]byte("\xff\xd8\xff"), // JPEG
+	[]byte("GIF8"),
+}
+
+// ValidateContent checks that the content of a file matches its claimed type
+// and, for ZIP-based formats, that the archive is within safety limits
+func (f *DocumentParserFactory) ValidateContent(fileType string, r io.ReaderAt, size int64) error {
+	sniffer, exists := contentSniffers[fileType]
+	if !exists {
+		return nil
+	}
+	return sniffer(r, size, f.archiveLimits)
+}
+
+// OpenArchive opens a ZIP bundle of documents after checking it against the archive limits.
+// Entries are not decompressed; callers must still bound what they extract.
+func (f *DocumentParserFactory) OpenArchive(r io.ReaderAt, size int64) (*zip.Reader, error) {
+	head, err := readHead(r, size)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(head, []byte("PK\x03\x04")) {
+		return nil, fmt.Errorf("%w: not a ZIP archive", ErrContentMismatch)
+	}
+
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("%w: corrupted ZIP archive", ErrContentMismatch)
+	}
+	if err := checkArchiveLimits(archive, f.archiveLimits); err != nil {
+		return nil, err
+	}
+	return archive, nil
+}
+
+// IsContentError reports whether err was produced by content validation
+// (as opposed to an I/O failure while reading the file)
+func IsContentError(err error) bool {
+	return errors.Is(err, ErrContentMismatch) || errors.Is(err, ErrUnsafeArchive)
+}
+
+// SetArchiveLimits overrides the archive limits used by ValidateContent
+func (f *DocumentParserFactory) SetArchiveLimits(limits ArchiveLimits) {
+	f.archiveLimits = limits
+}
+
+func readHead(r io.ReaderAt, size int64) ([]byte, error) {
+	n := int64(sniffLength)
+	if size < n {
+		n = size
+	}
+	head := make([]byte, n)
+	read, err := r.ReadAt(head, 0)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	return head[:read], nil
+}
+
+func sniffPDF(r io.ReaderAt, size int64, _ ArchiveLimits) error {
+	head, err := readHead(r, size)
+	if err != nil {
+		return err
+	}
+	// The PDF spec allows junk before the header, readers accept it within the first 1KB
+	if len(head) > 1024 {
+		head = head[:1024]
+	}
+	if !bytes.Contains(head, []byte("%PDF-")) {
+		return fmt.Errorf("%w: missing PDF header", ErrContentMismatch)
+	}
+	return nil
+}
+
+func sniffText(r io.ReaderAt, size int64, _ ArchiveLimits) error {
+	head, err := readHead(r, size)
+	if err != nil {
+		return err
+	}
+
+	// UTF-16 text legitimately contains NUL bytes
+	if bytes.HasPrefix(head, []byte{0xff, 0xfe}) || bytes.HasPrefix(head, []byte{0xfe, 0xff}) || detectUTF16(head) != "" {
+		return nil
+	}
+
+	for _, signature := range binarySignatures {
+		if bytes.HasPrefix(head, signature) {
+			return fmt.Errorf("%w: binary file uploaded as text", ErrContentMismatch)
+		}
+	}
+	if bytes.IndexByte(head, 0) >= 0 {
+		return fmt.Errorf("%w: binary file uploaded as text", ErrContentMismatch)
+	}
+	return nil
+}
+
+// sniffRTF requires a text file starting with the RTF header
+func sniffRTF(r io.ReaderAt, size int64, limits ArchiveLimits) error {
+	if err := sniffText(r, size, limits); err != nil {
+		return err
+	}
+	head, err := readHead(r, size)
+	if err != nil {
+		return err
+	}
+	if !bytes.HasPrefix(bytes.TrimLeft(head, " \r\n\t\xef\xbb\xbf"), []byte(`{\rtf`)) {
+		return fmt.Errorf("%w: missing RTF header", ErrContentMismatch)
+	}
+	return nil
+}
+
+// sniffNotebook requires a text file holding a JSON object
+func sniffNotebook(r io.ReaderAt, size int64, limits ArchiveLimits) error {
+	if err := sniffText(r, size, limits); err != nil {
+		return err
+	}
+	head, err := readHead(r, size)
+	if err != nil {
+		return err
+	}
+	head = bytes.TrimPrefix(head, []byte{0xef, 0xbb, 0xbf})
+	if !bytes.HasPrefix(bytes.TrimSpace(head), []byte("{")) {
+		return fmt.Errorf("%w: notebook is not a JSON document", ErrContentMismatch)
+	}
+	return nil
+}
+
+// zipSniffer validates a ZIP-based document (OOXML, OpenDocument, EPUB) and
+// requires the parts that identify the format to be present
+func zipSniffer(requiredParts ...string) contentSniffer {
+	return func(r io.ReaderAt, size int64, limits ArchiveLimits) error {
+		head, err := readHead(r, size)
+		if err != nil {
+			return err
+		}
+		if !bytes.HasPrefix(head, []byte("PK\x03\x04")) {
+			return fmt.Errorf("%w: not a ZIP container", ErrContentMismatch)
+		}
+
+		archive, err := zip.NewReader(r, size)
+		if err != nil {
+			return fmt.Errorf("%w: corrupted ZIP container", ErrContentMismatch)
+		}
+		if err := checkArchiveLimits(archive, limits); err != nil {
+			return err
+		}
+
+		names := make(map[string]bool, len(archive.File))
+		for _, file := range archive.File {
+			names[file.Name] = true
+		}
+		for _, part := range requiredParts {
+			if !names[part] {
+				return fmt.Errorf("%w: missing %s", ErrContentMismatch, part)
+			}
+		}
+		return nil
+	}
+}
+
+// unsafeEntryPath reports whether an archive entry would escape the extraction root:
+// an absolute path or a ".." segment. Dots inside names such as notes..v2.xml are fine.
+func unsafeEntryPath(name string) bool {
+	name = strings.ReplaceAll(name, `\`, "/")
+	if strings.HasPrefix(name, "/") || (len(name) >= 2 && name[1] == ':') {
+		return true
+	}
+	for _, segment := range strings.Split(name, "/") {
+		if segment == ".." {
+			return true
+		}
+	}
+	return false
+}
+
+// checkArchiveLimits inspects the central directory without decompressing anything
+func checkArchiveLimits(archive *zip.Reader, limits ArchiveLimits) error {
+	if limits.MaxEntries > 0 && len(archive.File) > limits.MaxEntries {
+		return fmt.Errorf("%w: %d entries, maximum is %d", ErrUnsafeArchive, len(archive.File), limits.MaxEntries)
+	}
+
+	var total uint64
+	for _, file := range archive.File {
+		if unsafeEntryPath(file.Name) {
+			return fmt.Errorf("%w: illegal entry path %q", ErrUnsafeArchive, file.Name)
+		}
+
+		total += file.UncompressedSize64
+		if limits.MaxUncompressedSize > 0 && total > uint64(limits.MaxUncompressedSize) {
+			return fmt.Errorf("%w: uncompressed size exceeds %d bytes", ErrUnsafeArchive, limits.MaxUncompressedSize)
+		}
+
+		if limits.MaxCompressionRatio > 0 && file.UncompressedSize64 > ratioCheckThreshold {
+			compressed := file.CompressedSize64
+			if compressed == 0 {
+				compressed = 1
+			}
+			ratio := float64(file.UncompressedSize64) / float64(compressed)
+			if ratio > limits.MaxCompressionRatio {
+				return fmt.Errorf("%w: entry %q compression ratio %.0f exceeds %.0f", ErrUnsafeArchive, file.Name, ratio, limits.MaxCompressionRatio)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package parser
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func buildZip(t *testing.T, files map[string][]byte) []byte {
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := writer.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write(content)
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+	return buf.Bytes()
+}
+
+func validate(factory *DocumentParserFactory, fileType string, content []byte) error {
+	return factory.ValidateContent(fileType, bytes.NewReader(content), int64(len(content)))
+}
+
+func TestValidateContent_PDF(t *testing.T) {
+	factory := NewDocumentParserFactory()
+
+	assert.NoError(t, validate(factory, "pdf", []byte("%PDF-1.7\n%âãÏÓ\n1 0 obj")))
+	assert.NoError(t, validate(factory, "pdf", []byte("\r\n%PDF-1.4\n")), "leading junk is tolerated")
+
+	err := validate(factory, "pdf", []byte("MZ\x90\x00\x03\x00\x00\x00"))
+	assert.ErrorIs(t, err, ErrContentMismatch)
+	assert.True(t, IsContentError(err))
+}
+
+func TestValidateContent_Text(t *testing.T) {
+	factory := NewDocumentParserFactory()
+
+	assert.NoError(t, validate(factory, "txt", []byte("Лекция 1. Введение")))
+	assert.NoError(t, validate(factory, "md", []byte("# Title\n\ntext")))
+	assert.NoError(t, validate(factory, "txt", []byte{0xff, 0xfe, 'h', 0, 'i', 0}), "UTF-16 with BOM is text")
+	assert.NoError(t, validate(factory, "txt", []byte{}))
+
+	assert.ErrorIs(t, validate(factory, "txt", []byte("\x7fELF\x02\x01\x01")), ErrContentMismatch)
+	assert.ErrorIs(t, validate(factory, "md", []byte("%PDF-1.4")), ErrContentMismatch)
+	assert.ErrorIs(t, validate(factory, "txt", []byte("text\x00with nul")), ErrContentMismatch)
+}
+
+func TestValidateContent_Notebook(t *testing.T) {
+	factory := NewDocumentParserFactory()
+
+	assert.NoError(t, validate(factory, "ipynb", []byte("\xef\xbb\xbf\n {\"cells\": []}")))
+	assert.NoError(t, validate(factory, "py", []byte("print('hi')")))
+
+	assert.ErrorIs(t, validate(factory, "ipynb", []byte("# not a notebook")), ErrContentMismatch)
+	assert.ErrorIs(t, validate(factory, "ipynb", []byte("PK\x03\x04")), ErrContentMismatch)
+}
+
+func TestValidateContent_OfficeContainers(t *testing.T) {
+	factory := NewDocumentParserFactory()
+
+	docx := buildZip(t, map[string][]byte{
+		"[Content_Types].xml": []byte("<Types/>"),
+		"word/document.xml":   []byte("<w:document/>"),
+	})
+	assert.NoError(t, validate(factory, "docx", docx))
+
+	// A DOCX renamed to .pptx lacks the presentation part
+	assert.ErrorIs(t, validate(factory, "pptx", docx), ErrContentMismatch)
+
+	// Not a ZIP at all
+	assert.ErrorIs(t, validate(factory, "docx", []byte("plain text")), ErrContentMismatch)
+
+	// Truncated ZIP
+	assert.ErrorIs(t, validate(factory, "docx", docx[:len(docx)/2]), ErrContentMismatch)
+
+	odt := buildZip(t, map[string][]byte{
+		"mimetype":    []byte("application/vnd.oasis.opendocument.text"),
+		"content.xml": []byte("<office:document-content/>"),
+	})
+	assert.NoError(t, validate(factory, "odt", odt))
+	assert.ErrorIs(t, validate(factory, "docx", odt), ErrContentMismatch)
+	assert.ErrorIs(t, validate(factory, "epub", odt), ErrContentMismatch)
+}
+
+func TestValidateContent_RTF(t *testing.T) {
+	factory := NewDocumentParserFactory()
+
+	assert.NoError(t, validate(factory, "rtf", []byte("{\\rtf1\\ansi Hello}")))
+	assert.NoError(t, validate(factory, "html", []byte("<!DOCTYPE html><p>Hi</p>")))
+
+	assert.ErrorIs(t, validate(factory, "rtf", []byte("Hello")), ErrContentMismatch)
+	assert.ErrorIs(t, validate(factory, "html", []byte("%PDF-1.4")), ErrContentMismatch)
+}
+
+func TestValidateContent_ArchiveLimits(t *testing.T) {
+	base := map[string][]byte{
+		"[Content_Types].xml": []byte("<Types/>"),
+		"word/document.xml":   []byte("<w:document/>"),
+	}
+
+	t.Run("too many entries", func(t *testing.T) {
+		factory := NewDocumentParserFactory()
+		factory.SetArchiveLimits(ArchiveLimits{MaxEntries: 2})
+
+		files := map[string][]byte{"word/media/extra.xml": []byte("x")}
+		for name, content := range base {
+			files[name] = content
+		}
+		assert.ErrorIs(t, validate(factory, "docx", buildZip(t, files)), ErrUnsafeArchive)
+	})
+
+	t.Run("uncompressed size", func(t *testing.T) {
+		factory := NewDocumentParserFactory()
+		factory.SetArchiveLimits(ArchiveLimits{MaxUncompressedSize: 1024})
+
+		files := map[string][]byte{"word/media/big.bin": bytes.Repeat([]byte("a"), 4096)}
+		for name, content := range base {
+			files[name] = content
+		}
+		assert.ErrorIs(t, validate(factory, "docx", buildZip(t, files)), ErrUnsafeArchive)
+	})
+
+	t.Run("compression ratio", func(t *testing.T) {
+		factory := NewDocumentParserFactory()
+
+		files := map[string][]byte{"word/document.xml": make([]byte, 4<<20)}
+		files["[Content_Types].xml"] = base["[Content_Types].xml"]
+		err := validate(factory, "docx", buildZip(t, files))
+		assert.ErrorIs(t, err, ErrUnsafeArchive)
+		assert.Contains(t, err.Error(), "compression ratio")
+	})
+
+	t.Run("path traversal", func(t *testing.T) {
+		factory := NewDocumentParserFactory()
+
+		files := map[string][]byte{"../../etc/passwd": []byte("root")}
+		for name, content := range base {
+			files[name] = content
+		}
+		assert.ErrorIs(t, validate(factory, "docx", buildZip(t, files)), ErrUnsafeArchive)
+	})
+
+	t.Run("double dots inside names", func(t *testing.T) {
+		factory := NewDocumentParserFactory()
+
+		files := map[string][]byte{"word/notes..v2.xml": []byte("x"), "word/a..b/c.png": []byte("x")}
+		for name, content := range base {
+			files[name] = content
+		}
+		assert.NoError(t, validate(factory, "docx", buildZip(t, files)))
+	})
+}
+
+func TestUnsafeEntryPath(t *testing.T) {
+	for _, name := range []string{"word/document.xml", "notes..v2.xml", "a..b/c.png", "..hidden", "media/...", "./content.xml"} {
+		assert.False(t, unsafeEntryPath(name), name)
+	}
+	for _, name := range []string{"../evil", "a/../../b", "a/..", "..", `..\evil`, `a\..\b`, "/etc/passwd", `\\server\share`, "C:/Windows/x", `C:\x`} {
+		assert.True(t, unsafeEntryPath(name), name)
+	}
+}
+
+func TestValidateContent_UnknownTypeIsNotSniffed(t *testing.T) {
+	factory := NewDocumentParserFactory()
+	assert.NoError(t, validate(factory, "unknown", []byte("MZ")))
+}
+
+func TestOpenArchive(t *testing.T) {
+	factory := NewDocumentParserFactory()
+
+	bundle := buildZip(t, map[string][]byte{"week1/notes.txt": []byte("notes")})
+	archive, err := factory.OpenArchive(bytes.NewReader(bundle), int64(len(bundle)))
+	require.NoError(t, err)
+	require.Len(t, archive.File, 1)
+	assert.Equal(t, "week1/notes.txt", archive.File[0].Name)
+
+	_, err = factory.OpenArchive(bytes.NewReader([]byte("%PDF-1.4")), 8)
+	assert.ErrorIs(t, err, ErrContentMismatch)
+
+	factory.SetArchiveLimits(ArchiveLimits{MaxEntries: 1})
+	bundle = buildZip(t, map[string][]byte{"a.txt": []byte("a"), "b.txt": []byte("b")})
+	_, err = factory.OpenArchive(bytes.NewReader(bundle), int64(len(bundle)))
+	assert.ErrorIs(t, err, ErrUnsafeArchive)
+}
@@ -0,0 +1,164 @@
+package parser
+
+import (
+	"bytes"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// Encoding names recorded on documents
+const (
+	EncodingUTF8    = "utf-8"
+	EncodingUTF16LE = "utf-16le"
+	EncodingUTF16BE = "utf-16be"
+	EncodingCP1251  = "windows-1251"
+	EncodingKOI8R   = "koi8-r"
+)
+
+// frequentRussianLetters are the most common lowercase Russian letters,
+// used to decide which single-byte Cyrillic code page produces real text
+const frequentRussianLetters = "оеаинтсрвлкмдпу"
+
+// DecodeText detects the charset of raw text content, transcodes it to UTF-8
+// and normalizes line endings and whitespace. It returns the text and the
+// detected source encoding.
+func DecodeText(content []byte) (string, string) {
+	text, enc := decodeToUTF8(content)
+	return NormalizeText(text), enc
+}
+
+func decodeToUTF8(content []byte) (string, string) {
+	switch {
+	case bytes.HasPrefix(content, []byte{0xef, 0xbb, 0xbf}):
+		return string(content[3:]), EncodingUTF8
+	case bytes.HasPrefix(content, []byte{0xff, 0xfe}):
+		return decodeWith(unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM), content[2:]), EncodingUTF16LE
+	case bytes.HasPrefix(content, []byte{0xfe, 0xff}):
+		return decodeWith(unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM), content[2:]), EncodingUTF16BE
+	}
+
+	if enc := detectUTF16(content); enc != "" {
+		endianness := unicode.LittleEndian
+		if enc == EncodingUTF16BE {
+			endianness = unicode.BigEndian
+		}
+		return decodeWith(unicode.UTF16(endianness, unicode.IgnoreBOM), content), enc
+	}
+
+	if utf8.Valid(content) {
+		return string(content), EncodingUTF8
+	}
+
+	// Legacy Russian code pages: both map every byte, so pick the one that
+	// yields more common lowercase letters
+	cp1251 := decodeWith(charmap.Windows1251, content)
+	koi8r := decodeWith(charmap.KOI8R, content)
+	if russianLetterScore(koi8r) > russianLetterScore(cp1251) {
+		return koi8r, EncodingKOI8R
+	}
+	return cp1251, EncodingCP1251
+}
+
+// DecodeArchiveName converts a ZIP entry name to UTF-8. Archivers on Windows
+// store names in the OEM code page (CP866 for Russian) without the UTF-8 flag.
+func DecodeArchiveName(name string, nonUTF8 bool) string {
+	if !nonUTF8 && utf8.ValidString(name) {
+		return name
+	}
+	for i := 0; i < len(name); i++ {
+		if name[i] >= utf8.RuneSelf {
+			return decodeWith(charmap.CodePage866, []byte(name))
+		}
+	}
+	return name
+}
+
+func decodeWith(enc encoding.Encoding, content []byte) string {
+	decoded, err := enc.NewDecoder().Bytes(content)
+	if err != nil {
+		// Decoders replace invalid sequences; an error here means nothing usable
+		return string(content)
+	}
+	return string(decoded)
+}
+
+// detectUTF16 recognizes BOM-less UTF-16 by the NUL bytes that ASCII
+// characters leave in every other position
+func detectUTF16(content []byte) string {
+	n := len(content)
+	if n > sniffLength {
+		n = sniffLength
+	}
+	n &^= 1
+	if n < 4 {
+		return ""
+	}
+
+	var evenZeros, oddZeros int
+	for i := 0; i < n; i += 2 {
+		if content[i] == 0 {
+			evenZeros++
+		}
+		if content[i+1] == 0 {
+			oddZeros++
+		}
+	}
+
+	pairs := n / 2
+	switch {
+	case oddZeros*10 >= pairs*4 && evenZeros*10 < pairs:
+		return EncodingUTF16LE
+	case evenZeros*10 >= pairs*4 && oddZeros*10 < pairs:
+		return EncodingUTF16BE
+	}
+	return ""
+}
+
+func russianLetterScore(text string) int {
+	score := 0
+	for _, r := range text {
+		if strings.ContainsRune(frequentRussianLetters, r) {
+			score++
+		}
+	}
+	return score
+}
+
+// NormalizeText converts line endings to \n, strips NUL bytes (rejected by
+// PostgreSQL text columns), trims trailing whitespace on each line and
+// collapses runs of more than two blank lines
+func NormalizeText(text string) string {
+	if !strings.ContainsAny(text, "\r\x00 \t") && !strings.Contains(text, "\n\n\n\n") {
+		return text
+	}
+
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+	text = strings.ReplaceAll(text, "\x00", "")
+
+	lines := strings.Split(text, "\n")
+	var builder strings.Builder
+	builder.Grow(len(text))
+
+	blank := 0
+	for i, line := range lines {
+		line = strings.TrimRight(line, " \t")
+		if line == "" {
+			blank++
+			if blank > 2 {
+				continue
+			}
+		} else {
+			blank = 0
+		}
+		if i > 0 {
+			builder.WriteByte('\n')
+		}
+		builder.WriteString(line)
+	}
+	return builder.String()
+}
@@ -0,0 +1,98 @@
+package parser
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+)
+
+const russianSample = "Лекция 3. Сетевые протоколы\nПротокол TCP обеспечивает надёжную доставку данных."
+
+func TestDecodeText_DetectsEncodings(t *testing.T) {
+	cp1251, err := charmap.Windows1251.NewEncoder().Bytes([]byte(russianSample))
+	require.NoError(t, err)
+	koi8r, err := charmap.KOI8R.NewEncoder().Bytes([]byte(russianSample))
+	require.NoError(t, err)
+	utf16le, err := unicode.UTF16(unicode.LittleEndian, unicode.UseBOM).NewEncoder().Bytes([]byte(russianSample))
+	require.NoError(t, err)
+	utf16beNoBOM, err := unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM).NewEncoder().Bytes([]byte("Plain ASCII lecture notes"))
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name     string
+		content  []byte
+		expected string
+		encoding string
+	}{
+		{"utf-8", []byte(russianSample), russianSample, EncodingUTF8},
+		{"utf-8 with BOM", append([]byte{0xef, 0xbb, 0xbf}, russianSample...), russianSample, EncodingUTF8},
+		{"windows-1251", cp1251, russianSample, EncodingCP1251},
+		{"koi8-r", koi8r, russianSample, EncodingKOI8R},
+		{"utf-16le with BOM", utf16le, russianSample, EncodingUTF16LE},
+		{"utf-16be without BOM", utf16beNoBOM, "Plain ASCII lecture notes", EncodingUTF16BE},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			text, enc := DecodeText(tc.content)
+			assert.Equal(t, tc.expected, text)
+			assert.Equal(t, tc.encoding, enc)
+		})
+	}
+}
+
+func TestNormalizeText(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"crlf", "a\r\nb\r\n", "a\nb\n"},
+		{"old mac", "a\rb", "a\nb"},
+		{"trailing whitespace", "a  \t\nb ", "a\nb"},
+		{"indentation kept", "    code\n\tmore", "    code\n\tmore"},
+		{"blank lines collapsed", "a\n\n\n\n\nb", "a\n\n\nb"},
+		{"nul removed", "a\x00b", "ab"},
+		{"unchanged", "plain text", "plain text"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, NormalizeText(tc.input))
+		})
+	}
+}
+
+func TestParseDocument_ReportsEncoding(t *testing.T) {
+	cp1251, err := charmap.Windows1251.NewEncoder().Bytes([]byte(russianSample))
+	require.NoError(t, err)
+
+	result, err := ParseDocument(NewTXTParser(), bytes.NewReader(cp1251))
+	require.NoError(t, err)
+	assert.Equal(t, russianSample, result.Text)
+	assert.Equal(t, EncodingCP1251, result.Encoding)
+
+	result, err = ParseDocument(NewMDParser(), bytes.NewReader([]byte("# Title\r\n")))
+	require.NoError(t, err)
+	assert.Equal(t, "# Title\n", result.Text)
+	assert.Equal(t, EncodingUTF8, result.Encoding)
+
+	// Parsers without metadata support still work
+	result, err = ParseDocument(NewPDFParser(), bytes.NewReader([]byte("%PDF-1.4")))
+	require.NoError(t, err)
+	assert.NotEmpty(t, result.Text)
+	assert.Empty(t, result.Encoding)
+}
+
+func TestDecodeArchiveName(t *testing.T) {
+	assert.Equal(t, "Лекции/intro.pdf", DecodeArchiveName("Лекции/intro.pdf", false))
+	assert.Equal(t, "slides/intro.pdf", DecodeArchiveName("slides/intro.pdf", true))
+
+	oem, err := charmap.CodePage866.NewEncoder().String("Лекции/Введение.pdf")
+	require.NoError(t, err)
+	assert.Equal(t, "Лекции/Введение.pdf", DecodeArchiveName(oem, true))
+}
@@ -0,0 +1,110 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// epubContainer is META-INF/container.xml, which points at the package document
+type epubContainer struct {
+	Rootfiles []struct {
+		FullPath string `xml:"full-path,attr"`
+	} `xml:"rootfiles>rootfile"`
+}
+
+// epubPackage is the part of the OPF package document that defines reading order
+type epubPackage struct {
+	Manifest []struct {
+		ID        string `xml:"id,attr"`
+		Href      string `xml:"href,attr"`
+		MediaType string `xml:"media-type,attr"`
+	} `xml:"manifest>item"`
+	Spine []struct {
+		IDRef  string `xml:"idref,attr"`
+		Linear string `xml:"linear,attr"`
+	} `xml:"spine>itemref"`
+}
+
+// EPUBParser implements DocumentParser for EPUB e-books
+type EPUBParser struct{}
+
+// NewEPUBParser creates a new EPUB parser
+func NewEPUBParser() *EPUBParser {
+	return &EPUBParser{}
+}
+
+// Parse extracts the chapters of an e-book in reading order
+func (p *EPUBParser) Parse(reader io.Reader) (string, error) {
+	archive, err := openZipDocument(reader)
+	if err != nil {
+		return "", err
+	}
+
+	containerData, err := readZipFile(archive, "META-INF/container.xml")
+	if err != nil {
+		return "", err
+	}
+	var container epubContainer
+	if err := xml.Unmarshal(containerData, &container); err != nil || len(container.Rootfiles) == 0 {
+		return "", fmt.Errorf("invalid EPUB container")
+	}
+
+	packagePath := container.Rootfiles[0].FullPath
+	packageData, err := readZipFile(archive, packagePath)
+	if err != nil {
+		return "", err
+	}
+	var pkg epubPackage
+	if err := xml.Unmarshal(packageData, &pkg); err != nil {
+		return "", fmt.Errorf("invalid EPUB package document: %w", err)
+	}
+
+	hrefs := make(map[string]string, len(pkg.Manifest))
+	for _, item := range pkg.Manifest {
+		if item.MediaType == "application/xhtml+xml" || item.MediaType == "text/html" {
+			hrefs[item.ID] = item.Href
+		}
+	}
+
+	// Manifest paths are relative to the package document
+	baseDir := path.Dir(packagePath)
+	var chapters []string
+	for _, ref := range pkg.Spine {
+		href, ok := hrefs[ref.IDRef]
+		if !ok || ref.Linear == "no" {
+			continue
+		}
+
+		href = strings.SplitN(href, "#", 2)[0]
+		if unescaped, err := url.PathUnescape(href); err == nil {
+			href = unescaped
+		}
+		chapterPath := path.Join(baseDir, href)
+		data, err := readZipFile(archive, chapterPath)
+		if err != nil {
+			return "", err
+		}
+		root, err := html.Parse(bytes.NewReader(data))
+		if err != nil {
+			return "", fmt.Errorf("failed to parse chapter %s: %w", href, err)
+		}
+		// Chapter <title> elements usually repeat the book title
+		if text := renderHTML(root, false); text != "" {
+			chapters = append(chapters, text)
+		}
+	}
+
+	return strings.Join(chapters, "\n\n"), nil
+}
+
+// SupportedType returns the file type this parser supports
+func (p *EPUBParser) SupportedType() string {
+	return "epub"
+}
@@ -0,0 +1,53 @@
+package parser
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEPUBParser_Parse(t *testing.T) {
+	book := buildZip(t, map[string][]byte{
+		"mimetype": []byte("application/epub+zip"),
+		"META-INF/container.xml": []byte(`<?xml version="1.0"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles><rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/></rootfiles>
+</container>`),
+		"OEBPS/content.opf": []byte(`<?xml version="1.0"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0">
+  <manifest>
+    <item id="cover" href="cover.xhtml" media-type="application/xhtml+xml"/>
+    <item id="ch2" href="text/chapter%202.xhtml" media-type="application/xhtml+xml"/>
+    <item id="ch1" href="text/chapter1.xhtml" media-type="application/xhtml+xml"/>
+    <item id="css" href="style.css" media-type="text/css"/>
+  </manifest>
+  <spine>
+    <itemref idref="cover" linear="no"/>
+    <itemref idref="ch1"/>
+    <itemref idref="ch2"/>
+  </spine>
+</package>`),
+		"OEBPS/cover.xhtml":          []byte(`<html><body><p>Cover</p></body></html>`),
+		"OEBPS/text/chapter1.xhtml":  []byte(`<html xmlns="http://www.w3.org/1999/xhtml"><head><title>Book</title></head><body><h1>Глава 1</h1><p>Введение.</p></body></html>`),
+		"OEBPS/text/chapter 2.xhtml": []byte(`<html xmlns="http://www.w3.org/1999/xhtml"><head><title>Book</title></head><body><h1>Глава 2</h1><ol><li>Первое</li></ol></body></html>`),
+		"OEBPS/style.css":            []byte(`p { margin: 0 }`),
+	})
+
+	result, err := NewEPUBParser().Parse(bytes.NewReader(book))
+
+	require.NoError(t, err)
+	assert.Equal(t, "# Глава 1\n\nВведение.\n\n# Глава 2\n\n- Первое", result)
+}
+
+func TestEPUBParser_InvalidContainer(t *testing.T) {
+	book := buildZip(t, map[string][]byte{
+		"mimetype":               []byte("application/epub+zip"),
+		"META-INF/container.xml": []byte(`<container><rootfiles/></container>`),
+	})
+
+	_, err := NewEPUBParser().Parse(bytes.NewReader(book))
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,216 @@
+package parser
+
+import (
+	"io"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// htmlSkippedElements hold no readable content or only site chrome
+var htmlSkippedElements = map[atom.Atom]bool{
+	atom.Head:     true,
+	atom.Script:   true,
+	atom.Style:    true,
+	atom.Noscript: true,
+	atom.Template: true,
+	atom.Nav:      true,
+	atom.Svg:      true,
+	atom.Iframe:   true,
+	atom.Button:   true,
+	atom.Select:   true,
+}
+
+// htmlHeadingLevels maps heading elements to their level
+var htmlHeadingLevels = map[atom.Atom]int{
+	atom.H1: 1, atom.H2: 2, atom.H3: 3, atom.H4: 4, atom.H5: 5, atom.H6: 6,
+}
+
+// HTMLParser implements DocumentParser for saved web pages
+type HTMLParser struct{}
+
+// NewHTMLParser creates a new HTML parser
+func NewHTMLParser() *HTMLParser {
+	return &HTMLParser{}
+}
+
+// Parse extracts the readable text of an HTML page
+func (p *HTMLParser) Parse(reader io.Reader) (string, error) {
+	result, err := p.ParseWithMetadata(reader)
+	if err != nil {
+		return "", err
+	}
+	return result.Text, nil
+}
+
+// ParseWithMetadata extracts the readable text of an HTML page, transcoding it to UTF-8
+func (p *HTMLParser) ParseWithMetadata(reader io.Reader) (*ParseResult, error) {
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	// Saved pages of Russian sites are often in windows-1251 or koi8-r
+	text, encoding := decodeToUTF8(content)
+	root, err := html.Parse(strings.NewReader(text))
+	if err != nil {
+		return nil, err
+	}
+	return &ParseResult{Text: renderHTML(root, true), Encoding: encoding}, nil
+}
+
+// SupportedType returns the file type this parser supports
+func (p *HTMLParser) SupportedType() string {
+	return "html"
+}
+
+// renderHTML converts an HTML document into structured text. With withTitle,
+// the page title becomes the top heading when the body has no h1 of its own.
+func renderHTML(root *html.Node, withTitle bool) string {
+	r := &htmlRenderer{}
+	if title := findElement(root, atom.Title); withTitle && title != nil && findElement(root, atom.H1) == nil {
+		r.out.heading(1, nodeText(title, false))
+	}
+	r.walk(root)
+	r.flush()
+	return r.out.String()
+}
+
+// htmlRenderer walks the DOM collecting inline text until a block element ends it
+type htmlRenderer struct {
+	out    structuredText
+	inline strings.Builder
+	lists  int // Depth of open ul/ol elements
+}
+
+func (r *htmlRenderer) walk(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		// Source line breaks are just whitespace; <br> produces real ones
+		r.inline.WriteString(strings.ReplaceAll(n.Data, "\n", " "))
+		return
+	case html.ElementNode:
+	default:
+		r.walkChildren(n)
+		return
+	}
+
+	if htmlSkippedElements[n.DataAtom] {
+		return
+	}
+	if level, ok := htmlHeadingLevels[n.DataAtom]; ok {
+		r.flush()
+		r.out.heading(level, nodeText(n, false))
+		return
+	}
+
+	switch n.DataAtom {
+	case atom.Br:
+		r.inline.WriteByte('\n')
+	case atom.Pre:
+		r.flush()
+		r.out.code(nodeText(n, true))
+	case atom.Tr:
+		r.flush()
+		var cells []string
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.DataAtom == atom.Td || c.DataAtom == atom.Th {
+				cells = append(cells, nodeText(c, false))
+			}
+		}
+		r.out.tableRow(cells)
+	case atom.Ul, atom.Ol:
+		r.flush()
+		r.lists++
+		r.walkChildren(n)
+		r.flush()
+		r.lists--
+	case atom.Img:
+		// Alternative text often describes diagrams and formulas
+		if alt := attr(n, "alt"); alt != "" {
+			r.inline.WriteString(" " + alt + " ")
+		}
+	default:
+		if isHTMLBlock(n.DataAtom) {
+			r.flush()
+			r.walkChildren(n)
+			r.flush()
+			return
+		}
+		r.walkChildren(n)
+	}
+}
+
+func (r *htmlRenderer) walkChildren(n *html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		r.walk(c)
+	}
+}
+
+// flush emits the collected inline text as a paragraph or, inside a list, a list item
+func (r *htmlRenderer) flush() {
+	text := r.inline.String()
+	r.inline.Reset()
+	if r.lists > 0 {
+		r.out.listItem(text)
+		return
+	}
+	r.out.paragraph(text)
+}
+
+// isHTMLBlock reports elements that start a new block of text
+func isHTMLBlock(a atom.Atom) bool {
+	switch a {
+	case atom.P, atom.Div, atom.Section, atom.Article, atom.Main, atom.Header, atom.Footer,
+		atom.Aside, atom.Blockquote, atom.Li, atom.Dl, atom.Dt, atom.Dd, atom.Figure,
+		atom.Figcaption, atom.Table, atom.Caption, atom.Hr, atom.Body, atom.Address, atom.Details, atom.Summary:
+		return true
+	}
+	return false
+}
+
+// nodeText returns the text of an element and its descendants. Source line
+// breaks are kept only for preformatted text, <br> always breaks the line.
+func nodeText(n *html.Node, preformatted bool) string {
+	var builder strings.Builder
+	var collect func(*html.Node)
+	collect = func(n *html.Node) {
+		switch {
+		case n.Type == html.TextNode && preformatted:
+			builder.WriteString(n.Data)
+		case n.Type == html.TextNode:
+			builder.WriteString(strings.ReplaceAll(n.Data, "\n", " "))
+		case n.Type == html.ElementNode && htmlSkippedElements[n.DataAtom]:
+		case n.DataAtom == atom.Br:
+			builder.WriteByte('\n')
+		default:
+			for c := n.FirstChild; c != nil; c = c.NextSibling {
+				collect(c)
+			}
+		}
+	}
+	collect(n)
+	return builder.String()
+}
+
+func findElement(n *html.Node, a atom.Atom) *html.Node {
+	if n.Type == html.ElementNode && n.DataAtom == a {
+		return n
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if found := findElement(c, a); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
@@ -0,0 +1,61 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding/charmap"
+)
+
+func TestHTMLParser_Parse(t *testing.T) {
+	parser := NewHTMLParser()
+
+	t.Run("should keep structure and drop scripts and navigation", func(t *testing.T) {
+		page := `<!DOCTYPE html>
+<html><head><title>Лекция 5 — Хеш-таблицы</title><style>body { color: red }</style></head>
+<body>
+<nav><a href="/">Главная</a> | <a href="/courses">Курсы</a></nav>
+<h2>Коллизии</h2>
+<p>Коллизия возникает, когда <b>два ключа</b>
+   дают один хеш.<br>Решения:</p>
+<ul><li>Цепочки</li><li>Открытая адресация<ul><li>линейное пробирование</li></ul></li></ul>
+<table><tr><th>Метод</th><th>Память</th></tr><tr><td>Цепочки</td><td>O(n)</td></tr></table>
+<pre>h(k) = k mod m
+  i = 0</pre>
+<img src="chart.png" alt="График заполнения">
+<script>alert("x")</script>
+</body></html>`
+
+		result, err := parser.Parse(strings.NewReader(page))
+
+		require.NoError(t, err)
+		assert.Equal(t, "# Лекция 5 — Хеш-таблицы\n\n"+
+			"## Коллизии\n\n"+
+			"Коллизия возникает, когда два ключа дают один хеш.\nРешения:\n\n"+
+			"- Цепочки\n- Открытая адресация\n- линейное пробирование\n\n"+
+			"Метод | Память\nЦепочки | O(n)\n\n"+
+			"```\nh(k) = k mod m\n  i = 0\n```\n\n"+
+			"График заполнения",
+			result)
+	})
+
+	t.Run("should not repeat the title when the page has its own h1", func(t *testing.T) {
+		result, err := parser.Parse(strings.NewReader("<title>Site</title><h1>Article</h1><p>Text</p>"))
+
+		require.NoError(t, err)
+		assert.Equal(t, "# Article\n\nText", result)
+	})
+
+	t.Run("should decode legacy Cyrillic pages", func(t *testing.T) {
+		page, err := charmap.Windows1251.NewEncoder().String("<p>Привет, мир! Это страница в старой кодировке.</p>")
+		require.NoError(t, err)
+
+		result, err := parser.ParseWithMetadata(strings.NewReader(page))
+
+		require.NoError(t, err)
+		assert.Equal(t, "Привет, мир! Это страница в старой кодировке.", result.Text)
+		assert.Equal(t, EncodingCP1251, result.Encoding)
+	})
+}
@@ -14,13 +14,23 @@ func NewMDParser() *MDParser {
 
 // Parse extracts text from a Markdown file
 func (p *MDParser) Parse(reader io.Reader) (string, error) {
+	result, err := p.ParseWithMetadata(reader)
+	if err != nil {
+		return "", err
+	}
+	return result.Text, nil
+}
+
+// ParseWithMetadata extracts text from a Markdown file, transcoding it to UTF-8
+func (p *MDParser) ParseWithMetadata(reader io.Reader) (*ParseResult, error) {
 	// Markdown is plain text, so we can read it directly
 	content, err := io.ReadAll(reader)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return string(content), nil
+	text, encoding := DecodeText(content)
+	return &ParseResult{Text: text, Encoding: encoding}, nil
 }
 
 // SupportedType returns the file type this parser supports
@@ -0,0 +1,146 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// notebookOutputLimit bounds the text kept from a single cell output; long
+// tables and logs add nothing a question could use
+const notebookOutputLimit = 2000
+
+// notebook is the part of the Jupyter nbformat 4 document the parser reads
+type notebook struct {
+	Cells    []notebookCell `json:"cells"`
+	Metadata struct {
+		KernelSpec struct {
+			Language string `json:"language"`
+		} `json:"kernelspec"`
+		LanguageInfo struct {
+			Name string `json:"name"`
+		} `json:"language_info"`
+	} `json:"metadata"`
+}
+
+type notebookCell struct {
+	CellType string           `json:"cell_type"`
+	Source   notebookText     `json:"source"`
+	Outputs  []notebookOutput `json:"outputs"`
+}
+
+type notebookOutput struct {
+	OutputType string                  `json:"output_type"`
+	Text       notebookText            `json:"text"`
+	Data       map[string]notebookText `json:"data"`
+	EName      string                  `json:"ename"`
+	EValue     string                  `json:"evalue"`
+}
+
+// notebookText is a multiline string, stored either as one string or as a list of lines
+type notebookText string
+
+// UnmarshalJSON accepts both representations allowed by nbformat
+func (t *notebookText) UnmarshalJSON(data []byte) error {
+	var lines []string
+	if err := json.Unmarshal(data, &lines); err == nil {
+		*t = notebookText(strings.Join(lines, ""))
+		return nil
+	}
+
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		// Non-text MIME bundles (images, widgets) are not needed
+		return nil
+	}
+	*t = notebookText(text)
+	return nil
+}
+
+// NotebookParser implements DocumentParser for Jupyter notebooks
+type NotebookParser struct {
+	includeOutputs bool
+}
+
+// NewNotebookParser creates a new Jupyter notebook parser.
+// With includeOutputs, text outputs of code cells follow the code.
+func NewNotebookParser(includeOutputs bool) *NotebookParser {
+	return &NotebookParser{includeOutputs: includeOutputs}
+}
+
+// Parse extracts markdown and code cells from a notebook
+func (p *NotebookParser) Parse(reader io.Reader) (string, error) {
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return "", err
+	}
+
+	var nb notebook
+	if err := json.Unmarshal(content, &nb); err != nil {
+		return "", fmt.Errorf("failed to parse notebook: %w", err)
+	}
+
+	language := nb.Metadata.LanguageInfo.Name
+	if language == "" {
+		language = nb.Metadata.KernelSpec.Language
+	}
+
+	var blocks []string
+	for _, cell := range nb.Cells {
+		source := strings.TrimSpace(NormalizeText(string(cell.Source)))
+		if source == "" {
+			continue
+		}
+
+		switch cell.CellType {
+		case "markdown":
+			blocks = append(blocks, source)
+		case "code":
+			blocks = append(blocks, fenceCode(language, source))
+			if p.includeOutputs {
+				if output := cellOutputText(cell.Outputs); output != "" {
+					blocks = append(blocks, fenceCode("output", output))
+				}
+			}
+		}
+	}
+
+	return strings.Join(blocks, "\n\n"), nil
+}
+
+// SupportedType returns the file type this parser supports
+func (p *NotebookParser) SupportedType() string {
+	return "ipynb"
+}
+
+// cellOutputText joins the plain text outputs of a code cell
+func cellOutputText(outputs []notebookOutput) string {
+	var parts []string
+	for _, output := range outputs {
+		var text string
+		switch output.OutputType {
+		case "stream":
+			text = string(output.Text)
+		case "execute_result", "display_data":
+			text = string(output.Data["text/plain"])
+		case "error":
+			text = output.EName + ": " + output.EValue
+		}
+
+		text = strings.TrimSpace(NormalizeText(text))
+		if text == "" {
+			continue
+		}
+		if runes := []rune(text); len(runes) > notebookOutputLimit {
+			text = string(runes[:notebookOutputLimit]) + "\n..."
+		}
+		parts = append(parts, text)
+	}
+	return strings.Join(parts, "\n")
+}
+
+// fenceCode wraps code in a Markdown fenced block so the model sees where code starts and ends
+func fenceCode(language, code string) string {
+	return "```" + language + "\n" + code + "\n```"
+}
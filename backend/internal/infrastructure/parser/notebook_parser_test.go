@@ -0,0 +1,69 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testNotebook = `{
+ "cells": [
+  {"cell_type": "markdown", "metadata": {}, "source": ["# Lists\n", "\n", "Lists are **mutable** sequences."]},
+  {"cell_type": "code", "execution_count": 1, "metadata": {}, "source": "nums = [3, 1, 2]\nnums.sort()\nprint(nums)",
+   "outputs": [{"output_type": "stream", "name": "stdout", "text": ["[1, 2, 3]\n"]}]},
+  {"cell_type": "code", "execution_count": 2, "metadata": {}, "source": ["len(nums)"],
+   "outputs": [{"output_type": "execute_result", "execution_count": 2, "metadata": {},
+     "data": {"text/plain": ["3"], "image/png": "iVBORw0KGgo="}}]},
+  {"cell_type": "code", "execution_count": 3, "metadata": {}, "source": ["nums[5]"],
+   "outputs": [{"output_type": "error", "ename": "IndexError", "evalue": "list index out of range", "traceback": []}]},
+  {"cell_type": "raw", "metadata": {}, "source": ["raw cell"]},
+  {"cell_type": "code", "execution_count": null, "metadata": {}, "source": [], "outputs": []}
+ ],
+ "metadata": {"kernelspec": {"language": "python", "name": "python3"}, "language_info": {"name": "python"}},
+ "nbformat": 4,
+ "nbformat_minor": 5
+}`
+
+func TestNotebookParser_Parse(t *testing.T) {
+	t.Run("should extract markdown and code cells with outputs", func(t *testing.T) {
+		result, err := NewNotebookParser(true).Parse(strings.NewReader(testNotebook))
+
+		require.NoError(t, err)
+		assert.Equal(t, "# Lists\n\nLists are **mutable** sequences.\n\n"+
+			"```python\nnums = [3, 1, 2]\nnums.sort()\nprint(nums)\n```\n\n"+
+			"```output\n[1, 2, 3]\n```\n\n"+
+			"```python\nlen(nums)\n```\n\n"+
+			"```output\n3\n```\n\n"+
+			"```python\nnums[5]\n```\n\n"+
+			"```output\nIndexError: list index out of range\n```",
+			result)
+	})
+
+	t.Run("should skip outputs when disabled", func(t *testing.T) {
+		result, err := NewNotebookParser(false).Parse(strings.NewReader(testNotebook))
+
+		require.NoError(t, err)
+		assert.NotContains(t, result, "```output")
+		assert.NotContains(t, result, "raw cell")
+		assert.Contains(t, result, "```python\nlen(nums)\n```")
+	})
+
+	t.Run("should fail on invalid JSON", func(t *testing.T) {
+		_, err := NewNotebookParser(true).Parse(strings.NewReader("{not json"))
+
+		assert.Error(t, err)
+	})
+}
+
+func TestDocumentParserFactory_SetNotebookOutputs(t *testing.T) {
+	factory := NewDocumentParserFactory()
+	factory.SetNotebookOutputs(false)
+
+	parser, err := factory.CreateParser("ipynb")
+	require.NoError(t, err)
+	result, err := parser.Parse(strings.NewReader(testNotebook))
+	require.NoError(t, err)
+	assert.NotContains(t, result, "```output")
+}
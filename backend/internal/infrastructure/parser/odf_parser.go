@@ -0,0 +1,266 @@
+package parser
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// odfNamespaces maps OpenDocument namespace URIs to their conventional prefixes
+var odfNamespaces = map[string]string{
+	"urn:oasis:names:tc:opendocument:xmlns:office:1.0":       "office",
+	"urn:oasis:names:tc:opendocument:xmlns:text:1.0":         "text",
+	"urn:oasis:names:tc:opendocument:xmlns:table:1.0":        "table",
+	"urn:oasis:names:tc:opendocument:xmlns:drawing:1.0":      "draw",
+	"urn:oasis:names:tc:opendocument:xmlns:presentation:1.0": "presentation",
+}
+
+// odfSkippedElements hold no body text: footnotes and comments would break up
+// the sentence they are attached to, change tracking holds deleted text
+var odfSkippedElements = map[string]bool{
+	"text:note":            true,
+	"office:annotation":    true,
+	"text:tracked-changes": true,
+	"text:sequence-decls":  true,
+	"draw:image":           true,
+}
+
+// ODTParser implements DocumentParser for OpenDocument text documents
+type ODTParser struct{}
+
+// NewODTParser creates a new OpenDocument text parser
+func NewODTParser() *ODTParser {
+	return &ODTParser{}
+}
+
+// Parse extracts headings, paragraphs, lists and tables of an ODT document
+func (p *ODTParser) Parse(reader io.Reader) (string, error) {
+	return parseODF(reader)
+}
+
+// SupportedType returns the file type this parser supports
+func (p *ODTParser) SupportedType() string {
+	return "odt"
+}
+
+// ODPParser implements DocumentParser for OpenDocument presentations
+type ODPParser struct{}
+
+// NewODPParser creates a new OpenDocument presentation parser
+func NewODPParser() *ODPParser {
+	return &ODPParser{}
+}
+
+// Parse extracts slide titles, slide text and speaker notes of an ODP presentation
+func (p *ODPParser) Parse(reader io.Reader) (string, error) {
+	return parseODF(reader)
+}
+
+// SupportedType returns the file type this parser supports
+func (p *ODPParser) SupportedType() string {
+	return "odp"
+}
+
+// parseODF renders content.xml of an OpenDocument package. Text documents and
+// presentations share the text vocabulary; slides add draw:page elements whose
+// title frame becomes a heading.
+func parseODF(reader io.Reader) (string, error) {
+	content, err := readZipPart(reader, "content.xml")
+	if err != nil {
+		return "", err
+	}
+
+	r := &odfRenderer{decoder: xml.NewDecoder(bytes.NewReader(content))}
+	if err := r.render(); err != nil {
+		return "", fmt.Errorf("failed to parse OpenDocument content: %w", err)
+	}
+	return r.out.String(), nil
+}
+
+// odfRenderer streams content.xml, collecting text of the innermost paragraph
+type odfRenderer struct {
+	decoder *xml.Decoder
+	out     structuredText
+
+	text         strings.Builder
+	paragraphs   int // Depth of open text:p/text:h elements
+	heading      int // Outline level of the open text:h, 0 for paragraphs
+	listItems    int // Depth of open text:list-item elements
+	titleFrames  int // Depth of open presentation title frames
+	cells        int // Depth of open table cells
+	cellText     strings.Builder
+	row          []string
+	frameIsTitle []bool
+}
+
+func (r *odfRenderer) render() error {
+	skip := 0
+	for {
+		token, err := r.decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			name := odfName(t.Name)
+			if skip > 0 || odfSkippedElements[name] {
+				skip++
+				continue
+			}
+			r.start(name, t)
+		case xml.EndElement:
+			if skip > 0 {
+				skip--
+				continue
+			}
+			r.end(odfName(t.Name))
+		case xml.CharData:
+			if skip == 0 && r.paragraphs > 0 {
+				r.text.Write(t)
+			}
+		}
+	}
+}
+
+func (r *odfRenderer) start(name string, t xml.StartElement) {
+	switch name {
+	case "text:h":
+		if r.paragraphs == 0 {
+			r.heading = 1
+			if level, err := strconv.Atoi(odfAttr(t, "outline-level")); err == nil {
+				r.heading = level
+			}
+		}
+		r.paragraphs++
+	case "text:p":
+		r.paragraphs++
+	case "text:s":
+		count, err := strconv.Atoi(odfAttr(t, "c"))
+		if err != nil || count < 1 {
+			count = 1
+		}
+		r.text.WriteString(strings.Repeat(" ", count))
+	case "text:tab":
+		r.text.WriteByte(' ')
+	case "text:line-break":
+		r.text.WriteByte('\n')
+	case "text:list-item":
+		r.listItems++
+	case "table:table-row":
+		r.row = nil
+	case "table:table-cell":
+		r.cells++
+	case "draw:frame":
+		isTitle := odfAttr(t, "class") == "title"
+		r.frameIsTitle = append(r.frameIsTitle, isTitle)
+		if isTitle {
+			r.titleFrames++
+		}
+	}
+}
+
+func (r *odfRenderer) end(name string) {
+	switch name {
+	case "text:h", "text:p":
+		r.paragraphs--
+		if r.paragraphs == 0 {
+			r.flushText()
+		}
+	case "text:list-item":
+		r.listItems--
+	case "table:table-cell":
+		r.cells--
+		if r.cells == 0 {
+			r.row = append(r.row, r.cellText.String())
+			r.cellText.Reset()
+		}
+	case "table:table-row":
+		r.out.tableRow(r.row)
+		r.row = nil
+	case "draw:frame":
+		if n := len(r.frameIsTitle); n > 0 {
+			if r.frameIsTitle[n-1] {
+				r.titleFrames--
+			}
+			r.frameIsTitle = r.frameIsTitle[:n-1]
+		}
+	}
+}
+
+// flushText emits the text of a finished paragraph according to where it sits
+func (r *odfRenderer) flushText() {
+	text := r.text.String()
+	r.text.Reset()
+	heading := r.heading
+	r.heading = 0
+
+	switch {
+	case r.cells > 0:
+		r.cellText.WriteString(text + " ")
+	case r.titleFrames > 0:
+		r.out.heading(2, text)
+	case heading > 0:
+		r.out.heading(heading, text)
+	case r.listItems > 0:
+		r.out.listItem(text)
+	default:
+		r.out.paragraph(text)
+	}
+}
+
+// odfName returns "prefix:local" for elements of the known ODF namespaces
+func odfName(name xml.Name) string {
+	if prefix, ok := odfNamespaces[name.Space]; ok {
+		return prefix + ":" + name.Local
+	}
+	return name.Local
+}
+
+// odfAttr returns an attribute value by local name; ODF attribute names are unique enough
+func odfAttr(t xml.StartElement, local string) string {
+	for _, a := range t.Attr {
+		if a.Name.Local == local {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// readZipPart reads one file of a ZIP-based document. Archive limits were
+// checked when the file was uploaded.
+func readZipPart(reader io.Reader, name string) ([]byte, error) {
+	archive, err := openZipDocument(reader)
+	if err != nil {
+		return nil, err
+	}
+	return readZipFile(archive, name)
+}
+
+func openZipDocument(reader io.Reader) (*zip.Reader, error) {
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	archive, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open document archive: %w", err)
+	}
+	return archive, nil
+}
+
+func readZipFile(archive *zip.Reader, name string) ([]byte, error) {
+	file, err := archive.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("document archive has no %s", name)
+	}
+	defer file.Close()
+	return io.ReadAll(file)
+}
@@ -0,0 +1,90 @@
+package parser
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const odfContentHeader = `<?xml version="1.0" encoding="UTF-8"?>
+<office:document-content
+  xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0"
+  xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0"
+  xmlns:table="urn:oasis:names:tc:opendocument:xmlns:table:1.0"
+  xmlns:draw="urn:oasis:names:tc:opendocument:xmlns:drawing:1.0"
+  xmlns:presentation="urn:oasis:names:tc:opendocument:xmlns:presentation:1.0">
+<office:body>`
+
+const odfContentFooter = `</office:body></office:document-content>`
+
+func buildODF(t *testing.T, mimeType, body string) []byte {
+	return buildZip(t, map[string][]byte{
+		"mimetype":    []byte(mimeType),
+		"content.xml": []byte(odfContentHeader + body + odfContentFooter),
+	})
+}
+
+func TestODTParser_Parse(t *testing.T) {
+	doc := buildODF(t, "application/vnd.oasis.opendocument.text", `<office:text>
+  <text:sequence-decls><text:sequence-decl text:name="Figure"/></text:sequence-decls>
+  <text:h text:outline-level="1">Сортировка</text:h>
+  <text:p>Алгоритм<text:s text:c="2"/>упорядочивает<text:note><text:note-body><text:p>сноска</text:p></text:note-body></text:note> элементы.</text:p>
+  <text:h text:outline-level="2">Виды</text:h>
+  <text:list>
+    <text:list-item><text:p>Быстрая</text:p></text:list-item>
+    <text:list-item><text:p>Слиянием</text:p></text:list-item>
+  </text:list>
+  <table:table>
+    <table:table-row><table:table-cell><text:p>Алгоритм</text:p></table:table-cell><table:table-cell><text:p>Сложность</text:p></table:table-cell></table:table-row>
+    <table:table-row><table:table-cell><text:p>Быстрая</text:p></table:table-cell><table:table-cell><text:p>O(n log n)</text:p></table:table-cell></table:table-row>
+  </table:table>
+  <office:annotation><text:p>комментарий рецензента</text:p></office:annotation>
+  <text:p>Строка<text:line-break/>вторая</text:p>
+</office:text>`)
+
+	result, err := NewODTParser().Parse(bytes.NewReader(doc))
+
+	require.NoError(t, err)
+	assert.Equal(t, "# Сортировка\n\n"+
+		"Алгоритм упорядочивает элементы.\n\n"+
+		"## Виды\n\n"+
+		"- Быстрая\n- Слиянием\n\n"+
+		"Алгоритм | Сложность\nБыстрая | O(n log n)\n\n"+
+		"Строка\nвторая",
+		result)
+}
+
+func TestODPParser_Parse(t *testing.T) {
+	doc := buildODF(t, "application/vnd.oasis.opendocument.presentation", `<office:presentation>
+  <draw:page draw:name="page1">
+    <draw:frame presentation:class="title"><draw:text-box><text:p>Графы</text:p></draw:text-box></draw:frame>
+    <draw:frame presentation:class="outline"><draw:text-box>
+      <text:list><text:list-item><text:p>Вершины</text:p></text:list-item><text:list-item><text:p>Ребра</text:p></text:list-item></text:list>
+    </draw:text-box></draw:frame>
+    <draw:frame><draw:image><text:p>подпись картинки</text:p></draw:image></draw:frame>
+    <presentation:notes><draw:frame presentation:class="notes"><draw:text-box><text:p>Напомнить про ориентированные графы.</text:p></draw:text-box></draw:frame></presentation:notes>
+  </draw:page>
+  <draw:page draw:name="page2">
+    <draw:frame presentation:class="title"><draw:text-box><text:p>Обход в ширину</text:p></draw:text-box></draw:frame>
+  </draw:page>
+</office:presentation>`)
+
+	result, err := NewODPParser().Parse(bytes.NewReader(doc))
+
+	require.NoError(t, err)
+	assert.Equal(t, "## Графы\n\n"+
+		"- Вершины\n- Ребра\n\n"+
+		"Напомнить про ориентированные графы.\n\n"+
+		"## Обход в ширину",
+		result)
+}
+
+func TestODFParser_InvalidArchive(t *testing.T) {
+	_, err := NewODTParser().Parse(bytes.NewReader([]byte("not a zip")))
+	assert.Error(t, err)
+
+	_, err = NewODTParser().Parse(bytes.NewReader(buildZip(t, map[string][]byte{"mimetype": []byte("x")})))
+	assert.ErrorContains(t, err, "content.xml")
+}
@@ -11,15 +11,41 @@ type DocumentParser interface {
 	SupportedType() string
 }
 
+// ParseResult holds extracted text together with metadata about the source
+type ParseResult struct {
+	Text     string
+	Encoding string // Detected source charset for text formats, empty otherwise
+}
+
+// MetadataParser is implemented by parsers that report metadata along with the text
+type MetadataParser interface {
+	ParseWithMetadata(reader io.Reader) (*ParseResult, error)
+}
+
+// ParseDocument runs the parser, collecting metadata when the parser supports it
+func ParseDocument(parser DocumentParser, reader io.Reader) (*ParseResult, error) {
+	if mp, ok := parser.(MetadataParser); ok {
+		return mp.ParseWithMetadata(reader)
+	}
+
+	text, err := parser.Parse(reader)
+	if err != nil {
+		return nil, err
+	}
+	return &ParseResult{Text: text}, nil
+}
+
 // DocumentParserFactory creates parsers based on file type (Factory Pattern)
 type DocumentParserFactory struct {
-	parsers map[string]DocumentParser
+	parsers       map[string]DocumentParser
+	archiveLimits ArchiveLimits
 }
 
 // NewDocumentParserFactory creates a new parser factory
 func NewDocumentParserFactory() *DocumentParserFactory {
 	factory := &DocumentParserFactory{
-		parsers: make(map[string]DocumentParser),
+		parsers:       make(map[string]DocumentParser),
+		archiveLimits: DefaultArchiveLimits(),
 	}
 
 	// Register all available parsers
@@ -28,6 +54,17 @@ func NewDocumentParserFactory() *DocumentParserFactory {
 	factory.Register(NewPPTXParser())
 	factory.Register(NewTXTParser())
 	factory.Register(NewMDParser())
+	factory.Register(NewSRTParser())
+	factory.Register(NewVTTParser())
+	factory.Register(NewNotebookParser(true))
+	factory.Register(NewGoParser())
+	factory.Register(NewPythonParser())
+	factory.Register(NewJavaParser())
+	factory.Register(NewODTParser())
+	factory.Register(NewODPParser())
+	factory.Register(NewRTFParser())
+	factory.Register(NewHTMLParser())
+	factory.Register(NewEPUBParser())
 
 	return factory
 }
@@ -37,6 +74,11 @@ func (f *DocumentParserFactory) Register(parser DocumentParser) {
 	f.parsers[parser.SupportedType()] = parser
 }
 
+// SetNotebookOutputs sets whether text outputs of notebook code cells are extracted
+func (f *DocumentParserFactory) SetNotebookOutputs(include bool) {
+	f.Register(NewNotebookParser(include))
+}
+
 // CreateParser returns appropriate parser for the file type
 func (f *DocumentParserFactory) CreateParser(fileType string) (DocumentParser, error) {
 	parser, exists := f.parsers[fileType]
@@ -0,0 +1,311 @@
+package parser
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// ErrInvalidRTF is returned when content does not start with an RTF header
+var ErrInvalidRTF = errors.New("not an RTF document")
+
+// rtfSkippedDestinations are groups that hold formatting tables, metadata or
+// embedded objects rather than document text
+var rtfSkippedDestinations = map[string]bool{
+	"fonttbl": true, "colortbl": true, "stylesheet": true, "info": true, "pict": true,
+	"header": true, "headerl": true, "headerr": true, "headerf": true,
+	"footer": true, "footerl": true, "footerr": true, "footerf": true,
+	"footnote": true, "annotation": true, "fldinst": true, "object": true,
+	"listtable": true, "listoverridetable": true, "rsidtbl": true, "generator": true,
+	"themedata": true, "colorschememapping": true, "latentstyles": true, "datastore": true,
+	"xmlnstbl": true, "revtbl": true, "filetbl": true, "pgdsctbl": true,
+	// Bullet and number text of list paragraphs; list items get their own marker
+	"listtext": true, "pntext": true,
+}
+
+// rtfCodePages maps \ansicpg values to decoders for \'hh escapes
+var rtfCodePages = map[int]*charmap.Charmap{
+	866:  charmap.CodePage866,
+	1250: charmap.Windows1250,
+	1251: charmap.Windows1251,
+	1252: charmap.Windows1252,
+	1253: charmap.Windows1253,
+	1254: charmap.Windows1254,
+	1257: charmap.Windows1257,
+}
+
+// RTFParser implements DocumentParser for Rich Text Format documents
+type RTFParser struct{}
+
+// NewRTFParser creates a new RTF parser
+func NewRTFParser() *RTFParser {
+	return &RTFParser{}
+}
+
+// Parse extracts paragraphs of an RTF document, marking outline headings and list items
+func (p *RTFParser) Parse(reader io.Reader) (string, error) {
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return "", err
+	}
+	if !strings.HasPrefix(strings.TrimLeft(string(content), " \r\n\t\xef\xbb\xbf"), `{\rtf`) {
+		return "", ErrInvalidRTF
+	}
+
+	r := &rtfRenderer{data: content, codePage: charmap.Windows1252, uc: 1}
+	r.render()
+	return r.out.String(), nil
+}
+
+// SupportedType returns the file type this parser supports
+func (p *RTFParser) SupportedType() string {
+	return "rtf"
+}
+
+// rtfGroup is the state saved when a group opens and restored when it closes
+type rtfGroup struct {
+	skip bool
+	uc   int
+}
+
+// rtfRenderer is a small RTF tokenizer that keeps text and paragraph properties only
+type rtfRenderer struct {
+	data []byte
+	pos  int
+	out  structuredText
+
+	codePage  *charmap.Charmap
+	text      strings.Builder
+	stack     []rtfGroup
+	skip      bool // Inside a skipped destination
+	uc        int  // Fallback characters that follow a \u escape
+	skipChars int  // Fallback characters still to drop
+	outline   int  // \outlinelevel + 1 of the current paragraph, 0 for body text
+	list      bool // Current paragraph is a list item
+	pendingHi rune // High surrogate of a \u pair
+}
+
+func (r *rtfRenderer) render() {
+	// Set when a group starts, so the first control word can mark it as a destination
+	groupStart := false
+	for r.pos < len(r.data) {
+		c := r.data[r.pos]
+		switch c {
+		case '{':
+			r.stack = append(r.stack, rtfGroup{skip: r.skip, uc: r.uc})
+			r.pos++
+			groupStart = true
+			continue
+		case '}':
+			if n := len(r.stack); n > 0 {
+				r.skip, r.uc = r.stack[n-1].skip, r.stack[n-1].uc
+				r.stack = r.stack[:n-1]
+			}
+			r.pos++
+		case '\\':
+			r.controlWord(groupStart)
+		case '\r', '\n':
+			r.pos++
+		default:
+			r.pos++
+			r.writeByte(c)
+		}
+		groupStart = false
+	}
+	r.endParagraph()
+}
+
+// controlWord handles a control word or control symbol starting at a backslash
+func (r *rtfRenderer) controlWord(groupStart bool) {
+	r.pos++
+	if r.pos >= len(r.data) {
+		return
+	}
+
+	c := r.data[r.pos]
+	if !isASCIILetter(c) {
+		r.pos++
+		r.controlSymbol(c, groupStart)
+		return
+	}
+
+	start := r.pos
+	for r.pos < len(r.data) && isASCIILetter(r.data[r.pos]) {
+		r.pos++
+	}
+	word := string(r.data[start:r.pos])
+
+	param, hasParam := 0, false
+	paramStart := r.pos
+	if r.pos < len(r.data) && r.data[r.pos] == '-' {
+		r.pos++
+	}
+	for r.pos < len(r.data) && r.data[r.pos] >= '0' && r.data[r.pos] <= '9' {
+		r.pos++
+	}
+	if r.pos > paramStart {
+		if n, err := strconv.Atoi(string(r.data[paramStart:r.pos])); err == nil {
+			param, hasParam = n, true
+		} else {
+			r.pos = paramStart
+		}
+	}
+	// A single space delimits the control word and is not text
+	if r.pos < len(r.data) && r.data[r.pos] == ' ' {
+		r.pos++
+	}
+
+	if groupStart && rtfSkippedDestinations[word] {
+		r.skip = true
+		return
+	}
+	r.applyWord(word, param, hasParam)
+}
+
+func (r *rtfRenderer) controlSymbol(c byte, groupStart bool) {
+	switch c {
+	case '*':
+		// Ignorable destination: readers that do not know it must skip the group
+		if groupStart {
+			r.skip = true
+		}
+	case '\'':
+		if r.pos+2 <= len(r.data) {
+			if b, err := strconv.ParseUint(string(r.data[r.pos:r.pos+2]), 16, 8); err == nil {
+				r.pos += 2
+				if r.skipChars > 0 {
+					r.skipChars--
+					return
+				}
+				if !r.skip {
+					r.text.WriteRune(r.codePage.DecodeByte(byte(b)))
+				}
+			}
+		}
+	case '~':
+		r.writeText(" ")
+	case '_':
+		r.writeText("-")
+	case '\\', '{', '}':
+		r.writeByte(c)
+	case '\r', '\n':
+		r.endParagraph()
+	}
+}
+
+func (r *rtfRenderer) applyWord(word string, param int, hasParam bool) {
+	// Properties set inside skipped destinations must not leak into body paragraphs
+	if r.skip && word != "bin" {
+		return
+	}
+
+	switch word {
+	case "ansicpg":
+		if cp, ok := rtfCodePages[param]; ok {
+			r.codePage = cp
+		}
+	case "par", "sect", "page", "row":
+		r.endParagraph()
+	case "pard":
+		r.outline, r.list = 0, false
+	case "outlinelevel":
+		if hasParam && param >= 0 && param < 9 {
+			r.outline = param + 1
+		}
+	case "ls", "ilvl", "pnlvlblt", "pnlvlbody":
+		r.list = true
+	case "line":
+		r.writeText("\n")
+	case "tab":
+		r.writeText(" ")
+	case "cell":
+		r.writeText(" | ")
+	case "emdash":
+		r.writeText("—")
+	case "endash":
+		r.writeText("–")
+	case "bullet":
+		r.writeText("•")
+	case "lquote", "rquote":
+		r.writeText("'")
+	case "ldblquote", "rdblquote":
+		r.writeText("\"")
+	case "uc":
+		if hasParam && param >= 0 {
+			r.uc = param
+		}
+	case "u":
+		if !hasParam {
+			return
+		}
+		if param < 0 {
+			param += 65536
+		}
+		r.writeRune(rune(param))
+		r.skipChars = r.uc
+	case "bin":
+		// Raw binary data follows
+		if hasParam && param > 0 {
+			r.pos += param
+		}
+	}
+}
+
+func (r *rtfRenderer) writeRune(ch rune) {
+	if r.skip {
+		return
+	}
+	if utf16.IsSurrogate(ch) {
+		if r.pendingHi == 0 {
+			r.pendingHi = ch
+			return
+		}
+		ch = utf16.DecodeRune(r.pendingHi, ch)
+	}
+	r.pendingHi = 0
+	r.text.WriteRune(ch)
+}
+
+func (r *rtfRenderer) writeByte(c byte) {
+	if r.skipChars > 0 {
+		r.skipChars--
+		return
+	}
+	if !r.skip {
+		r.text.WriteRune(r.codePage.DecodeByte(c))
+	}
+}
+
+func (r *rtfRenderer) writeText(s string) {
+	if !r.skip {
+		r.text.WriteString(s)
+	}
+}
+
+// endParagraph emits the collected paragraph with the properties set for it
+func (r *rtfRenderer) endParagraph() {
+	// Paragraph marks of skipped destinations (footnotes, headers) do not end body paragraphs
+	if r.skip {
+		return
+	}
+	// Table rows end with a \cell separator
+	text := strings.TrimSuffix(strings.TrimSpace(r.text.String()), "|")
+	r.text.Reset()
+
+	switch {
+	case r.outline > 0:
+		r.out.heading(r.outline, text)
+	case r.list:
+		r.out.listItem(text)
+	default:
+		r.out.paragraph(text)
+	}
+}
+
+func isASCIILetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
@@ -0,0 +1,43 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRTFParser_Parse(t *testing.T) {
+	parser := NewRTFParser()
+
+	t.Run("should extract headings, lists and Cyrillic text", func(t *testing.T) {
+		rtf := `{\rtf1\ansi\ansicpg1251\deff0` +
+			`{\fonttbl{\f0\fswiss Arial;}}{\colortbl;\red0\green0\blue0;}` +
+			`{\*\generator Riched20 10.0;}{\info{\title Lecture}}` +
+			`{\header \pard Page header\par}` +
+			"\r\n" + `\pard\outlinelevel0\b \'d0\'e5\'ea\'f3\'f0\'f1\'e8\'ff\b0\par` +
+			`\pard Function calls itself{\footnote \pard footnote text\par}.\par` +
+			`{\listtext\'b7\tab}\pard\ls1\ilvl0 Base case\par` +
+			`{\listtext\'b7\tab}\pard\ls1\ilvl0 Recursive step\par` +
+			`\pard Unicode: \u1055?\u1088?\u1080?\u1084?\u1077?\u1088?\~\{x\}\par` +
+			`\trowd\intbl n\cell n!\cell\row` +
+			`}`
+
+		result, err := parser.Parse(strings.NewReader(rtf))
+
+		require.NoError(t, err)
+		assert.Equal(t, "# Рекурсия\n\n"+
+			"Function calls itself.\n\n"+
+			"- Base case\n- Recursive step\n\n"+
+			"Unicode: Пример {x}\n\n"+
+			"n | n!",
+			result)
+	})
+
+	t.Run("should reject content without RTF header", func(t *testing.T) {
+		_, err := parser.Parse(strings.NewReader("plain text"))
+
+		assert.ErrorIs(t, err, ErrInvalidRTF)
+	})
+}
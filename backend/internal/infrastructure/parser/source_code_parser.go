@@ -0,0 +1,200 @@
+package parser
+
+import (
+	"io"
+	"strings"
+)
+
+// commentSyntax describes how a language marks comments
+type commentSyntax struct {
+	line       string   // Line comment marker
+	blockStart string   // Block comment start, empty when the language has none
+	blockEnd   string   // Block comment end
+	docStrings []string // Statement-level string literals used as documentation
+}
+
+var (
+	cStyleComments = commentSyntax{line: "//", blockStart: "/*", blockEnd: "*/"}
+	pythonComments = commentSyntax{line: "#", docStrings: []string{`"""`, `'''`}}
+)
+
+// SourceCodeParser implements DocumentParser for program source files.
+// Comments become prose paragraphs and code stays in fenced blocks, so the
+// generation prompt can quote the code in full.
+type SourceCodeParser struct {
+	fileType string
+	language string // Fence info string, e.g. "python"
+	comments commentSyntax
+}
+
+// NewGoParser creates a parser for Go source files
+func NewGoParser() *SourceCodeParser {
+	return &SourceCodeParser{fileType: "go", language: "go", comments: cStyleComments}
+}
+
+// NewPythonParser creates a parser for Python source files
+func NewPythonParser() *SourceCodeParser {
+	return &SourceCodeParser{fileType: "py", language: "python", comments: pythonComments}
+}
+
+// NewJavaParser creates a parser for Java source files
+func NewJavaParser() *SourceCodeParser {
+	return &SourceCodeParser{fileType: "java", language: "java", comments: cStyleComments}
+}
+
+// Parse extracts code and comments from a source file
+func (p *SourceCodeParser) Parse(reader io.Reader) (string, error) {
+	result, err := p.ParseWithMetadata(reader)
+	if err != nil {
+		return "", err
+	}
+	return result.Text, nil
+}
+
+// ParseWithMetadata extracts code and comments from a source file, transcoding it to UTF-8
+func (p *SourceCodeParser) ParseWithMetadata(reader io.Reader) (*ParseResult, error) {
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	text, encoding := DecodeText(content)
+	return &ParseResult{Text: p.render(text), Encoding: encoding}, nil
+}
+
+// SupportedType returns the file type this parser supports
+func (p *SourceCodeParser) SupportedType() string {
+	return p.fileType
+}
+
+// render splits source into alternating prose and code blocks. Only top-level
+// comments (file and declaration docs) become prose; indented comments and
+// trailing comments stay with the code so functions are never cut in half.
+func (p *SourceCodeParser) render(text string) string {
+	var (
+		blocks []string
+		prose  []string
+		code   []string
+	)
+
+	flushProse := func() {
+		var paragraphs []string
+		for _, paragraph := range strings.Split(strings.Join(prose, "\n"), "\n\n") {
+			paragraph = strings.TrimSpace(paragraph)
+			if paragraph == "" || (len(blocks) == 0 && len(paragraphs) == 0 && isLicenseHeader(paragraph)) {
+				continue
+			}
+			paragraphs = append(paragraphs, paragraph)
+		}
+		if len(paragraphs) > 0 {
+			blocks = append(blocks, strings.Join(paragraphs, "\n\n"))
+		}
+		prose = nil
+	}
+	flushCode := func() {
+		if snippet := strings.Trim(strings.Join(code, "\n"), "\n"); strings.TrimSpace(snippet) != "" {
+			blocks = append(blocks, fenceCode(p.language, snippet))
+		}
+		code = nil
+	}
+
+	lines := strings.Split(text, "\n")
+	for i := 0; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+
+		topLevel := trimmed != "" && !strings.HasPrefix(lines[i], " ") && !strings.HasPrefix(lines[i], "\t")
+		if comment, end, ok := p.commentAt(lines, i); topLevel && ok {
+			flushCode()
+			prose = append(prose, comment...)
+			i = end
+			continue
+		}
+
+		if trimmed == "" {
+			// Blank lines separate comment paragraphs and stay inside code
+			if len(code) > 0 {
+				code = append(code, "")
+			} else if len(prose) > 0 {
+				prose = append(prose, "")
+			}
+			continue
+		}
+
+		flushProse()
+		code = append(code, lines[i])
+	}
+	flushProse()
+	flushCode()
+
+	return strings.Join(blocks, "\n\n")
+}
+
+// commentAt reports whether a comment-only construct starts at line i and
+// returns its text with markers removed and the index of its last line
+func (p *SourceCodeParser) commentAt(lines []string, i int) ([]string, int, bool) {
+	trimmed := strings.TrimSpace(lines[i])
+	syntax := p.comments
+
+	if strings.HasPrefix(trimmed, syntax.line) {
+		if isDirectiveComment(trimmed, syntax.line) {
+			return nil, i, true
+		}
+		return []string{strings.TrimSpace(strings.TrimPrefix(trimmed, syntax.line))}, i, true
+	}
+
+	if syntax.blockStart != "" && strings.HasPrefix(trimmed, syntax.blockStart) {
+		return blockComment(lines, i, syntax.blockStart, syntax.blockEnd)
+	}
+
+	for _, quote := range syntax.docStrings {
+		if strings.HasPrefix(trimmed, quote) {
+			return blockComment(lines, i, quote, quote)
+		}
+	}
+	return nil, i, false
+}
+
+// blockComment collects a block comment or docstring that starts at line i
+func blockComment(lines []string, i int, start, end string) ([]string, int, bool) {
+	body := strings.TrimPrefix(strings.TrimSpace(lines[i]), start)
+	var text []string
+	for j := i; j < len(lines); j++ {
+		if j > i {
+			body = strings.TrimSpace(lines[j])
+		}
+		closed := false
+		if idx := strings.Index(body, end); idx >= 0 {
+			body = body[:idx]
+			closed = true
+		}
+		// Javadoc-style leading asterisks
+		body = strings.TrimSpace(strings.TrimPrefix(strings.TrimPrefix(body, "*"), "*"))
+		text = append(text, body)
+		if closed {
+			return text, j, true
+		}
+	}
+	return text, len(lines) - 1, true
+}
+
+// directivePrefixes start comments that instruct tools instead of explaining code
+var directivePrefixes = []string{"go:", "nolint", "line ", "!", " -*-", " type:", " noqa", " pylint:"}
+
+// isDirectiveComment reports compiler and tool directives (//go:build, //nolint,
+// #!/usr/bin/env, # -*- coding) that carry no explanation
+func isDirectiveComment(trimmed, marker string) bool {
+	rest := strings.TrimPrefix(trimmed, marker)
+	for _, prefix := range directivePrefixes {
+		if strings.HasPrefix(rest, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// isLicenseHeader reports a copyright notice; only the top of a file is checked
+func isLicenseHeader(paragraph string) bool {
+	lower := strings.ToLower(paragraph)
+	return strings.Contains(lower, "copyright") || strings.Contains(lower, "spdx-license-identifier") ||
+		strings.Contains(lower, "licensed under")
+}
@@ -0,0 +1,83 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSourceCodeParser_Go(t *testing.T) {
+	source := `// Copyright 2024 The Course Authors. All rights reserved.
+
+//go:build linux
+
+// Package stack implements a LIFO stack.
+//
+// Push and Pop run in constant time.
+package stack
+
+// Stack holds integers.
+type Stack struct {
+	items []int // backing slice
+}
+
+// Pop removes the top element.
+func (s *Stack) Pop() int {
+	// Take the last element
+	top := s.items[len(s.items)-1]
+
+	s.items = s.items[:len(s.items)-1]
+	return top
+}
+`
+	result, err := NewGoParser().Parse(strings.NewReader(source))
+
+	require.NoError(t, err)
+	assert.Equal(t, "Package stack implements a LIFO stack.\n\nPush and Pop run in constant time.\n\n"+
+		"```go\npackage stack\n```\n\n"+
+		"Stack holds integers.\n\n"+
+		"```go\ntype Stack struct {\n\titems []int // backing slice\n}\n```\n\n"+
+		"Pop removes the top element.\n\n"+
+		"```go\nfunc (s *Stack) Pop() int {\n\t// Take the last element\n\ttop := s.items[len(s.items)-1]\n\n\ts.items = s.items[:len(s.items)-1]\n\treturn top\n}\n```",
+		result)
+}
+
+func TestSourceCodeParser_Java(t *testing.T) {
+	source := `/**
+ * Demonstrates method overriding.
+ * @author lecturer
+ */
+class Child extends Parent {
+    /* called instead of Parent.foo */
+    void foo() {}
+}
+`
+	result, err := NewJavaParser().Parse(strings.NewReader(source))
+
+	require.NoError(t, err)
+	assert.Equal(t, "Demonstrates method overriding.\n@author lecturer\n\n"+
+		"```java\nclass Child extends Parent {\n    /* called instead of Parent.foo */\n    void foo() {}\n}\n```",
+		result)
+}
+
+func TestSourceCodeParser_Python(t *testing.T) {
+	source := "#!/usr/bin/env python3\n" +
+		"# -*- coding: utf-8 -*-\n" +
+		"\"\"\"Binary search over a sorted list.\"\"\"\n" +
+		"\n" +
+		"# Returns the index or -1\n" +
+		"def search(items, x):\n" +
+		"    \"\"\"Classic iterative version.\"\"\"\n" +
+		"    lo, hi = 0, len(items) - 1  # inclusive bounds\n" +
+		"    return -1\n"
+
+	result, err := NewPythonParser().ParseWithMetadata(strings.NewReader(source))
+
+	require.NoError(t, err)
+	assert.Equal(t, "Binary search over a sorted list.\n\nReturns the index or -1\n\n"+
+		"```python\ndef search(items, x):\n    \"\"\"Classic iterative version.\"\"\"\n    lo, hi = 0, len(items) - 1  # inclusive bounds\n    return -1\n```",
+		result.Text)
+	assert.Equal(t, EncodingUTF8, result.Encoding)
+}
@@ -0,0 +1,95 @@
+package parser
+
+import (
+	"strings"
+)
+
+// blockKind is the kind of a block of structured text
+type blockKind int
+
+const (
+	blockParagraph blockKind = iota
+	blockHeading
+	blockListItem
+	blockTableRow
+	blockCode
+)
+
+// structuredText renders document structure as lightweight Markdown, the same
+// shape the Markdown and notebook parsers produce: "#" headings, "- " list
+// items, "a | b" table rows and fenced code, with blocks separated by blank lines.
+// Consecutive list items and table rows stay together in one block.
+type structuredText struct {
+	blocks []string
+	last   blockKind
+}
+
+// heading adds a heading; levels outside 1-6 are clamped
+func (s *structuredText) heading(level int, text string) {
+	if level < 1 {
+		level = 1
+	}
+	if level > 6 {
+		level = 6
+	}
+	s.add(blockHeading, strings.Repeat("#", level)+" ", text)
+}
+
+func (s *structuredText) paragraph(text string) {
+	s.add(blockParagraph, "", text)
+}
+
+func (s *structuredText) listItem(text string) {
+	s.add(blockListItem, "- ", text)
+}
+
+func (s *structuredText) tableRow(cells []string) {
+	var kept []string
+	for _, cell := range cells {
+		if cell = collapseSpaces(cell); cell != "" {
+			kept = append(kept, cell)
+		}
+	}
+	s.add(blockTableRow, "", strings.Join(kept, " | "))
+}
+
+// code adds preformatted text verbatim
+func (s *structuredText) code(text string) {
+	text = strings.Trim(text, "\n")
+	if strings.TrimSpace(text) == "" {
+		return
+	}
+	s.blocks = append(s.blocks, fenceCode("", text))
+	s.last = blockCode
+}
+
+func (s *structuredText) add(kind blockKind, prefix, text string) {
+	text = collapseSpaces(text)
+	if text == "" {
+		return
+	}
+
+	line := prefix + text
+	if len(s.blocks) > 0 && kind == s.last && (kind == blockListItem || kind == blockTableRow) {
+		s.blocks[len(s.blocks)-1] += "\n" + line
+		return
+	}
+	s.blocks = append(s.blocks, line)
+	s.last = kind
+}
+
+func (s *structuredText) String() string {
+	return strings.Join(s.blocks, "\n\n")
+}
+
+// collapseSpaces collapses runs of whitespace within lines and drops empty lines,
+// keeping explicit line breaks
+func collapseSpaces(text string) string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
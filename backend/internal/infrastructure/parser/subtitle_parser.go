@@ -0,0 +1,284 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNoSubtitleCues is returned when a subtitle file contains no timed cues
+var ErrNoSubtitleCues = errors.New("no subtitle cues found")
+
+const (
+	// subtitlePauseGap is a pause in speech long enough to start a new paragraph
+	subtitlePauseGap = 2 * time.Second
+
+	// subtitleParagraphSpan is how long a paragraph runs before it is closed at the next sentence end
+	subtitleParagraphSpan = 45 * time.Second
+
+	// subtitleMaxParagraphSpan closes a paragraph even mid-sentence, so timestamps stay useful
+	subtitleMaxParagraphSpan = 2 * time.Minute
+
+	// subtitleRecentLines is how many emitted lines are remembered to drop repeated rolling captions
+	subtitleRecentLines = 3
+)
+
+var (
+	// subtitleTagPattern matches HTML-like markup (<i>, <font>, <c.class>, <v Speaker>, <00:01.000>)
+	subtitleTagPattern = regexp.MustCompile(`<[^>]*>`)
+
+	// subtitleASSTagPattern matches SSA/ASS override codes some SRT files carry ({\an8}, {\i1})
+	subtitleASSTagPattern = regexp.MustCompile(`\{\\[^}]*\}`)
+)
+
+// subtitleCue is one timed block of a subtitle file
+type subtitleCue struct {
+	start time.Duration
+	end   time.Duration
+	lines []string
+}
+
+// SRTParser implements DocumentParser for SubRip subtitles
+type SRTParser struct{}
+
+// NewSRTParser creates a new SubRip subtitle parser
+func NewSRTParser() *SRTParser {
+	return &SRTParser{}
+}
+
+// Parse extracts the spoken text of an SRT file
+func (p *SRTParser) Parse(reader io.Reader) (string, error) {
+	result, err := p.ParseWithMetadata(reader)
+	if err != nil {
+		return "", err
+	}
+	return result.Text, nil
+}
+
+// ParseWithMetadata extracts the spoken text of an SRT file as timestamped paragraphs
+func (p *SRTParser) ParseWithMetadata(reader io.Reader) (*ParseResult, error) {
+	return parseSubtitles(reader)
+}
+
+// SupportedType returns the file type this parser supports
+func (p *SRTParser) SupportedType() string {
+	return "srt"
+}
+
+// VTTParser implements DocumentParser for WebVTT subtitles
+type VTTParser struct{}
+
+// NewVTTParser creates a new WebVTT subtitle parser
+func NewVTTParser() *VTTParser {
+	return &VTTParser{}
+}
+
+// Parse extracts the spoken text of a WebVTT file
+func (p *VTTParser) Parse(reader io.Reader) (string, error) {
+	result, err := p.ParseWithMetadata(reader)
+	if err != nil {
+		return "", err
+	}
+	return result.Text, nil
+}
+
+// ParseWithMetadata extracts the spoken text of a WebVTT file as timestamped paragraphs
+func (p *VTTParser) ParseWithMetadata(reader io.Reader) (*ParseResult, error) {
+	return parseSubtitles(reader)
+}
+
+// SupportedType returns the file type this parser supports
+func (p *VTTParser) SupportedType() string {
+	return "vtt"
+}
+
+// parseSubtitles handles both formats: they differ only in the header, metadata
+// blocks and the millisecond separator, none of which survive into the text.
+// Every paragraph starts with the time it is spoken in the recording, e.g. "[12:34]".
+func parseSubtitles(reader io.Reader) (*ParseResult, error) {
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	text, encoding := DecodeText(content)
+	cues := parseSubtitleCues(text)
+	if len(cues) == 0 {
+		return nil, ErrNoSubtitleCues
+	}
+
+	return &ParseResult{Text: mergeSubtitleCues(cues), Encoding: encoding}, nil
+}
+
+// parseSubtitleCues splits subtitle text into cues. Blocks without a timing line
+// (the WEBVTT header, NOTE, STYLE and REGION blocks) are ignored, as are cue
+// numbers and identifiers in front of the timing line.
+func parseSubtitleCues(text string) []subtitleCue {
+	var cues []subtitleCue
+	for _, block := range strings.Split(text, "\n\n") {
+		lines := strings.Split(strings.Trim(block, "\n"), "\n")
+
+		timing := -1
+		for i, line := range lines {
+			if strings.Contains(line, "-->") {
+				timing = i
+				break
+			}
+		}
+		if timing < 0 {
+			continue
+		}
+
+		start, end, ok := parseCueTiming(lines[timing])
+		if !ok {
+			continue
+		}
+
+		cue := subtitleCue{start: start, end: end}
+		for _, line := range lines[timing+1:] {
+			if line = cleanSubtitleLine(line); line != "" {
+				cue.lines = append(cue.lines, line)
+			}
+		}
+		if len(cue.lines) > 0 {
+			cues = append(cues, cue)
+		}
+	}
+	return cues
+}
+
+// parseCueTiming reads "00:01:02,500 --> 00:01:04,000" (SRT) or
+// "01:02.500 --> 01:04.000 align:start" (WebVTT, with optional cue settings)
+func parseCueTiming(line string) (time.Duration, time.Duration, bool) {
+	startText, rest, _ := strings.Cut(line, "-->")
+	endFields := strings.Fields(rest)
+	if len(endFields) == 0 {
+		return 0, 0, false
+	}
+
+	start, err := parseCueTime(strings.TrimSpace(startText))
+	if err != nil {
+		return 0, 0, false
+	}
+	end, err := parseCueTime(endFields[0])
+	if err != nil {
+		return 0, 0, false
+	}
+	return start, end, true
+}
+
+// parseCueTime parses "hh:mm:ss,mmm", "hh:mm:ss.mmm" or "mm:ss.mmm"
+func parseCueTime(value string) (time.Duration, error) {
+	value = strings.Replace(value, ",", ".", 1)
+	parts := strings.Split(value, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, fmt.Errorf("invalid cue time: %s", value)
+	}
+
+	seconds, err := strconv.ParseFloat(parts[len(parts)-1], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid cue time: %s", value)
+	}
+	total := time.Duration(seconds * float64(time.Second))
+
+	units := []time.Duration{time.Minute, time.Hour}
+	for i, part := range parts[:len(parts)-1] {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid cue time: %s", value)
+		}
+		total += time.Duration(n) * units[len(parts)-2-i]
+	}
+	return total, nil
+}
+
+// cleanSubtitleLine strips markup and entities and collapses whitespace
+func cleanSubtitleLine(line string) string {
+	line = subtitleTagPattern.ReplaceAllString(line, "")
+	line = subtitleASSTagPattern.ReplaceAllString(line, "")
+	line = html.UnescapeString(line)
+	// Speaker dashes of dialogue cues ("- Hello") are not part of the speech
+	line = strings.TrimLeft(strings.TrimSpace(line), "-– ")
+	return strings.Join(strings.Fields(line), " ")
+}
+
+// mergeSubtitleCues joins cues into paragraphs, starting a new one after a pause
+// in speech or once a paragraph grows long and a sentence ends
+func mergeSubtitleCues(cues []subtitleCue) string {
+	var (
+		paragraphs []string
+		current    []string
+		start      time.Duration
+		lastEnd    time.Duration
+		recent     []string
+	)
+
+	flush := func() {
+		if len(current) > 0 {
+			paragraphs = append(paragraphs, formatCueTimestamp(start)+" "+strings.Join(current, " "))
+			current = nil
+		}
+	}
+
+	for _, cue := range cues {
+		if len(current) > 0 {
+			span := cue.start - start
+			last := current[len(current)-1]
+			if cue.start-lastEnd >= subtitlePauseGap ||
+				span >= subtitleMaxParagraphSpan ||
+				(span >= subtitleParagraphSpan && endsSentence(last)) {
+				flush()
+			}
+		}
+
+		for _, line := range cue.lines {
+			// Auto-generated captions repeat the previous line while scrolling
+			if containsString(recent, line) {
+				continue
+			}
+			if len(current) == 0 {
+				start = cue.start
+			}
+			current = append(current, line)
+			recent = append(recent, line)
+			if len(recent) > subtitleRecentLines {
+				recent = recent[1:]
+			}
+		}
+		if cue.end > lastEnd {
+			lastEnd = cue.end
+		}
+	}
+	flush()
+
+	return strings.Join(paragraphs, "\n\n")
+}
+
+// formatCueTimestamp renders a position in the recording as "[12:34]" or "[1:02:03]"
+func formatCueTimestamp(d time.Duration) string {
+	total := int(d / time.Second)
+	hours, minutes, seconds := total/3600, total%3600/60, total%60
+	if hours > 0 {
+		return fmt.Sprintf("[%d:%02d:%02d]", hours, minutes, seconds)
+	}
+	return fmt.Sprintf("[%02d:%02d]", minutes, seconds)
+}
+
+func endsSentence(text string) bool {
+	return strings.HasSuffix(text, ".") || strings.HasSuffix(text, "!") ||
+		strings.HasSuffix(text, "?") || strings.HasSuffix(text, "…")
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,135 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding/charmap"
+)
+
+func TestSRTParser_Parse(t *testing.T) {
+	parser := NewSRTParser()
+
+	t.Run("should strip numbering and markup and merge cues into paragraphs", func(t *testing.T) {
+		srt := "1\r\n00:00:01,000 --> 00:00:03,500\r\n<i>Welcome to the lecture</i>\r\n\r\n" +
+			"2\r\n00:00:03,600 --> 00:00:06,000\r\n{\\an8}on <b>sorting</b> algorithms.\r\n\r\n" +
+			"3\r\n00:12:34,200 --> 00:12:38,000\r\n- Quicksort picks a pivot\r\n- and partitions &amp; recurses.\r\n"
+
+		result, err := parser.Parse(strings.NewReader(srt))
+
+		require.NoError(t, err)
+		assert.Equal(t,
+			"[00:01] Welcome to the lecture on sorting algorithms.\n\n"+
+				"[12:34] Quicksort picks a pivot and partitions & recurses.",
+			result)
+	})
+
+	t.Run("should decode legacy Cyrillic subtitles", func(t *testing.T) {
+		srt, err := charmap.Windows1251.NewEncoder().String("1\n00:00:05,000 --> 00:00:07,000\nСегодня мы поговорим о графах\n")
+		require.NoError(t, err)
+
+		result, err := parser.ParseWithMetadata(strings.NewReader(srt))
+
+		require.NoError(t, err)
+		assert.Equal(t, "[00:05] Сегодня мы поговорим о графах", result.Text)
+		assert.Equal(t, EncodingCP1251, result.Encoding)
+	})
+
+	t.Run("should fail when there are no cues", func(t *testing.T) {
+		_, err := parser.Parse(strings.NewReader("just some text\nwithout timings"))
+
+		assert.ErrorIs(t, err, ErrNoSubtitleCues)
+	})
+}
+
+func TestVTTParser_Parse(t *testing.T) {
+	parser := NewVTTParser()
+
+	t.Run("should skip header and metadata blocks", func(t *testing.T) {
+		vtt := "WEBVTT - Lecture 3\n\n" +
+			"NOTE recorded in room 101\n\n" +
+			"STYLE\n::cue { color: yellow }\n\n" +
+			"intro\n01:02:03.000 --> 01:02:05.000 align:start position:10%\n<v Lecturer>Graphs consist of <c.term>vertices</c></v>\n\n" +
+			"01:02:05.100 --> 01:02:07.000\nand edges.\n"
+
+		result, err := parser.Parse(strings.NewReader(vtt))
+
+		require.NoError(t, err)
+		assert.Equal(t, "[1:02:03] Graphs consist of vertices and edges.", result)
+	})
+
+	t.Run("should drop lines repeated by rolling captions", func(t *testing.T) {
+		vtt := "WEBVTT\n\n" +
+			"00:10.000 --> 00:12.000\nthe stack is a\n\n" +
+			"00:12.000 --> 00:14.000\nthe stack is a\nLIFO structure\n\n" +
+			"00:14.000 --> 00:16.000\nLIFO structure\nwith push and pop\n"
+
+		result, err := parser.Parse(strings.NewReader(vtt))
+
+		require.NoError(t, err)
+		assert.Equal(t, "[00:10] the stack is a LIFO structure with push and pop", result)
+	})
+}
+
+func TestMergeSubtitleCues_ParagraphBreaks(t *testing.T) {
+	cue := func(start time.Duration, text string) subtitleCue {
+		return subtitleCue{start: start, end: start + 4*time.Second, lines: []string{text}}
+	}
+
+	t.Run("long paragraph is closed at a sentence end", func(t *testing.T) {
+		var cues []subtitleCue
+		for i := 0; i < 12; i++ {
+			cues = append(cues, cue(time.Duration(i)*4*time.Second, fmt.Sprintf("part %d", i)))
+		}
+		cues = append(cues, cue(48*time.Second, "the end."), cue(52*time.Second, "Next topic"))
+
+		text := mergeSubtitleCues(cues)
+
+		paragraphs := strings.Split(text, "\n\n")
+		require.Len(t, paragraphs, 2)
+		assert.True(t, strings.HasSuffix(paragraphs[0], "the end."))
+		assert.Equal(t, "[00:52] Next topic", paragraphs[1])
+	})
+
+	t.Run("very long paragraph is closed mid sentence", func(t *testing.T) {
+		var cues []subtitleCue
+		for i := 0; i <= 31; i++ {
+			cues = append(cues, cue(time.Duration(i)*4*time.Second, fmt.Sprintf("word %d", i)))
+		}
+
+		paragraphs := strings.Split(mergeSubtitleCues(cues), "\n\n")
+
+		require.Len(t, paragraphs, 2)
+		assert.True(t, strings.HasPrefix(paragraphs[1], "[02:00] "))
+	})
+}
+
+func TestParseCueTime(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected time.Duration
+		wantErr  bool
+	}{
+		{"00:00:01,500", 1500 * time.Millisecond, false},
+		{"01:02:03.250", time.Hour + 2*time.Minute + 3250*time.Millisecond, false},
+		{"12:34.000", 12*time.Minute + 34*time.Second, false},
+		{"1.5", 0, true},
+		{"aa:00.000", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseCueTime(tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
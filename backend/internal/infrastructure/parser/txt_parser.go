@@ -14,11 +14,22 @@ func NewTXTParser() *TXTParser {
 
 // Parse extracts text from TXT file
 func (p *TXTParser) Parse(reader io.Reader) (string, error) {
-	content, err := io.ReadAll(reader)
+	result, err := p.ParseWithMetadata(reader)
 	if err != nil {
 		return "", err
 	}
-	return string(content), nil
+	return result.Text, nil
+}
+
+// ParseWithMetadata extracts text from TXT file, transcoding it to UTF-8
+func (p *TXTParser) ParseWithMetadata(reader io.Reader) (*ParseResult, error) {
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	text, encoding := DecodeText(content)
+	return &ParseResult{Text: text, Encoding: encoding}, nil
 }
 
 // SupportedType returns the file type this parser supports
@@ -0,0 +1,4 @@
+-- Remove parse queue index and progress columns
+DROP INDEX IF EXISTS idx_documents_status_updated_at;
+ALTER TABLE documents DROP COLUMN IF EXISTS parse_attempts;
+ALTER TABLE documents DROP COLUMN IF EXISTS parse_progress;
@@ -0,0 +1,6 @@
+-- Track background parsing progress and retry attempts
+ALTER TABLE documents ADD COLUMN parse_progress INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE documents ADD COLUMN parse_attempts INTEGER NOT NULL DEFAULT 0;
+
+-- The parse queue polls for uploaded documents and recovers stale parsing rows
+CREATE INDEX IF NOT EXISTS idx_documents_status_updated_at ON documents(status, updated_at) WHERE deleted_at IS NULL;
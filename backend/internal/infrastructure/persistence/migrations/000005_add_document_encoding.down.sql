@@ -0,0 +1,2 @@
+-- Remove encoding column from documents table
+ALTER TABLE documents DROP COLUMN IF EXISTS encoding;
@@ -0,0 +1,2 @@
+-- Record the detected source charset of TXT/MD uploads (utf-8, windows-1251, koi8-r, ...)
+ALTER TABLE documents ADD COLUMN encoding VARCHAR(32);
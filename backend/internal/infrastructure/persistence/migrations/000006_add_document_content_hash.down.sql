@@ -0,0 +1,4 @@
+-- Remove content hash column and deduplication indexes
+DROP INDEX IF EXISTS idx_documents_file_path;
+DROP INDEX IF EXISTS idx_documents_content_hash;
+ALTER TABLE documents DROP COLUMN IF EXISTS content_hash;
@@ -0,0 +1,5 @@
+-- SHA-256 of uploaded file content, used to deduplicate uploads
+ALTER TABLE documents ADD COLUMN content_hash VARCHAR(64);
+
+CREATE INDEX IF NOT EXISTS idx_documents_content_hash ON documents(content_hash) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_documents_file_path ON documents(file_path) WHERE deleted_at IS NULL;
@@ -0,0 +1,4 @@
+-- Remove corrected text history
+DROP TABLE IF EXISTS document_text_revisions;
+ALTER TABLE documents DROP COLUMN IF EXISTS text_revision;
+ALTER TABLE documents DROP COLUMN IF EXISTS corrected_text;
@@ -0,0 +1,15 @@
+-- Teacher corrections of parsed document text; parsed_text keeps the parser output
+ALTER TABLE documents ADD COLUMN corrected_text TEXT;
+ALTER TABLE documents ADD COLUMN text_revision INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS document_text_revisions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    document_id UUID NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    revision INTEGER NOT NULL,
+    text TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (document_id, revision)
+);
+
+CREATE INDEX IF NOT EXISTS idx_document_text_revisions_document_id ON document_text_revisions(document_id);
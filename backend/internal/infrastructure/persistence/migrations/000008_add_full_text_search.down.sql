@@ -0,0 +1,8 @@
+-- Remove full-text search columns and indexes
+DROP INDEX IF EXISTS idx_questions_search_vector;
+DROP INDEX IF EXISTS idx_tests_search_vector;
+DROP INDEX IF EXISTS idx_documents_search_vector;
+
+ALTER TABLE questions DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE tests DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE documents DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,27 @@
+-- Full-text search over documents, tests and questions.
+-- Each vector combines the russian and english configurations so that queries
+-- match both Russian and English word forms. Titles weigh more than body text.
+-- Document text is truncated because a tsvector is limited to 1MB.
+
+ALTER TABLE documents ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('russian'::regconfig, coalesce(title, '')), 'A') ||
+    setweight(to_tsvector('english'::regconfig, coalesce(title, '')), 'A') ||
+    setweight(to_tsvector('russian'::regconfig, left(coalesce(CASE WHEN text_revision > 0 THEN corrected_text ELSE parsed_text END, ''), 250000)), 'B') ||
+    setweight(to_tsvector('english'::regconfig, left(coalesce(CASE WHEN text_revision > 0 THEN corrected_text ELSE parsed_text END, ''), 250000)), 'B')
+) STORED;
+
+ALTER TABLE tests ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('russian'::regconfig, coalesce(title, '')), 'A') ||
+    setweight(to_tsvector('english'::regconfig, coalesce(title, '')), 'A') ||
+    setweight(to_tsvector('russian'::regconfig, coalesce(description, '')), 'B') ||
+    setweight(to_tsvector('english'::regconfig, coalesce(description, '')), 'B')
+) STORED;
+
+ALTER TABLE questions ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    to_tsvector('russian'::regconfig, coalesce(question_text, '')) ||
+    to_tsvector('english'::regconfig, coalesce(question_text, ''))
+) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_documents_search_vector ON documents USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_tests_search_vector ON tests USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_questions_search_vector ON questions USING GIN (search_vector);
@@ -0,0 +1,7 @@
+-- Remove document text statistics
+ALTER TABLE documents DROP COLUMN IF EXISTS token_estimates;
+ALTER TABLE documents DROP COLUMN IF EXISTS readability_score;
+ALTER TABLE documents DROP COLUMN IF EXISTS language;
+ALTER TABLE documents DROP COLUMN IF EXISTS section_count;
+ALTER TABLE documents DROP COLUMN IF EXISTS char_count;
+ALTER TABLE documents DROP COLUMN IF EXISTS word_count;
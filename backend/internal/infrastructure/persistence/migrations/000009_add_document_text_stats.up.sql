@@ -0,0 +1,8 @@
+-- Statistics of the effective document text (corrected_text when text_revision > 0, else parsed_text)
+ALTER TABLE documents ADD COLUMN word_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE documents ADD COLUMN char_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE documents ADD COLUMN section_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE documents ADD COLUMN language VARCHAR(16);
+ALTER TABLE documents ADD COLUMN readability_score DOUBLE PRECISION NOT NULL DEFAULT 0;
+-- Provider name -> estimated prompt tokens; NULL for documents parsed before statistics existed
+ALTER TABLE documents ADD COLUMN token_estimates JSONB;
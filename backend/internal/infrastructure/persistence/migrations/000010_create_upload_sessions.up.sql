@@ -0,0 +1,23 @@
+-- Resumable chunked uploads; chunks are staged in file storage under staging/<session id>/
+CREATE TABLE IF NOT EXISTS upload_sessions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    file_name VARCHAR(500) NOT NULL,
+    title VARCHAR(500),
+    content_type VARCHAR(255),
+    total_size BIGINT NOT NULL CHECK (total_size > 0),
+    chunk_size BIGINT NOT NULL CHECK (chunk_size > 0),
+    received_size BIGINT NOT NULL DEFAULT 0,
+    checksum VARCHAR(64),
+    status VARCHAR(50) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'completed')),
+    document_id UUID REFERENCES documents(id) ON DELETE SET NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_upload_sessions_user_id ON upload_sessions(user_id);
+CREATE INDEX IF NOT EXISTS idx_upload_sessions_expires_at ON upload_sessions(expires_at);
+
+CREATE TRIGGER update_upload_sessions_updated_at BEFORE UPDATE ON upload_sessions
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,3 @@
+-- Remove file purge tracking
+DROP INDEX IF EXISTS idx_documents_pending_purge;
+ALTER TABLE documents DROP COLUMN IF EXISTS file_purged_at;
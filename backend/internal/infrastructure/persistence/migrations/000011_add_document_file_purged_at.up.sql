@@ -0,0 +1,5 @@
+-- Files of soft-deleted documents are kept for a retention period, then removed by the storage janitor
+ALTER TABLE documents ADD COLUMN file_purged_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_documents_pending_purge ON documents(deleted_at)
+    WHERE deleted_at IS NOT NULL AND file_purged_at IS NULL;
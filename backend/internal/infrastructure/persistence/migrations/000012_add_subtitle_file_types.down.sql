@@ -0,0 +1,6 @@
+-- Revert file_type constraint to values without subtitle formats; subtitle documents are removed
+DELETE FROM documents WHERE file_type IN ('srt', 'vtt');
+
+ALTER TABLE documents DROP CONSTRAINT IF EXISTS documents_file_type_check;
+ALTER TABLE documents ADD CONSTRAINT documents_file_type_check
+    CHECK (file_type IN ('pdf', 'docx', 'pptx', 'txt', 'md'));
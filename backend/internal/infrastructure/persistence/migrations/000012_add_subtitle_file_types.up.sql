@@ -0,0 +1,4 @@
+-- Allow subtitle files of recorded lectures (SubRip and WebVTT)
+ALTER TABLE documents DROP CONSTRAINT IF EXISTS documents_file_type_check;
+ALTER TABLE documents ADD CONSTRAINT documents_file_type_check
+    CHECK (file_type IN ('pdf', 'docx', 'pptx', 'txt', 'md', 'srt', 'vtt'));
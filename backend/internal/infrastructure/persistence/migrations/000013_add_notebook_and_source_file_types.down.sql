@@ -0,0 +1,6 @@
+-- Revert file_type constraint to values without notebooks and source files; such documents are removed
+DELETE FROM documents WHERE file_type IN ('ipynb', 'go', 'py', 'java');
+
+ALTER TABLE documents DROP CONSTRAINT IF EXISTS documents_file_type_check;
+ALTER TABLE documents ADD CONSTRAINT documents_file_type_check
+    CHECK (file_type IN ('pdf', 'docx', 'pptx', 'txt', 'md', 'srt', 'vtt'));
@@ -0,0 +1,4 @@
+-- Allow Jupyter notebooks and program source files of programming courses
+ALTER TABLE documents DROP CONSTRAINT IF EXISTS documents_file_type_check;
+ALTER TABLE documents ADD CONSTRAINT documents_file_type_check
+    CHECK (file_type IN ('pdf', 'docx', 'pptx', 'txt', 'md', 'srt', 'vtt', 'ipynb', 'go', 'py', 'java'));
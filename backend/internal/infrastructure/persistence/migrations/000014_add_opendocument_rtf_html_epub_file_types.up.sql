@@ -0,0 +1,5 @@
+-- Allow OpenDocument text and presentations, RTF, saved web pages and e-books
+ALTER TABLE documents DROP CONSTRAINT IF EXISTS documents_file_type_check;
+ALTER TABLE documents ADD CONSTRAINT documents_file_type_check
+    CHECK (file_type IN ('pdf', 'docx', 'pptx', 'txt', 'md', 'srt', 'vtt', 'ipynb', 'go', 'py', 'java',
+                         'odt', 'odp', 'rtf', 'html', 'epub'));
@@ -0,0 +1,7 @@
+-- Remove the question bank; questions already copied into tests are kept
+DROP TABLE IF EXISTS test_bank_questions;
+DROP TABLE IF EXISTS bank_question_tags;
+DROP TABLE IF EXISTS question_tags;
+DROP TABLE IF EXISTS bank_answers;
+DROP TABLE IF EXISTS bank_questions;
+DROP TABLE IF EXISTS question_categories;
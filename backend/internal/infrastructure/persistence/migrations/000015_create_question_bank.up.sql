@@ -0,0 +1,75 @@
+-- Reusable question bank: questions owned by a teacher, organized in nested
+-- categories and tags. Adding a bank question to a test copies it into the
+-- test; test_bank_questions links the bank question to every such copy.
+CREATE TABLE IF NOT EXISTS question_categories (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    parent_id UUID REFERENCES question_categories(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK (parent_id IS NULL OR parent_id <> id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_question_categories_user_id ON question_categories(user_id);
+CREATE INDEX IF NOT EXISTS idx_question_categories_parent_id ON question_categories(parent_id);
+
+CREATE TABLE IF NOT EXISTS bank_questions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    category_id UUID REFERENCES question_categories(id) ON DELETE SET NULL,
+    question_text TEXT NOT NULL,
+    question_type VARCHAR(50) DEFAULT 'single_choice' CHECK (
+        question_type IN ('single_choice', 'multiple_choice', 'true_false', 'short_answer')
+    ),
+    difficulty VARCHAR(50) DEFAULT 'medium' CHECK (difficulty IN ('easy', 'medium', 'hard')),
+    points DECIMAL(5,2) DEFAULT 1.0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_bank_questions_user_id ON bank_questions(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_bank_questions_category_id ON bank_questions(category_id);
+
+CREATE TABLE IF NOT EXISTS bank_answers (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    bank_question_id UUID NOT NULL REFERENCES bank_questions(id) ON DELETE CASCADE,
+    answer_text TEXT NOT NULL,
+    is_correct BOOLEAN DEFAULT FALSE,
+    order_num INTEGER NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_bank_answers_bank_question_id ON bank_answers(bank_question_id);
+
+CREATE TABLE IF NOT EXISTS question_tags (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS bank_question_tags (
+    bank_question_id UUID NOT NULL REFERENCES bank_questions(id) ON DELETE CASCADE,
+    question_tag_id UUID NOT NULL REFERENCES question_tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (bank_question_id, question_tag_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_bank_question_tags_tag_id ON bank_question_tags(question_tag_id);
+
+CREATE TABLE IF NOT EXISTS test_bank_questions (
+    test_id UUID NOT NULL REFERENCES tests(id) ON DELETE CASCADE,
+    bank_question_id UUID NOT NULL REFERENCES bank_questions(id) ON DELETE CASCADE,
+    question_id UUID NOT NULL REFERENCES questions(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (test_id, bank_question_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_test_bank_questions_bank_question_id ON test_bank_questions(bank_question_id);
+
+CREATE TRIGGER update_question_categories_updated_at BEFORE UPDATE ON question_categories
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_bank_questions_updated_at BEFORE UPDATE ON bank_questions
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,16 @@
+-- Revert to choice, true/false and short answer questions; questions of the new types are removed
+DELETE FROM questions WHERE question_type IN ('matching', 'ordering', 'numerical', 'cloze', 'essay');
+DELETE FROM bank_questions WHERE question_type IN ('matching', 'ordering', 'numerical', 'cloze', 'essay');
+
+ALTER TABLE bank_answers DROP COLUMN IF EXISTS tolerance;
+ALTER TABLE bank_answers DROP COLUMN IF EXISTS match_text;
+ALTER TABLE answers DROP COLUMN IF EXISTS tolerance;
+ALTER TABLE answers DROP COLUMN IF EXISTS match_text;
+
+ALTER TABLE bank_questions DROP CONSTRAINT IF EXISTS bank_questions_question_type_check;
+ALTER TABLE bank_questions ADD CONSTRAINT bank_questions_question_type_check
+    CHECK (question_type IN ('single_choice', 'multiple_choice', 'true_false', 'short_answer'));
+
+ALTER TABLE questions DROP CONSTRAINT IF EXISTS questions_question_type_check;
+ALTER TABLE questions ADD CONSTRAINT questions_question_type_check
+    CHECK (question_type IN ('single_choice', 'multiple_choice', 'true_false', 'short_answer'));
@@ -0,0 +1,17 @@
+-- Add matching, ordering, numerical, cloze (embedded answers) and essay questions
+ALTER TABLE questions DROP CONSTRAINT IF EXISTS questions_question_type_check;
+ALTER TABLE questions ADD CONSTRAINT questions_question_type_check
+    CHECK (question_type IN ('single_choice', 'multiple_choice', 'true_false', 'short_answer',
+                             'matching', 'ordering', 'numerical', 'cloze', 'essay'));
+
+ALTER TABLE bank_questions DROP CONSTRAINT IF EXISTS bank_questions_question_type_check;
+ALTER TABLE bank_questions ADD CONSTRAINT bank_questions_question_type_check
+    CHECK (question_type IN ('single_choice', 'multiple_choice', 'true_false', 'short_answer',
+                             'matching', 'ordering', 'numerical', 'cloze', 'essay'));
+
+-- Matching answers pair answer_text with match_text; numerical answers accept an absolute error
+ALTER TABLE answers ADD COLUMN IF NOT EXISTS match_text TEXT;
+ALTER TABLE answers ADD COLUMN IF NOT EXISTS tolerance DOUBLE PRECISION CHECK (tolerance >= 0);
+
+ALTER TABLE bank_answers ADD COLUMN IF NOT EXISTS match_text TEXT;
+ALTER TABLE bank_answers ADD COLUMN IF NOT EXISTS tolerance DOUBLE PRECISION CHECK (tolerance >= 0);
@@ -0,0 +1,3 @@
+-- Drop teacher-set answer weights; exports fall back to computed fractions
+ALTER TABLE bank_answers DROP COLUMN IF EXISTS weight;
+ALTER TABLE answers DROP COLUMN IF EXISTS weight;
@@ -0,0 +1,4 @@
+-- Teacher-set grade percentage of a choice answer, overriding the computed Moodle fraction
+ALTER TABLE answers ADD COLUMN IF NOT EXISTS weight DOUBLE PRECISION CHECK (weight BETWEEN -100 AND 100);
+
+ALTER TABLE bank_answers ADD COLUMN IF NOT EXISTS weight DOUBLE PRECISION CHECK (weight BETWEEN -100 AND 100);
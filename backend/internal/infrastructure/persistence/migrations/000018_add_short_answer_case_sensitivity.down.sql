@@ -0,0 +1,3 @@
+-- Drop case sensitivity of short answer questions; variants are matched ignoring case
+ALTER TABLE bank_questions DROP COLUMN IF EXISTS case_sensitive;
+ALTER TABLE questions DROP COLUMN IF EXISTS case_sensitive;
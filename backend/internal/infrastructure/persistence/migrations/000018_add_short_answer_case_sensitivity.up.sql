@@ -0,0 +1,4 @@
+-- Short answer questions may require variants to match letter case (Moodle usecase)
+ALTER TABLE questions ADD COLUMN IF NOT EXISTS case_sensitive BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE bank_questions ADD COLUMN IF NOT EXISTS case_sensitive BOOLEAN NOT NULL DEFAULT FALSE;
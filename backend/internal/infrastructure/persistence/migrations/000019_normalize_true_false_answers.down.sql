@@ -0,0 +1,8 @@
+-- Canonical true/false keys go back to the Russian labels the generator used to store
+UPDATE answers SET answer_text = CASE answer_text WHEN 'true' THEN 'Верно' ELSE 'Неверно' END
+WHERE answer_text IN ('true', 'false')
+  AND question_id IN (SELECT id FROM questions WHERE question_type = 'true_false');
+
+UPDATE bank_answers SET answer_text = CASE answer_text WHEN 'true' THEN 'Верно' ELSE 'Неверно' END
+WHERE answer_text IN ('true', 'false')
+  AND bank_question_id IN (SELECT id FROM bank_questions WHERE question_type = 'true_false');
@@ -0,0 +1,15 @@
+-- True/false answers are stored under the canonical keys 'true' and 'false'
+-- and rendered as localized labels, so existing labels are converted
+UPDATE answers SET answer_text = CASE
+        WHEN lower(trim(answer_text)) IN ('true', 'верно', 'правда', 'истина', 'да') THEN 'true'
+        ELSE 'false'
+    END
+WHERE lower(trim(answer_text)) IN ('true', 'верно', 'правда', 'истина', 'да', 'false', 'неверно', 'ложь', 'нет')
+  AND question_id IN (SELECT id FROM questions WHERE question_type = 'true_false');
+
+UPDATE bank_answers SET answer_text = CASE
+        WHEN lower(trim(answer_text)) IN ('true', 'верно', 'правда', 'истина', 'да') THEN 'true'
+        ELSE 'false'
+    END
+WHERE lower(trim(answer_text)) IN ('true', 'верно', 'правда', 'истина', 'да', 'false', 'неверно', 'ложь', 'нет')
+  AND bank_question_id IN (SELECT id FROM bank_questions WHERE question_type = 'true_false');
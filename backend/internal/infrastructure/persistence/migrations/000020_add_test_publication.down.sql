@@ -0,0 +1,7 @@
+-- Remove test assignments and version tracking
+DROP TABLE IF EXISTS test_assignments;
+
+DROP INDEX IF EXISTS idx_tests_previous_version_id;
+ALTER TABLE tests DROP COLUMN IF EXISTS published_at;
+ALTER TABLE tests DROP COLUMN IF EXISTS previous_version_id;
+ALTER TABLE tests DROP COLUMN IF EXISTS version;
@@ -0,0 +1,18 @@
+-- Published tests are locked; changes go into a new version that remembers its predecessor
+ALTER TABLE tests ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE tests ADD COLUMN IF NOT EXISTS previous_version_id UUID REFERENCES tests(id) ON DELETE SET NULL;
+-- A test published before gets a new version number when it is republished
+ALTER TABLE tests ADD COLUMN IF NOT EXISTS published_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_tests_previous_version_id ON tests(previous_version_id);
+
+-- Students see a test once it is published and assigned to them
+CREATE TABLE IF NOT EXISTS test_assignments (
+    test_id UUID NOT NULL REFERENCES tests(id) ON DELETE CASCADE,
+    student_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    assigned_by UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (test_id, student_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_test_assignments_student_id ON test_assignments(student_id);
@@ -23,6 +23,9 @@ func setupAnswerTestDB(t *testing.T) *gorm.DB {
                         question_id TEXT NOT NULL,
                         answer_text TEXT NOT NULL,
                         is_correct BOOLEAN,
+                        match_text TEXT,
+                        tolerance REAL,
+                        weight REAL,
                         order_num INTEGER,
                         created_at DATETIME
                 );
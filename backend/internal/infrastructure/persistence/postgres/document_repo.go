@@ -2,6 +2,7 @@ package postgres
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/shester1kov/testgen-backend/internal/domain/entity"
@@ -85,3 +86,131 @@ func (r *documentRepository) CountAll(ctx context.Context) (int64, error) {
 		Count(&count).Error
 	return count, err
 }
+
+func (r *documentRepository) FindByStatus(ctx context.Context, status entity.DocumentStatus, limit int) ([]*entity.Document, error) {
+	var documents []*entity.Document
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND deleted_at IS NULL", status).
+		Order("created_at ASC").
+		Limit(limit).
+		Find(&documents).Error
+	return documents, err
+}
+
+func (r *documentRepository) ClaimForParsing(ctx context.Context, id uuid.UUID) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&entity.Document{}).
+		Where("id = ? AND status = ? AND deleted_at IS NULL", id, entity.StatusUploaded).
+		Updates(map[string]interface{}{
+			"status":         entity.StatusParsing,
+			"parse_progress": 0,
+			"error_msg":      "",
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *documentRepository) UpdateParseState(ctx context.Context, document *entity.Document) error {
+	updates := map[string]interface{}{
+		"status":         document.Status,
+		"parse_progress": document.ParseProgress,
+		"parse_attempts": document.ParseAttempts,
+		"error_msg":      document.ErrorMsg,
+	}
+	if document.IsParsed() {
+		updates["parsed_text"] = document.ParsedText
+		updates["encoding"] = document.Encoding
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&entity.Document{}).
+			Where("id = ? AND deleted_at IS NULL", document.ID).
+			Updates(updates).Error; err != nil {
+			return err
+		}
+		if !document.IsParsed() {
+			return nil
+		}
+
+		// Statistics of a corrected document describe the correction, not the parser output;
+		// the revision is checked in the row, a correction may have landed while parsing
+		return tx.Model(&entity.Document{}).
+			Where("id = ? AND deleted_at IS NULL AND text_revision = 0", document.ID).
+			Updates(map[string]interface{}{
+				"word_count":        document.WordCount,
+				"char_count":        document.CharCount,
+				"section_count":     document.SectionCount,
+				"language":          document.Language,
+				"readability_score": document.ReadabilityScore,
+				"token_estimates":   tokenEstimatesJSON(document.TokenEstimates),
+			}).Error
+	})
+}
+
+func (r *documentRepository) ResetStaleParsing(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Model(&entity.Document{}).
+		Where("status = ? AND updated_at < ? AND deleted_at IS NULL", entity.StatusParsing, before).
+		Updates(map[string]interface{}{
+			"status":         entity.StatusUploaded,
+			"parse_progress": 0,
+		})
+	return result.RowsAffected, result.Error
+}
+
+func (r *documentRepository) FindByContentHash(ctx context.Context, contentHash string) ([]*entity.Document, error) {
+	var documents []*entity.Document
+	err := r.db.WithContext(ctx).
+		Where("content_hash = ? AND deleted_at IS NULL", contentHash).
+		Order("created_at ASC").
+		Find(&documents).Error
+	return documents, err
+}
+
+func (r *documentRepository) CountByFilePath(ctx context.Context, filePath string) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&entity.Document{}).
+		Where("file_path = ? AND deleted_at IS NULL", filePath).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *documentRepository) FindDeletedBefore(ctx context.Context, before time.Time, limit, offset int) ([]*entity.Document, error) {
+	var documents []*entity.Document
+	err := r.db.WithContext(ctx).
+		Where("deleted_at IS NOT NULL AND deleted_at < ? AND file_purged_at IS NULL", before).
+		Order("deleted_at ASC").
+		Limit(limit).
+		Offset(offset).
+		Find(&documents).Error
+	return documents, err
+}
+
+func (r *documentRepository) CountRetainedByFilePath(ctx context.Context, filePath string, deletedBefore time.Time) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&entity.Document{}).
+		Where("file_path = ? AND file_purged_at IS NULL AND (deleted_at IS NULL OR deleted_at >= ?)", filePath, deletedBefore).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *documentRepository) MarkFilePurged(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).
+		Model(&entity.Document{}).
+		Where("id = ?", id).
+		Update("file_purged_at", gorm.Expr("CURRENT_TIMESTAMP")).Error
+}
+
+func (r *documentRepository) FindFilePaths(ctx context.Context) ([]string, error) {
+	var paths []string
+	err := r.db.WithContext(ctx).
+		Model(&entity.Document{}).
+		Where("file_purged_at IS NULL").
+		Distinct().
+		Pluck("file_path", &paths).Error
+	return paths, err
+}
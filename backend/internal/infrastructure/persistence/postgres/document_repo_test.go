@@ -42,9 +42,22 @@ func setupDocumentTestDB(t *testing.T) *gorm.DB {
                         parsed_text TEXT,
                         status TEXT,
                         error_msg TEXT,
+                        parse_progress INTEGER DEFAULT 0,
+                        parse_attempts INTEGER DEFAULT 0,
+                        encoding TEXT,
+                        content_hash TEXT,
+                        corrected_text TEXT,
+                        text_revision INTEGER DEFAULT 0,
+                        word_count INTEGER DEFAULT 0,
+                        char_count INTEGER DEFAULT 0,
+                        section_count INTEGER DEFAULT 0,
+                        language TEXT,
+                        readability_score REAL DEFAULT 0,
+                        token_estimates TEXT,
                         created_at DATETIME,
                         updated_at DATETIME,
-                        deleted_at DATETIME
+                        deleted_at DATETIME,
+                        file_purged_at DATETIME
                 );
         `).Error
 	require.NoError(t, err)
@@ -136,3 +149,157 @@ func TestDocumentRepository_CountAndFindErrors(t *testing.T) {
 	_, err = repo.FindByUserID(context.Background(), userID, 5, 0)
 	assert.Error(t, err)
 }
+
+func TestDocumentRepository_ParseQueueMethods(t *testing.T) {
+	db := setupDocumentTestDB(t)
+	repo := NewDocumentRepository(db)
+	ctx := context.Background()
+	doc := createDocument(t, db, uuid.New())
+
+	uploaded, err := repo.FindByStatus(ctx, entity.StatusUploaded, 10)
+	require.NoError(t, err)
+	assert.Len(t, uploaded, 1)
+
+	claimed, err := repo.ClaimForParsing(ctx, doc.ID)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+
+	// Second claim must lose: the row is no longer uploaded
+	claimed, err = repo.ClaimForParsing(ctx, doc.ID)
+	require.NoError(t, err)
+	assert.False(t, claimed)
+
+	fetched, err := repo.FindByID(ctx, doc.ID)
+	require.NoError(t, err)
+	assert.Equal(t, entity.StatusParsing, fetched.Status)
+
+	// Fresh parsing rows are not stale
+	reset, err := repo.ResetStaleParsing(ctx, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.EqualValues(t, 0, reset)
+
+	reset, err = repo.ResetStaleParsing(ctx, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, reset)
+
+	fetched, err = repo.FindByID(ctx, doc.ID)
+	require.NoError(t, err)
+	assert.Equal(t, entity.StatusUploaded, fetched.Status)
+	assert.Equal(t, 0, fetched.ParseProgress)
+}
+
+func TestDocumentRepository_UpdateParseState(t *testing.T) {
+	db := setupDocumentTestDB(t)
+	repo := NewDocumentRepository(db)
+	ctx := context.Background()
+	doc := createDocument(t, db, uuid.New())
+
+	// The worker holds a copy loaded before parsing; the row changes meanwhile
+	stale, err := repo.FindByID(ctx, doc.ID)
+	require.NoError(t, err)
+	require.NoError(t, db.Model(&entity.Document{}).Where("id = ?", doc.ID).
+		Updates(map[string]interface{}{"title": "Renamed"}).Error)
+
+	stale.ParseAttempts = 1
+	stale.Encoding = "utf-8"
+	stale.MarkAsParsed("parsed words here")
+	require.NoError(t, repo.UpdateParseState(ctx, stale))
+
+	fetched, err := repo.FindByID(ctx, doc.ID)
+	require.NoError(t, err)
+	assert.Equal(t, entity.StatusParsed, fetched.Status)
+	assert.Equal(t, "parsed words here", fetched.ParsedText)
+	assert.Equal(t, 100, fetched.ParseProgress)
+	assert.Equal(t, 1, fetched.ParseAttempts)
+	assert.Equal(t, "utf-8", fetched.Encoding)
+	assert.Equal(t, 3, fetched.WordCount)
+	assert.NotEmpty(t, fetched.TokenEstimates)
+	assert.Equal(t, "Renamed", fetched.Title, "columns outside the parse state are not overwritten")
+
+	// A correction saved while parsing keeps its statistics, whatever the worker's copy says
+	require.NoError(t, db.Model(&entity.Document{}).Where("id = ?", doc.ID).
+		Updates(map[string]interface{}{"text_revision": 1, "word_count": 7}).Error)
+	stale.MarkAsParsed("reparsed text")
+	require.NoError(t, repo.UpdateParseState(ctx, stale))
+
+	fetched, err = repo.FindByID(ctx, doc.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "reparsed text", fetched.ParsedText)
+	assert.Equal(t, 7, fetched.WordCount)
+
+	// A document deleted while parsing stays deleted
+	require.NoError(t, repo.Delete(ctx, doc.ID))
+	stale.MarkAsError("late failure")
+	require.NoError(t, repo.UpdateParseState(ctx, stale))
+
+	var deleted entity.Document
+	require.NoError(t, db.Where("id = ?", doc.ID).First(&deleted).Error)
+	assert.NotNil(t, deleted.DeletedAt)
+	assert.Equal(t, entity.StatusParsed, deleted.Status)
+}
+
+func TestDocumentRepository_DeduplicationMethods(t *testing.T) {
+	db := setupDocumentTestDB(t)
+	repo := NewDocumentRepository(db)
+	ctx := context.Background()
+	doc := createDocument(t, db, uuid.New())
+	require.NoError(t, db.Model(doc).Update("content_hash", "abc").Error)
+
+	duplicates, err := repo.FindByContentHash(ctx, "abc")
+	require.NoError(t, err)
+	require.Len(t, duplicates, 1)
+	assert.Equal(t, doc.ID, duplicates[0].ID)
+
+	count, err := repo.CountByFilePath(ctx, doc.FilePath)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+
+	// Soft-deleted documents no longer reference the content or the file
+	require.NoError(t, repo.Delete(ctx, doc.ID))
+	duplicates, err = repo.FindByContentHash(ctx, "abc")
+	require.NoError(t, err)
+	assert.Empty(t, duplicates)
+	count, err = repo.CountByFilePath(ctx, doc.FilePath)
+	require.NoError(t, err)
+	assert.EqualValues(t, 0, count)
+}
+
+func TestDocumentRepository_PurgeMethods(t *testing.T) {
+	db := setupDocumentTestDB(t)
+	repo := NewDocumentRepository(db)
+	ctx := context.Background()
+	now := time.Now().UTC()
+	cutoff := now.Add(-24 * time.Hour)
+
+	expired := createDocument(t, db, uuid.New())
+	require.NoError(t, db.Model(expired).Update("deleted_at", now.Add(-48*time.Hour)).Error)
+
+	// Same blob, deleted recently: the file must be kept for it
+	recent := &entity.Document{ID: uuid.New(), UserID: expired.UserID, Title: "Copy", FileName: "file.txt",
+		FilePath: expired.FilePath, FileType: entity.FileTypeTXT, FileSize: 10, Status: entity.StatusUploaded}
+	require.NoError(t, db.Create(recent).Error)
+	require.NoError(t, db.Model(recent).Update("deleted_at", now.Add(-time.Hour)).Error)
+
+	documents, err := repo.FindDeletedBefore(ctx, cutoff, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, documents, 1)
+	assert.Equal(t, expired.ID, documents[0].ID)
+
+	count, err := repo.CountRetainedByFilePath(ctx, expired.FilePath, cutoff)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+
+	paths, err := repo.FindFilePaths(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{expired.FilePath}, paths)
+
+	require.NoError(t, repo.MarkFilePurged(ctx, expired.ID))
+	documents, err = repo.FindDeletedBefore(ctx, cutoff, 10, 0)
+	require.NoError(t, err)
+	assert.Empty(t, documents)
+
+	require.NoError(t, repo.MarkFilePurged(ctx, recent.ID))
+	paths, err = repo.FindFilePaths(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, paths)
+}
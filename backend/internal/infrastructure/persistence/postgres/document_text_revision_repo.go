@@ -0,0 +1,76 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"github.com/shester1kov/testgen-backend/internal/domain/entity"
+	"github.com/shester1kov/testgen-backend/internal/domain/repository"
+	"gorm.io/gorm"
+)
+
+type documentTextRevisionRepository struct {
+	db *gorm.DB
+}
+
+// NewDocumentTextRevisionRepository creates a new document text revision repository
+func NewDocumentTextRevisionRepository(db *gorm.DB) repository.DocumentTextRevisionRepository {
+	return &documentTextRevisionRepository{db: db}
+}
+
+func (r *documentTextRevisionRepository) Create(ctx context.Context, revision *entity.DocumentTextRevision) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Bumping the counter row-locks the document, so concurrent saves get distinct numbers
+		result := tx.Model(&entity.Document{}).
+			Where("id = ? AND deleted_at IS NULL", revision.DocumentID).
+			Updates(map[string]interface{}{
+				"text_revision":     gorm.Expr("text_revision + 1"),
+				"corrected_text":    revision.Text,
+				"word_count":        revision.Stats.WordCount,
+				"char_count":        revision.Stats.CharCount,
+				"section_count":     revision.Stats.SectionCount,
+				"language":          revision.Stats.Language,
+				"readability_score": revision.Stats.ReadabilityScore,
+				"token_estimates":   tokenEstimatesJSON(revision.Stats.TokenEstimates),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		var document entity.Document
+		if err := tx.Select("text_revision").Where("id = ?", revision.DocumentID).First(&document).Error; err != nil {
+			return err
+		}
+		revision.Revision = document.TextRevision
+
+		return tx.Create(revision).Error
+	})
+}
+
+func (r *documentTextRevisionRepository) FindByDocumentID(ctx context.Context, documentID uuid.UUID) ([]*entity.DocumentTextRevision, error) {
+	var revisions []*entity.DocumentTextRevision
+	err := r.db.WithContext(ctx).
+		Where("document_id = ?", documentID).
+		Order("revision DESC").
+		Find(&revisions).Error
+	if err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+// tokenEstimatesJSON encodes estimates for map-based updates, which bypass the field's JSON serializer
+func tokenEstimatesJSON(estimates map[string]int) interface{} {
+	if estimates == nil {
+		return nil
+	}
+	encoded, err := json.Marshal(estimates)
+	if err != nil {
+		return nil
+	}
+	return string(encoded)
+}
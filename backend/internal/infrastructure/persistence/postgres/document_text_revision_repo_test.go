@@ -0,0 +1,78 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/shester1kov/testgen-backend/internal/domain/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func setupDocumentTextRevisionTestDB(t *testing.T) *gorm.DB {
+	db := setupDocumentTestDB(t)
+	err := db.Exec(`
+                CREATE TABLE document_text_revisions (
+                        id TEXT PRIMARY KEY,
+                        document_id TEXT NOT NULL,
+                        user_id TEXT NOT NULL,
+                        revision INTEGER NOT NULL,
+                        text TEXT NOT NULL,
+                        created_at DATETIME,
+                        UNIQUE (document_id, revision)
+                );
+        `).Error
+	require.NoError(t, err)
+	return db
+}
+
+func TestDocumentTextRevisionRepository_CreateAndList(t *testing.T) {
+	db := setupDocumentTextRevisionTestDB(t)
+	repo := NewDocumentTextRevisionRepository(db)
+	documentRepo := NewDocumentRepository(db)
+	ctx := context.Background()
+	doc := createDocument(t, db, uuid.New())
+
+	first := &entity.DocumentTextRevision{ID: uuid.New(), DocumentID: doc.ID, UserID: doc.UserID, Text: "first fix"}
+	require.NoError(t, repo.Create(ctx, first))
+	assert.Equal(t, 1, first.Revision)
+
+	second := &entity.DocumentTextRevision{
+		ID: uuid.New(), DocumentID: doc.ID, UserID: doc.UserID, Text: "second fix",
+		Stats: entity.NewTextStats("second fix"),
+	}
+	require.NoError(t, repo.Create(ctx, second))
+	assert.Equal(t, 2, second.Revision)
+
+	// The document points at the latest correction
+	fetched, err := documentRepo.FindByID(ctx, doc.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, fetched.TextRevision)
+	assert.Equal(t, "second fix", fetched.CorrectedText)
+	assert.Equal(t, "second fix", fetched.EffectiveText())
+	assert.Equal(t, 2, fetched.WordCount)
+	assert.Equal(t, second.Stats.TokenEstimates, fetched.TokenEstimates)
+
+	revisions, err := repo.FindByDocumentID(ctx, doc.ID)
+	require.NoError(t, err)
+	require.Len(t, revisions, 2)
+	assert.Equal(t, 2, revisions[0].Revision)
+	assert.Equal(t, 1, revisions[1].Revision)
+}
+
+func TestDocumentTextRevisionRepository_CreateForDeletedDocument(t *testing.T) {
+	db := setupDocumentTextRevisionTestDB(t)
+	repo := NewDocumentTextRevisionRepository(db)
+	ctx := context.Background()
+	doc := createDocument(t, db, uuid.New())
+	require.NoError(t, NewDocumentRepository(db).Delete(ctx, doc.ID))
+
+	err := repo.Create(ctx, &entity.DocumentTextRevision{ID: uuid.New(), DocumentID: doc.ID, UserID: doc.UserID, Text: "fix"})
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	revisions, err := repo.FindByDocumentID(ctx, doc.ID)
+	require.NoError(t, err)
+	assert.Empty(t, revisions)
+}
@@ -0,0 +1,402 @@
+package postgres
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shester1kov/testgen-backend/internal/domain/entity"
+	"github.com/shester1kov/testgen-backend/internal/domain/repository"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// categorySubtreeSQL selects the IDs of a category and all its nested categories
+const categorySubtreeSQL = `WITH RECURSIVE subtree(id) AS (
+		SELECT id FROM question_categories WHERE id = ?
+		UNION ALL
+		SELECT c.id FROM question_categories c JOIN subtree s ON c.parent_id = s.id
+	) SELECT id FROM subtree`
+
+// likeEscaper escapes LIKE wildcards in user input; patterns use ESCAPE '\'
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// bankQuestionTag is a row of the bank_question_tags join table
+type bankQuestionTag struct {
+	BankQuestionID uuid.UUID `gorm:"type:uuid;primaryKey"`
+	QuestionTagID  uuid.UUID `gorm:"type:uuid;primaryKey"`
+}
+
+func (bankQuestionTag) TableName() string {
+	return "bank_question_tags"
+}
+
+type questionCategoryRepository struct {
+	db *gorm.DB
+}
+
+// NewQuestionCategoryRepository creates a new question bank category repository
+func NewQuestionCategoryRepository(db *gorm.DB) repository.QuestionCategoryRepository {
+	return &questionCategoryRepository{db: db}
+}
+
+func (r *questionCategoryRepository) Create(ctx context.Context, category *entity.QuestionCategory) error {
+	return r.db.WithContext(ctx).Create(category).Error
+}
+
+func (r *questionCategoryRepository) FindByID(ctx context.Context, id uuid.UUID) (*entity.QuestionCategory, error) {
+	var category entity.QuestionCategory
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&category).Error; err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+func (r *questionCategoryRepository) FindByUserID(ctx context.Context, userID uuid.UUID) ([]*entity.QuestionCategory, error) {
+	var categories []*entity.QuestionCategory
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("name ASC").
+		Find(&categories).Error
+	if err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
+func (r *questionCategoryRepository) Update(ctx context.Context, category *entity.QuestionCategory) error {
+	return r.db.WithContext(ctx).Save(category).Error
+}
+
+func (r *questionCategoryRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var ids []uuid.UUID
+		if err := tx.Raw(categorySubtreeSQL, id).Scan(&ids).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		if err := tx.Model(&entity.BankQuestion{}).
+			Where("category_id IN ?", ids).
+			Update("category_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&entity.QuestionCategory{}, "id IN ?", ids).Error
+	})
+}
+
+type bankQuestionRepository struct {
+	db *gorm.DB
+}
+
+// NewBankQuestionRepository creates a new question bank repository
+func NewBankQuestionRepository(db *gorm.DB) repository.BankQuestionRepository {
+	return &bankQuestionRepository{db: db}
+}
+
+func (r *bankQuestionRepository) Create(ctx context.Context, questions ...*entity.BankQuestion) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, question := range questions {
+			if question.ID == uuid.Nil {
+				question.ID = uuid.New()
+			}
+			if err := tx.Omit(clause.Associations).Create(question).Error; err != nil {
+				return err
+			}
+			if err := saveBankQuestionRelations(tx, question); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *bankQuestionRepository) FindByID(ctx context.Context, id uuid.UUID) (*entity.BankQuestion, error) {
+	var question entity.BankQuestion
+	err := preloadBankQuestionRelations(r.db.WithContext(ctx)).
+		Where("id = ?", id).
+		First(&question).Error
+	if err != nil {
+		return nil, err
+	}
+	return &question, nil
+}
+
+func (r *bankQuestionRepository) FindByIDs(ctx context.Context, ids []uuid.UUID) ([]*entity.BankQuestion, error) {
+	var questions []*entity.BankQuestion
+	if len(ids) == 0 {
+		return questions, nil
+	}
+	err := preloadBankQuestionRelations(r.db.WithContext(ctx)).
+		Where("id IN ?", ids).
+		Find(&questions).Error
+	if err != nil {
+		return nil, err
+	}
+	return questions, nil
+}
+
+func (r *bankQuestionRepository) List(ctx context.Context, filter repository.BankQuestionFilter) ([]*entity.BankQuestion, int64, error) {
+	var total int64
+	if err := r.filtered(ctx, filter).Model(&entity.BankQuestion{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var questions []*entity.BankQuestion
+	err := preloadBankQuestionRelations(r.filtered(ctx, filter)).
+		Order("created_at DESC, id").
+		Limit(filter.Limit).
+		Offset(filter.Offset).
+		Find(&questions).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return questions, total, nil
+}
+
+func (r *bankQuestionRepository) FindMatching(ctx context.Context, filter repository.BankQuestionFilter) ([]*entity.BankQuestion, error) {
+	var questions []*entity.BankQuestion
+	if err := r.filtered(ctx, filter).Order("created_at DESC, id").Find(&questions).Error; err != nil {
+		return nil, err
+	}
+	return questions, nil
+}
+
+// filtered builds the WHERE clause of List and FindMatching
+func (r *bankQuestionRepository) filtered(ctx context.Context, filter repository.BankQuestionFilter) *gorm.DB {
+	db := r.db.WithContext(ctx)
+	query := db.Where("user_id = ?", filter.UserID)
+
+	if filter.CategoryID != nil {
+		if filter.IncludeSubcategories {
+			query = query.Where("category_id IN (?)", gorm.Expr(categorySubtreeSQL, *filter.CategoryID))
+		} else {
+			query = query.Where("category_id = ?", *filter.CategoryID)
+		}
+	}
+	if len(filter.Tags) > 0 {
+		tagged := db.Table("bank_question_tags bqt").
+			Select("bqt.bank_question_id").
+			Joins("JOIN question_tags t ON t.id = bqt.question_tag_id").
+			Where("t.name IN ?", filter.Tags).
+			Group("bqt.bank_question_id").
+			Having("COUNT(DISTINCT t.name) = ?", len(filter.Tags))
+		query = query.Where("id IN (?)", tagged)
+	}
+	if filter.QuestionType != "" {
+		query = query.Where("question_type = ?", filter.QuestionType)
+	}
+	if filter.Difficulty != "" {
+		query = query.Where("difficulty = ?", filter.Difficulty)
+	}
+	if filter.Text != "" {
+		pattern := "%" + likeEscaper.Replace(strings.ToLower(filter.Text)) + "%"
+		query = query.Where(`LOWER(question_text) LIKE ? ESCAPE '\'`, pattern)
+	}
+	return query
+}
+
+func (r *bankQuestionRepository) Update(ctx context.Context, question *entity.BankQuestion) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Save(question).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&entity.BankAnswer{}, "bank_question_id = ?", question.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&bankQuestionTag{}, "bank_question_id = ?", question.ID).Error; err != nil {
+			return err
+		}
+		return saveBankQuestionRelations(tx, question)
+	})
+}
+
+func (r *bankQuestionRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&bankQuestionTag{}, "bank_question_id = ?", id).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&entity.BankAnswer{}, "bank_question_id = ?", id).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&entity.TestBankQuestion{}, "bank_question_id = ?", id).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&entity.BankQuestion{}, "id = ?", id).Error
+	})
+}
+
+func (r *bankQuestionRepository) FindTagsByUserID(ctx context.Context, userID uuid.UUID) ([]*entity.QuestionTag, error) {
+	var tags []*entity.QuestionTag
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("name ASC").
+		Find(&tags).Error
+	if err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+func (r *bankQuestionRepository) FindTestIDs(ctx context.Context, id uuid.UUID) ([]uuid.UUID, error) {
+	var testIDs []uuid.UUID
+	err := r.db.WithContext(ctx).
+		Table("test_bank_questions tbq").
+		Joins("JOIN tests t ON t.id = tbq.test_id AND t.deleted_at IS NULL").
+		Where("tbq.bank_question_id = ?", id).
+		Order("tbq.created_at ASC").
+		Pluck("tbq.test_id", &testIDs).Error
+	if err != nil {
+		return nil, err
+	}
+	return testIDs, nil
+}
+
+func (r *bankQuestionRepository) AddToTest(ctx context.Context, testID uuid.UUID, questions []*entity.BankQuestion) ([]*entity.Question, error) {
+	var copies []*entity.Question
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockTest(tx, testID); err != nil {
+			return err
+		}
+
+		bankIDs := make([]uuid.UUID, len(questions))
+		for i, q := range questions {
+			bankIDs[i] = q.ID
+		}
+		// Links whose copy was deleted from the test no longer count
+		var linked int64
+		if err := tx.Table("test_bank_questions tbq").
+			Joins("JOIN questions q ON q.id = tbq.question_id").
+			Where("tbq.test_id = ? AND tbq.bank_question_id IN ?", testID, bankIDs).
+			Count(&linked).Error; err != nil {
+			return err
+		}
+		if linked > 0 {
+			return repository.ErrBankQuestionInTest
+		}
+
+		var count int64
+		if err := tx.Model(&entity.Question{}).Where("test_id = ?", testID).Count(&count).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		copies = make([]*entity.Question, len(questions))
+		var answers []entity.Answer
+		links := make([]entity.TestBankQuestion, len(questions))
+		for i, bankQuestion := range questions {
+			question := bankQuestion.ToQuestion(testID, int(count)+i+1)
+			question.ID = uuid.New()
+			for j := range question.Answers {
+				answer := &question.Answers[j]
+				answer.ID = uuid.New()
+				answer.QuestionID = question.ID
+				answer.CreatedAt = now
+				answers = append(answers, *answer)
+			}
+			copies[i] = question
+			links[i] = entity.TestBankQuestion{TestID: testID, BankQuestionID: bankQuestion.ID, QuestionID: question.ID}
+		}
+
+		// Drop stale links left by deleted copies so the new ones fit the primary key
+		if err := tx.Where("test_id = ? AND bank_question_id IN ?", testID, bankIDs).
+			Delete(&entity.TestBankQuestion{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Omit(clause.Associations).CreateInBatches(copies, insertBatchSize).Error; err != nil {
+			return err
+		}
+		if len(answers) > 0 {
+			if err := tx.Omit(clause.Associations).CreateInBatches(&answers, insertBatchSize).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.CreateInBatches(&links, insertBatchSize).Error; err != nil {
+			return err
+		}
+
+		return updateTotalQuestions(tx, testID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return copies, nil
+}
+
+func (r *bankQuestionRepository) CreateTest(ctx context.Context, test *entity.Test, links []entity.TestBankQuestion) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := createTestWithQuestions(tx, test); err != nil {
+			return err
+		}
+		if len(links) == 0 {
+			return nil
+		}
+		for i := range links {
+			links[i].TestID = test.ID
+		}
+		return tx.CreateInBatches(&links, insertBatchSize).Error
+	})
+}
+
+func preloadBankQuestionRelations(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("Answers", func(db *gorm.DB) *gorm.DB {
+			return db.Order("order_num ASC")
+		}).
+		Preload("Tags", func(db *gorm.DB) *gorm.DB {
+			return db.Order("name ASC")
+		})
+}
+
+// saveBankQuestionRelations inserts the answers of a saved bank question and
+// links it to its tags, creating tags that do not exist yet
+func saveBankQuestionRelations(tx *gorm.DB, question *entity.BankQuestion) error {
+	now := time.Now()
+	for i := range question.Answers {
+		answer := &question.Answers[i]
+		if answer.ID == uuid.Nil {
+			answer.ID = uuid.New()
+		}
+		answer.BankQuestionID = question.ID
+		answer.OrderNum = i + 1
+		if answer.CreatedAt.IsZero() {
+			answer.CreatedAt = now
+		}
+	}
+	if len(question.Answers) > 0 {
+		if err := tx.Create(&question.Answers).Error; err != nil {
+			return err
+		}
+	}
+
+	if len(question.Tags) == 0 {
+		return nil
+	}
+	names := make([]string, len(question.Tags))
+	missing := make([]entity.QuestionTag, len(question.Tags))
+	for i, tag := range question.Tags {
+		names[i] = tag.Name
+		missing[i] = entity.QuestionTag{ID: uuid.New(), UserID: question.UserID, Name: tag.Name, CreatedAt: now}
+	}
+	// Tags are unique per user and name; concurrent requests may create the same tag
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&missing).Error; err != nil {
+		return err
+	}
+
+	var tags []entity.QuestionTag
+	if err := tx.Where("user_id = ? AND name IN ?", question.UserID, names).Order("name ASC").Find(&tags).Error; err != nil {
+		return err
+	}
+	links := make([]bankQuestionTag, len(tags))
+	for i, tag := range tags {
+		links[i] = bankQuestionTag{BankQuestionID: question.ID, QuestionTagID: tag.ID}
+	}
+	if err := tx.Create(&links).Error; err != nil {
+		return err
+	}
+	question.Tags = tags
+	return nil
+}
@@ -0,0 +1,391 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shester1kov/testgen-backend/internal/domain/entity"
+	"github.com/shester1kov/testgen-backend/internal/domain/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func setupQuestionBankTestDB(t *testing.T) *gorm.DB {
+	db := setupQuestionTestDB(t)
+
+	for _, schema := range []string{`
+                CREATE TABLE question_categories (
+                        id TEXT PRIMARY KEY,
+                        user_id TEXT NOT NULL,
+                        parent_id TEXT,
+                        name TEXT NOT NULL,
+                        created_at DATETIME,
+                        updated_at DATETIME
+                );`, `
+                CREATE TABLE bank_questions (
+                        id TEXT PRIMARY KEY,
+                        user_id TEXT NOT NULL,
+                        category_id TEXT,
+                        question_text TEXT NOT NULL,
+                        question_type TEXT,
+                        difficulty TEXT,
+                        points REAL,
+                        case_sensitive BOOLEAN DEFAULT FALSE,
+                        created_at DATETIME,
+                        updated_at DATETIME
+                );`, `
+                CREATE TABLE bank_answers (
+                        id TEXT PRIMARY KEY,
+                        bank_question_id TEXT NOT NULL,
+                        answer_text TEXT NOT NULL,
+                        is_correct BOOLEAN,
+                        match_text TEXT,
+                        tolerance REAL,
+                        weight REAL,
+                        order_num INTEGER,
+                        created_at DATETIME
+                );`, `
+                CREATE TABLE question_tags (
+                        id TEXT PRIMARY KEY,
+                        user_id TEXT NOT NULL,
+                        name TEXT NOT NULL,
+                        created_at DATETIME,
+                        UNIQUE (user_id, name)
+                );`, `
+                CREATE TABLE bank_question_tags (
+                        bank_question_id TEXT NOT NULL,
+                        question_tag_id TEXT NOT NULL,
+                        PRIMARY KEY (bank_question_id, question_tag_id)
+                );`, `
+                CREATE TABLE test_bank_questions (
+                        test_id TEXT NOT NULL,
+                        bank_question_id TEXT NOT NULL,
+                        question_id TEXT NOT NULL,
+                        created_at DATETIME,
+                        PRIMARY KEY (test_id, bank_question_id)
+                );`,
+		`ALTER TABLE tests ADD COLUMN description TEXT;`,
+		`ALTER TABLE tests ADD COLUMN moodle_synced BOOLEAN;`,
+		`ALTER TABLE tests ADD COLUMN moodle_test_id TEXT;`,
+		`ALTER TABLE tests ADD COLUMN version INTEGER DEFAULT 1;`,
+		`ALTER TABLE tests ADD COLUMN previous_version_id TEXT;`,
+		`ALTER TABLE tests ADD COLUMN published_at DATETIME;`,
+	} {
+		require.NoError(t, db.Exec(schema).Error)
+	}
+
+	return db
+}
+
+func newBankQuestion(userID uuid.UUID, text string, categoryID *uuid.UUID, tags ...string) *entity.BankQuestion {
+	question := &entity.BankQuestion{
+		ID:           uuid.New(),
+		UserID:       userID,
+		CategoryID:   categoryID,
+		QuestionText: text,
+		QuestionType: entity.QuestionTypeSingleChoice,
+		Difficulty:   entity.DifficultyMedium,
+		Points:       1,
+		Answers: []entity.BankAnswer{
+			{AnswerText: "Right", IsCorrect: true},
+			{AnswerText: "Wrong"},
+		},
+	}
+	for _, name := range tags {
+		question.Tags = append(question.Tags, entity.QuestionTag{Name: name})
+	}
+	return question
+}
+
+func seedCategory(t *testing.T, repo repository.QuestionCategoryRepository, userID uuid.UUID, parentID *uuid.UUID, name string) *entity.QuestionCategory {
+	category := &entity.QuestionCategory{ID: uuid.New(), UserID: userID, ParentID: parentID, Name: name}
+	require.NoError(t, repo.Create(context.Background(), category))
+	return category
+}
+
+func TestBankQuestionRepository_CreateReusesTags(t *testing.T) {
+	db := setupQuestionBankTestDB(t)
+	repo := NewBankQuestionRepository(db)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	first := newBankQuestion(userID, "What is Go?", nil, "go", "basics")
+	second := newBankQuestion(userID, "What is a goroutine?", nil, "go", "concurrency")
+	require.NoError(t, repo.Create(ctx, first, second))
+
+	stored, err := repo.FindByID(ctx, second.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"concurrency", "go"}, stored.TagNames())
+	require.Len(t, stored.Answers, 2)
+	assert.Equal(t, "Right", stored.Answers[0].AnswerText)
+	assert.Equal(t, 1, stored.Answers[0].OrderNum)
+
+	tags, err := repo.FindTagsByUserID(ctx, userID)
+	require.NoError(t, err)
+	names := make([]string, len(tags))
+	for i, tag := range tags {
+		names[i] = tag.Name
+	}
+	assert.Equal(t, []string{"basics", "concurrency", "go"}, names)
+}
+
+func TestBankQuestionRepository_ListFilters(t *testing.T) {
+	db := setupQuestionBankTestDB(t)
+	categories := NewQuestionCategoryRepository(db)
+	repo := NewBankQuestionRepository(db)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	root := seedCategory(t, categories, userID, nil, "Programming")
+	child := seedCategory(t, categories, userID, &root.ID, "Go")
+
+	inRoot := newBankQuestion(userID, "What is a compiler?", &root.ID, "theory")
+	inChild := newBankQuestion(userID, "What does 100% coverage mean?", &child.ID, "go", "testing")
+	inChild.CreatedAt = time.Now().Add(time.Minute)
+	hard := newBankQuestion(userID, "Explain channels", nil, "go")
+	hard.Difficulty = entity.DifficultyHard
+	foreign := newBankQuestion(uuid.New(), "What is a compiler?", nil)
+	require.NoError(t, repo.Create(ctx, inRoot, inChild, hard, foreign))
+
+	ids := func(questions []*entity.BankQuestion) []uuid.UUID {
+		result := make([]uuid.UUID, len(questions))
+		for i, q := range questions {
+			result[i] = q.ID
+		}
+		return result
+	}
+
+	tests := []struct {
+		name   string
+		filter repository.BankQuestionFilter
+		want   []uuid.UUID
+	}{
+		{"category subtree", repository.BankQuestionFilter{CategoryID: &root.ID, IncludeSubcategories: true}, []uuid.UUID{inChild.ID, inRoot.ID}},
+		{"category only", repository.BankQuestionFilter{CategoryID: &root.ID}, []uuid.UUID{inRoot.ID}},
+		{"every tag", repository.BankQuestionFilter{Tags: []string{"go", "testing"}}, []uuid.UUID{inChild.ID}},
+		{"difficulty", repository.BankQuestionFilter{Difficulty: entity.DifficultyHard}, []uuid.UUID{hard.ID}},
+		{"text is case-insensitive", repository.BankQuestionFilter{Text: "COMPILER"}, []uuid.UUID{inRoot.ID}},
+		{"text wildcards are literal", repository.BankQuestionFilter{Text: "100%"}, []uuid.UUID{inChild.ID}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.filter.UserID = userID
+			tt.filter.Limit = 10
+			questions, total, err := repo.List(ctx, tt.filter)
+			require.NoError(t, err)
+			assert.Equal(t, int64(len(tt.want)), total)
+			assert.ElementsMatch(t, tt.want, ids(questions))
+		})
+	}
+
+	page, total, err := repo.List(ctx, repository.BankQuestionFilter{UserID: userID, Limit: 1})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), total)
+	assert.Equal(t, []uuid.UUID{inChild.ID}, ids(page), "newest question comes first")
+}
+
+func TestBankQuestionRepository_UpdateReplacesAnswersAndTags(t *testing.T) {
+	db := setupQuestionBankTestDB(t)
+	repo := NewBankQuestionRepository(db)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	question := newBankQuestion(userID, "Old text", nil, "old")
+	require.NoError(t, repo.Create(ctx, question))
+
+	question.QuestionText = "New text"
+	question.Answers = []entity.BankAnswer{{AnswerText: "Only", IsCorrect: true}}
+	question.Tags = []entity.QuestionTag{{Name: "new"}}
+	require.NoError(t, repo.Update(ctx, question))
+
+	stored, err := repo.FindByID(ctx, question.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "New text", stored.QuestionText)
+	require.Len(t, stored.Answers, 1)
+	assert.Equal(t, "Only", stored.Answers[0].AnswerText)
+	assert.Equal(t, []string{"new"}, stored.TagNames())
+
+	var answers int64
+	require.NoError(t, db.Model(&entity.BankAnswer{}).Count(&answers).Error)
+	assert.Equal(t, int64(1), answers)
+}
+
+func TestQuestionCategoryRepository_DeleteUncategorizesSubtree(t *testing.T) {
+	db := setupQuestionBankTestDB(t)
+	categories := NewQuestionCategoryRepository(db)
+	repo := NewBankQuestionRepository(db)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	root := seedCategory(t, categories, userID, nil, "Root")
+	child := seedCategory(t, categories, userID, &root.ID, "Child")
+	sibling := seedCategory(t, categories, userID, nil, "Sibling")
+
+	inChild := newBankQuestion(userID, "In child", &child.ID)
+	inSibling := newBankQuestion(userID, "In sibling", &sibling.ID)
+	require.NoError(t, repo.Create(ctx, inChild, inSibling))
+
+	require.NoError(t, categories.Delete(ctx, root.ID))
+
+	remaining, err := categories.FindByUserID(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, remaining, 1)
+	assert.Equal(t, sibling.ID, remaining[0].ID)
+
+	stored, err := repo.FindByID(ctx, inChild.ID)
+	require.NoError(t, err)
+	assert.Nil(t, stored.CategoryID)
+
+	stored, err = repo.FindByID(ctx, inSibling.ID)
+	require.NoError(t, err)
+	require.NotNil(t, stored.CategoryID)
+	assert.Equal(t, sibling.ID, *stored.CategoryID)
+
+	assert.ErrorIs(t, categories.Delete(ctx, uuid.New()), gorm.ErrRecordNotFound)
+}
+
+func TestBankQuestionRepository_AddToTest(t *testing.T) {
+	db := setupQuestionBankTestDB(t)
+	repo := NewBankQuestionRepository(db)
+	ctx := context.Background()
+	userID := uuid.New()
+	testID := uuid.New()
+
+	seedQuestionTest(t, db, testID)
+	seedQuestions(t, db, testID)
+	var existing int64
+	require.NoError(t, db.Model(&entity.Question{}).Where("test_id = ?", testID).Count(&existing).Error)
+
+	first := newBankQuestion(userID, "First", nil)
+	second := newBankQuestion(userID, "Second", nil)
+	require.NoError(t, repo.Create(ctx, first, second))
+
+	copies, err := repo.AddToTest(ctx, testID, []*entity.BankQuestion{second, first})
+	require.NoError(t, err)
+	require.Len(t, copies, 2)
+	assert.Equal(t, "Second", copies[0].QuestionText)
+	assert.Equal(t, int(existing)+1, copies[0].OrderNum)
+	assert.Equal(t, int(existing)+2, copies[1].OrderNum)
+
+	stored, err := NewQuestionRepository(db).FindByTestIDWithAnswers(ctx, testID)
+	require.NoError(t, err)
+	require.Len(t, stored, int(existing)+2)
+	last := stored[len(stored)-1]
+	assert.Equal(t, "First", last.QuestionText)
+	require.Len(t, last.Answers, 2)
+	assert.True(t, last.Answers[0].IsCorrect)
+
+	var total int
+	require.NoError(t, db.Raw("SELECT total_questions FROM tests WHERE id = ?", testID).Scan(&total).Error)
+	assert.Equal(t, int(existing)+2, total)
+
+	testIDs, err := repo.FindTestIDs(ctx, first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{testID}, testIDs)
+
+	// Editing the bank question does not touch the copy
+	first.QuestionText = "First, edited"
+	require.NoError(t, repo.Update(ctx, first))
+	stored, err = NewQuestionRepository(db).FindByTestIDWithAnswers(ctx, testID)
+	require.NoError(t, err)
+	assert.Equal(t, "First", stored[len(stored)-1].QuestionText)
+}
+
+func TestBankQuestionRepository_AddToTestRejectsDuplicates(t *testing.T) {
+	db := setupQuestionBankTestDB(t)
+	repo := NewBankQuestionRepository(db)
+	ctx := context.Background()
+	testID := uuid.New()
+	seedQuestionTest(t, db, testID)
+
+	question := newBankQuestion(uuid.New(), "Once", nil)
+	require.NoError(t, repo.Create(ctx, question))
+
+	copies, err := repo.AddToTest(ctx, testID, []*entity.BankQuestion{question})
+	require.NoError(t, err)
+
+	_, err = repo.AddToTest(ctx, testID, []*entity.BankQuestion{question})
+	assert.ErrorIs(t, err, repository.ErrBankQuestionInTest)
+
+	// Once the copy is removed from the test the question can be added again
+	require.NoError(t, db.Exec("DELETE FROM questions WHERE id = ?", copies[0].ID).Error)
+	_, err = repo.AddToTest(ctx, testID, []*entity.BankQuestion{question})
+	assert.NoError(t, err)
+
+	_, err = repo.AddToTest(ctx, uuid.New(), []*entity.BankQuestion{question})
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestBankQuestionRepository_DeleteKeepsCopies(t *testing.T) {
+	db := setupQuestionBankTestDB(t)
+	repo := NewBankQuestionRepository(db)
+	ctx := context.Background()
+	testID := uuid.New()
+	seedQuestionTest(t, db, testID)
+
+	question := newBankQuestion(uuid.New(), "Keep me", nil, "tag")
+	require.NoError(t, repo.Create(ctx, question))
+	_, err := repo.AddToTest(ctx, testID, []*entity.BankQuestion{question})
+	require.NoError(t, err)
+
+	require.NoError(t, repo.Delete(ctx, question.ID))
+
+	_, err = repo.FindByID(ctx, question.ID)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	var copies, links int64
+	require.NoError(t, db.Model(&entity.Question{}).Where("test_id = ?", testID).Count(&copies).Error)
+	require.NoError(t, db.Model(&entity.TestBankQuestion{}).Count(&links).Error)
+	assert.Equal(t, int64(1), copies)
+	assert.Equal(t, int64(0), links)
+}
+
+func TestBankQuestionRepository_FindMatchingIgnoresPaging(t *testing.T) {
+	db := setupQuestionBankTestDB(t)
+	repo := NewBankQuestionRepository(db)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, repo.Create(ctx, newBankQuestion(userID, "Matching", nil, "net")))
+	}
+	require.NoError(t, repo.Create(ctx, newBankQuestion(userID, "Untagged", nil)))
+
+	questions, err := repo.FindMatching(ctx, repository.BankQuestionFilter{UserID: userID, Tags: []string{"net"}, Limit: 1})
+	require.NoError(t, err)
+	assert.Len(t, questions, 3)
+	assert.Empty(t, questions[0].Answers, "candidates come without relations")
+}
+
+func TestBankQuestionRepository_CreateTest(t *testing.T) {
+	db := setupQuestionBankTestDB(t)
+	repo := NewBankQuestionRepository(db)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	bankQuestion := newBankQuestion(userID, "From bank", nil)
+	require.NoError(t, repo.Create(ctx, bankQuestion))
+
+	copied := bankQuestion.ToQuestion(uuid.Nil, 0)
+	copied.ID = uuid.New()
+	test := &entity.Test{UserID: userID, Title: "Assembled", Status: entity.TestStatusDraft,
+		Questions: []entity.Question{*copied, {QuestionText: "Generated", QuestionType: entity.QuestionTypeSingleChoice, Points: 1}}}
+	links := []entity.TestBankQuestion{{BankQuestionID: bankQuestion.ID, QuestionID: copied.ID}}
+
+	require.NoError(t, repo.CreateTest(ctx, test, links))
+
+	assert.Equal(t, 2, test.TotalQuestions)
+	stored, err := NewQuestionRepository(db).FindByTestIDWithAnswers(ctx, test.ID)
+	require.NoError(t, err)
+	require.Len(t, stored, 2)
+	assert.Equal(t, copied.ID, stored[0].ID)
+	assert.Len(t, stored[0].Answers, 2)
+	assert.Equal(t, 2, stored[1].OrderNum)
+
+	testIDs, err := repo.FindTestIDs(ctx, bankQuestion.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{test.ID}, testIDs)
+}
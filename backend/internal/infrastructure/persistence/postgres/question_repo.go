@@ -2,11 +2,13 @@ package postgres
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/shester1kov/testgen-backend/internal/domain/entity"
 	"github.com/shester1kov/testgen-backend/internal/domain/repository"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type questionRepository struct {
@@ -43,6 +45,21 @@ func (r *questionRepository) FindByTestID(ctx context.Context, testID uuid.UUID)
 	return questions, nil
 }
 
+func (r *questionRepository) FindByTestIDWithAnswers(ctx context.Context, testID uuid.UUID) ([]*entity.Question, error) {
+	var questions []*entity.Question
+	err := r.db.WithContext(ctx).
+		Preload("Answers", func(db *gorm.DB) *gorm.DB {
+			return db.Order("order_num ASC")
+		}).
+		Where("test_id = ?", testID).
+		Order("order_num ASC").
+		Find(&questions).Error
+	if err != nil {
+		return nil, err
+	}
+	return questions, nil
+}
+
 func (r *questionRepository) Update(ctx context.Context, question *entity.Question) error {
 	return r.db.WithContext(ctx).Save(question).Error
 }
@@ -60,8 +77,82 @@ func (r *questionRepository) CountByTestID(ctx context.Context, testID uuid.UUID
 	return int(count), err
 }
 
+func (r *questionRepository) CreateInTest(ctx context.Context, question *entity.Question) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockTest(tx, question.TestID); err != nil {
+			return err
+		}
+
+		var count int64
+		if err := tx.Model(&entity.Question{}).Where("test_id = ?", question.TestID).Count(&count).Error; err != nil {
+			return err
+		}
+
+		if question.OrderNum < 1 || question.OrderNum > int(count) {
+			question.OrderNum = int(count) + 1
+		} else if err := tx.Model(&entity.Question{}).
+			Where("test_id = ? AND order_num >= ?", question.TestID, question.OrderNum).
+			Update("order_num", gorm.Expr("order_num + 1")).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Omit(clause.Associations).Create(question).Error; err != nil {
+			return err
+		}
+		for i := range question.Answers {
+			question.Answers[i].QuestionID = question.ID
+		}
+		if len(question.Answers) > 0 {
+			if err := tx.Omit(clause.Associations).Create(&question.Answers).Error; err != nil {
+				return err
+			}
+		}
+
+		return updateTotalQuestions(tx, question.TestID)
+	})
+}
+
+func (r *questionRepository) DeleteFromTest(ctx context.Context, testID, questionID uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockTest(tx, testID); err != nil {
+			return err
+		}
+
+		var question entity.Question
+		if err := tx.Where("id = ? AND test_id = ?", questionID, testID).First(&question).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Delete(&entity.Answer{}, "question_id = ?", questionID).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&entity.Question{}, "id = ?", questionID).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&entity.Question{}).
+			Where("test_id = ? AND order_num > ?", testID, question.OrderNum).
+			Update("order_num", gorm.Expr("order_num - 1")).Error; err != nil {
+			return err
+		}
+
+		return updateTotalQuestions(tx, testID)
+	})
+}
+
 func (r *questionRepository) ReorderQuestions(ctx context.Context, testID uuid.UUID, questionIDs []uuid.UUID) error {
 	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockTest(tx, testID); err != nil {
+			return err
+		}
+
+		var existing []uuid.UUID
+		if err := tx.Model(&entity.Question{}).Where("test_id = ?", testID).Pluck("id", &existing).Error; err != nil {
+			return err
+		}
+		if !sameQuestionSet(existing, questionIDs) {
+			return repository.ErrQuestionSetMismatch
+		}
+
 		for i, qid := range questionIDs {
 			if err := tx.Model(&entity.Question{}).
 				Where("id = ? AND test_id = ?", qid, testID).
@@ -73,6 +164,56 @@ func (r *questionRepository) ReorderQuestions(ctx context.Context, testID uuid.U
 	})
 }
 
+// lockTest touches the test row so that concurrent question changes of the
+// same test are serialized until the transaction ends. Only drafts can be
+// changed; other tests fail with repository.ErrTestLocked.
+func lockTest(tx *gorm.DB, testID uuid.UUID) error {
+	result := tx.Model(&entity.Test{}).
+		Where("id = ? AND deleted_at IS NULL", testID).
+		Update("updated_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	// Checked under the lock, so a concurrent publish cannot slip in before the change
+	var test entity.Test
+	if err := tx.Select("status").Where("id = ?", testID).First(&test).Error; err != nil {
+		return err
+	}
+	if !test.IsEditable() {
+		return repository.ErrTestLocked
+	}
+	return nil
+}
+
+func updateTotalQuestions(tx *gorm.DB, testID uuid.UUID) error {
+	var count int64
+	if err := tx.Model(&entity.Question{}).Where("test_id = ?", testID).Count(&count).Error; err != nil {
+		return err
+	}
+	return tx.Model(&entity.Test{}).Where("id = ?", testID).Update("total_questions", count).Error
+}
+
+func sameQuestionSet(existing, requested []uuid.UUID) bool {
+	if len(existing) != len(requested) {
+		return false
+	}
+	seen := make(map[uuid.UUID]bool, len(existing))
+	for _, id := range existing {
+		seen[id] = true
+	}
+	for _, id := range requested {
+		if !seen[id] {
+			return false
+		}
+		delete(seen, id)
+	}
+	return true
+}
+
 func (r *questionRepository) CountByUserID(ctx context.Context, userID uuid.UUID) (int64, error) {
 	var count int64
 	err := r.db.WithContext(ctx).
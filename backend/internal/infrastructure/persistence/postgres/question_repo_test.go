@@ -2,18 +2,20 @@ package postgres
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/shester1kov/testgen-backend/internal/domain/entity"
+	"github.com/shester1kov/testgen-backend/internal/domain/repository"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
 
-func setupQuestionTestDB(t *testing.T) *gorm.DB {
+func setupQuestionTestDB(t testing.TB) *gorm.DB {
 	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{SkipDefaultTransaction: true})
 	require.NoError(t, err)
 
@@ -24,6 +26,7 @@ func setupQuestionTestDB(t *testing.T) *gorm.DB {
                         document_id TEXT,
                         title TEXT,
                         status TEXT,
+                        total_questions INTEGER DEFAULT 0,
                         deleted_at DATETIME,
                         created_at DATETIME,
                         updated_at DATETIME
@@ -39,6 +42,7 @@ func setupQuestionTestDB(t *testing.T) *gorm.DB {
                         question_type TEXT,
                         difficulty TEXT,
                         points REAL,
+                        case_sensitive BOOLEAN DEFAULT FALSE,
                         order_num INTEGER,
                         created_at DATETIME,
                         updated_at DATETIME
@@ -46,9 +50,28 @@ func setupQuestionTestDB(t *testing.T) *gorm.DB {
         `).Error
 	require.NoError(t, err)
 
+	err = db.Exec(`
+                CREATE TABLE answers (
+                        id TEXT PRIMARY KEY,
+                        question_id TEXT NOT NULL,
+                        answer_text TEXT NOT NULL,
+                        is_correct BOOLEAN,
+                        match_text TEXT,
+                        tolerance REAL,
+                        weight REAL,
+                        order_num INTEGER,
+                        created_at DATETIME
+                );
+        `).Error
+	require.NoError(t, err)
+
 	return db
 }
 
+func seedQuestionTest(t *testing.T, db *gorm.DB, testID uuid.UUID) {
+	require.NoError(t, db.Exec("INSERT INTO tests (id, title, total_questions) VALUES (?, ?, ?)", testID, "Test", 0).Error)
+}
+
 func seedQuestions(t *testing.T, db *gorm.DB, testID uuid.UUID) []*entity.Question {
 	baseTime := time.Time{}
 	questions := []*entity.Question{
@@ -136,6 +159,7 @@ func TestQuestionRepository_ReorderQuestions(t *testing.T) {
 	db := setupQuestionTestDB(t)
 	repo := NewQuestionRepository(db)
 	testID := uuid.New()
+	seedQuestionTest(t, db, testID)
 	questions := seedQuestions(t, db, testID)
 
 	newOrder := []uuid.UUID{questions[0].ID, questions[1].ID}
@@ -147,6 +171,14 @@ func TestQuestionRepository_ReorderQuestions(t *testing.T) {
 	assert.Equal(t, 1, reordered[0].OrderNum)
 	assert.Equal(t, questions[0].ID, reordered[0].ID)
 
+	// the list must name every question of the test exactly once
+	err = repo.ReorderQuestions(context.Background(), testID, []uuid.UUID{questions[0].ID})
+	assert.ErrorIs(t, err, repository.ErrQuestionSetMismatch)
+	err = repo.ReorderQuestions(context.Background(), testID, []uuid.UUID{questions[0].ID, questions[0].ID})
+	assert.ErrorIs(t, err, repository.ErrQuestionSetMismatch)
+	err = repo.ReorderQuestions(context.Background(), testID, []uuid.UUID{questions[0].ID, uuid.New()})
+	assert.ErrorIs(t, err, repository.ErrQuestionSetMismatch)
+
 	// cause error by dropping table inside transaction
 	require.NoError(t, db.Exec("DROP TABLE questions;").Error)
 	err = repo.ReorderQuestions(context.Background(), testID, newOrder)
@@ -219,3 +251,221 @@ func TestQuestionRepository_Delete(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, 0, count)
 }
+
+func newTestQuestion(testID uuid.UUID, text string, orderNum int) *entity.Question {
+	return &entity.Question{
+		ID:           uuid.New(),
+		TestID:       testID,
+		QuestionText: text,
+		QuestionType: entity.QuestionTypeSingleChoice,
+		Difficulty:   entity.DifficultyMedium,
+		Points:       1,
+		OrderNum:     orderNum,
+		Answers: []entity.Answer{
+			{ID: uuid.New(), AnswerText: "yes", IsCorrect: true, OrderNum: 1},
+			{ID: uuid.New(), AnswerText: "no", OrderNum: 2},
+		},
+	}
+}
+
+func questionOrder(t *testing.T, db *gorm.DB, testID uuid.UUID) []string {
+	var texts []string
+	require.NoError(t, db.Model(&entity.Question{}).Where("test_id = ?", testID).Order("order_num").Pluck("question_text", &texts).Error)
+	return texts
+}
+
+func totalQuestions(t *testing.T, db *gorm.DB, testID uuid.UUID) int {
+	var total int
+	require.NoError(t, db.Raw("SELECT total_questions FROM tests WHERE id = ?", testID).Scan(&total).Error)
+	return total
+}
+
+func TestQuestionRepository_CreateInTest(t *testing.T) {
+	db := setupQuestionTestDB(t)
+	repo := NewQuestionRepository(db)
+	ctx := context.Background()
+	testID := uuid.New()
+	seedQuestionTest(t, db, testID)
+
+	require.NoError(t, repo.CreateInTest(ctx, newTestQuestion(testID, "A", 0)))
+	require.NoError(t, repo.CreateInTest(ctx, newTestQuestion(testID, "C", 99)))
+
+	inserted := newTestQuestion(testID, "B", 2)
+	require.NoError(t, repo.CreateInTest(ctx, inserted))
+
+	assert.Equal(t, []string{"A", "B", "C"}, questionOrder(t, db, testID))
+	assert.Equal(t, 3, totalQuestions(t, db, testID))
+	assert.Equal(t, 2, inserted.OrderNum)
+
+	var answers []entity.Answer
+	require.NoError(t, db.Where("question_id = ?", inserted.ID).Find(&answers).Error)
+	assert.Len(t, answers, 2)
+
+	err := repo.CreateInTest(ctx, newTestQuestion(uuid.New(), "orphan", 0))
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestQuestionRepository_RefusesChangesToLockedTest(t *testing.T) {
+	db := setupQuestionTestDB(t)
+	repo := NewQuestionRepository(db)
+	ctx := context.Background()
+	testID := uuid.New()
+	seedQuestionTest(t, db, testID)
+	questions := seedQuestions(t, db, testID)
+	require.NoError(t, db.Exec("UPDATE tests SET status = ? WHERE id = ?", entity.TestStatusPublished, testID).Error)
+
+	assert.ErrorIs(t, repo.CreateInTest(ctx, newTestQuestion(testID, "late", 0)), repository.ErrTestLocked)
+	assert.ErrorIs(t, repo.DeleteFromTest(ctx, testID, questions[0].ID), repository.ErrTestLocked)
+	assert.ErrorIs(t, repo.ReorderQuestions(ctx, testID, []uuid.UUID{questions[0].ID, questions[1].ID}), repository.ErrTestLocked)
+
+	assert.Equal(t, []string{"Q2", "Q1"}, questionOrder(t, db, testID))
+}
+
+func TestQuestionRepository_StoresMatchTextAndTolerance(t *testing.T) {
+	db := setupQuestionTestDB(t)
+	repo := NewQuestionRepository(db)
+	ctx := context.Background()
+	testID := uuid.New()
+	seedQuestionTest(t, db, testID)
+
+	tolerance := 0.5
+	matching := &entity.Question{ID: uuid.New(), TestID: testID, QuestionText: "Match", QuestionType: entity.QuestionTypeMatching,
+		Answers: []entity.Answer{{AnswerText: "Go", MatchText: "Google"}, {AnswerText: "C#", MatchText: "Microsoft"}}}
+	numerical := &entity.Question{ID: uuid.New(), TestID: testID, QuestionText: "Boiling point", QuestionType: entity.QuestionTypeNumerical,
+		Answers: []entity.Answer{{AnswerText: "100", IsCorrect: true, Tolerance: &tolerance}}}
+	require.NoError(t, repo.CreateInTest(ctx, matching))
+	require.NoError(t, repo.CreateInTest(ctx, numerical))
+
+	questions, err := repo.FindByTestIDWithAnswers(ctx, testID)
+	require.NoError(t, err)
+	require.Len(t, questions, 2)
+	assert.Equal(t, "Microsoft", questions[0].Answers[1].MatchText)
+	assert.Nil(t, questions[0].Answers[0].Tolerance)
+	require.NotNil(t, questions[1].Answers[0].Tolerance)
+	assert.Equal(t, 0.5, *questions[1].Answers[0].Tolerance)
+}
+
+func TestQuestionRepository_CreateInTestRollsBack(t *testing.T) {
+	db := setupQuestionTestDB(t)
+	repo := NewQuestionRepository(db)
+	ctx := context.Background()
+	testID := uuid.New()
+	seedQuestionTest(t, db, testID)
+	require.NoError(t, repo.CreateInTest(ctx, newTestQuestion(testID, "A", 0)))
+
+	require.NoError(t, db.Exec("DROP TABLE answers;").Error)
+	err := repo.CreateInTest(ctx, newTestQuestion(testID, "B", 1))
+
+	assert.Error(t, err)
+	assert.Equal(t, []string{"A"}, questionOrder(t, db, testID), "the shifted order and the question must be rolled back")
+	assert.Equal(t, 1, totalQuestions(t, db, testID))
+}
+
+func TestQuestionRepository_DeleteFromTest(t *testing.T) {
+	db := setupQuestionTestDB(t)
+	repo := NewQuestionRepository(db)
+	ctx := context.Background()
+	testID := uuid.New()
+	seedQuestionTest(t, db, testID)
+
+	first := newTestQuestion(testID, "A", 0)
+	second := newTestQuestion(testID, "B", 0)
+	require.NoError(t, repo.CreateInTest(ctx, first))
+	require.NoError(t, repo.CreateInTest(ctx, second))
+	require.NoError(t, repo.CreateInTest(ctx, newTestQuestion(testID, "C", 0)))
+
+	require.NoError(t, repo.DeleteFromTest(ctx, testID, second.ID))
+
+	var orders []int
+	require.NoError(t, db.Model(&entity.Question{}).Where("test_id = ?", testID).Order("order_num").Pluck("order_num", &orders).Error)
+	assert.Equal(t, []int{1, 2}, orders)
+	assert.Equal(t, []string{"A", "C"}, questionOrder(t, db, testID))
+	assert.Equal(t, 2, totalQuestions(t, db, testID))
+
+	var answers int64
+	require.NoError(t, db.Model(&entity.Answer{}).Where("question_id = ?", second.ID).Count(&answers).Error)
+	assert.Zero(t, answers)
+
+	// a question of another test is not found
+	otherTest := uuid.New()
+	seedQuestionTest(t, db, otherTest)
+	err := repo.DeleteFromTest(ctx, otherTest, first.ID)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+// countQueries counts the SELECT statements run through db, preload queries
+// included
+func countQueries(t testing.TB, db *gorm.DB) *int {
+	var count int
+	require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:count_queries", func(*gorm.DB) {
+		count++
+	}))
+	return &count
+}
+
+func seedQuestionsWithAnswers(t testing.TB, db *gorm.DB, testID uuid.UUID, n int) {
+	for i := 1; i <= n; i++ {
+		require.NoError(t, db.Create(newTestQuestion(testID, fmt.Sprintf("Q%d", i), i)).Error)
+	}
+}
+
+func TestQuestionRepository_FindByTestIDWithAnswers(t *testing.T) {
+	db := setupQuestionTestDB(t)
+	repo := NewQuestionRepository(db)
+	testID := uuid.New()
+	seedQuestionsWithAnswers(t, db, testID, 3)
+	seedQuestionsWithAnswers(t, db, uuid.New(), 1)
+
+	questions, err := repo.FindByTestIDWithAnswers(context.Background(), testID)
+	require.NoError(t, err)
+	require.Len(t, questions, 3)
+	for i, q := range questions {
+		assert.Equal(t, fmt.Sprintf("Q%d", i+1), q.QuestionText)
+		require.Len(t, q.Answers, 2)
+		assert.Equal(t, "yes", q.Answers[0].AnswerText)
+		assert.Equal(t, "no", q.Answers[1].AnswerText)
+		assert.Equal(t, q.ID, q.Answers[0].QuestionID)
+	}
+
+	empty, err := repo.FindByTestIDWithAnswers(context.Background(), uuid.New())
+	require.NoError(t, err)
+	assert.Empty(t, empty)
+}
+
+func TestQuestionRepository_FindByTestIDWithAnswersQueryCount(t *testing.T) {
+	queriesFor := func(n int) int {
+		db := setupQuestionTestDB(t)
+		testID := uuid.New()
+		seedQuestionsWithAnswers(t, db, testID, n)
+		queries := countQueries(t, db)
+
+		questions, err := NewQuestionRepository(db).FindByTestIDWithAnswers(context.Background(), testID)
+		require.NoError(t, err)
+		require.Len(t, questions, n)
+		return *queries
+	}
+
+	assert.Equal(t, 2, queriesFor(1), "one query for questions and one for their answers")
+	assert.Equal(t, queriesFor(1), queriesFor(50))
+}
+
+func BenchmarkQuestionRepository_FindByTestIDWithAnswers(b *testing.B) {
+	for _, n := range []int{10, 100, 500} {
+		b.Run(fmt.Sprintf("questions=%d", n), func(b *testing.B) {
+			db := setupQuestionTestDB(b)
+			repo := NewQuestionRepository(db)
+			testID := uuid.New()
+			seedQuestionsWithAnswers(b, db, testID, n)
+			queries := countQueries(b, db)
+			ctx := context.Background()
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := repo.FindByTestIDWithAnswers(ctx, testID); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(*queries)/float64(b.N), "queries/op")
+		})
+	}
+}
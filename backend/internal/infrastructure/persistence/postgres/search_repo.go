@@ -0,0 +1,182 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shester1kov/testgen-backend/internal/domain/entity"
+	"github.com/shester1kov/testgen-backend/internal/domain/repository"
+	"gorm.io/gorm"
+)
+
+// Markers ts_headline puts around matches. Private-use characters cannot occur in
+// real text, so the snippet can be HTML-escaped before they become <mark> tags.
+const (
+	highlightStart = "\ue000"
+	highlightStop  = "\ue001"
+)
+
+// Snippets are built from at most this many characters of the matched text;
+// ts_headline re-parses the whole input, which is slow for long documents
+const snippetSourceLimit = 100000
+
+// searchTSQuery matches both russian and english word forms, see migration 000008
+const searchTSQuery = `SELECT websearch_to_tsquery('russian'::regconfig, @query) || websearch_to_tsquery('english'::regconfig, @query) AS query`
+
+var searchBranchSQL = map[entity.SearchResultType]struct {
+	query     string
+	ownerExpr string
+}{
+	entity.SearchResultDocument: {
+		query: `SELECT 'document' AS type, d.id, d.user_id, NULL::uuid AS test_id, d.title,
+			ts_rank(d.search_vector, q.query) AS rank, d.created_at
+			FROM documents d CROSS JOIN q
+			WHERE d.deleted_at IS NULL AND d.search_vector @@ q.query`,
+		ownerExpr: "d.user_id",
+	},
+	entity.SearchResultTest: {
+		query: `SELECT 'test' AS type, t.id, t.user_id, NULL::uuid AS test_id, t.title,
+			ts_rank(t.search_vector, q.query) AS rank, t.created_at
+			FROM tests t CROSS JOIN q
+			WHERE t.deleted_at IS NULL AND t.search_vector @@ q.query`,
+		ownerExpr: "t.user_id",
+	},
+	entity.SearchResultQuestion: {
+		query: `SELECT 'question' AS type, qu.id, t.user_id, t.id AS test_id, t.title,
+			ts_rank(qu.search_vector, q.query) AS rank, qu.created_at
+			FROM questions qu JOIN tests t ON t.id = qu.test_id CROSS JOIN q
+			WHERE t.deleted_at IS NULL AND qu.search_vector @@ q.query`,
+		ownerExpr: "t.user_id",
+	},
+}
+
+// searchOrder lists result types in a stable order for building the query
+var searchOrder = []entity.SearchResultType{
+	entity.SearchResultDocument,
+	entity.SearchResultTest,
+	entity.SearchResultQuestion,
+}
+
+type searchRepository struct {
+	db *gorm.DB
+}
+
+// NewSearchRepository creates a new full-text search repository
+func NewSearchRepository(db *gorm.DB) repository.SearchRepository {
+	return &searchRepository{db: db}
+}
+
+type searchRow struct {
+	Type      string
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	TestID    *uuid.UUID
+	Title     string
+	Snippet   string
+	Rank      float64
+	CreatedAt time.Time
+}
+
+func (r *searchRepository) Search(ctx context.Context, query repository.SearchQuery) ([]*entity.SearchResult, int64, error) {
+	hits := buildSearchHits(query)
+	args := map[string]interface{}{
+		"query":   query.Text,
+		"limit":   query.Limit,
+		"offset":  query.Offset,
+		"options": headlineOptions(),
+	}
+	if query.UserID != nil {
+		args["user_id"] = *query.UserID
+	}
+
+	var total int64
+	err := r.db.WithContext(ctx).
+		Raw(`WITH q AS (`+searchTSQuery+`) SELECT count(*) FROM (`+hits+`) hits`, args).
+		Scan(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	if total == 0 || query.Offset >= int(total) {
+		return []*entity.SearchResult{}, total, nil
+	}
+
+	// Snippets are computed only for the requested page
+	var rows []searchRow
+	err = r.db.WithContext(ctx).Raw(`WITH q AS (`+searchTSQuery+`), hits AS (`+hits+`)
+		SELECT h.type, h.id, h.user_id, h.test_id, h.title, h.rank, h.created_at,
+			ts_headline('russian'::regconfig, left(coalesce(CASE h.type
+				WHEN 'document' THEN (SELECT CASE WHEN d.text_revision > 0 THEN d.corrected_text ELSE d.parsed_text END FROM documents d WHERE d.id = h.id)
+				WHEN 'test' THEN (SELECT NULLIF(t.description, '') FROM tests t WHERE t.id = h.id)
+				ELSE (SELECT qu.question_text FROM questions qu WHERE qu.id = h.id)
+			END, h.title), `+fmt.Sprint(snippetSourceLimit)+`), q.query, @options) AS snippet
+		FROM (SELECT * FROM hits ORDER BY rank DESC, created_at DESC, id LIMIT @limit OFFSET @offset) h
+		CROSS JOIN q
+		ORDER BY h.rank DESC, h.created_at DESC, h.id`, args).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	results := make([]*entity.SearchResult, len(rows))
+	for i, row := range rows {
+		results[i] = &entity.SearchResult{
+			Type:      entity.SearchResultType(row.Type),
+			ID:        row.ID,
+			UserID:    row.UserID,
+			TestID:    row.TestID,
+			Title:     row.Title,
+			Snippet:   highlightSnippet(row.Snippet),
+			Rank:      row.Rank,
+			CreatedAt: row.CreatedAt,
+		}
+	}
+	return results, total, nil
+}
+
+// buildSearchHits builds a UNION of the per-type queries selected by the search
+func buildSearchHits(query repository.SearchQuery) string {
+	types := query.Types
+	if len(types) == 0 {
+		types = searchOrder
+	}
+
+	var branches []string
+	for _, resultType := range searchOrder {
+		if !containsSearchType(types, resultType) {
+			continue
+		}
+		branch := searchBranchSQL[resultType]
+		sql := branch.query
+		if query.UserID != nil {
+			sql += " AND " + branch.ownerExpr + " = @user_id"
+		}
+		branches = append(branches, sql)
+	}
+	return strings.Join(branches, "\nUNION ALL\n")
+}
+
+func containsSearchType(types []entity.SearchResultType, resultType entity.SearchResultType) bool {
+	for _, t := range types {
+		if t == resultType {
+			return true
+		}
+	}
+	return false
+}
+
+// headlineOptions configures ts_headline fragments
+func headlineOptions() string {
+	return fmt.Sprintf(`StartSel="%s", StopSel="%s", MaxWords=35, MinWords=15, MaxFragments=2, FragmentDelimiter=" ... "`,
+		highlightStart, highlightStop)
+}
+
+// highlightSnippet escapes user content and turns match markers into <mark> tags
+func highlightSnippet(snippet string) string {
+	escaped := html.EscapeString(snippet)
+	escaped = strings.ReplaceAll(escaped, highlightStart, "<mark>")
+	return strings.ReplaceAll(escaped, highlightStop, "</mark>")
+}
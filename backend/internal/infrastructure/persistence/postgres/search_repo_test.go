@@ -0,0 +1,37 @@
+package postgres
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/shester1kov/testgen-backend/internal/domain/entity"
+	"github.com/shester1kov/testgen-backend/internal/domain/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+// Full-text search relies on Postgres tsvector support, which the sqlite test
+// database lacks, so only query building and snippet handling are tested here.
+
+func TestBuildSearchHits_SelectsTypesAndOwner(t *testing.T) {
+	all := buildSearchHits(repository.SearchQuery{Text: "go"})
+	assert.Equal(t, 2, strings.Count(all, "UNION ALL"))
+	assert.NotContains(t, all, "@user_id")
+
+	userID := uuid.New()
+	owned := buildSearchHits(repository.SearchQuery{
+		Text:   "go",
+		UserID: &userID,
+		Types:  []entity.SearchResultType{entity.SearchResultQuestion},
+	})
+	assert.NotContains(t, owned, "UNION ALL")
+	assert.Contains(t, owned, "FROM questions qu")
+	assert.Contains(t, owned, "t.user_id = @user_id")
+}
+
+func TestHighlightSnippet_EscapesContent(t *testing.T) {
+	snippet := "<script>alert(1)</script> about " + highlightStart + "goroutines" + highlightStop
+	assert.Equal(t,
+		"&lt;script&gt;alert(1)&lt;/script&gt; about <mark>goroutines</mark>",
+		highlightSnippet(snippet))
+}
@@ -2,13 +2,18 @@ package postgres
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/shester1kov/testgen-backend/internal/domain/entity"
 	"github.com/shester1kov/testgen-backend/internal/domain/repository"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// insertBatchSize bounds the rows sent in one INSERT when saving a test aggregate
+const insertBatchSize = 100
+
 type testRepository struct {
 	db *gorm.DB
 }
@@ -22,13 +27,82 @@ func (r *testRepository) Create(ctx context.Context, test *entity.Test) error {
 	return r.db.WithContext(ctx).Create(test).Error
 }
 
+func (r *testRepository) CreateWithQuestions(ctx context.Context, test *entity.Test) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return createTestWithQuestions(tx, test)
+	})
+}
+
+// createTestWithQuestions inserts a new test with its questions and answers,
+// numbering them in slice order and filling in missing IDs
+func createTestWithQuestions(tx *gorm.DB, test *entity.Test) error {
+	now := time.Now()
+	if test.ID == uuid.Nil {
+		test.ID = uuid.New()
+	}
+	test.TotalQuestions = len(test.Questions)
+
+	var answers []entity.Answer
+	for i := range test.Questions {
+		question := &test.Questions[i]
+		if question.ID == uuid.Nil {
+			question.ID = uuid.New()
+		}
+		question.TestID = test.ID
+		question.OrderNum = i + 1
+		for j := range question.Answers {
+			answer := &question.Answers[j]
+			if answer.ID == uuid.Nil {
+				answer.ID = uuid.New()
+			}
+			answer.QuestionID = question.ID
+			answer.OrderNum = j + 1
+			if answer.CreatedAt.IsZero() {
+				answer.CreatedAt = now
+			}
+			answers = append(answers, *answer)
+		}
+	}
+
+	if err := tx.Omit(clause.Associations).Create(test).Error; err != nil {
+		return err
+	}
+	if len(test.Questions) > 0 {
+		if err := tx.Omit(clause.Associations).CreateInBatches(&test.Questions, insertBatchSize).Error; err != nil {
+			return err
+		}
+	}
+	if len(answers) > 0 {
+		if err := tx.Omit(clause.Associations).CreateInBatches(&answers, insertBatchSize).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (r *testRepository) FindByID(ctx context.Context, id uuid.UUID) (*entity.Test, error) {
 	var test entity.Test
 	err := r.db.WithContext(ctx).
 		Preload("User").
 		Preload("Document").
-		Preload("Questions").
-		Preload("Questions.Answers").
+		Preload("Questions", func(db *gorm.DB) *gorm.DB {
+			return db.Order("order_num ASC")
+		}).
+		Preload("Questions.Answers", func(db *gorm.DB) *gorm.DB {
+			return db.Order("order_num ASC")
+		}).
+		Where("id = ? AND deleted_at IS NULL", id).
+		First(&test).Error
+	if err != nil {
+		return nil, err
+	}
+	return &test, nil
+}
+
+func (r *testRepository) FindByIDForUpdate(ctx context.Context, id uuid.UUID) (*entity.Test, error) {
+	var test entity.Test
+	err := r.db.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
 		Where("id = ? AND deleted_at IS NULL", id).
 		First(&test).Error
 	if err != nil {
@@ -53,6 +127,18 @@ func (r *testRepository) Update(ctx context.Context, test *entity.Test) error {
 	return r.db.WithContext(ctx).Save(test).Error
 }
 
+func (r *testRepository) UpdateStatus(ctx context.Context, test *entity.Test) error {
+	return r.db.WithContext(ctx).
+		Model(&entity.Test{}).
+		Where("id = ? AND deleted_at IS NULL", test.ID).
+		Updates(map[string]interface{}{
+			"status":       test.Status,
+			"version":      test.Version,
+			"published_at": test.PublishedAt,
+			"updated_at":   test.UpdatedAt,
+		}).Error
+}
+
 func (r *testRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return r.db.WithContext(ctx).
 		Model(&entity.Test{}).
@@ -90,3 +176,60 @@ func (r *testRepository) CountAll(ctx context.Context) (int64, error) {
 		Count(&count).Error
 	return count, err
 }
+
+func (r *testRepository) FindPublishedByStudentID(ctx context.Context, studentID uuid.UUID, limit, offset int) ([]*entity.Test, error) {
+	var tests []*entity.Test
+	err := r.publishedForStudent(ctx, studentID).
+		Limit(limit).
+		Offset(offset).
+		Order("tests.created_at DESC").
+		Find(&tests).Error
+	return tests, err
+}
+
+func (r *testRepository) CountPublishedByStudentID(ctx context.Context, studentID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.publishedForStudent(ctx, studentID).Count(&count).Error
+	return count, err
+}
+
+// publishedForStudent selects the published tests assigned to the student
+func (r *testRepository) publishedForStudent(ctx context.Context, studentID uuid.UUID) *gorm.DB {
+	return r.db.WithContext(ctx).
+		Model(&entity.Test{}).
+		Joins("JOIN test_assignments ON test_assignments.test_id = tests.id").
+		Where("test_assignments.student_id = ? AND tests.status = ? AND tests.deleted_at IS NULL", studentID, entity.TestStatusPublished)
+}
+
+func (r *testRepository) AssignStudents(ctx context.Context, assignments []entity.TestAssignment) error {
+	if len(assignments) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).
+		Omit(clause.Associations).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&assignments).Error
+}
+
+func (r *testRepository) UnassignStudent(ctx context.Context, testID, studentID uuid.UUID) error {
+	result := r.db.WithContext(ctx).
+		Where("test_id = ? AND student_id = ?", testID, studentID).
+		Delete(&entity.TestAssignment{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *testRepository) FindAssignments(ctx context.Context, testID uuid.UUID) ([]*entity.TestAssignment, error) {
+	var assignments []*entity.TestAssignment
+	err := r.db.WithContext(ctx).
+		Preload("Student").
+		Where("test_id = ?", testID).
+		Order("created_at ASC").
+		Find(&assignments).Error
+	return assignments, err
+}
@@ -41,6 +41,9 @@ func setupTestRepoDB(t *testing.T) *gorm.DB {
                         status TEXT,
                         moodle_synced BOOLEAN,
                         moodle_test_id TEXT,
+                        version INTEGER DEFAULT 1,
+                        previous_version_id TEXT,
+                        published_at DATETIME,
                         created_at DATETIME,
                         updated_at DATETIME,
                         deleted_at DATETIME
@@ -62,7 +65,13 @@ func setupTestRepoDB(t *testing.T) *gorm.DB {
                         id TEXT PRIMARY KEY,
                         test_id TEXT,
                         question_text TEXT,
-                        order_num INTEGER
+                        question_type TEXT,
+                        difficulty TEXT,
+                        points REAL,
+                        case_sensitive BOOLEAN DEFAULT FALSE,
+                        order_num INTEGER,
+                        created_at DATETIME,
+                        updated_at DATETIME
                 );
         `).Error
 	require.NoError(t, err)
@@ -73,7 +82,22 @@ func setupTestRepoDB(t *testing.T) *gorm.DB {
                         question_id TEXT,
                         answer_text TEXT,
                         is_correct BOOLEAN,
-                        order_num INTEGER
+                        match_text TEXT,
+                        tolerance REAL,
+                        weight REAL,
+                        order_num INTEGER,
+                        created_at DATETIME
+                );
+        `).Error
+	require.NoError(t, err)
+
+	err = db.Exec(`
+                CREATE TABLE test_assignments (
+                        test_id TEXT,
+                        student_id TEXT,
+                        assigned_by TEXT,
+                        created_at DATETIME,
+                        PRIMARY KEY (test_id, student_id)
                 );
         `).Error
 	require.NoError(t, err)
@@ -136,6 +160,61 @@ func TestTestRepository_CreateAndFetch(t *testing.T) {
 	assert.EqualValues(t, 0, count)
 }
 
+func TestTestRepository_FindByIDOrdersQuestions(t *testing.T) {
+	db := setupTestRepoDB(t)
+	repo := NewTestRepository(db)
+	test := seedTest(t, db, false)
+
+	first, second := uuid.New(), uuid.New()
+	require.NoError(t, db.Exec("INSERT INTO questions (id, test_id, question_text, order_num) VALUES (?, ?, 'Q2', 2), (?, ?, 'Q1', 1)",
+		second.String(), test.ID.String(), first.String(), test.ID.String()).Error)
+	require.NoError(t, db.Exec("INSERT INTO answers (id, question_id, answer_text, order_num) VALUES (?, ?, 'B', 2), (?, ?, 'A', 1)",
+		uuid.New().String(), first.String(), uuid.New().String(), first.String()).Error)
+
+	fetched, err := repo.FindByID(context.Background(), test.ID)
+	require.NoError(t, err)
+	require.Len(t, fetched.Questions, 2)
+	assert.Equal(t, "Q1", fetched.Questions[0].QuestionText)
+	assert.Equal(t, "Q2", fetched.Questions[1].QuestionText)
+	require.Len(t, fetched.Questions[0].Answers, 2)
+	assert.Equal(t, "A", fetched.Questions[0].Answers[0].AnswerText)
+	assert.Equal(t, "B", fetched.Questions[0].Answers[1].AnswerText)
+}
+
+func TestTestRepository_FindByIDForUpdate(t *testing.T) {
+	db := setupTestRepoDB(t)
+	repo := NewTestRepository(db)
+	test := seedTest(t, db, true)
+
+	locked, err := repo.FindByIDForUpdate(context.Background(), test.ID)
+	require.NoError(t, err)
+	assert.Equal(t, test.Title, locked.Title)
+	assert.Nil(t, locked.Document, "relations are not loaded")
+
+	require.NoError(t, repo.Delete(context.Background(), test.ID))
+	_, err = repo.FindByIDForUpdate(context.Background(), test.ID)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestTestRepository_UpdateStatus(t *testing.T) {
+	db := setupTestRepoDB(t)
+	repo := NewTestRepository(db)
+	test := seedTest(t, db, false)
+
+	// a stale copy must not roll back columns changed by someone else
+	require.NoError(t, db.Exec("UPDATE tests SET title = 'Renamed', total_questions = 5 WHERE id = ?", test.ID.String()).Error)
+	require.NoError(t, test.Publish())
+	test.UpdatedAt = time.Now()
+	require.NoError(t, repo.UpdateStatus(context.Background(), test))
+
+	var stored entity.Test
+	require.NoError(t, db.First(&stored, "id = ?", test.ID).Error)
+	assert.Equal(t, entity.TestStatusPublished, stored.Status)
+	assert.NotNil(t, stored.PublishedAt)
+	assert.Equal(t, "Renamed", stored.Title)
+	assert.Equal(t, 5, stored.TotalQuestions)
+}
+
 func TestTestRepository_ErrorBranches(t *testing.T) {
 	db := setupTestRepoDB(t)
 	repo := NewTestRepository(db)
@@ -178,3 +257,108 @@ func TestTestRepository_Create(t *testing.T) {
 	assert.Equal(t, newTest.Description, fetched.Description)
 	assert.Equal(t, newTest.TotalQuestions, fetched.TotalQuestions)
 }
+
+func newTestAggregate(userID uuid.UUID, questions int) *entity.Test {
+	test := &entity.Test{UserID: userID, Title: "Aggregate", Status: entity.TestStatusDraft}
+	for i := 0; i < questions; i++ {
+		test.Questions = append(test.Questions, entity.Question{
+			QuestionText: "Question",
+			QuestionType: entity.QuestionTypeTrueFalse,
+			Difficulty:   entity.DifficultyEasy,
+			Points:       1,
+			Answers: []entity.Answer{
+				{AnswerText: "True", IsCorrect: true},
+				{AnswerText: "False"},
+			},
+		})
+	}
+	return test
+}
+
+func TestTestRepository_CreateWithQuestions(t *testing.T) {
+	db := setupTestRepoDB(t)
+	repo := NewTestRepository(db)
+
+	// more questions than one batch holds
+	test := newTestAggregate(uuid.New(), insertBatchSize+5)
+	require.NoError(t, repo.CreateWithQuestions(context.Background(), test))
+
+	assert.NotEqual(t, uuid.Nil, test.ID)
+	assert.Equal(t, insertBatchSize+5, test.TotalQuestions)
+
+	var stored entity.Test
+	require.NoError(t, db.First(&stored, "id = ?", test.ID).Error)
+	assert.Equal(t, insertBatchSize+5, stored.TotalQuestions)
+
+	var orders []int
+	require.NoError(t, db.Model(&entity.Question{}).Where("test_id = ?", test.ID).Order("order_num").Pluck("order_num", &orders).Error)
+	require.Len(t, orders, insertBatchSize+5)
+	assert.Equal(t, 1, orders[0])
+	assert.Equal(t, insertBatchSize+5, orders[len(orders)-1])
+
+	var answers []entity.Answer
+	require.NoError(t, db.Where("question_id = ?", test.Questions[0].ID).Order("order_num").Find(&answers).Error)
+	require.Len(t, answers, 2)
+	assert.Equal(t, "True", answers[0].AnswerText)
+	assert.True(t, answers[0].IsCorrect)
+}
+
+func TestTestRepository_CreateWithQuestionsRollsBack(t *testing.T) {
+	db := setupTestRepoDB(t)
+	repo := NewTestRepository(db)
+	require.NoError(t, db.Exec("DROP TABLE answers;").Error)
+
+	test := newTestAggregate(uuid.New(), 3)
+	err := repo.CreateWithQuestions(context.Background(), test)
+	require.Error(t, err)
+
+	var tests, questions int64
+	require.NoError(t, db.Model(&entity.Test{}).Count(&tests).Error)
+	require.NoError(t, db.Model(&entity.Question{}).Count(&questions).Error)
+	assert.Zero(t, tests, "a failed answer insert must not leave the test behind")
+	assert.Zero(t, questions)
+}
+
+func TestTestRepository_Assignments(t *testing.T) {
+	db := setupTestRepoDB(t)
+	repo := NewTestRepository(db)
+	ctx := context.Background()
+
+	test := seedTest(t, db, false)
+	studentID := uuid.New()
+	require.NoError(t, db.Exec("INSERT INTO users (id, email, password_hash, full_name, role_id) VALUES (?, ?, 'hash', 'Student', ?)", studentID.String(), "student@example.com", uuid.New().String()).Error)
+
+	assignment := entity.TestAssignment{TestID: test.ID, StudentID: studentID, AssignedBy: test.UserID}
+	require.NoError(t, repo.AssignStudents(ctx, []entity.TestAssignment{assignment}))
+	require.NoError(t, repo.AssignStudents(ctx, []entity.TestAssignment{assignment}), "assigning twice keeps one assignment")
+
+	assignments, err := repo.FindAssignments(ctx, test.ID)
+	require.NoError(t, err)
+	require.Len(t, assignments, 1)
+	assert.Equal(t, "Student", assignments[0].Student.FullName)
+
+	// A draft stays hidden from assigned students
+	tests, err := repo.FindPublishedByStudentID(ctx, studentID, 10, 0)
+	require.NoError(t, err)
+	assert.Empty(t, tests)
+
+	test.Status = entity.TestStatusPublished
+	require.NoError(t, repo.Update(ctx, test))
+	tests, err = repo.FindPublishedByStudentID(ctx, studentID, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, tests, 1)
+	assert.Equal(t, test.ID, tests[0].ID)
+	count, err := repo.CountPublishedByStudentID(ctx, studentID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	count, err = repo.CountPublishedByStudentID(ctx, uuid.New())
+	require.NoError(t, err)
+	assert.Zero(t, count, "tests are listed only for assigned students")
+
+	require.NoError(t, repo.UnassignStudent(ctx, test.ID, studentID))
+	assert.ErrorIs(t, repo.UnassignStudent(ctx, test.ID, studentID), gorm.ErrRecordNotFound)
+	count, err = repo.CountPublishedByStudentID(ctx, studentID)
+	require.NoError(t, err)
+	assert.Zero(t, count)
+}
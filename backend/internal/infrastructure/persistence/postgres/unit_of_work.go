@@ -0,0 +1,27 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/shester1kov/testgen-backend/internal/domain/repository"
+	"gorm.io/gorm"
+)
+
+type unitOfWork struct {
+	db *gorm.DB
+}
+
+// NewUnitOfWork creates a unit of work over the GORM repositories
+func NewUnitOfWork(db *gorm.DB) repository.UnitOfWork {
+	return &unitOfWork{db: db}
+}
+
+func (u *unitOfWork) Do(ctx context.Context, fn func(repos repository.TxRepositories) error) error {
+	return u.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(repository.TxRepositories{
+			Tests:     NewTestRepository(tx),
+			Questions: NewQuestionRepository(tx),
+			Answers:   NewAnswerRepository(tx),
+		})
+	})
+}
@@ -0,0 +1,57 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/shester1kov/testgen-backend/internal/domain/entity"
+	"github.com/shester1kov/testgen-backend/internal/domain/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnitOfWork_Do(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("commits all changes", func(t *testing.T) {
+		db := setupQuestionTestDB(t)
+		testID := uuid.New()
+		seedQuestionTest(t, db, testID)
+		questions := seedQuestions(t, db, testID)
+
+		err := NewUnitOfWork(db).Do(ctx, func(repos repository.TxRepositories) error {
+			questions[0].QuestionText = "Renamed"
+			if err := repos.Questions.Update(ctx, questions[0]); err != nil {
+				return err
+			}
+			return repos.Answers.Create(ctx, &entity.Answer{ID: uuid.New(), QuestionID: questions[0].ID, AnswerText: "yes", OrderNum: 1})
+		})
+		require.NoError(t, err)
+
+		var answers int64
+		require.NoError(t, db.Model(&entity.Answer{}).Count(&answers).Error)
+		assert.EqualValues(t, 1, answers)
+	})
+
+	t.Run("rolls back when the function fails", func(t *testing.T) {
+		db := setupQuestionTestDB(t)
+		testID := uuid.New()
+		seedQuestionTest(t, db, testID)
+		questions := seedQuestions(t, db, testID)
+		failure := errors.New("boom")
+
+		err := NewUnitOfWork(db).Do(ctx, func(repos repository.TxRepositories) error {
+			if err := repos.Questions.Delete(ctx, questions[0].ID); err != nil {
+				return err
+			}
+			return failure
+		})
+		assert.ErrorIs(t, err, failure)
+
+		count, err := NewQuestionRepository(db).CountByTestID(ctx, testID)
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+	})
+}
@@ -0,0 +1,63 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shester1kov/testgen-backend/internal/domain/entity"
+	"github.com/shester1kov/testgen-backend/internal/domain/repository"
+	"gorm.io/gorm"
+)
+
+type uploadSessionRepository struct {
+	db *gorm.DB
+}
+
+// NewUploadSessionRepository creates a new upload session repository
+func NewUploadSessionRepository(db *gorm.DB) repository.UploadSessionRepository {
+	return &uploadSessionRepository{db: db}
+}
+
+func (r *uploadSessionRepository) Create(ctx context.Context, session *entity.UploadSession) error {
+	return r.db.WithContext(ctx).Create(session).Error
+}
+
+func (r *uploadSessionRepository) FindByID(ctx context.Context, id uuid.UUID) (*entity.UploadSession, error) {
+	var session entity.UploadSession
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&session).Error; err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *uploadSessionRepository) Update(ctx context.Context, session *entity.UploadSession) error {
+	return r.db.WithContext(ctx).Save(session).Error
+}
+
+func (r *uploadSessionRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&entity.UploadSession{}, "id = ?", id).Error
+}
+
+func (r *uploadSessionRepository) AdvanceOffset(ctx context.Context, id uuid.UUID, from, to int64) (bool, error) {
+	// Conditional update, so concurrent retries of the same chunk advance the offset only once
+	result := r.db.WithContext(ctx).
+		Model(&entity.UploadSession{}).
+		Where("id = ? AND status = ? AND received_size = ?", id, entity.UploadSessionActive, from).
+		Update("received_size", to)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *uploadSessionRepository) FindExpired(ctx context.Context, before time.Time, limit, offset int) ([]*entity.UploadSession, error) {
+	var sessions []*entity.UploadSession
+	err := r.db.WithContext(ctx).
+		Where("expires_at < ?", before).
+		Order("expires_at ASC").
+		Limit(limit).
+		Offset(offset).
+		Find(&sessions).Error
+	return sessions, err
+}
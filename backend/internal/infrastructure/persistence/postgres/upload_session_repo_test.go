@@ -0,0 +1,106 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shester1kov/testgen-backend/internal/domain/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupUploadSessionTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{SkipDefaultTransaction: true})
+	require.NoError(t, err)
+
+	err = db.Exec(`
+                CREATE TABLE upload_sessions (
+                        id TEXT PRIMARY KEY,
+                        user_id TEXT NOT NULL,
+                        file_name TEXT NOT NULL,
+                        title TEXT,
+                        content_type TEXT,
+                        total_size INTEGER NOT NULL,
+                        chunk_size INTEGER NOT NULL,
+                        received_size INTEGER NOT NULL DEFAULT 0,
+                        checksum TEXT,
+                        status TEXT DEFAULT 'active',
+                        document_id TEXT,
+                        expires_at DATETIME NOT NULL,
+                        created_at DATETIME,
+                        updated_at DATETIME
+                );
+        `).Error
+	require.NoError(t, err)
+	return db
+}
+
+func TestUploadSessionRepository_AdvanceOffset(t *testing.T) {
+	db := setupUploadSessionTestDB(t)
+	repo := NewUploadSessionRepository(db)
+	ctx := context.Background()
+
+	session := &entity.UploadSession{
+		ID: uuid.New(), UserID: uuid.New(), FileName: "lecture.pdf",
+		TotalSize: 200, ChunkSize: 100, Status: entity.UploadSessionActive,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	require.NoError(t, repo.Create(ctx, session))
+
+	advanced, err := repo.AdvanceOffset(ctx, session.ID, 0, 100)
+	require.NoError(t, err)
+	assert.True(t, advanced)
+
+	// A retry from the old offset does not move the session again
+	advanced, err = repo.AdvanceOffset(ctx, session.ID, 0, 100)
+	require.NoError(t, err)
+	assert.False(t, advanced)
+
+	fetched, err := repo.FindByID(ctx, session.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), fetched.ReceivedSize)
+
+	// Completed sessions take no more chunks
+	fetched.MarkAsCompleted(uuid.New())
+	require.NoError(t, repo.Update(ctx, fetched))
+	advanced, err = repo.AdvanceOffset(ctx, session.ID, 100, 200)
+	require.NoError(t, err)
+	assert.False(t, advanced)
+
+	require.NoError(t, repo.Delete(ctx, session.ID))
+	_, err = repo.FindByID(ctx, session.ID)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestUploadSessionRepository_FindExpired(t *testing.T) {
+	db := setupUploadSessionTestDB(t)
+	repo := NewUploadSessionRepository(db)
+	ctx := context.Background()
+	now := time.Now()
+
+	expired := &entity.UploadSession{
+		ID: uuid.New(), UserID: uuid.New(), FileName: "old.pdf",
+		TotalSize: 100, ChunkSize: 100, Status: entity.UploadSessionActive,
+		ExpiresAt: now.Add(-time.Hour),
+	}
+	active := &entity.UploadSession{
+		ID: uuid.New(), UserID: uuid.New(), FileName: "new.pdf",
+		TotalSize: 100, ChunkSize: 100, Status: entity.UploadSessionActive,
+		ExpiresAt: now.Add(time.Hour),
+	}
+	require.NoError(t, repo.Create(ctx, expired))
+	require.NoError(t, repo.Create(ctx, active))
+
+	sessions, err := repo.FindExpired(ctx, now, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, expired.ID, sessions[0].ID)
+
+	sessions, err = repo.FindExpired(ctx, now, 10, 1)
+	require.NoError(t, err)
+	assert.Empty(t, sessions)
+}
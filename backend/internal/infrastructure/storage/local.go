@@ -0,0 +1,194 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LocalStorage stores files on the local filesystem under a base directory
+type LocalStorage struct {
+	baseDir string
+}
+
+// NewLocalStorage creates a local storage rooted at baseDir
+func NewLocalStorage(baseDir string) (*LocalStorage, error) {
+	if err := os.MkdirAll(baseDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+	abs, err := filepath.Abs(baseDir)
+	if err != nil {
+		return nil, err
+	}
+	return &LocalStorage{baseDir: abs}, nil
+}
+
+// resolve maps a key to a filesystem path inside the base directory.
+// Documents uploaded before pluggable storage stored the full path
+// (e.g. "./uploads/<uuid>.pdf"); such keys are accepted as long as they
+// point inside the base directory.
+func (s *LocalStorage) resolve(key string) (string, error) {
+	if key == "" {
+		return "", ErrInvalidKey
+	}
+
+	path := filepath.FromSlash(key)
+	if abs, err := filepath.Abs(path); err == nil && isWithin(s.baseDir, abs) {
+		return abs, nil
+	}
+	if filepath.IsAbs(path) {
+		return "", ErrInvalidKey
+	}
+
+	full := filepath.Join(s.baseDir, path)
+	if !isWithin(s.baseDir, full) || full == s.baseDir {
+		return "", ErrInvalidKey
+	}
+	return full, nil
+}
+
+func isWithin(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// Put writes the reader to key, replacing any existing file atomically
+func (s *LocalStorage) Put(ctx context.Context, key string, reader io.Reader, size int64, contentType string) error {
+	path, err := s.resolve(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+
+	if _, err := io.Copy(tmp, reader); err != nil {
+		tmp.Close()
+		os.Remove(tmpName)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+	if err := os.Rename(tmpName, path); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+	return nil
+}
+
+// Get opens the file stored under key
+func (s *LocalStorage) Get(ctx context.Context, key string) (Object, error) {
+	path, err := s.resolve(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return file, nil
+}
+
+// Delete removes the file stored under key. Missing files are not an error.
+func (s *LocalStorage) Delete(ctx context.Context, key string) error {
+	path, err := s.resolve(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// Stat returns metadata of the file stored under key
+func (s *LocalStorage) Stat(ctx context.Context, key string) (*FileInfo, error) {
+	path, err := s.resolve(key)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if info.IsDir() {
+		return nil, ErrNotFound
+	}
+	return &FileInfo{
+		Key:         key,
+		Size:        info.Size(),
+		ContentType: mime.TypeByExtension(filepath.Ext(path)),
+		ModTime:     info.ModTime(),
+	}, nil
+}
+
+// List returns all files under the prefix directory. A missing directory yields no files.
+func (s *LocalStorage) List(ctx context.Context, prefix string) ([]FileInfo, error) {
+	root := s.baseDir
+	if prefix != "" {
+		root = filepath.Join(s.baseDir, filepath.FromSlash(prefix))
+		if !isWithin(s.baseDir, root) {
+			return nil, ErrInvalidKey
+		}
+	}
+
+	var files []FileInfo
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		rel, err := filepath.Rel(s.baseDir, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, FileInfo{
+			Key:         filepath.ToSlash(rel),
+			Size:        info.Size(),
+			ContentType: mime.TypeByExtension(filepath.Ext(path)),
+			ModTime:     info.ModTime(),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
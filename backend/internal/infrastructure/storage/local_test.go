@@ -0,0 +1,136 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalStorage_PutGetStatDelete(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+
+	key := DocumentKey("lecture.txt")
+	require.NoError(t, s.Put(ctx, key, strings.NewReader("hello"), 5, "text/plain"))
+
+	info, err := s.Stat(ctx, key)
+	require.NoError(t, err)
+	assert.EqualValues(t, 5, info.Size)
+
+	object, err := s.Get(ctx, key)
+	require.NoError(t, err)
+	content, err := io.ReadAll(object)
+	require.NoError(t, err)
+	require.NoError(t, object.Close())
+	assert.Equal(t, "hello", string(content))
+
+	require.NoError(t, s.Delete(ctx, key))
+	_, err = s.Get(ctx, key)
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = s.Stat(ctx, key)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// Deleting a missing file is not an error
+	assert.NoError(t, s.Delete(ctx, key))
+}
+
+func TestLocalStorage_RejectsKeysOutsideBaseDir(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+
+	for _, key := range []string{"", "../secret.txt", "documents/../../secret.txt", "/etc/passwd"} {
+		_, err := s.Get(ctx, key)
+		assert.ErrorIs(t, err, ErrInvalidKey, key)
+		assert.ErrorIs(t, s.Put(ctx, key, strings.NewReader("x"), 1, ""), ErrInvalidKey, key)
+	}
+}
+
+func TestLocalStorage_ResolvesLegacyFilePaths(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	s, err := NewLocalStorage(dir)
+	require.NoError(t, err)
+
+	// Documents uploaded before pluggable storage stored the full path
+	legacyPath := filepath.Join(dir, "legacy.pdf")
+	require.NoError(t, os.WriteFile(legacyPath, []byte("%PDF-1.4"), 0644))
+
+	info, err := s.Stat(ctx, legacyPath)
+	require.NoError(t, err)
+	assert.EqualValues(t, 8, info.Size)
+	assert.Equal(t, "application/pdf", info.ContentType)
+}
+
+func TestLocalStorage_List(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+
+	require.NoError(t, s.Put(ctx, DocumentKey("a.txt"), strings.NewReader("aaa"), 3, "text/plain"))
+	require.NoError(t, s.Put(ctx, UploadChunkKey("session", 0), strings.NewReader("c"), 1, ""))
+
+	files, err := s.List(ctx, "documents/")
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.Equal(t, "documents/a.txt", files[0].Key)
+	assert.EqualValues(t, 3, files[0].Size)
+
+	files, err = s.List(ctx, "")
+	require.NoError(t, err)
+	assert.Len(t, files, 2)
+
+	files, err = s.List(ctx, "missing/")
+	require.NoError(t, err)
+	assert.Empty(t, files)
+
+	_, err = s.List(ctx, "../")
+	assert.ErrorIs(t, err, ErrInvalidKey)
+}
+
+func TestNormalizeDocumentKey(t *testing.T) {
+	assert.Equal(t, "documents/a.pdf", NormalizeDocumentKey("uploads/a.pdf"))
+	assert.Equal(t, "documents/a.pdf", NormalizeDocumentKey("./uploads/a.pdf"))
+	assert.Equal(t, "documents/a.pdf", NormalizeDocumentKey(`C:\app\uploads\a.pdf`))
+	assert.Equal(t, "documents/a.pdf", NormalizeDocumentKey("documents/a.pdf"))
+}
+
+func TestCopy_BetweenStorages(t *testing.T) {
+	ctx := context.Background()
+	srcDir := t.TempDir()
+	src, err := NewLocalStorage(srcDir)
+	require.NoError(t, err)
+	dst, err := NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+
+	legacyPath := filepath.Join(srcDir, "a.txt")
+	require.NoError(t, os.WriteFile(legacyPath, []byte("content"), 0644))
+
+	copied, err := Copy(ctx, src, dst, legacyPath, NormalizeDocumentKey(legacyPath))
+	require.NoError(t, err)
+	assert.True(t, copied)
+
+	info, err := dst.Stat(ctx, "documents/a.txt")
+	require.NoError(t, err)
+	assert.EqualValues(t, 7, info.Size)
+
+	// Second run is a no-op
+	copied, err = Copy(ctx, src, dst, legacyPath, "documents/a.txt")
+	require.NoError(t, err)
+	assert.False(t, copied)
+
+	_, err = Copy(ctx, src, dst, "documents/missing.txt", "documents/missing.txt")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestNew_UnsupportedBackend(t *testing.T) {
+	_, err := New(context.Background(), Config{Backend: "ftp"})
+	assert.Error(t, err)
+}
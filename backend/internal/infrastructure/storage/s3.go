@@ -0,0 +1,148 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// S3Config holds S3-compatible storage settings (AWS S3, MinIO, Yandex Object Storage)
+type S3Config struct {
+	Endpoint     string
+	AccessKey    string
+	SecretKey    string
+	Bucket       string
+	Region       string
+	UseSSL       bool
+	CreateBucket bool // Create the bucket on startup if it does not exist
+}
+
+// S3Storage stores files in an S3-compatible bucket
+type S3Storage struct {
+	client *minio.Client
+	bucket string
+}
+
+// NewS3Storage creates an S3 storage and verifies the bucket is reachable
+func NewS3Storage(ctx context.Context, cfg S3Config) (*S3Storage, error) {
+	if cfg.Endpoint == "" || cfg.Bucket == "" {
+		return nil, errors.New("S3 endpoint and bucket are required")
+	}
+
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure: cfg.UseSSL,
+		Region: cfg.Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create S3 client: %w", err)
+	}
+
+	exists, err := client.BucketExists(ctx, cfg.Bucket)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check S3 bucket: %w", err)
+	}
+	if !exists {
+		if !cfg.CreateBucket {
+			return nil, fmt.Errorf("S3 bucket %q does not exist", cfg.Bucket)
+		}
+		if err := client.MakeBucket(ctx, cfg.Bucket, minio.MakeBucketOptions{Region: cfg.Region}); err != nil {
+			return nil, fmt.Errorf("failed to create S3 bucket: %w", err)
+		}
+	}
+
+	return &S3Storage{client: client, bucket: cfg.Bucket}, nil
+}
+
+func validateS3Key(key string) error {
+	if key == "" || key[0] == '/' {
+		return ErrInvalidKey
+	}
+	return nil
+}
+
+func isS3NotFound(err error) bool {
+	code := minio.ToErrorResponse(err).Code
+	return code == "NoSuchKey" || code == "NotFound"
+}
+
+// Put uploads the reader to key. A negative size streams with multipart upload.
+func (s *S3Storage) Put(ctx context.Context, key string, reader io.Reader, size int64, contentType string) error {
+	if err := validateS3Key(key); err != nil {
+		return err
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	_, err := s.client.PutObject(ctx, s.bucket, key, reader, size, minio.PutObjectOptions{ContentType: contentType})
+	return err
+}
+
+// Get opens the object stored under key
+func (s *S3Storage) Get(ctx context.Context, key string) (Object, error) {
+	if err := validateS3Key(key); err != nil {
+		return nil, err
+	}
+	object, err := s.client.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	// GetObject is lazy; Stat surfaces missing keys before the caller reads
+	if _, err := object.Stat(); err != nil {
+		object.Close()
+		if isS3NotFound(err) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return object, nil
+}
+
+// Delete removes the object stored under key. Missing objects are not an error.
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	if err := validateS3Key(key); err != nil {
+		return err
+	}
+	return s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{})
+}
+
+// Stat returns metadata of the object stored under key
+func (s *S3Storage) Stat(ctx context.Context, key string) (*FileInfo, error) {
+	if err := validateS3Key(key); err != nil {
+		return nil, err
+	}
+	info, err := s.client.StatObject(ctx, s.bucket, key, minio.StatObjectOptions{})
+	if err != nil {
+		if isS3NotFound(err) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &FileInfo{
+		Key:         key,
+		Size:        info.Size,
+		ContentType: info.ContentType,
+		ModTime:     info.LastModified,
+	}, nil
+}
+
+// List returns all objects whose keys start with prefix
+func (s *S3Storage) List(ctx context.Context, prefix string) ([]FileInfo, error) {
+	var files []FileInfo
+	for object := range s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+		if object.Err != nil {
+			return nil, object.Err
+		}
+		files = append(files, FileInfo{
+			Key:         object.Key,
+			Size:        object.Size,
+			ContentType: object.ContentType,
+			ModTime:     object.LastModified,
+		})
+	}
+	return files, nil
+}
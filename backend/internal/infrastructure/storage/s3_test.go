@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestS3Storage_MinIO runs against a real S3-compatible server.
+// This test is skipped unless S3_TEST_ENDPOINT is set.
+// To run with the MinIO service from docker-compose:
+// S3_TEST_ENDPOINT=localhost:9000 S3_TEST_ACCESS_KEY=minioadmin S3_TEST_SECRET_KEY=minioadmin go test -v -run TestS3Storage_MinIO ./internal/infrastructure/storage
+func TestS3Storage_MinIO(t *testing.T) {
+	endpoint := os.Getenv("S3_TEST_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("Skipping S3 integration test: S3_TEST_ENDPOINT not set")
+	}
+
+	ctx := context.Background()
+	s, err := NewS3Storage(ctx, S3Config{
+		Endpoint:     endpoint,
+		AccessKey:    os.Getenv("S3_TEST_ACCESS_KEY"),
+		SecretKey:    os.Getenv("S3_TEST_SECRET_KEY"),
+		Bucket:       "testgen-storage-test",
+		Region:       "us-east-1",
+		CreateBucket: true,
+	})
+	require.NoError(t, err)
+
+	key := DocumentKey(uuid.New().String() + ".txt")
+	require.NoError(t, s.Put(ctx, key, strings.NewReader("hello s3"), 8, "text/plain"))
+	defer s.Delete(ctx, key)
+
+	info, err := s.Stat(ctx, key)
+	require.NoError(t, err)
+	assert.EqualValues(t, 8, info.Size)
+	assert.Equal(t, "text/plain", info.ContentType)
+
+	object, err := s.Get(ctx, key)
+	require.NoError(t, err)
+	buf := make([]byte, 2)
+	_, err = object.ReadAt(buf, 6)
+	require.NoError(t, err)
+	assert.Equal(t, "s3", string(buf))
+	content, err := io.ReadAll(object)
+	require.NoError(t, err)
+	assert.Equal(t, "hello s3", string(content))
+	require.NoError(t, object.Close())
+
+	files, err := s.List(ctx, "documents/")
+	require.NoError(t, err)
+	var listed bool
+	for _, file := range files {
+		if file.Key == key {
+			listed = file.Size == 8
+		}
+	}
+	assert.True(t, listed, "uploaded object is listed with its size")
+
+	require.NoError(t, s.Delete(ctx, key))
+	_, err = s.Get(ctx, key)
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = s.Stat(ctx, key)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
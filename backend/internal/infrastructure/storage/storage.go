@@ -0,0 +1,125 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrNotFound is returned when no object exists under the key
+	ErrNotFound = errors.New("file not found in storage")
+
+	// ErrInvalidKey is returned for keys escaping the storage root
+	ErrInvalidKey = errors.New("invalid storage key")
+)
+
+// Storage backends
+const (
+	BackendLocal = "local"
+	BackendS3    = "s3"
+)
+
+// Key prefixes of stored files
+const (
+	DocumentPrefix = "documents/"
+	StagingPrefix  = "staging/"
+)
+
+// Object is a stored file opened for reading. Random access is required by
+// ZIP-based parsers and HTTP range requests.
+type Object interface {
+	io.Reader
+	io.ReaderAt
+	io.Seeker
+	io.Closer
+}
+
+// FileInfo describes a stored file
+type FileInfo struct {
+	Key         string
+	Size        int64
+	ContentType string
+	ModTime     time.Time
+}
+
+// FileStorage abstracts where uploaded files live (Strategy Pattern).
+// Keys are slash-separated relative paths such as "documents/<uuid>.pdf".
+type FileStorage interface {
+	Put(ctx context.Context, key string, reader io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (Object, error)
+	Delete(ctx context.Context, key string) error
+	Stat(ctx context.Context, key string) (*FileInfo, error)
+
+	// List returns all files under a directory-style prefix such as "documents/"
+	List(ctx context.Context, prefix string) ([]FileInfo, error)
+}
+
+// Config selects and configures a storage backend
+type Config struct {
+	Backend  string
+	LocalDir string
+	S3       S3Config
+}
+
+// New creates the storage backend selected by config
+func New(ctx context.Context, cfg Config) (FileStorage, error) {
+	switch cfg.Backend {
+	case "", BackendLocal:
+		return NewLocalStorage(cfg.LocalDir)
+	case BackendS3:
+		return NewS3Storage(ctx, cfg.S3)
+	default:
+		return nil, fmt.Errorf("unsupported storage backend: %s", cfg.Backend)
+	}
+}
+
+// DocumentKey builds the storage key for an uploaded document file
+func DocumentKey(filename string) string {
+	return DocumentPrefix + filename
+}
+
+// UploadChunkKey builds the storage key for a staged chunk of a resumable upload
+func UploadChunkKey(sessionID string, index int) string {
+	return fmt.Sprintf("%s%s/%06d", StagingPrefix, sessionID, index)
+}
+
+// NormalizeDocumentKey converts a legacy file path (e.g. "uploads/<uuid>.pdf")
+// to a document storage key; keys already in that form are returned unchanged
+func NormalizeDocumentKey(filePath string) string {
+	key := strings.ReplaceAll(filePath, "\\", "/")
+	if strings.HasPrefix(key, DocumentPrefix) {
+		return key
+	}
+	return DocumentKey(path.Base(key))
+}
+
+// Copy streams a file between storages and reports whether data was copied.
+// It is a no-op when the destination already holds a file of the same size.
+func Copy(ctx context.Context, src, dst FileStorage, srcKey, dstKey string) (bool, error) {
+	info, err := src.Stat(ctx, srcKey)
+	if err != nil {
+		return false, err
+	}
+
+	if existing, err := dst.Stat(ctx, dstKey); err == nil && existing.Size == info.Size {
+		return false, nil
+	} else if err != nil && !errors.Is(err, ErrNotFound) {
+		return false, err
+	}
+
+	object, err := src.Get(ctx, srcKey)
+	if err != nil {
+		return false, err
+	}
+	defer object.Close()
+
+	if err := dst.Put(ctx, dstKey, object, info.Size, info.ContentType); err != nil {
+		return false, err
+	}
+	return true, nil
+}
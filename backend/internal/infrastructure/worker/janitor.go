@@ -0,0 +1,399 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/shester1kov/testgen-backend/internal/domain/entity"
+	"github.com/shester1kov/testgen-backend/internal/domain/repository"
+	"github.com/shester1kov/testgen-backend/internal/infrastructure/storage"
+	"github.com/shester1kov/testgen-backend/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// maxReportErrors caps the errors kept in a cleanup report
+const maxReportErrors = 20
+
+// ErrCleanupRunning is returned when a cleanup is requested while another one is in progress
+var ErrCleanupRunning = errors.New("cleanup is already running")
+
+// JanitorConfig holds storage cleanup settings
+type JanitorConfig struct {
+	Interval          time.Duration // How often cleanup runs; 0 disables scheduled runs
+	Retention         time.Duration // How long files of deleted documents are kept
+	OrphanGracePeriod time.Duration // Files younger than this are never orphans (uploads in flight)
+	UploadSessionTTL  time.Duration // Staged chunks older than TTL plus grace period belong to expired sessions
+	DryRun            bool          // Scheduled runs only report what would be removed
+	BatchSize         int           // Rows fetched from the database at once
+}
+
+// CleanupReport describes the outcome of a cleanup run
+type CleanupReport struct {
+	DryRun          bool
+	StartedAt       time.Time
+	FinishedAt      time.Time
+	PurgedDocuments int   // Deleted documents past retention whose files were released
+	PurgedFiles     int   // Files of those documents removed from storage
+	SharedFilesKept int   // Files kept because other documents still reference them
+	OrphanedFiles   int   // Files without a document or upload session
+	ExpiredUploads  int   // Expired upload sessions removed with their chunks
+	ReclaimedBytes  int64 // In dry run: bytes that would be reclaimed
+	Errors          []string
+}
+
+func (r *CleanupReport) addError(format string, args ...interface{}) {
+	if len(r.Errors) < maxReportErrors {
+		r.Errors = append(r.Errors, fmt.Sprintf(format, args...))
+	}
+}
+
+// Janitor removes files nobody needs anymore: files of documents deleted longer than
+// the retention period, files without a document row and chunks of expired uploads
+type Janitor struct {
+	documentRepo repository.DocumentRepository
+	uploadRepo   repository.UploadSessionRepository
+	fileStorage  storage.FileStorage
+	config       JanitorConfig
+	logger       *logger.Logger
+
+	running    atomic.Bool
+	mu         sync.Mutex
+	lastReport *CleanupReport
+	wg         sync.WaitGroup
+	cancel     context.CancelFunc
+}
+
+// NewJanitor creates a new storage janitor
+func NewJanitor(
+	documentRepo repository.DocumentRepository,
+	uploadRepo repository.UploadSessionRepository,
+	fileStorage storage.FileStorage,
+	config JanitorConfig,
+	log *logger.Logger,
+) *Janitor {
+	if config.Retention < 0 {
+		config.Retention = 0
+	}
+	if config.OrphanGracePeriod < 0 {
+		config.OrphanGracePeriod = 0
+	}
+	if config.BatchSize < 1 {
+		config.BatchSize = 100
+	}
+	if log == nil {
+		log = logger.NewDefault()
+	}
+
+	return &Janitor{
+		documentRepo: documentRepo,
+		uploadRepo:   uploadRepo,
+		fileStorage:  fileStorage,
+		config:       config,
+		logger:       log,
+	}
+}
+
+// Config returns the janitor settings
+func (j *Janitor) Config() JanitorConfig {
+	return j.config
+}
+
+// IsRunning reports whether a cleanup is in progress
+func (j *Janitor) IsRunning() bool {
+	return j.running.Load()
+}
+
+// LastReport returns the report of the latest finished run, or nil if none ran yet
+func (j *Janitor) LastReport() *CleanupReport {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.lastReport
+}
+
+// Start runs cleanup now and then on every interval. It does nothing when the interval is not set.
+func (j *Janitor) Start(ctx context.Context) {
+	if j.config.Interval <= 0 {
+		j.logger.Info("Storage janitor disabled")
+		return
+	}
+	ctx, j.cancel = context.WithCancel(ctx)
+
+	j.wg.Add(1)
+	go j.runScheduler(ctx)
+
+	j.logger.Info("Storage janitor started",
+		zap.Duration("interval", j.config.Interval),
+		zap.Duration("retention", j.config.Retention),
+		zap.Bool("dry_run", j.config.DryRun),
+	)
+}
+
+// Stop cancels a running cleanup and waits for the scheduler to exit
+func (j *Janitor) Stop() {
+	if j.cancel == nil {
+		return
+	}
+	j.cancel()
+	j.wg.Wait()
+	j.logger.Info("Storage janitor stopped")
+}
+
+func (j *Janitor) runScheduler(ctx context.Context) {
+	defer j.wg.Done()
+
+	ticker := time.NewTicker(j.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := j.Run(ctx, j.config.DryRun); err != nil && ctx.Err() == nil && !errors.Is(err, ErrCleanupRunning) {
+			j.logger.Error("Storage cleanup failed", zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Run performs one cleanup. In dry run nothing is removed; the report tells what would be.
+func (j *Janitor) Run(ctx context.Context, dryRun bool) (*CleanupReport, error) {
+	if !j.running.CompareAndSwap(false, true) {
+		return nil, ErrCleanupRunning
+	}
+	defer j.running.Store(false)
+
+	run := &cleanupRun{
+		Janitor: j,
+		report:  &CleanupReport{DryRun: dryRun, StartedAt: time.Now()},
+		dryRun:  dryRun,
+		seen:    make(map[string]bool),
+	}
+
+	steps := []func(context.Context) error{
+		run.purgeDeletedDocuments,
+		run.removeExpiredUploads,
+		run.removeOrphanedFiles,
+	}
+	for _, step := range steps {
+		if err := step(ctx); err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			run.report.addError("%v", err)
+		}
+	}
+	run.report.FinishedAt = time.Now()
+
+	j.mu.Lock()
+	j.lastReport = run.report
+	j.mu.Unlock()
+
+	j.logger.Info("Storage cleanup finished",
+		zap.Bool("dry_run", dryRun),
+		zap.Int("purged_documents", run.report.PurgedDocuments),
+		zap.Int("purged_files", run.report.PurgedFiles),
+		zap.Int("orphaned_files", run.report.OrphanedFiles),
+		zap.Int("expired_uploads", run.report.ExpiredUploads),
+		zap.Int64("reclaimed_bytes", run.report.ReclaimedBytes),
+		zap.Int("errors", len(run.report.Errors)),
+	)
+	return run.report, nil
+}
+
+// cleanupRun holds the state of a single cleanup
+type cleanupRun struct {
+	*Janitor
+	report *CleanupReport
+	dryRun bool
+	seen   map[string]bool // Storage keys already accounted for in this run
+}
+
+// purgeDeletedDocuments removes files of documents deleted longer than the retention period
+func (r *cleanupRun) purgeDeletedDocuments(ctx context.Context) error {
+	cutoff := r.report.StartedAt.Add(-r.config.Retention)
+
+	// Purged documents drop out of the query; skipped ones (dry run, failures) are paged over
+	offset := 0
+	for {
+		documents, err := r.documentRepo.FindDeletedBefore(ctx, cutoff, r.config.BatchSize, offset)
+		if err != nil {
+			return fmt.Errorf("failed to find deleted documents: %w", err)
+		}
+
+		for _, document := range documents {
+			if err := r.purgeDocument(ctx, document, cutoff); err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				r.report.addError("document %s: %v", document.ID, err)
+				offset++
+				continue
+			}
+			if r.dryRun {
+				offset++
+			}
+		}
+
+		if len(documents) < r.config.BatchSize {
+			return nil
+		}
+	}
+}
+
+func (r *cleanupRun) purgeDocument(ctx context.Context, document *entity.Document, cutoff time.Time) error {
+	if !r.seen[document.FilePath] {
+		// Deduplicated uploads share one file; keep it while anyone still needs it
+		references, err := r.documentRepo.CountRetainedByFilePath(ctx, document.FilePath, cutoff)
+		if err != nil {
+			return fmt.Errorf("failed to count file references: %w", err)
+		}
+		if references > 0 {
+			r.seen[document.FilePath] = true
+			r.report.SharedFilesKept++
+		} else {
+			if err := r.removeFile(ctx, document.FilePath); err != nil {
+				return err
+			}
+			r.report.PurgedFiles++
+		}
+	}
+
+	if !r.dryRun {
+		if err := r.documentRepo.MarkFilePurged(ctx, document.ID); err != nil {
+			return fmt.Errorf("failed to mark file purged: %w", err)
+		}
+	}
+	r.report.PurgedDocuments++
+	return nil
+}
+
+// removeExpiredUploads deletes expired upload sessions and their staged chunks
+func (r *cleanupRun) removeExpiredUploads(ctx context.Context) error {
+	offset := 0
+	for {
+		sessions, err := r.uploadRepo.FindExpired(ctx, r.report.StartedAt, r.config.BatchSize, offset)
+		if err != nil {
+			return fmt.Errorf("failed to find expired upload sessions: %w", err)
+		}
+
+		for _, session := range sessions {
+			if err := r.removeUploadSession(ctx, session); err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				r.report.addError("upload session %s: %v", session.ID, err)
+				offset++
+				continue
+			}
+			if r.dryRun {
+				offset++
+			}
+		}
+
+		if len(sessions) < r.config.BatchSize {
+			return nil
+		}
+	}
+}
+
+func (r *cleanupRun) removeUploadSession(ctx context.Context, session *entity.UploadSession) error {
+	for index := 0; index < session.ChunkCount(); index++ {
+		if err := r.removeFile(ctx, storage.UploadChunkKey(session.ID.String(), index)); err != nil {
+			return err
+		}
+	}
+	if !r.dryRun {
+		if err := r.uploadRepo.Delete(ctx, session.ID); err != nil {
+			return fmt.Errorf("failed to delete upload session: %w", err)
+		}
+	}
+	r.report.ExpiredUploads++
+	return nil
+}
+
+// removeOrphanedFiles deletes document files without a document row and chunks
+// left behind by upload sessions that no longer exist
+func (r *cleanupRun) removeOrphanedFiles(ctx context.Context) error {
+	paths, err := r.documentRepo.FindFilePaths(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load document file paths: %w", err)
+	}
+	referenced := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		referenced[path] = true
+		referenced[storage.NormalizeDocumentKey(path)] = true
+	}
+
+	documentFiles, err := r.fileStorage.List(ctx, storage.DocumentPrefix)
+	if err != nil {
+		return fmt.Errorf("failed to list document files: %w", err)
+	}
+	graceCutoff := r.report.StartedAt.Add(-r.config.OrphanGracePeriod)
+	for _, file := range documentFiles {
+		if referenced[file.Key] || !file.ModTime.Before(graceCutoff) {
+			continue
+		}
+		r.removeOrphan(ctx, file)
+	}
+
+	// Chunks are written before their session expires, so older ones cannot belong to a live session
+	stagedFiles, err := r.fileStorage.List(ctx, storage.StagingPrefix)
+	if err != nil {
+		return fmt.Errorf("failed to list staged chunks: %w", err)
+	}
+	stagingCutoff := graceCutoff.Add(-r.config.UploadSessionTTL)
+	for _, file := range stagedFiles {
+		if !file.ModTime.Before(stagingCutoff) {
+			continue
+		}
+		r.removeOrphan(ctx, file)
+	}
+	return nil
+}
+
+func (r *cleanupRun) removeOrphan(ctx context.Context, file storage.FileInfo) {
+	if r.seen[file.Key] {
+		return
+	}
+	r.seen[file.Key] = true
+
+	if !r.dryRun {
+		if err := r.fileStorage.Delete(ctx, file.Key); err != nil {
+			r.report.addError("file %s: %v", file.Key, err)
+			return
+		}
+	}
+	r.report.OrphanedFiles++
+	r.report.ReclaimedBytes += file.Size
+}
+
+// removeFile deletes a stored file and accounts for its size. Missing files are not an error.
+func (r *cleanupRun) removeFile(ctx context.Context, key string) error {
+	if r.seen[key] {
+		return nil
+	}
+
+	info, err := r.fileStorage.Stat(ctx, key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) || errors.Is(err, storage.ErrInvalidKey) {
+			r.seen[key] = true
+			return nil
+		}
+		return fmt.Errorf("failed to stat file: %w", err)
+	}
+
+	if !r.dryRun {
+		if err := r.fileStorage.Delete(ctx, key); err != nil {
+			return fmt.Errorf("failed to delete file: %w", err)
+		}
+	}
+	r.seen[key] = true
+	r.report.ReclaimedBytes += info.Size
+	return nil
+}
@@ -0,0 +1,275 @@
+package worker
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shester1kov/testgen-backend/internal/domain/entity"
+	"github.com/shester1kov/testgen-backend/internal/domain/repository"
+	"github.com/shester1kov/testgen-backend/internal/infrastructure/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func (r *memoryDocumentRepository) FindDeletedBefore(ctx context.Context, before time.Time, limit, offset int) ([]*entity.Document, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var result []*entity.Document
+	for _, doc := range r.documents {
+		if doc.DeletedAt != nil && doc.DeletedAt.Before(before) && doc.FilePurgedAt == nil {
+			d := doc
+			result = append(result, &d)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].DeletedAt.Before(*result[j].DeletedAt) })
+	if offset >= len(result) {
+		return nil, nil
+	}
+	result = result[offset:]
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+func (r *memoryDocumentRepository) CountRetainedByFilePath(ctx context.Context, filePath string, deletedBefore time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var count int64
+	for _, doc := range r.documents {
+		if doc.FilePath == filePath && doc.FilePurgedAt == nil && (doc.DeletedAt == nil || !doc.DeletedAt.Before(deletedBefore)) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *memoryDocumentRepository) MarkFilePurged(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	doc := r.documents[id]
+	now := time.Now()
+	doc.FilePurgedAt = &now
+	r.documents[id] = doc
+	return nil
+}
+
+func (r *memoryDocumentRepository) FindFilePaths(ctx context.Context) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var paths []string
+	for _, doc := range r.documents {
+		if doc.FilePurgedAt == nil {
+			paths = append(paths, doc.FilePath)
+		}
+	}
+	return paths, nil
+}
+
+// memoryUploadSessionRepository keeps upload sessions in memory for janitor tests
+type memoryUploadSessionRepository struct {
+	repository.UploadSessionRepository
+
+	mu       sync.Mutex
+	sessions map[uuid.UUID]entity.UploadSession
+}
+
+func newMemoryUploadSessionRepository(sessions ...*entity.UploadSession) *memoryUploadSessionRepository {
+	repo := &memoryUploadSessionRepository{sessions: make(map[uuid.UUID]entity.UploadSession)}
+	for _, session := range sessions {
+		repo.sessions[session.ID] = *session
+	}
+	return repo
+}
+
+func (r *memoryUploadSessionRepository) FindExpired(ctx context.Context, before time.Time, limit, offset int) ([]*entity.UploadSession, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var result []*entity.UploadSession
+	for _, session := range r.sessions {
+		if session.ExpiresAt.Before(before) {
+			s := session
+			result = append(result, &s)
+		}
+	}
+	if offset >= len(result) {
+		return nil, nil
+	}
+	result = result[offset:]
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+func (r *memoryUploadSessionRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.sessions, id)
+	return nil
+}
+
+// janitorTestStorage is a local storage whose file modification times can be backdated
+type janitorTestStorage struct {
+	*storage.LocalStorage
+	dir string
+}
+
+func newJanitorTestStorage(t *testing.T) *janitorTestStorage {
+	dir := t.TempDir()
+	local, err := storage.NewLocalStorage(dir)
+	require.NoError(t, err)
+	return &janitorTestStorage{LocalStorage: local, dir: dir}
+}
+
+func (s *janitorTestStorage) put(t *testing.T, key, content string, age time.Duration) {
+	require.NoError(t, s.Put(context.Background(), key, strings.NewReader(content), int64(len(content)), ""))
+	modTime := time.Now().Add(-age)
+	require.NoError(t, os.Chtimes(filepath.Join(s.dir, filepath.FromSlash(key)), modTime, modTime))
+}
+
+func (s *janitorTestStorage) exists(key string) bool {
+	_, err := s.Stat(context.Background(), key)
+	return err == nil
+}
+
+func deletedDocument(key string, deletedAgo time.Duration) *entity.Document {
+	doc := &entity.Document{ID: uuid.New(), UserID: uuid.New(), FilePath: key, FileType: entity.FileTypeTXT}
+	if deletedAgo > 0 {
+		deletedAt := time.Now().Add(-deletedAgo)
+		doc.DeletedAt = &deletedAt
+	}
+	return doc
+}
+
+func testJanitorConfig() JanitorConfig {
+	return JanitorConfig{
+		Retention:         30 * 24 * time.Hour,
+		OrphanGracePeriod: time.Hour,
+		UploadSessionTTL:  24 * time.Hour,
+		BatchSize:         1, // Exercise paging
+	}
+}
+
+const day = 24 * time.Hour
+
+func TestJanitor_PurgesFilesOfDocumentsPastRetention(t *testing.T) {
+	fileStorage := newJanitorTestStorage(t)
+	fileStorage.put(t, storage.DocumentKey("expired.txt"), "12345", 40*day)
+	fileStorage.put(t, storage.DocumentKey("recent.txt"), "123", 40*day)
+	fileStorage.put(t, storage.DocumentKey("shared.txt"), "1234567", 40*day)
+
+	expired := deletedDocument(storage.DocumentKey("expired.txt"), 40*day)
+	recent := deletedDocument(storage.DocumentKey("recent.txt"), day)
+	sharedDeleted := deletedDocument(storage.DocumentKey("shared.txt"), 40*day)
+	sharedLive := deletedDocument(storage.DocumentKey("shared.txt"), 0)
+	repo := newMemoryDocumentRepository(expired, recent, sharedDeleted, sharedLive)
+
+	janitor := NewJanitor(repo, newMemoryUploadSessionRepository(), fileStorage, testJanitorConfig(), nil)
+	report, err := janitor.Run(context.Background(), false)
+	require.NoError(t, err)
+
+	assert.False(t, report.DryRun)
+	assert.Equal(t, 2, report.PurgedDocuments)
+	assert.Equal(t, 1, report.PurgedFiles)
+	assert.Equal(t, 1, report.SharedFilesKept)
+	assert.Equal(t, 0, report.OrphanedFiles)
+	assert.EqualValues(t, 5, report.ReclaimedBytes)
+	assert.Empty(t, report.Errors)
+
+	assert.False(t, fileStorage.exists(expired.FilePath))
+	assert.True(t, fileStorage.exists(recent.FilePath), "still within retention")
+	assert.True(t, fileStorage.exists(sharedLive.FilePath), "referenced by a live document")
+
+	assert.NotNil(t, repo.get(expired.ID).FilePurgedAt)
+	assert.NotNil(t, repo.get(sharedDeleted.ID).FilePurgedAt)
+	assert.Nil(t, repo.get(recent.ID).FilePurgedAt)
+	assert.Same(t, report, janitor.LastReport())
+
+	// Nothing is left to purge on the next run
+	report, err = janitor.Run(context.Background(), false)
+	require.NoError(t, err)
+	assert.Equal(t, 0, report.PurgedDocuments)
+	assert.Zero(t, report.ReclaimedBytes)
+}
+
+func TestJanitor_RemovesOrphanedFilesAndExpiredUploads(t *testing.T) {
+	fileStorage := newJanitorTestStorage(t)
+	fileStorage.put(t, storage.DocumentKey("orphan.pdf"), "orphan", 2*day)
+	fileStorage.put(t, storage.DocumentKey("in-flight.pdf"), "new", time.Minute)
+	fileStorage.put(t, storage.DocumentKey("owned.pdf"), "owned", 2*day)
+
+	session := &entity.UploadSession{
+		ID: uuid.New(), TotalSize: 6, ChunkSize: 3, ReceivedSize: 6,
+		Status: entity.UploadSessionActive, ExpiresAt: time.Now().Add(-time.Hour),
+	}
+	fileStorage.put(t, storage.UploadChunkKey(session.ID.String(), 0), "abc", 2*time.Hour)
+	fileStorage.put(t, storage.UploadChunkKey(session.ID.String(), 1), "def", 2*time.Hour)
+	abandoned := uuid.New().String()
+	fileStorage.put(t, storage.UploadChunkKey(abandoned, 0), "xyz", 3*day)
+	live := uuid.New().String()
+	fileStorage.put(t, storage.UploadChunkKey(live, 0), "live", time.Hour)
+
+	repo := newMemoryDocumentRepository(deletedDocument(storage.DocumentKey("owned.pdf"), 0))
+	uploadRepo := newMemoryUploadSessionRepository(session)
+
+	janitor := NewJanitor(repo, uploadRepo, fileStorage, testJanitorConfig(), nil)
+	report, err := janitor.Run(context.Background(), false)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, report.ExpiredUploads)
+	assert.Equal(t, 2, report.OrphanedFiles)
+	assert.EqualValues(t, len("abcdef")+len("orphan")+len("xyz"), report.ReclaimedBytes)
+
+	assert.Empty(t, uploadRepo.sessions)
+	assert.False(t, fileStorage.exists(storage.UploadChunkKey(session.ID.String(), 0)))
+	assert.False(t, fileStorage.exists(storage.UploadChunkKey(abandoned, 0)))
+	assert.False(t, fileStorage.exists(storage.DocumentKey("orphan.pdf")))
+	assert.True(t, fileStorage.exists(storage.DocumentKey("in-flight.pdf")), "within grace period")
+	assert.True(t, fileStorage.exists(storage.DocumentKey("owned.pdf")))
+	assert.True(t, fileStorage.exists(storage.UploadChunkKey(live, 0)), "session may still be active")
+}
+
+func TestJanitor_DryRunRemovesNothing(t *testing.T) {
+	fileStorage := newJanitorTestStorage(t)
+	fileStorage.put(t, storage.DocumentKey("expired.txt"), "12345", 40*day)
+	fileStorage.put(t, storage.DocumentKey("orphan.pdf"), "orphan", 2*day)
+
+	expired := deletedDocument(storage.DocumentKey("expired.txt"), 40*day)
+	repo := newMemoryDocumentRepository(expired)
+	session := &entity.UploadSession{ID: uuid.New(), TotalSize: 1, ChunkSize: 1, ExpiresAt: time.Now().Add(-time.Hour)}
+	uploadRepo := newMemoryUploadSessionRepository(session)
+
+	janitor := NewJanitor(repo, uploadRepo, fileStorage, testJanitorConfig(), nil)
+	for i := 0; i < 2; i++ {
+		report, err := janitor.Run(context.Background(), true)
+		require.NoError(t, err)
+		assert.True(t, report.DryRun)
+		assert.Equal(t, 1, report.PurgedDocuments)
+		assert.Equal(t, 1, report.PurgedFiles)
+		assert.Equal(t, 1, report.OrphanedFiles)
+		assert.Equal(t, 1, report.ExpiredUploads)
+		assert.EqualValues(t, len("12345")+len("orphan"), report.ReclaimedBytes)
+	}
+
+	assert.True(t, fileStorage.exists(expired.FilePath))
+	assert.True(t, fileStorage.exists(storage.DocumentKey("orphan.pdf")))
+	assert.Nil(t, repo.get(expired.ID).FilePurgedAt)
+	assert.Len(t, uploadRepo.sessions, 1)
+}
+
+func TestJanitor_RejectsConcurrentRun(t *testing.T) {
+	janitor := NewJanitor(newMemoryDocumentRepository(), newMemoryUploadSessionRepository(), newJanitorTestStorage(t), testJanitorConfig(), nil)
+	janitor.running.Store(true)
+
+	_, err := janitor.Run(context.Background(), false)
+	assert.ErrorIs(t, err, ErrCleanupRunning)
+	assert.Nil(t, janitor.LastReport())
+}
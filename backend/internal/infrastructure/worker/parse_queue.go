@@ -0,0 +1,437 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shester1kov/testgen-backend/internal/domain/entity"
+	"github.com/shester1kov/testgen-backend/internal/domain/repository"
+	"github.com/shester1kov/testgen-backend/internal/infrastructure/parser"
+	"github.com/shester1kov/testgen-backend/internal/infrastructure/storage"
+	"github.com/shester1kov/testgen-backend/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// releaseTimeout bounds the database write that hands an interrupted job back on shutdown
+const releaseTimeout = 5 * time.Second
+
+// Parse progress checkpoints reported through the document's parse_progress field
+const (
+	progressClaimed    = 5
+	progressFileOpened = 20
+	progressParsing    = 40
+	progressParsed     = 90
+)
+
+// ParseQueueConfig holds parse queue settings
+type ParseQueueConfig struct {
+	Workers      int           // Number of concurrent parser goroutines
+	QueueSize    int           // Buffered jobs waiting for a worker
+	JobTimeout   time.Duration // Upper bound for a single parse attempt
+	MaxRetries   int           // Retries for transient failures (I/O, database)
+	RetryBackoff time.Duration // Base delay, doubled on each retry
+	PollInterval time.Duration // How often the database is scanned for uploaded documents
+	StaleAfter   time.Duration // Parsing rows older than this are considered abandoned on startup
+}
+
+// permanentError marks failures that retrying will not fix (bad format, timeout)
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+func permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+func isPermanent(err error) bool {
+	var pe *permanentError
+	return errors.As(err, &pe)
+}
+
+// ParseQueue parses uploaded documents in the background with a bounded worker pool
+type ParseQueue struct {
+	documentRepo  repository.DocumentRepository
+	parserFactory *parser.DocumentParserFactory
+	fileStorage   storage.FileStorage
+	config        ParseQueueConfig
+	logger        *logger.Logger
+
+	jobs    chan uuid.UUID
+	mu      sync.Mutex
+	pending map[uuid.UUID]struct{}
+	wg      sync.WaitGroup
+	cancel  context.CancelFunc
+}
+
+// NewParseQueue creates a new parse queue
+func NewParseQueue(
+	documentRepo repository.DocumentRepository,
+	parserFactory *parser.DocumentParserFactory,
+	fileStorage storage.FileStorage,
+	config ParseQueueConfig,
+	log *logger.Logger,
+) *ParseQueue {
+	if config.Workers < 1 {
+		config.Workers = 1
+	}
+	if config.QueueSize < 1 {
+		config.QueueSize = 1
+	}
+	if config.JobTimeout <= 0 {
+		config.JobTimeout = 2 * time.Minute
+	}
+	if config.MaxRetries < 0 {
+		config.MaxRetries = 0
+	}
+	if log == nil {
+		log = logger.NewDefault()
+	}
+
+	return &ParseQueue{
+		documentRepo:  documentRepo,
+		parserFactory: parserFactory,
+		fileStorage:   fileStorage,
+		config:        config,
+		logger:        log,
+		jobs:          make(chan uuid.UUID, config.QueueSize),
+		pending:       make(map[uuid.UUID]struct{}),
+	}
+}
+
+// Start recovers abandoned jobs and launches workers and the database poller
+func (q *ParseQueue) Start(ctx context.Context) {
+	ctx, q.cancel = context.WithCancel(ctx)
+
+	q.recoverStale(ctx)
+
+	for i := 0; i < q.config.Workers; i++ {
+		q.wg.Add(1)
+		go q.runWorker(ctx)
+	}
+
+	q.wg.Add(1)
+	go q.runPoller(ctx)
+
+	q.logger.Info("Parse queue started",
+		zap.Int("workers", q.config.Workers),
+		zap.Int("queue_size", q.config.QueueSize),
+	)
+}
+
+// Stop signals workers to finish and waits for them. Documents being parsed
+// are put back to uploaded, so the next start picks them up right away.
+func (q *ParseQueue) Stop() {
+	if q.cancel != nil {
+		q.cancel()
+	}
+	q.wg.Wait()
+	q.logger.Info("Parse queue stopped")
+}
+
+// Enqueue schedules a document for parsing. It never blocks: when the queue is
+// full the document stays uploaded and the poller picks it up later.
+func (q *ParseQueue) Enqueue(documentID uuid.UUID) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if _, exists := q.pending[documentID]; exists {
+		return true
+	}
+
+	select {
+	case q.jobs <- documentID:
+		q.pending[documentID] = struct{}{}
+		return true
+	default:
+		return false
+	}
+}
+
+func (q *ParseQueue) done(documentID uuid.UUID) {
+	q.mu.Lock()
+	delete(q.pending, documentID)
+	q.mu.Unlock()
+}
+
+// recoverStale returns documents left in parsing by a crashed process to the queue
+func (q *ParseQueue) recoverStale(ctx context.Context) {
+	staleAfter := q.config.StaleAfter
+	if staleAfter <= 0 {
+		staleAfter = q.config.JobTimeout * time.Duration(q.config.MaxRetries+1)
+	}
+
+	count, err := q.documentRepo.ResetStaleParsing(ctx, time.Now().Add(-staleAfter))
+	if err != nil {
+		q.logger.Error("Failed to recover stale parsing documents", zap.Error(err))
+		return
+	}
+	if count > 0 {
+		q.logger.Warn("Recovered documents stuck in parsing", zap.Int64("count", count))
+	}
+}
+
+func (q *ParseQueue) runPoller(ctx context.Context) {
+	defer q.wg.Done()
+
+	q.enqueueUploaded(ctx)
+	if q.config.PollInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(q.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			q.enqueueUploaded(ctx)
+		}
+	}
+}
+
+// enqueueUploaded fills free queue slots with documents waiting in uploaded status
+func (q *ParseQueue) enqueueUploaded(ctx context.Context) {
+	free := cap(q.jobs) - len(q.jobs)
+	if free <= 0 {
+		return
+	}
+
+	documents, err := q.documentRepo.FindByStatus(ctx, entity.StatusUploaded, free)
+	if err != nil {
+		if ctx.Err() == nil {
+			q.logger.Error("Failed to fetch uploaded documents", zap.Error(err))
+		}
+		return
+	}
+
+	for _, document := range documents {
+		if !q.Enqueue(document.ID) {
+			return
+		}
+	}
+}
+
+func (q *ParseQueue) runWorker(ctx context.Context) {
+	defer q.wg.Done()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case documentID := <-q.jobs:
+			q.process(ctx, documentID)
+			q.done(documentID)
+		}
+	}
+}
+
+// process claims a document and parses it, retrying transient failures
+func (q *ParseQueue) process(ctx context.Context, documentID uuid.UUID) {
+	claimed, err := q.documentRepo.ClaimForParsing(ctx, documentID)
+	if err != nil {
+		q.logger.Error("Failed to claim document for parsing", zap.String("document_id", documentID.String()), zap.Error(err))
+		return
+	}
+	if !claimed {
+		// Already parsed, deleted, or taken by another replica
+		return
+	}
+
+	document, err := q.documentRepo.FindByID(ctx, documentID)
+	if err != nil {
+		if ctx.Err() != nil {
+			q.release(&entity.Document{ID: documentID})
+			return
+		}
+		q.logger.Error("Failed to load claimed document", zap.String("document_id", documentID.String()), zap.Error(err))
+		// Release the claim instead of leaving the row in parsing until the next start
+		released := &entity.Document{ID: documentID, ParseAttempts: 1}
+		released.MarkAsError(fmt.Sprintf("failed to load document: %v", err))
+		if updateErr := q.documentRepo.UpdateParseState(ctx, released); updateErr != nil {
+			q.logger.Error("Failed to release claimed document", zap.String("document_id", documentID.String()), zap.Error(updateErr))
+		}
+		return
+	}
+
+	for attempt := 0; ; attempt++ {
+		document.ParseAttempts++
+		err = q.parse(ctx, document)
+		if err == nil {
+			return
+		}
+		if ctx.Err() != nil {
+			q.release(document)
+			return
+		}
+		if isPermanent(err) || attempt >= q.config.MaxRetries {
+			break
+		}
+
+		backoff := q.config.RetryBackoff << attempt
+		q.logger.Warn("Transient parsing failure, retrying",
+			zap.String("document_id", documentID.String()),
+			zap.Int("attempt", document.ParseAttempts),
+			zap.Duration("backoff", backoff),
+			zap.Error(err),
+		)
+
+		select {
+		case <-ctx.Done():
+			q.release(document)
+			return
+		case <-time.After(backoff):
+		}
+	}
+
+	q.logger.Error("Document parsing failed",
+		zap.String("document_id", documentID.String()),
+		zap.Int("attempts", document.ParseAttempts),
+		zap.Error(err),
+	)
+
+	document.MarkAsError(err.Error())
+	if updateErr := q.documentRepo.UpdateParseState(ctx, document); updateErr != nil {
+		q.logger.Error("Failed to save parsing error", zap.String("document_id", documentID.String()), zap.Error(updateErr))
+	}
+}
+
+// release hands a document interrupted by shutdown back to the queue. The job
+// context is already canceled, so the write gets its own short deadline.
+func (q *ParseQueue) release(document *entity.Document) {
+	ctx, cancel := context.WithTimeout(context.Background(), releaseTimeout)
+	defer cancel()
+
+	document.MarkAsQueued()
+	if err := q.documentRepo.UpdateParseState(ctx, document); err != nil {
+		q.logger.Error("Failed to release document on shutdown", zap.String("document_id", document.ID.String()), zap.Error(err))
+	}
+}
+
+// parse runs one parsing attempt bounded by the job timeout
+func (q *ParseQueue) parse(ctx context.Context, document *entity.Document) error {
+	jobCtx, cancel := context.WithTimeout(ctx, q.config.JobTimeout)
+	defer cancel()
+
+	q.reportProgress(jobCtx, document, progressClaimed)
+
+	// Identical content may already have been parsed for another upload
+	if source := q.findParsedDuplicate(jobCtx, document); source != nil {
+		document.CopyParsedTextFrom(source)
+		if err := q.documentRepo.UpdateParseState(ctx, document); err != nil {
+			return fmt.Errorf("failed to save parsed text: %w", err)
+		}
+		return nil
+	}
+
+	docParser, err := q.parserFactory.CreateParser(string(document.FileType))
+	if err != nil {
+		return permanent(err)
+	}
+
+	info, err := q.fileStorage.Stat(jobCtx, document.FilePath)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) || errors.Is(err, storage.ErrInvalidKey) {
+			return permanent(err)
+		}
+		return fmt.Errorf("failed to stat file: %w", err)
+	}
+
+	file, err := q.fileStorage.Get(jobCtx, document.FilePath)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return permanent(err)
+		}
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+
+	if err := q.parserFactory.ValidateContent(string(document.FileType), file, info.Size); err != nil {
+		file.Close()
+		if parser.IsContentError(err) {
+			return permanent(err)
+		}
+		return fmt.Errorf("failed to validate file: %w", err)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+
+	q.reportProgress(jobCtx, document, progressFileOpened)
+
+	type parseResult struct {
+		result *parser.ParseResult
+		err    error
+	}
+	resultCh := make(chan parseResult, 1)
+
+	q.reportProgress(jobCtx, document, progressParsing)
+
+	// Parsers are not context-aware, so a hung parser is abandoned on timeout;
+	// the goroutine closes the file once it eventually returns.
+	go func() {
+		defer file.Close()
+		result, err := parser.ParseDocument(docParser, file)
+		resultCh <- parseResult{result: result, err: err}
+	}()
+
+	var result parseResult
+	select {
+	case <-jobCtx.Done():
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return permanent(fmt.Errorf("parsing timed out after %s", q.config.JobTimeout))
+	case result = <-resultCh:
+	}
+
+	if result.err != nil {
+		return permanent(result.err)
+	}
+
+	q.reportProgress(jobCtx, document, progressParsed)
+
+	document.Encoding = result.result.Encoding
+	document.MarkAsParsed(result.result.Text)
+	if err := q.documentRepo.UpdateParseState(ctx, document); err != nil {
+		return fmt.Errorf("failed to save parsed text: %w", err)
+	}
+
+	return nil
+}
+
+// findParsedDuplicate returns a parsed document with the same content and file type, if any
+func (q *ParseQueue) findParsedDuplicate(ctx context.Context, document *entity.Document) *entity.Document {
+	if document.ContentHash == "" {
+		return nil
+	}
+	duplicates, err := q.documentRepo.FindByContentHash(ctx, document.ContentHash)
+	if err != nil {
+		q.logger.Warn("Failed to look up duplicate documents", zap.String("document_id", document.ID.String()), zap.Error(err))
+		return nil
+	}
+	for _, duplicate := range duplicates {
+		if duplicate.ID != document.ID && duplicate.FileType == document.FileType && duplicate.IsParsed() {
+			return duplicate
+		}
+	}
+	return nil
+}
+
+// reportProgress persists a progress checkpoint; failures are logged but not fatal
+func (q *ParseQueue) reportProgress(ctx context.Context, document *entity.Document, progress int) {
+	document.MarkAsParsing()
+	document.SetParseProgress(progress)
+	if err := q.documentRepo.UpdateParseState(ctx, document); err != nil {
+		q.logger.Warn("Failed to report parse progress", zap.String("document_id", document.ID.String()), zap.Error(err))
+	}
+}
@@ -0,0 +1,416 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shester1kov/testgen-backend/internal/domain/entity"
+	"github.com/shester1kov/testgen-backend/internal/domain/repository"
+	"github.com/shester1kov/testgen-backend/internal/infrastructure/parser"
+	"github.com/shester1kov/testgen-backend/internal/infrastructure/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryDocumentRepository is an in-memory DocumentRepository for queue tests
+type memoryDocumentRepository struct {
+	repository.DocumentRepository
+
+	mu             sync.Mutex
+	documents      map[uuid.UUID]entity.Document
+	failParsedN    int  // number of parse state updates with parsed status that fail
+	failFindByID   bool // FindByID fails after the document was claimed
+	updatesSaved   int
+	updatesDropped int // updates ignored because the document was deleted
+}
+
+func newMemoryDocumentRepository(docs ...*entity.Document) *memoryDocumentRepository {
+	repo := &memoryDocumentRepository{documents: make(map[uuid.UUID]entity.Document)}
+	for _, doc := range docs {
+		repo.documents[doc.ID] = *doc
+	}
+	return repo
+}
+
+func (r *memoryDocumentRepository) FindByID(ctx context.Context, id uuid.UUID) (*entity.Document, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	doc, ok := r.documents[id]
+	if !ok || r.failFindByID {
+		return nil, errors.New("not found")
+	}
+	return &doc, nil
+}
+
+// UpdateParseState mirrors the postgres repository: only parsing columns of live documents change
+func (r *memoryDocumentRepository) UpdateParseState(ctx context.Context, document *entity.Document) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if document.Status == entity.StatusParsed && r.failParsedN > 0 {
+		r.failParsedN--
+		return errors.New("connection reset")
+	}
+	stored, ok := r.documents[document.ID]
+	if !ok || stored.DeletedAt != nil {
+		r.updatesDropped++
+		return nil
+	}
+	stored.Status = document.Status
+	stored.ParseProgress = document.ParseProgress
+	stored.ParseAttempts = document.ParseAttempts
+	stored.ErrorMsg = document.ErrorMsg
+	if document.IsParsed() {
+		stored.ParsedText = document.ParsedText
+		stored.Encoding = document.Encoding
+		if stored.TextRevision == 0 {
+			stored.TextStats = document.TextStats
+		}
+	}
+	r.documents[document.ID] = stored
+	r.updatesSaved++
+	return nil
+}
+
+// modify changes a stored document as a concurrent request would
+func (r *memoryDocumentRepository) modify(id uuid.UUID, change func(*entity.Document)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	doc := r.documents[id]
+	change(&doc)
+	r.documents[id] = doc
+}
+
+func (r *memoryDocumentRepository) FindByStatus(ctx context.Context, status entity.DocumentStatus, limit int) ([]*entity.Document, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var result []*entity.Document
+	for _, doc := range r.documents {
+		if doc.Status == status && len(result) < limit {
+			d := doc
+			result = append(result, &d)
+		}
+	}
+	return result, nil
+}
+
+func (r *memoryDocumentRepository) ClaimForParsing(ctx context.Context, id uuid.UUID) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	doc, ok := r.documents[id]
+	if !ok || doc.Status != entity.StatusUploaded {
+		return false, nil
+	}
+	doc.Status = entity.StatusParsing
+	r.documents[id] = doc
+	return true, nil
+}
+
+func (r *memoryDocumentRepository) ResetStaleParsing(ctx context.Context, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var count int64
+	for id, doc := range r.documents {
+		if doc.Status == entity.StatusParsing {
+			doc.Status = entity.StatusUploaded
+			r.documents[id] = doc
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *memoryDocumentRepository) FindByContentHash(ctx context.Context, contentHash string) ([]*entity.Document, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var result []*entity.Document
+	for _, doc := range r.documents {
+		if doc.ContentHash == contentHash {
+			d := doc
+			result = append(result, &d)
+		}
+	}
+	return result, nil
+}
+
+func (r *memoryDocumentRepository) get(id uuid.UUID) entity.Document {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.documents[id]
+}
+
+type funcParser struct {
+	fileType string
+	parse    func(io.Reader) (string, error)
+}
+
+func (p funcParser) Parse(reader io.Reader) (string, error) { return p.parse(reader) }
+func (p funcParser) SupportedType() string                  { return p.fileType }
+
+// newTestDocument stores content in a fresh local storage and returns a document pointing at it
+func newTestDocument(t *testing.T, content string) (*entity.Document, storage.FileStorage) {
+	fileStorage, err := storage.NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+
+	key := storage.DocumentKey("doc.txt")
+	require.NoError(t, fileStorage.Put(context.Background(), key, strings.NewReader(content), int64(len(content)), "text/plain"))
+
+	return &entity.Document{
+		ID:       uuid.New(),
+		UserID:   uuid.New(),
+		FilePath: key,
+		FileType: entity.FileTypeTXT,
+		Status:   entity.StatusUploaded,
+	}, fileStorage
+}
+
+func testQueueConfig() ParseQueueConfig {
+	return ParseQueueConfig{
+		Workers:      1,
+		QueueSize:    4,
+		JobTimeout:   time.Second,
+		MaxRetries:   2,
+		RetryBackoff: time.Millisecond,
+	}
+}
+
+func waitForStatus(t *testing.T, repo *memoryDocumentRepository, id uuid.UUID, status entity.DocumentStatus) entity.Document {
+	var doc entity.Document
+	require.Eventually(t, func() bool {
+		doc = repo.get(id)
+		return doc.Status == status
+	}, 3*time.Second, 5*time.Millisecond)
+	return doc
+}
+
+func TestParseQueue_ParsesUploadedDocument(t *testing.T) {
+	doc, fileStorage := newTestDocument(t, "lecture notes")
+	repo := newMemoryDocumentRepository(doc)
+	queue := NewParseQueue(repo, parser.NewDocumentParserFactory(), fileStorage, testQueueConfig(), nil)
+
+	queue.Start(context.Background())
+	defer queue.Stop()
+
+	parsed := waitForStatus(t, repo, doc.ID, entity.StatusParsed)
+	assert.Equal(t, "lecture notes", parsed.ParsedText)
+	assert.Equal(t, 100, parsed.ParseProgress)
+	assert.Equal(t, 1, parsed.ParseAttempts)
+}
+
+func TestParseQueue_ParserErrorIsNotRetried(t *testing.T) {
+	doc, fileStorage := newTestDocument(t, "broken")
+	repo := newMemoryDocumentRepository(doc)
+	factory := parser.NewDocumentParserFactory()
+	factory.Register(funcParser{fileType: "txt", parse: func(io.Reader) (string, error) {
+		return "", errors.New("corrupted file")
+	}})
+	queue := NewParseQueue(repo, factory, fileStorage, testQueueConfig(), nil)
+
+	queue.Start(context.Background())
+	defer queue.Stop()
+
+	failed := waitForStatus(t, repo, doc.ID, entity.StatusError)
+	assert.Equal(t, "corrupted file", failed.ErrorMsg)
+	assert.Equal(t, 1, failed.ParseAttempts)
+}
+
+func TestParseQueue_RetriesTransientFailures(t *testing.T) {
+	doc, fileStorage := newTestDocument(t, "retry me")
+	repo := newMemoryDocumentRepository(doc)
+	repo.failParsedN = 1
+	queue := NewParseQueue(repo, parser.NewDocumentParserFactory(), fileStorage, testQueueConfig(), nil)
+
+	queue.Start(context.Background())
+	defer queue.Stop()
+
+	parsed := waitForStatus(t, repo, doc.ID, entity.StatusParsed)
+	assert.Equal(t, "retry me", parsed.ParsedText)
+	assert.Equal(t, 2, parsed.ParseAttempts)
+}
+
+func TestParseQueue_TimesOutHungParser(t *testing.T) {
+	doc, fileStorage := newTestDocument(t, "slow")
+	repo := newMemoryDocumentRepository(doc)
+	release := make(chan struct{})
+	defer close(release)
+
+	factory := parser.NewDocumentParserFactory()
+	factory.Register(funcParser{fileType: "txt", parse: func(io.Reader) (string, error) {
+		<-release
+		return "", nil
+	}})
+	cfg := testQueueConfig()
+	cfg.JobTimeout = 20 * time.Millisecond
+	queue := NewParseQueue(repo, factory, fileStorage, cfg, nil)
+
+	queue.Start(context.Background())
+	defer queue.Stop()
+
+	failed := waitForStatus(t, repo, doc.ID, entity.StatusError)
+	assert.Contains(t, failed.ErrorMsg, "timed out")
+	assert.Equal(t, 1, failed.ParseAttempts)
+}
+
+func TestParseQueue_RecoversStaleParsingOnStart(t *testing.T) {
+	doc, fileStorage := newTestDocument(t, "crashed mid-parse")
+	doc.Status = entity.StatusParsing
+	repo := newMemoryDocumentRepository(doc)
+	queue := NewParseQueue(repo, parser.NewDocumentParserFactory(), fileStorage, testQueueConfig(), nil)
+
+	queue.Start(context.Background())
+	defer queue.Stop()
+
+	waitForStatus(t, repo, doc.ID, entity.StatusParsed)
+}
+
+func TestParseQueue_EnqueueDeduplicatesAndRespectsCapacity(t *testing.T) {
+	repo := newMemoryDocumentRepository()
+	cfg := testQueueConfig()
+	cfg.QueueSize = 1
+	queue := NewParseQueue(repo, parser.NewDocumentParserFactory(), nil, cfg, nil)
+
+	// Not started: jobs stay buffered
+	first := uuid.New()
+	assert.True(t, queue.Enqueue(first))
+	assert.True(t, queue.Enqueue(first), "duplicate enqueue is a no-op")
+	assert.False(t, queue.Enqueue(uuid.New()), "full queue rejects new jobs")
+}
+
+func TestParseQueue_MissingFileFailsWithoutRetry(t *testing.T) {
+	doc, fileStorage := newTestDocument(t, "gone")
+	require.NoError(t, fileStorage.Delete(context.Background(), doc.FilePath))
+	repo := newMemoryDocumentRepository(doc)
+	queue := NewParseQueue(repo, parser.NewDocumentParserFactory(), fileStorage, testQueueConfig(), nil)
+
+	queue.Start(context.Background())
+	defer queue.Stop()
+
+	failed := waitForStatus(t, repo, doc.ID, entity.StatusError)
+	assert.Contains(t, failed.ErrorMsg, "not found")
+	assert.Equal(t, 1, failed.ParseAttempts)
+}
+
+func TestParseQueue_ReusesParsedTextOfIdenticalContent(t *testing.T) {
+	doc, fileStorage := newTestDocument(t, "same syllabus")
+	doc.ContentHash = "abc123"
+	parsed := &entity.Document{
+		ID:          uuid.New(),
+		UserID:      uuid.New(),
+		FilePath:    doc.FilePath,
+		FileType:    entity.FileTypeTXT,
+		ContentHash: doc.ContentHash,
+		Status:      entity.StatusParsed,
+		ParsedText:  "same syllabus",
+		Encoding:    parser.EncodingUTF8,
+	}
+	repo := newMemoryDocumentRepository(doc, parsed)
+
+	factory := parser.NewDocumentParserFactory()
+	factory.Register(funcParser{fileType: "txt", parse: func(io.Reader) (string, error) {
+		return "", errors.New("parser must not run for duplicate content")
+	}})
+	queue := NewParseQueue(repo, factory, fileStorage, testQueueConfig(), nil)
+
+	queue.Start(context.Background())
+	defer queue.Stop()
+
+	reused := waitForStatus(t, repo, doc.ID, entity.StatusParsed)
+	assert.Equal(t, "same syllabus", reused.ParsedText)
+	assert.Equal(t, parser.EncodingUTF8, reused.Encoding)
+}
+
+func TestParseQueue_ReleasesClaimWhenLoadFails(t *testing.T) {
+	doc, fileStorage := newTestDocument(t, "unreachable")
+	repo := newMemoryDocumentRepository(doc)
+	repo.failFindByID = true
+	queue := NewParseQueue(repo, parser.NewDocumentParserFactory(), fileStorage, testQueueConfig(), nil)
+
+	queue.Start(context.Background())
+	defer queue.Stop()
+
+	failed := waitForStatus(t, repo, doc.ID, entity.StatusError)
+	assert.Contains(t, failed.ErrorMsg, "failed to load document")
+}
+
+func TestParseQueue_KeepsChangesMadeWhileParsing(t *testing.T) {
+	doc, fileStorage := newTestDocument(t, "original text")
+	doc.Title = "Lecture"
+	repo := newMemoryDocumentRepository(doc)
+
+	factory := parser.NewDocumentParserFactory()
+	factory.Register(funcParser{fileType: "txt", parse: func(io.Reader) (string, error) {
+		repo.modify(doc.ID, func(d *entity.Document) {
+			d.Title = "Renamed lecture"
+			d.CorrectedText = "teacher correction"
+			d.TextRevision = 1
+			d.WordCount = 2
+		})
+		return "parsed text", nil
+	}})
+	queue := NewParseQueue(repo, factory, fileStorage, testQueueConfig(), nil)
+
+	queue.Start(context.Background())
+	defer queue.Stop()
+
+	parsed := waitForStatus(t, repo, doc.ID, entity.StatusParsed)
+	assert.Equal(t, "parsed text", parsed.ParsedText)
+	assert.Equal(t, "Renamed lecture", parsed.Title)
+	assert.Equal(t, "teacher correction", parsed.CorrectedText)
+	assert.Equal(t, 1, parsed.TextRevision)
+	assert.Equal(t, 2, parsed.WordCount, "statistics of the correction are kept")
+}
+
+func TestParseQueue_DoesNotRestoreDocumentDeletedWhileParsing(t *testing.T) {
+	doc, fileStorage := newTestDocument(t, "deleted soon")
+	repo := newMemoryDocumentRepository(doc)
+	factory := parser.NewDocumentParserFactory()
+	factory.Register(funcParser{fileType: "txt", parse: func(io.Reader) (string, error) {
+		deletedAt := time.Now()
+		repo.modify(doc.ID, func(d *entity.Document) { d.DeletedAt = &deletedAt })
+		return "parsed text", nil
+	}})
+	queue := NewParseQueue(repo, factory, fileStorage, testQueueConfig(), nil)
+
+	queue.Start(context.Background())
+	defer queue.Stop()
+
+	require.Eventually(t, func() bool {
+		repo.mu.Lock()
+		defer repo.mu.Unlock()
+		return repo.updatesDropped > 0
+	}, 3*time.Second, 5*time.Millisecond, "the parsed result must be written and dropped")
+
+	deleted := repo.get(doc.ID)
+	assert.NotNil(t, deleted.DeletedAt)
+	assert.Equal(t, entity.StatusParsing, deleted.Status)
+	assert.Empty(t, deleted.ParsedText)
+}
+
+func TestParseQueue_ReleasesInterruptedDocumentOnStop(t *testing.T) {
+	doc, fileStorage := newTestDocument(t, "long lecture")
+	repo := newMemoryDocumentRepository(doc)
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+
+	factory := parser.NewDocumentParserFactory()
+	factory.Register(funcParser{fileType: "txt", parse: func(io.Reader) (string, error) {
+		close(started)
+		<-release
+		return "too late", nil
+	}})
+	queue := NewParseQueue(repo, factory, fileStorage, testQueueConfig(), nil)
+
+	queue.Start(context.Background())
+	<-started
+	queue.Stop()
+
+	released := repo.get(doc.ID)
+	assert.Equal(t, entity.StatusUploaded, released.Status, "the next start parses it without waiting for stale recovery")
+	assert.Equal(t, 0, released.ParseProgress)
+}
@@ -0,0 +1,246 @@
+package handler
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/shester1kov/testgen-backend/internal/application/dto"
+	"github.com/shester1kov/testgen-backend/internal/domain/entity"
+	"github.com/shester1kov/testgen-backend/internal/infrastructure/parser"
+)
+
+// defaultBulkUploadMaxFiles bounds how many documents one ZIP bundle may create
+const defaultBulkUploadMaxFiles = 100
+
+// bulkTitleSeparator joins archive folders and the file name into a document title
+const bulkTitleSeparator = " / "
+
+// systemFileNames are files archivers and file managers add next to real content
+var systemFileNames = map[string]bool{
+	"thumbs.db":   true,
+	"desktop.ini": true,
+}
+
+// bulkEntry is a file of a ZIP bundle classified before anything is extracted
+type bulkEntry struct {
+	file       *zip.File
+	path       string // Decoded path inside the archive
+	title      string
+	skipReason string
+}
+
+// SetBulkUploadLimit overrides how many documents one ZIP bundle may create
+func (h *DocumentHandler) SetBulkUploadLimit(maxFiles int) {
+	if maxFiles > 0 {
+		h.bulkMaxFiles = maxFiles
+	}
+}
+
+// BulkUpload godoc
+// @Summary Upload a ZIP bundle of documents
+// @Description Create one document per supported file of a ZIP archive. Folder names become a title prefix ("Week 1 / Slides / intro.pptx"). Unsupported, oversized and system files are skipped. Each file follows the rules of a single upload, including deduplication
+// @Tags documents
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param file formData file true "ZIP archive"
+// @Param on_duplicate query string false "Set to reject to report files uploaded before as failed instead of returning existing documents"
+// @Success 200 {object} dto.BulkUploadResponse "Per-file report"
+// @Failure 400 {object} dto.ErrorResponse "Not a ZIP archive, unsafe archive or too many documents"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /documents/bulk [post]
+func (h *DocumentHandler) BulkUpload(c *fiber.Ctx) error {
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(
+			dto.NewErrorResponse(dto.ErrCodeUnauthorized, "Unauthorized"),
+		)
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInvalidInput, "no file provided"),
+		)
+	}
+	if !strings.EqualFold(path.Ext(file.Filename), ".zip") {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInvalidFileType, "bundle must be a .zip archive"),
+		)
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInvalidInput, "failed to read uploaded file"),
+		)
+	}
+	defer src.Close()
+
+	// Entry count, total size and compression ratio are checked before extraction
+	archive, err := h.parserFactory.OpenArchive(src, file.Size)
+	if err != nil {
+		return contentValidationError(c, err)
+	}
+
+	entries := h.classifyBulkEntries(archive)
+	documents := 0
+	for _, entry := range entries {
+		if entry.skipReason == "" {
+			documents++
+		}
+	}
+	if documents > h.bulkMaxFiles {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInvalidInput,
+				fmt.Sprintf("archive contains %d documents, maximum is %d", documents, h.bulkMaxFiles)),
+		)
+	}
+
+	onDuplicate := c.Query("on_duplicate", c.FormValue("on_duplicate"))
+	response := dto.BulkUploadResponse{Entries: make([]dto.BulkUploadEntryResponse, 0, len(entries))}
+	for _, entry := range entries {
+		result := dto.BulkUploadEntryResponse{Path: entry.path}
+
+		if entry.skipReason != "" {
+			result.Status = dto.BulkEntrySkipped
+			result.Reason = entry.skipReason
+			response.Skipped++
+			response.Entries = append(response.Entries, result)
+			continue
+		}
+
+		document, duplicate, uploadErr := h.storeBulkEntry(c.Context(), userID, entry, onDuplicate)
+		switch {
+		case uploadErr != nil:
+			result.Status = dto.BulkEntryFailed
+			result.Reason = uploadErr.message
+			result.ErrorCode = uploadErr.code
+			response.Failed++
+		case duplicate:
+			result.Status = dto.BulkEntryDuplicate
+			response.Duplicates++
+		default:
+			result.Status = dto.BulkEntryCreated
+			response.Created++
+		}
+		if document != nil {
+			documentResult := documentResponse(document)
+			documentResult.Duplicate = duplicate
+			result.Document = &documentResult
+		}
+		response.Entries = append(response.Entries, result)
+	}
+
+	return c.JSON(response)
+}
+
+// classifyBulkEntries lists the files of an archive and marks those that cannot become documents
+func (h *DocumentHandler) classifyBulkEntries(archive *zip.Reader) []bulkEntry {
+	entries := make([]bulkEntry, 0, len(archive.File))
+	for _, file := range archive.File {
+		entryPath := parser.DecodeArchiveName(file.Name, file.NonUTF8)
+		if strings.HasSuffix(entryPath, "/") || file.Mode().IsDir() {
+			continue
+		}
+
+		entry := bulkEntry{file: file, path: entryPath, title: bulkEntryTitle(entryPath)}
+		switch {
+		case !file.Mode().IsRegular():
+			entry.skipReason = "not a regular file"
+		case isSystemFile(entryPath):
+			entry.skipReason = "system file"
+		default:
+			// Declared sizes are checked here and enforced again while extracting
+			if _, err := h.validateUploadFile(path.Base(entryPath), int64(file.UncompressedSize64)); err != nil {
+				entry.skipReason = err.message
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// storeBulkEntry extracts one archive file to a temporary file and stores it like a single upload
+func (h *DocumentHandler) storeBulkEntry(ctx context.Context, userID uuid.UUID, entry bulkEntry, onDuplicate string) (*entity.Document, bool, *uploadError) {
+	extracted, size, uploadErr := h.extractBulkEntry(entry.file)
+	if uploadErr != nil {
+		return nil, false, uploadErr
+	}
+	defer func() {
+		extracted.Close()
+		os.Remove(extracted.Name())
+	}()
+
+	fileName := path.Base(entry.path)
+	return h.storeUpload(ctx, userID, incomingFile{
+		Source:      extracted,
+		FileName:    fileName,
+		Size:        size,
+		ContentType: mime.TypeByExtension(path.Ext(fileName)),
+		Title:       entry.title,
+		OnDuplicate: onDuplicate,
+	})
+}
+
+func (h *DocumentHandler) extractBulkEntry(file *zip.File) (*os.File, int64, *uploadError) {
+	reader, err := file.Open()
+	if err != nil {
+		return nil, 0, &uploadError{fiber.StatusBadRequest, dto.ErrCodeInvalidFileType, "failed to open archive entry"}
+	}
+	defer reader.Close()
+
+	extracted, err := os.CreateTemp("", "bulk-entry-*")
+	if err != nil {
+		return nil, 0, &uploadError{fiber.StatusInternalServerError, dto.ErrCodeInternalError, "failed to extract file"}
+	}
+	fail := func(uploadErr *uploadError) (*os.File, int64, *uploadError) {
+		extracted.Close()
+		os.Remove(extracted.Name())
+		return nil, 0, uploadErr
+	}
+
+	// Sizes in the central directory can lie; never write more than the upload limit
+	size, err := io.Copy(extracted, io.LimitReader(reader, h.maxFileSize+1))
+	if err != nil {
+		return fail(&uploadError{fiber.StatusBadRequest, dto.ErrCodeInvalidFileType, "corrupted archive entry"})
+	}
+	if size > h.maxFileSize {
+		return fail(&uploadError{fiber.StatusBadRequest, dto.ErrCodeFileTooLarge,
+			fmt.Sprintf("file size exceeds maximum allowed size of %d bytes", h.maxFileSize)})
+	}
+	if _, err := extracted.Seek(0, io.SeekStart); err != nil {
+		return fail(&uploadError{fiber.StatusInternalServerError, dto.ErrCodeInternalError, "failed to extract file"})
+	}
+	return extracted, size, nil
+}
+
+// bulkEntryTitle turns "Week 1/Slides/intro.pptx" into "Week 1 / Slides / intro.pptx"
+func bulkEntryTitle(entryPath string) string {
+	var parts []string
+	for _, part := range strings.Split(entryPath, "/") {
+		if part = strings.TrimSpace(part); part != "" && part != "." {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, bulkTitleSeparator)
+}
+
+// isSystemFile reports hidden files and metadata added by macOS and Windows
+func isSystemFile(entryPath string) bool {
+	for _, part := range strings.Split(entryPath, "/") {
+		if strings.HasPrefix(part, ".") || part == "__MACOSX" {
+			return true
+		}
+	}
+	return systemFileNames[strings.ToLower(path.Base(entryPath))]
+}
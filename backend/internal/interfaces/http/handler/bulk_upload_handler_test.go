@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/shester1kov/testgen-backend/internal/application/dto"
+	"github.com/shester1kov/testgen-backend/internal/domain/entity"
+	"github.com/shester1kov/testgen-backend/internal/infrastructure/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding/charmap"
+)
+
+type zipEntry struct {
+	name    string
+	content string
+	nonUTF8 bool
+}
+
+func buildBundle(t *testing.T, entries ...zipEntry) []byte {
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	for _, entry := range entries {
+		w, err := writer.CreateHeader(&zip.FileHeader{Name: entry.name, Method: zip.Deflate, NonUTF8: entry.nonUTF8})
+		require.NoError(t, err)
+		_, err = w.Write([]byte(entry.content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+	return buf.Bytes()
+}
+
+func bulkUploadRequest(t *testing.T, fileName string, content []byte) *http.Request {
+	var b bytes.Buffer
+	writer := multipart.NewWriter(&b)
+	part, err := writer.CreateFormFile("file", fileName)
+	require.NoError(t, err)
+	_, err = part.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/bulk", &b)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+func newBulkUploadApp(t *testing.T, repo *mockDocumentRepository, userID uuid.UUID) (*fiber.App, *DocumentHandler) {
+	handler := NewDocumentHandler(repo, new(mockDocUserRepository), nil, nil, parser.NewDocumentParserFactory(), newTestStorage(t, t.TempDir()), 64, nil)
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("userID", userID)
+		return c.Next()
+	})
+	app.Post("/bulk", handler.BulkUpload)
+	return app, handler
+}
+
+func TestDocumentBulkUpload_CreatesDocumentsAndReportsEntries(t *testing.T) {
+	repo := new(mockDocumentRepository)
+	userID := uuid.New()
+	app, _ := newBulkUploadApp(t, repo, userID)
+
+	var created []*entity.Document
+	repo.On("FindByContentHash", mock.Anything, mock.AnythingOfType("string")).Return([]*entity.Document{}, nil)
+	repo.On("Create", mock.Anything, mock.AnythingOfType("*entity.Document")).Run(func(args mock.Arguments) {
+		doc := args.Get(1).(*entity.Document)
+		doc.ID = uuid.New()
+		created = append(created, doc)
+	}).Return(nil)
+
+	// Windows archivers store Cyrillic names in CP866 without the UTF-8 flag
+	oemName, err := charmap.CodePage866.NewEncoder().String("Курс/Лекции/Введение.md")
+	require.NoError(t, err)
+
+	bundle := buildBundle(t,
+		zipEntry{name: "Course/"},
+		zipEntry{name: "Course/Week 1/intro.txt", content: "Introduction"},
+		zipEntry{name: oemName, content: "# Введение", nonUTF8: true},
+		zipEntry{name: "Course/tool.exe", content: "MZ"},
+		zipEntry{name: "__MACOSX/Course/._intro.txt", content: "meta"},
+		zipEntry{name: "Course/huge.txt", content: strings.Repeat("x", 100)},
+		zipEntry{name: "Course/fake.pdf", content: "MZ\x90\x00"},
+	)
+
+	resp, err := app.Test(bulkUploadRequest(t, "course.zip", bundle))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var body dto.BulkUploadResponse
+	require.NoError(t, json.Unmarshal(getBodyBytes(t, resp), &body))
+	assert.Equal(t, 2, body.Created)
+	assert.Equal(t, 3, body.Skipped)
+	assert.Equal(t, 1, body.Failed)
+	require.Len(t, body.Entries, 6, "folders are not reported")
+
+	statuses := make(map[string]dto.BulkUploadEntryResponse)
+	for _, entry := range body.Entries {
+		statuses[entry.Path] = entry
+	}
+
+	intro := statuses["Course/Week 1/intro.txt"]
+	assert.Equal(t, dto.BulkEntryCreated, intro.Status)
+	require.NotNil(t, intro.Document)
+	assert.Equal(t, "Course / Week 1 / intro.txt", intro.Document.Title)
+	assert.Equal(t, "intro.txt", intro.Document.FileName)
+
+	cyrillic := statuses["Курс/Лекции/Введение.md"]
+	assert.Equal(t, dto.BulkEntryCreated, cyrillic.Status)
+	require.NotNil(t, cyrillic.Document)
+	assert.Equal(t, "Курс / Лекции / Введение.md", cyrillic.Document.Title)
+
+	assert.Equal(t, dto.BulkEntrySkipped, statuses["Course/tool.exe"].Status)
+	assert.Equal(t, "system file", statuses["__MACOSX/Course/._intro.txt"].Reason)
+	assert.Contains(t, statuses["Course/huge.txt"].Reason, "exceeds maximum allowed size")
+
+	fake := statuses["Course/fake.pdf"]
+	assert.Equal(t, dto.BulkEntryFailed, fake.Status)
+	assert.Equal(t, dto.ErrCodeInvalidFileType, fake.ErrorCode)
+	assert.Nil(t, fake.Document)
+
+	require.Len(t, created, 2)
+	for _, doc := range created {
+		assert.Equal(t, userID, doc.UserID)
+	}
+	repo.AssertExpectations(t)
+}
+
+func TestDocumentBulkUpload_RejectsInvalidBundles(t *testing.T) {
+	repo := new(mockDocumentRepository)
+	app, handler := newBulkUploadApp(t, repo, uuid.New())
+	handler.SetBulkUploadLimit(1)
+
+	tests := []struct {
+		name     string
+		fileName string
+		content  []byte
+		code     string
+	}{
+		{"not a zip extension", "course.rar", buildBundle(t, zipEntry{name: "a.txt", content: "a"}), dto.ErrCodeInvalidFileType},
+		{"not a zip content", "course.zip", []byte("plain text"), dto.ErrCodeInvalidFileType},
+		{"too many documents", "course.zip", buildBundle(t,
+			zipEntry{name: "a.txt", content: "a"},
+			zipEntry{name: "b.txt", content: "b"},
+		), dto.ErrCodeInvalidInput},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := app.Test(bulkUploadRequest(t, tt.fileName, tt.content))
+			require.NoError(t, err)
+			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+			var body dto.ErrorResponse
+			require.NoError(t, json.Unmarshal(getBodyBytes(t, resp), &body))
+			assert.Equal(t, tt.code, body.Error.Code)
+		})
+	}
+
+	repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestBulkEntryHelpers(t *testing.T) {
+	assert.Equal(t, "Week 1 / Slides / intro.pptx", bulkEntryTitle("Week 1/Slides/intro.pptx"))
+	assert.Equal(t, "intro.pptx", bulkEntryTitle("./intro.pptx"))
+
+	assert.True(t, isSystemFile("Course/.DS_Store"))
+	assert.True(t, isSystemFile("Course/Thumbs.db"))
+	assert.True(t, isSystemFile("__MACOSX/Course/._a.pdf"))
+	assert.False(t, isSystemFile("Course/lecture.pdf"))
+}
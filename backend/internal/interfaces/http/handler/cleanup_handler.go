@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/shester1kov/testgen-backend/internal/application/dto"
+	"github.com/shester1kov/testgen-backend/internal/infrastructure/worker"
+)
+
+// CleanupHandler exposes the storage janitor to administrators
+type CleanupHandler struct {
+	janitor *worker.Janitor
+}
+
+// NewCleanupHandler creates a new cleanup handler
+func NewCleanupHandler(janitor *worker.Janitor) *CleanupHandler {
+	return &CleanupHandler{janitor: janitor}
+}
+
+// GetStatus godoc
+// @Summary Storage cleanup status
+// @Description Get storage cleanup settings and the report of the latest run (admin only)
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.CleanupStatusResponse
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 403 {object} dto.ErrorResponse "Admin role required"
+// @Router /admin/cleanup [get]
+func (h *CleanupHandler) GetStatus(c *fiber.Ctx) error {
+	config := h.janitor.Config()
+
+	response := dto.CleanupStatusResponse{
+		Enabled:           config.Interval > 0,
+		Interval:          config.Interval.String(),
+		Retention:         config.Retention.String(),
+		OrphanGracePeriod: config.OrphanGracePeriod.String(),
+		DryRun:            config.DryRun,
+		Running:           h.janitor.IsRunning(),
+	}
+	if report := h.janitor.LastReport(); report != nil {
+		response.LastReport = cleanupReportResponse(report)
+	}
+
+	return c.JSON(response)
+}
+
+// Run godoc
+// @Summary Run storage cleanup
+// @Description Remove files of documents deleted longer than the retention period, files without a document and expired upload chunks. With dry_run nothing is removed (admin only)
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param dry_run query bool false "Only report what would be removed" default(false)
+// @Success 200 {object} dto.CleanupReportResponse
+// @Failure 400 {object} dto.ErrorResponse "Invalid dry_run value"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 403 {object} dto.ErrorResponse "Admin role required"
+// @Failure 409 {object} dto.ErrorResponse "Cleanup already running"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /admin/cleanup/run [post]
+func (h *CleanupHandler) Run(c *fiber.Ctx) error {
+	dryRun := false
+	if raw := c.Query("dry_run"); raw != "" {
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(
+				dto.NewErrorResponse(dto.ErrCodeInvalidInput, "dry_run must be true or false"),
+			)
+		}
+		dryRun = parsed
+	}
+
+	report, err := h.janitor.Run(c.Context(), dryRun)
+	if err != nil {
+		if errors.Is(err, worker.ErrCleanupRunning) {
+			return c.Status(fiber.StatusConflict).JSON(
+				dto.NewErrorResponse(dto.ErrCodeConflict, "cleanup is already running"),
+			)
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInternalError, "cleanup failed"),
+		)
+	}
+
+	return c.JSON(cleanupReportResponse(report))
+}
+
+func cleanupReportResponse(report *worker.CleanupReport) *dto.CleanupReportResponse {
+	return &dto.CleanupReportResponse{
+		DryRun:          report.DryRun,
+		StartedAt:       report.StartedAt.Format("2006-01-02T15:04:05Z"),
+		FinishedAt:      report.FinishedAt.Format("2006-01-02T15:04:05Z"),
+		PurgedDocuments: report.PurgedDocuments,
+		PurgedFiles:     report.PurgedFiles,
+		SharedFilesKept: report.SharedFilesKept,
+		OrphanedFiles:   report.OrphanedFiles,
+		ExpiredUploads:  report.ExpiredUploads,
+		ReclaimedBytes:  report.ReclaimedBytes,
+		Errors:          report.Errors,
+	}
+}
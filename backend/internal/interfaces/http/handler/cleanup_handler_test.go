@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/shester1kov/testgen-backend/internal/application/dto"
+	"github.com/shester1kov/testgen-backend/internal/domain/entity"
+	"github.com/shester1kov/testgen-backend/internal/infrastructure/worker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCleanupHandler_RunAndStatus(t *testing.T) {
+	repo := new(mockDocumentRepository)
+	repo.On("FindDeletedBefore", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return([]*entity.Document{}, nil)
+	repo.On("FindFilePaths", mock.Anything).Return([]string{}, nil)
+
+	janitor := worker.NewJanitor(repo, newMemoryUploadSessionRepository(), newTestStorage(t, t.TempDir()), worker.JanitorConfig{
+		Interval:  6 * time.Hour,
+		Retention: 720 * time.Hour,
+	}, nil)
+	handler := NewCleanupHandler(janitor)
+
+	app := fiber.New()
+	app.Get("/admin/cleanup", handler.GetStatus)
+	app.Post("/admin/cleanup/run", handler.Run)
+
+	// No run has finished yet
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/admin/cleanup", nil))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	var status dto.CleanupStatusResponse
+	require.NoError(t, json.Unmarshal(getBodyBytes(t, resp), &status))
+	assert.True(t, status.Enabled)
+	assert.Equal(t, "720h0m0s", status.Retention)
+	assert.Nil(t, status.LastReport)
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodPost, "/admin/cleanup/run?dry_run=maybe", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodPost, "/admin/cleanup/run?dry_run=true", nil))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	var report dto.CleanupReportResponse
+	require.NoError(t, json.Unmarshal(getBodyBytes(t, resp), &report))
+	assert.True(t, report.DryRun)
+	assert.Zero(t, report.ReclaimedBytes)
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/admin/cleanup", nil))
+	require.NoError(t, err)
+	status = dto.CleanupStatusResponse{}
+	require.NoError(t, json.Unmarshal(getBodyBytes(t, resp), &status))
+	require.NotNil(t, status.LastReport)
+	assert.True(t, status.LastReport.DryRun)
+
+	repo.AssertExpectations(t)
+}
@@ -3,6 +3,8 @@ package handler
 import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+
+	"github.com/shester1kov/testgen-backend/internal/domain/entity"
 )
 
 // getUserIDFromContext safely extracts the authenticated user ID from Fiber context.
@@ -30,3 +32,12 @@ func getUserIDFromContext(c *fiber.Ctx) (uuid.UUID, bool) {
 		return uuid.Nil, false
 	}
 }
+
+// labelLanguage picks the language true/false answers are shown in from the
+// Accept-Language header, falling back to entity.DefaultLabelLanguage
+func labelLanguage(c *fiber.Ctx) string {
+	if language := c.AcceptsLanguages(entity.LabelLanguages...); language != "" {
+		return language
+	}
+	return entity.DefaultLabelLanguage
+}
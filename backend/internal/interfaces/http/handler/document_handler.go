@@ -1,10 +1,16 @@
 package handler
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
-	"os"
+	"io"
 	"path/filepath"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -12,6 +18,8 @@ import (
 	"github.com/shester1kov/testgen-backend/internal/domain/entity"
 	"github.com/shester1kov/testgen-backend/internal/domain/repository"
 	"github.com/shester1kov/testgen-backend/internal/infrastructure/parser"
+	"github.com/shester1kov/testgen-backend/internal/infrastructure/storage"
+	"github.com/shester1kov/testgen-backend/internal/infrastructure/worker"
 	"github.com/shester1kov/testgen-backend/pkg/security"
 )
 
@@ -19,43 +27,73 @@ import (
 type DocumentHandler struct {
 	documentRepo  repository.DocumentRepository
 	userRepo      repository.UserRepository
+	revisionRepo  repository.DocumentTextRevisionRepository
+	uploadRepo    repository.UploadSessionRepository
 	parserFactory *parser.DocumentParserFactory
-	uploadDir     string
+	fileStorage   storage.FileStorage
 	maxFileSize   int64
+	parseQueue    *worker.ParseQueue // nil means documents are parsed inline
+
+	// Resumable upload limits
+	maxChunkSize     int64
+	uploadSessionTTL time.Duration
+
+	// ZIP bundle uploads
+	bulkMaxFiles int
 }
 
 // NewDocumentHandler creates a new document handler
 func NewDocumentHandler(
 	documentRepo repository.DocumentRepository,
 	userRepo repository.UserRepository,
+	revisionRepo repository.DocumentTextRevisionRepository,
+	uploadRepo repository.UploadSessionRepository,
 	parserFactory *parser.DocumentParserFactory,
-	uploadDir string,
+	fileStorage storage.FileStorage,
 	maxFileSize int64,
+	parseQueue *worker.ParseQueue,
 ) *DocumentHandler {
-	// Ensure upload directory exists
-	os.MkdirAll(uploadDir, 0755)
-
 	return &DocumentHandler{
 		documentRepo:  documentRepo,
 		userRepo:      userRepo,
+		revisionRepo:  revisionRepo,
+		uploadRepo:    uploadRepo,
 		parserFactory: parserFactory,
-		uploadDir:     uploadDir,
+		fileStorage:   fileStorage,
 		maxFileSize:   maxFileSize,
+		parseQueue:    parseQueue,
+
+		maxChunkSize:     defaultMaxChunkSize,
+		uploadSessionTTL: defaultUploadSessionTTL,
+		bulkMaxFiles:     defaultBulkUploadMaxFiles,
+	}
+}
+
+// SetUploadLimits overrides the chunk size and session lifetime of resumable uploads
+func (h *DocumentHandler) SetUploadLimits(maxChunkSize int64, sessionTTL time.Duration) {
+	if maxChunkSize > 0 {
+		h.maxChunkSize = maxChunkSize
+	}
+	if sessionTTL > 0 {
+		h.uploadSessionTTL = sessionTTL
 	}
 }
 
 // Upload godoc
 // @Summary Upload a document
-// @Description Upload a document file (PDF, DOCX, PPTX, TXT, MD) for processing
+// @Description Upload a document file (PDF, DOCX, PPTX, TXT, MD) for processing. Parsing starts automatically in background. Re-uploads of identical content are deduplicated by SHA-256
 // @Tags documents
 // @Accept multipart/form-data
 // @Produce json
 // @Security BearerAuth
 // @Param file formData file true "Document file"
 // @Param title formData string false "Document title"
+// @Param on_duplicate query string false "Set to reject to fail with 409 when the same file was already uploaded"
+// @Success 200 {object} dto.DocumentUploadResponse "Same file already uploaded by this user; existing document returned"
 // @Success 201 {object} dto.DocumentUploadResponse
-// @Failure 400 {object} dto.ErrorResponse "Invalid input or unsupported file type"
+// @Failure 400 {object} dto.ErrorResponse "Invalid input, unsupported file type, content not matching extension or unsafe archive"
 // @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 409 {object} dto.ErrorResponse "Same file already uploaded (on_duplicate=reject)"
 // @Failure 500 {object} dto.ErrorResponse "Internal server error"
 // @Router /documents [post]
 func (h *DocumentHandler) Upload(c *fiber.Ctx) error {
@@ -74,78 +112,211 @@ func (h *DocumentHandler) Upload(c *fiber.Ctx) error {
 		)
 	}
 
-	// Validate file size
-	if file.Size > h.maxFileSize {
+	// Reject oversized and unsupported files before reading them
+	if _, err := h.validateUploadFile(file.Filename, file.Size); err != nil {
+		return err.send(c)
+	}
+
+	src, err := file.Open()
+	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(
-			dto.NewErrorResponse(dto.ErrCodeFileTooLarge, fmt.Sprintf("file size exceeds maximum allowed size of %d bytes", h.maxFileSize)),
+			dto.NewErrorResponse(dto.ErrCodeInvalidInput, "failed to read uploaded file"),
 		)
 	}
+	defer src.Close()
 
-	// Get file extension
-	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(file.Filename), "."))
+	document, duplicate, uploadErr := h.storeUpload(c.Context(), userID, incomingFile{
+		Source:      src,
+		FileName:    file.Filename,
+		Size:        file.Size,
+		ContentType: file.Header.Get("Content-Type"),
+		Title:       c.FormValue("title"),
+		OnDuplicate: c.Query("on_duplicate", c.FormValue("on_duplicate")),
+	})
+	if uploadErr != nil {
+		return uploadErr.send(c)
+	}
+
+	response := documentResponse(document)
+	if duplicate {
+		response.Duplicate = true
+		return c.JSON(response)
+	}
+	return c.Status(fiber.StatusCreated).JSON(response)
+}
+
+// uploadError is a failure of the shared upload path, carrying the API error it maps to
+type uploadError struct {
+	status  int
+	code    string
+	message string
+}
+
+func (e *uploadError) send(c *fiber.Ctx) error {
+	return c.Status(e.status).JSON(dto.NewErrorResponse(e.code, e.message))
+}
+
+// uploadSource is an uploaded file opened for reading; content sniffing needs random access
+type uploadSource interface {
+	io.ReadSeeker
+	io.ReaderAt
+}
+
+// incomingFile is a fully received upload, from a multipart form or an assembled upload session
+type incomingFile struct {
+	Source      uploadSource
+	FileName    string
+	Size        int64
+	ContentType string
+	Title       string // Defaults to FileName
+	OnDuplicate string // "reject" fails with 409 instead of returning the user's existing document
+}
+
+// validateUploadFile checks size and extension of a file and returns its type
+func (h *DocumentHandler) validateUploadFile(fileName string, size int64) (string, *uploadError) {
+	if size > h.maxFileSize {
+		return "", &uploadError{fiber.StatusBadRequest, dto.ErrCodeFileTooLarge,
+			fmt.Sprintf("file size exceeds maximum allowed size of %d bytes", h.maxFileSize)}
+	}
+
+	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(fileName), "."))
 	if ext == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(
-			dto.NewErrorResponse(dto.ErrCodeInvalidFileType, "file has no extension"),
-		)
+		return "", &uploadError{fiber.StatusBadRequest, dto.ErrCodeInvalidFileType, "file has no extension"}
 	}
 
-	// Validate file type
 	supportedTypes := h.parserFactory.GetSupportedTypes()
-	isSupported := false
 	for _, supportedType := range supportedTypes {
 		if ext == supportedType {
-			isSupported = true
-			break
+			return ext, nil
 		}
 	}
+	return "", &uploadError{fiber.StatusBadRequest, dto.ErrCodeInvalidFileType,
+		fmt.Sprintf("unsupported file type. Supported types: %v", supportedTypes)}
+}
 
-	if !isSupported {
-		return c.Status(fiber.StatusBadRequest).JSON(
-			dto.NewErrorResponse(dto.ErrCodeInvalidFileType, fmt.Sprintf("unsupported file type. Supported types: %v", supportedTypes)),
-		)
+// storeUpload validates, deduplicates and stores a received file, then creates its document.
+// A re-upload of the user's own file returns the existing document with duplicate set.
+func (h *DocumentHandler) storeUpload(ctx context.Context, userID uuid.UUID, file incomingFile) (*entity.Document, bool, *uploadError) {
+	ext, uploadErr := h.validateUploadFile(file.FileName, file.Size)
+	if uploadErr != nil {
+		return nil, false, uploadErr
 	}
+	src := file.Source
 
-	// Generate unique filename
-	uniqueID := uuid.New()
-	savedFilename := fmt.Sprintf("%s%s", uniqueID.String(), filepath.Ext(file.Filename))
-	savedPath := filepath.Join(h.uploadDir, savedFilename)
+	// Verify content matches the extension before anything hands it to a parser
+	if err := h.parserFactory.ValidateContent(ext, src, file.Size); err != nil {
+		return nil, false, contentValidationFailure(err)
+	}
+	if _, err := src.Seek(0, io.SeekStart); err != nil {
+		return nil, false, &uploadError{fiber.StatusInternalServerError, dto.ErrCodeInternalError, "failed to read uploaded file"}
+	}
 
-	// Save file
-	if err := c.SaveFile(file, savedPath); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(
-			dto.NewErrorResponse(dto.ErrCodeInternalError, "failed to save file"),
-		)
+	// Hash content to detect re-uploads of the same file
+	contentHash, err := hashContent(src)
+	if err != nil {
+		return nil, false, &uploadError{fiber.StatusInternalServerError, dto.ErrCodeInternalError, "failed to read uploaded file"}
 	}
 
-	// Get title from form or use filename
-	title := c.FormValue("title")
-	if title == "" {
-		title = file.Filename
+	duplicates, err := h.documentRepo.FindByContentHash(ctx, contentHash)
+	if err != nil {
+		return nil, false, &uploadError{fiber.StatusInternalServerError, dto.ErrCodeDatabaseError, "failed to check for duplicate documents"}
 	}
 
-	// Sanitize title to prevent XSS
-	sanitizedTitle := security.SanitizeInput(title)
+	// The same user uploading the same file gets the existing document back
+	var shared *entity.Document
+	for _, duplicate := range duplicates {
+		if duplicate.FileType != entity.FileType(ext) {
+			continue
+		}
+		if duplicate.UserID == userID {
+			if file.OnDuplicate == "reject" {
+				return nil, false, &uploadError{fiber.StatusConflict, dto.ErrCodeDocumentExists,
+					fmt.Sprintf("document with the same content already exists: %s", duplicate.ID)}
+			}
+			return duplicate, true, nil
+		}
+		// Prefer a parsed document so its text can be reused
+		if shared == nil || (!shared.IsParsed() && duplicate.IsParsed()) {
+			shared = duplicate
+		}
+	}
 
-	// Create document record
+	// Another user's copy already sits in storage: share the blob instead of storing it again
+	var storageKey string
+	if shared != nil {
+		storageKey = shared.FilePath
+	} else {
+		if _, err := src.Seek(0, io.SeekStart); err != nil {
+			return nil, false, &uploadError{fiber.StatusInternalServerError, dto.ErrCodeInternalError, "failed to read uploaded file"}
+		}
+
+		// Generate unique storage key
+		uniqueID := uuid.New()
+		storageKey = storage.DocumentKey(fmt.Sprintf("%s%s", uniqueID.String(), filepath.Ext(file.FileName)))
+
+		// Save file
+		if err := h.fileStorage.Put(ctx, storageKey, src, file.Size, file.ContentType); err != nil {
+			return nil, false, &uploadError{fiber.StatusInternalServerError, dto.ErrCodeInternalError, "failed to save file"}
+		}
+	}
+
+	// Use the file name when no title was given
+	title := file.Title
+	if title == "" {
+		title = file.FileName
+	}
+
+	// Create document record; the title is sanitized to prevent XSS
 	document := &entity.Document{
 		UserID:   userID,
-		Title:    sanitizedTitle,
-		FileName: file.Filename,
-		FilePath: savedPath,
+		Title:    security.SanitizeInput(title),
+		FileName: file.FileName,
+		FilePath: storageKey,
 		FileType: entity.FileType(ext),
 		FileSize: file.Size,
 		Status:   entity.StatusUploaded,
+
+		ContentHash: contentHash,
 	}
 
-	if err := h.documentRepo.Create(c.Context(), document); err != nil {
-		// Clean up file if database insert fails
-		os.Remove(savedPath)
-		return c.Status(fiber.StatusInternalServerError).JSON(
-			dto.NewErrorResponse(dto.ErrCodeDatabaseError, "failed to create document record"),
-		)
+	// Identical content parsed before does not need to be parsed again
+	if shared != nil && shared.IsParsed() {
+		document.CopyParsedTextFrom(shared)
+	}
+
+	if err := h.documentRepo.Create(ctx, document); err != nil {
+		// Clean up file if database insert fails; shared blobs belong to other documents
+		if shared == nil {
+			h.fileStorage.Delete(ctx, storageKey)
+		}
+		return nil, false, &uploadError{fiber.StatusInternalServerError, dto.ErrCodeDatabaseError, "failed to create document record"}
+	}
+
+	// Parse in background; if the queue is full the poller picks the document up later
+	if h.parseQueue != nil && !document.IsParsed() {
+		h.parseQueue.Enqueue(document.ID)
+	}
+
+	return document, false, nil
+}
+
+// hashContent returns the hex-encoded SHA-256 of the reader's content
+func hashContent(reader io.Reader) (string, error) {
+	hash := sha256.New()
+	if _, err := io.Copy(hash, reader); err != nil {
+		return "", err
 	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
 
-	return c.Status(fiber.StatusCreated).JSON(dto.DocumentUploadResponse{
+// documentResponse converts a document to its upload response without parsed text
+func documentResponse(document *entity.Document) dto.DocumentUploadResponse {
+	var errorMsg *string
+	if document.ErrorMsg != "" {
+		errorMsg = &document.ErrorMsg
+	}
+
+	return dto.DocumentUploadResponse{
 		ID:        document.ID.String(),
 		UserID:    document.UserID.String(),
 		Title:     document.Title,
@@ -153,8 +324,31 @@ func (h *DocumentHandler) Upload(c *fiber.Ctx) error {
 		FileType:  string(document.FileType),
 		FileSize:  document.FileSize,
 		Status:    string(document.Status),
+		ErrorMsg:  errorMsg,
 		CreatedAt: document.CreatedAt.Format("2006-01-02T15:04:05Z"),
-	})
+
+		ParseProgress: document.ParseProgress,
+		ParseAttempts: document.ParseAttempts,
+		Encoding:      document.Encoding,
+		ContentHash:   document.ContentHash,
+		TextRevision:  document.TextRevision,
+		Stats:         documentStatsResponse(document),
+	}
+}
+
+// documentStatsResponse returns text statistics of a parsed document, nil if they were never computed
+func documentStatsResponse(document *entity.Document) *dto.DocumentStatsResponse {
+	if !document.IsParsed() || !document.TextStats.IsComputed() {
+		return nil
+	}
+	return &dto.DocumentStatsResponse{
+		WordCount:        document.WordCount,
+		CharCount:        document.CharCount,
+		SectionCount:     document.SectionCount,
+		Language:         document.Language,
+		ReadabilityScore: document.ReadabilityScore,
+		TokenEstimates:   document.TokenEstimates,
+	}
 }
 
 // List godoc
@@ -241,6 +435,10 @@ func (h *DocumentHandler) List(c *fiber.Ctx) error {
 		if doc.ErrorMsg != "" {
 			errorMsg = &doc.ErrorMsg
 		}
+		var correctedText *string
+		if doc.TextRevision > 0 {
+			correctedText = &doc.CorrectedText
+		}
 
 		// Include user info for admin
 		var userName *string
@@ -263,6 +461,14 @@ func (h *DocumentHandler) List(c *fiber.Ctx) error {
 			Status:     string(doc.Status),
 			ErrorMsg:   errorMsg,
 			CreatedAt:  doc.CreatedAt.Format("2006-01-02T15:04:05Z"),
+
+			ParseProgress: doc.ParseProgress,
+			ParseAttempts: doc.ParseAttempts,
+			Encoding:      doc.Encoding,
+			ContentHash:   doc.ContentHash,
+			CorrectedText: correctedText,
+			TextRevision:  doc.TextRevision,
+			Stats:         documentStatsResponse(doc),
 		}
 	}
 
@@ -276,7 +482,7 @@ func (h *DocumentHandler) List(c *fiber.Ctx) error {
 
 // GetByID godoc
 // @Summary Get document by ID
-// @Description Get details of a specific document by its ID
+// @Description Get details of a specific document by its ID, including background parsing progress
 // @Tags documents
 // @Produce json
 // @Security BearerAuth
@@ -323,6 +529,10 @@ func (h *DocumentHandler) GetByID(c *fiber.Ctx) error {
 	if document.ErrorMsg != "" {
 		errorMsg = &document.ErrorMsg
 	}
+	var correctedText *string
+	if document.TextRevision > 0 {
+		correctedText = &document.CorrectedText
+	}
 
 	return c.JSON(dto.DocumentUploadResponse{
 		ID:         document.ID.String(),
@@ -335,12 +545,20 @@ func (h *DocumentHandler) GetByID(c *fiber.Ctx) error {
 		Status:     string(document.Status),
 		ErrorMsg:   errorMsg,
 		CreatedAt:  document.CreatedAt.Format("2006-01-02T15:04:05Z"),
+
+		ParseProgress: document.ParseProgress,
+		ParseAttempts: document.ParseAttempts,
+		Encoding:      document.Encoding,
+		ContentHash:   document.ContentHash,
+		CorrectedText: correctedText,
+		TextRevision:  document.TextRevision,
+		Stats:         documentStatsResponse(document),
 	})
 }
 
 // Delete godoc
 // @Summary Delete a document
-// @Description Delete a document and its associated file
+// @Description Soft-delete a document. Its file is kept for the retention period and then removed by the storage janitor
 // @Tags documents
 // @Produce json
 // @Security BearerAuth
@@ -380,10 +598,7 @@ func (h *DocumentHandler) Delete(c *fiber.Ctx) error {
 		)
 	}
 
-	// Delete file
-	os.Remove(document.FilePath)
-
-	// Delete from database
+	// Soft delete only; the storage janitor removes the file once the retention period passes
 	if err := h.documentRepo.Delete(c.Context(), documentID); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(
 			dto.NewErrorResponse(dto.ErrCodeDatabaseError, "failed to delete document"),
@@ -395,17 +610,20 @@ func (h *DocumentHandler) Delete(c *fiber.Ctx) error {
 
 // Parse godoc
 // @Summary Parse a document
-// @Description Extract text content from an uploaded document
+// @Description Queue text extraction for an uploaded document. Poll GET /documents/{id} for progress
 // @Tags documents
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "Document ID"
-// @Success 200 {object} dto.ParseDocumentResponse
+// @Success 200 {object} dto.ParseDocumentResponse "Parsed inline (queue disabled)"
+// @Success 202 {object} dto.ParseJobResponse "Parsing queued"
 // @Failure 400 {object} dto.ErrorResponse "Invalid document ID or file type"
 // @Failure 401 {object} dto.ErrorResponse "Unauthorized"
 // @Failure 403 {object} dto.ErrorResponse "Access denied"
 // @Failure 404 {object} dto.ErrorResponse "Document not found"
+// @Failure 409 {object} dto.ErrorResponse "Document is already being parsed"
 // @Failure 500 {object} dto.ErrorResponse "Parsing failed"
+// @Failure 503 {object} dto.ErrorResponse "Parse queue is full"
 // @Router /documents/{id}/parse [post]
 func (h *DocumentHandler) Parse(c *fiber.Ctx) error {
 	userID, ok := getUserIDFromContext(c)
@@ -435,6 +653,10 @@ func (h *DocumentHandler) Parse(c *fiber.Ctx) error {
 		)
 	}
 
+	if h.parseQueue != nil {
+		return h.queueParse(c, document)
+	}
+
 	// Get parser
 	docParser, err := h.parserFactory.CreateParser(string(document.FileType))
 	if err != nil {
@@ -444,7 +666,13 @@ func (h *DocumentHandler) Parse(c *fiber.Ctx) error {
 	}
 
 	// Open file
-	file, err := os.Open(document.FilePath)
+	info, err := h.fileStorage.Stat(c.Context(), document.FilePath)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInternalError, "failed to open file"),
+		)
+	}
+	file, err := h.fileStorage.Get(c.Context(), document.FilePath)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(
 			dto.NewErrorResponse(dto.ErrCodeInternalError, "failed to open file"),
@@ -452,11 +680,25 @@ func (h *DocumentHandler) Parse(c *fiber.Ctx) error {
 	}
 	defer file.Close()
 
+	// Re-check content: files uploaded before validation existed were never sniffed
+	if err := h.parserFactory.ValidateContent(string(document.FileType), file, info.Size); err != nil {
+		if parser.IsContentError(err) {
+			document.MarkAsError(err.Error())
+			h.documentRepo.Update(c.Context(), document)
+		}
+		return contentValidationError(c, err)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInternalError, "failed to read file"),
+		)
+	}
+
 	// Parse document
 	document.MarkAsParsing()
 	h.documentRepo.Update(c.Context(), document)
 
-	parsedText, err := docParser.Parse(file)
+	result, err := parser.ParseDocument(docParser, file)
 	if err != nil {
 		document.MarkAsError(err.Error())
 		h.documentRepo.Update(c.Context(), document)
@@ -466,6 +708,8 @@ func (h *DocumentHandler) Parse(c *fiber.Ctx) error {
 	}
 
 	// Update document with parsed text
+	parsedText := result.Text
+	document.Encoding = result.Encoding
 	document.MarkAsParsed(parsedText)
 	if err := h.documentRepo.Update(c.Context(), document); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(
@@ -486,3 +730,420 @@ func (h *DocumentHandler) Parse(c *fiber.Ctx) error {
 		TextPreview: preview,
 	})
 }
+
+// documentContentTypes maps stored file types to the Content-Type served on download.
+// The type recorded at upload comes from the client and is not trusted.
+var documentContentTypes = map[entity.FileType]string{
+	entity.FileTypePDF:   "application/pdf",
+	entity.FileTypeDOCX:  "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	entity.FileTypePPTX:  "application/vnd.openxmlformats-officedocument.presentationml.presentation",
+	entity.FileTypeTXT:   "text/plain",
+	entity.FileTypeMD:    "text/markdown",
+	entity.FileTypeSRT:   "application/x-subrip",
+	entity.FileTypeVTT:   "text/vtt",
+	entity.FileTypeIPYNB: "application/x-ipynb+json",
+	// Source files are served as plain text so browsers can show them inline
+	entity.FileTypeGo:   "text/plain",
+	entity.FileTypePy:   "text/plain",
+	entity.FileTypeJava: "text/plain",
+	entity.FileTypeODT:  "application/vnd.oasis.opendocument.text",
+	entity.FileTypeODP:  "application/vnd.oasis.opendocument.presentation",
+	entity.FileTypeRTF:  "application/rtf",
+	entity.FileTypeEPUB: "application/epub+zip",
+	// Uploaded pages must never run scripts on our origin, even with ?inline=true
+	entity.FileTypeHTML: "text/plain",
+}
+
+// Limits for paging through document text, in characters
+const (
+	defaultTextPageLimit = 10000
+	maxTextPageLimit     = 100000
+)
+
+// DownloadFile godoc
+// @Summary Download original file
+// @Description Stream the originally uploaded file. Supports single byte ranges (Range header) for previews and resumed downloads
+// @Tags documents
+// @Produce octet-stream
+// @Security BearerAuth
+// @Param id path string true "Document ID"
+// @Param inline query bool false "Display in the browser instead of downloading"
+// @Param Range header string false "Byte range, e.g. bytes=0-1023"
+// @Success 200 {file} file "Whole file"
+// @Success 206 {file} file "Requested byte range"
+// @Failure 400 {object} dto.ErrorResponse "Invalid document ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 403 {object} dto.ErrorResponse "Access denied"
+// @Failure 404 {object} dto.ErrorResponse "Document or file not found"
+// @Failure 416 {object} dto.ErrorResponse "Range not satisfiable"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /documents/{id}/file [get]
+func (h *DocumentHandler) DownloadFile(c *fiber.Ctx) error {
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(
+			dto.NewErrorResponse(dto.ErrCodeUnauthorized, "Unauthorized"),
+		)
+	}
+	documentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInvalidUUID, "invalid document ID"),
+		)
+	}
+
+	document, err := h.documentRepo.FindByID(c.Context(), documentID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(
+			dto.NewErrorResponse(dto.ErrCodeDocumentNotFound, "document not found"),
+		)
+	}
+
+	// Check ownership
+	if document.UserID != userID {
+		return c.Status(fiber.StatusForbidden).JSON(
+			dto.NewErrorResponse(dto.ErrCodeForbidden, "access denied"),
+		)
+	}
+
+	info, err := h.fileStorage.Stat(c.Context(), document.FilePath)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) || errors.Is(err, storage.ErrInvalidKey) {
+			return c.Status(fiber.StatusNotFound).JSON(
+				dto.NewErrorResponse(dto.ErrCodeNotFound, "file not found"),
+			)
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInternalError, "failed to open file"),
+		)
+	}
+
+	byteRange, err := parseByteRange(c.Get("Range"), info.Size)
+	if err != nil {
+		c.Set("Content-Range", fmt.Sprintf("bytes */%d", info.Size))
+		return c.Status(fiber.StatusRequestedRangeNotSatisfiable).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInvalidInput, err.Error()),
+		)
+	}
+
+	file, err := h.fileStorage.Get(c.Context(), document.FilePath)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInternalError, "failed to open file"),
+		)
+	}
+
+	contentType, ok := documentContentTypes[document.FileType]
+	if !ok {
+		contentType = "application/octet-stream"
+	}
+	if document.Encoding != "" {
+		contentType += "; charset=" + document.Encoding
+	}
+
+	disposition := "attachment"
+	if c.QueryBool("inline") {
+		disposition = "inline"
+	}
+
+	c.Set("Content-Type", contentType)
+	c.Set("Content-Disposition", contentDisposition(disposition, document.FileName))
+	c.Set("Accept-Ranges", "bytes")
+	c.Set("X-Content-Type-Options", "nosniff")
+
+	// fasthttp closes the stream once the body is sent
+	if byteRange != nil {
+		c.Set("Content-Range", byteRange.contentRange(info.Size))
+		c.Status(fiber.StatusPartialContent)
+		return c.SendStream(struct {
+			io.Reader
+			io.Closer
+		}{io.NewSectionReader(file, byteRange.start, byteRange.length), file}, int(byteRange.length))
+	}
+	return c.SendStream(file, int(info.Size))
+}
+
+// GetText godoc
+// @Summary Page through document text
+// @Description Get a page of the document text by character offset. Returns the latest corrected text if any, otherwise the parsed text
+// @Tags documents
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Document ID"
+// @Param offset query int false "Offset in characters" default(0)
+// @Param limit query int false "Page size in characters (max 100000)" default(10000)
+// @Param original query bool false "Page through the parsed text even if it was corrected"
+// @Success 200 {object} dto.DocumentTextPageResponse
+// @Failure 400 {object} dto.ErrorResponse "Invalid document ID, offset or limit, or document not parsed"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 403 {object} dto.ErrorResponse "Access denied"
+// @Failure 404 {object} dto.ErrorResponse "Document not found"
+// @Router /documents/{id}/text [get]
+func (h *DocumentHandler) GetText(c *fiber.Ctx) error {
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(
+			dto.NewErrorResponse(dto.ErrCodeUnauthorized, "Unauthorized"),
+		)
+	}
+	documentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInvalidUUID, "invalid document ID"),
+		)
+	}
+
+	offset := c.QueryInt("offset", 0)
+	if offset < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInvalidOffset, "offset must not be negative"),
+		)
+	}
+	limit := c.QueryInt("limit", defaultTextPageLimit)
+	if limit < 1 || limit > maxTextPageLimit {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInvalidLimit, fmt.Sprintf("limit must be between 1 and %d", maxTextPageLimit)),
+		)
+	}
+
+	document, err := h.documentRepo.FindByID(c.Context(), documentID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(
+			dto.NewErrorResponse(dto.ErrCodeDocumentNotFound, "document not found"),
+		)
+	}
+
+	// Check ownership
+	if document.UserID != userID {
+		return c.Status(fiber.StatusForbidden).JSON(
+			dto.NewErrorResponse(dto.ErrCodeForbidden, "access denied"),
+		)
+	}
+
+	if !document.IsParsed() {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			dto.NewErrorResponse(dto.ErrCodeDocumentNotParsed, "document not parsed yet"),
+		)
+	}
+
+	text := document.EffectiveText()
+	revision := document.TextRevision
+	if c.QueryBool("original") {
+		text = document.ParsedText
+		revision = 0
+	}
+
+	page, total := sliceRunes(text, offset, limit)
+	return c.JSON(dto.DocumentTextPageResponse{
+		ID:           document.ID.String(),
+		TextRevision: revision,
+		Offset:       offset,
+		Limit:        limit,
+		Total:        total,
+		Text:         page,
+		HasMore:      offset+utf8.RuneCountInString(page) < total,
+	})
+}
+
+// UpdateText godoc
+// @Summary Correct parsed text
+// @Description Save a corrected version of the parsed text. The original parsed text is kept; every correction is stored as a new revision and test generation uses the latest one
+// @Tags documents
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Document ID"
+// @Param request body dto.UpdateDocumentTextRequest true "Corrected text"
+// @Success 200 {object} dto.DocumentTextRevisionResponse
+// @Failure 400 {object} dto.ErrorResponse "Invalid document ID, empty text or document not parsed"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 403 {object} dto.ErrorResponse "Access denied"
+// @Failure 404 {object} dto.ErrorResponse "Document not found"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /documents/{id}/text [put]
+func (h *DocumentHandler) UpdateText(c *fiber.Ctx) error {
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(
+			dto.NewErrorResponse(dto.ErrCodeUnauthorized, "Unauthorized"),
+		)
+	}
+	documentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInvalidUUID, "invalid document ID"),
+		)
+	}
+
+	var req dto.UpdateDocumentTextRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInvalidInput, "invalid request body"),
+		)
+	}
+
+	text := parser.NormalizeText(req.Text)
+	if strings.TrimSpace(text) == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInvalidInput, "text is required"),
+		)
+	}
+
+	document, err := h.documentRepo.FindByID(c.Context(), documentID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(
+			dto.NewErrorResponse(dto.ErrCodeDocumentNotFound, "document not found"),
+		)
+	}
+
+	// Check ownership
+	if document.UserID != userID {
+		return c.Status(fiber.StatusForbidden).JSON(
+			dto.NewErrorResponse(dto.ErrCodeForbidden, "access denied"),
+		)
+	}
+
+	// Re-parsing rewrites the document row, so corrections are only accepted once parsing is done
+	if !document.IsParsed() {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			dto.NewErrorResponse(dto.ErrCodeDocumentNotParsed, "document not parsed yet"),
+		)
+	}
+
+	revision := &entity.DocumentTextRevision{
+		DocumentID: document.ID,
+		UserID:     userID,
+		Text:       text,
+		Stats:      entity.NewTextStats(text),
+	}
+	if err := h.revisionRepo.Create(c.Context(), revision); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(
+			dto.NewErrorResponse(dto.ErrCodeDatabaseError, "failed to save corrected text"),
+		)
+	}
+
+	return c.JSON(textRevisionResponse(revision))
+}
+
+// ListTextRevisions godoc
+// @Summary List text corrections
+// @Description Get the history of corrected text for a document, newest first. The original parsed text is returned by GET /documents/{id}
+// @Tags documents
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Document ID"
+// @Success 200 {object} dto.DocumentTextRevisionListResponse
+// @Failure 400 {object} dto.ErrorResponse "Invalid document ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 403 {object} dto.ErrorResponse "Access denied"
+// @Failure 404 {object} dto.ErrorResponse "Document not found"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /documents/{id}/text/revisions [get]
+func (h *DocumentHandler) ListTextRevisions(c *fiber.Ctx) error {
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(
+			dto.NewErrorResponse(dto.ErrCodeUnauthorized, "Unauthorized"),
+		)
+	}
+	documentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInvalidUUID, "invalid document ID"),
+		)
+	}
+
+	document, err := h.documentRepo.FindByID(c.Context(), documentID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(
+			dto.NewErrorResponse(dto.ErrCodeDocumentNotFound, "document not found"),
+		)
+	}
+
+	// Check ownership
+	if document.UserID != userID {
+		return c.Status(fiber.StatusForbidden).JSON(
+			dto.NewErrorResponse(dto.ErrCodeForbidden, "access denied"),
+		)
+	}
+
+	revisions, err := h.revisionRepo.FindByDocumentID(c.Context(), document.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(
+			dto.NewErrorResponse(dto.ErrCodeDatabaseError, "failed to fetch text revisions"),
+		)
+	}
+
+	result := make([]dto.DocumentTextRevisionResponse, len(revisions))
+	for i, revision := range revisions {
+		result[i] = textRevisionResponse(revision)
+	}
+
+	return c.JSON(dto.DocumentTextRevisionListResponse{
+		Revisions: result,
+		Total:     len(result),
+	})
+}
+
+// textRevisionResponse converts a text revision to its API representation
+func textRevisionResponse(revision *entity.DocumentTextRevision) dto.DocumentTextRevisionResponse {
+	return dto.DocumentTextRevisionResponse{
+		ID:         revision.ID.String(),
+		DocumentID: revision.DocumentID.String(),
+		UserID:     revision.UserID.String(),
+		Revision:   revision.Revision,
+		Text:       revision.Text,
+		CreatedAt:  revision.CreatedAt.Format("2006-01-02T15:04:05Z"),
+	}
+}
+
+// contentValidationError maps content sniffing failures to API errors
+func contentValidationError(c *fiber.Ctx, err error) error {
+	return contentValidationFailure(err).send(c)
+}
+
+func contentValidationFailure(err error) *uploadError {
+	switch {
+	case errors.Is(err, parser.ErrUnsafeArchive):
+		return &uploadError{fiber.StatusBadRequest, dto.ErrCodeUnsafeArchive, err.Error()}
+	case errors.Is(err, parser.ErrContentMismatch):
+		return &uploadError{fiber.StatusBadRequest, dto.ErrCodeInvalidFileType, err.Error()}
+	default:
+		return &uploadError{fiber.StatusInternalServerError, dto.ErrCodeInternalError, "failed to read file"}
+	}
+}
+
+// queueParse resets the document to uploaded and hands it to the background parse queue
+func (h *DocumentHandler) queueParse(c *fiber.Ctx, document *entity.Document) error {
+	if document.IsParsing() {
+		return c.Status(fiber.StatusConflict).JSON(
+			dto.NewErrorResponse(dto.ErrCodeConflict, "document is already being parsed"),
+		)
+	}
+
+	if _, err := h.parserFactory.CreateParser(string(document.FileType)); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInvalidFileType, err.Error()),
+		)
+	}
+
+	document.MarkAsQueued()
+	if err := h.documentRepo.Update(c.Context(), document); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(
+			dto.NewErrorResponse(dto.ErrCodeDatabaseError, "failed to queue document"),
+		)
+	}
+
+	if !h.parseQueue.Enqueue(document.ID) {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(
+			dto.NewErrorResponse(dto.ErrCodeParseQueueFull, "parse queue is full, document will be parsed later"),
+		)
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(dto.ParseJobResponse{
+		ID:            document.ID.String(),
+		Status:        string(document.Status),
+		ParseProgress: document.ParseProgress,
+		Message:       "document queued for parsing",
+	})
+}
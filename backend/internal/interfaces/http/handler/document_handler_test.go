@@ -10,13 +10,17 @@ import (
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"github.com/shester1kov/testgen-backend/internal/application/dto"
 	"github.com/shester1kov/testgen-backend/internal/domain/entity"
 	"github.com/shester1kov/testgen-backend/internal/infrastructure/parser"
+	"github.com/shester1kov/testgen-backend/internal/infrastructure/storage"
+	"github.com/shester1kov/testgen-backend/internal/infrastructure/worker"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -97,6 +101,79 @@ func (m *mockDocumentRepository) CountAll(ctx context.Context) (int64, error) {
 	return args.Get(0).(int64), args.Error(1)
 }
 
+func (m *mockDocumentRepository) FindByStatus(ctx context.Context, status entity.DocumentStatus, limit int) ([]*entity.Document, error) {
+	args := m.Called(ctx, status, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.Document), args.Error(1)
+}
+
+func (m *mockDocumentRepository) ClaimForParsing(ctx context.Context, id uuid.UUID) (bool, error) {
+	args := m.Called(ctx, id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *mockDocumentRepository) UpdateParseState(ctx context.Context, document *entity.Document) error {
+	args := m.Called(ctx, document)
+	return args.Error(0)
+}
+
+func (m *mockDocumentRepository) ResetStaleParsing(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *mockDocumentRepository) FindByContentHash(ctx context.Context, contentHash string) ([]*entity.Document, error) {
+	args := m.Called(ctx, contentHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.Document), args.Error(1)
+}
+
+func (m *mockDocumentRepository) CountByFilePath(ctx context.Context, filePath string) (int64, error) {
+	args := m.Called(ctx, filePath)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *mockDocumentRepository) FindDeletedBefore(ctx context.Context, before time.Time, limit, offset int) ([]*entity.Document, error) {
+	args := m.Called(ctx, before, limit, offset)
+	return args.Get(0).([]*entity.Document), args.Error(1)
+}
+
+func (m *mockDocumentRepository) CountRetainedByFilePath(ctx context.Context, filePath string, deletedBefore time.Time) (int64, error) {
+	args := m.Called(ctx, filePath, deletedBefore)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *mockDocumentRepository) MarkFilePurged(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *mockDocumentRepository) FindFilePaths(ctx context.Context) ([]string, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+type mockTextRevisionRepository struct {
+	mock.Mock
+}
+
+func (m *mockTextRevisionRepository) Create(ctx context.Context, revision *entity.DocumentTextRevision) error {
+	args := m.Called(ctx, revision)
+	return args.Error(0)
+}
+
+func (m *mockTextRevisionRepository) FindByDocumentID(ctx context.Context, documentID uuid.UUID) ([]*entity.DocumentTextRevision, error) {
+	args := m.Called(ctx, documentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.DocumentTextRevision), args.Error(1)
+}
+
 type stubParser struct {
 	result string
 	err    error
@@ -108,6 +185,12 @@ func (p stubParser) Parse(reader io.Reader) (string, error) {
 
 func (p stubParser) SupportedType() string { return "txt" }
 
+func newTestStorage(t *testing.T, dir string) storage.FileStorage {
+	fileStorage, err := storage.NewLocalStorage(dir)
+	require.NoError(t, err)
+	return fileStorage
+}
+
 func createUploadRequest(t *testing.T, path, fieldName, fileName string) (*http.Request, error) {
 	var b bytes.Buffer
 	writer := multipart.NewWriter(&b)
@@ -130,12 +213,13 @@ func TestDocumentUpload_SucceedsWithSupportedType(t *testing.T) {
 	uploadDir := t.TempDir()
 	userID := uuid.New()
 
+	repo.On("FindByContentHash", mock.Anything, mock.AnythingOfType("string")).Return([]*entity.Document{}, nil)
 	repo.On("Create", mock.Anything, mock.AnythingOfType("*entity.Document")).Run(func(args mock.Arguments) {
 		doc := args.Get(1).(*entity.Document)
 		doc.ID = uuid.New()
 	}).Return(nil)
 
-	handler := NewDocumentHandler(repo, new(mockDocUserRepository), factory, uploadDir, 1024)
+	handler := NewDocumentHandler(repo, new(mockDocUserRepository), nil, nil, factory, newTestStorage(t, uploadDir), 1024, nil)
 	app := fiber.New()
 	app.Use(func(c *fiber.Ctx) error {
 		c.Locals("userID", userID)
@@ -165,7 +249,7 @@ func TestDocumentUpload_RejectsUnsupportedOrLargeFiles(t *testing.T) {
 	uploadDir := t.TempDir()
 	userID := uuid.New()
 
-	handler := NewDocumentHandler(repo, new(mockDocUserRepository), factory, uploadDir, 4)
+	handler := NewDocumentHandler(repo, new(mockDocUserRepository), nil, nil, factory, newTestStorage(t, uploadDir), 4, nil)
 	app := fiber.New()
 	app.Use(func(c *fiber.Ctx) error {
 		c.Locals("userID", userID)
@@ -189,6 +273,130 @@ func TestDocumentUpload_RejectsUnsupportedOrLargeFiles(t *testing.T) {
 	repo.AssertNotCalled(t, "Create")
 }
 
+func TestDocumentUpload_RejectsContentNotMatchingExtension(t *testing.T) {
+	repo := new(mockDocumentRepository)
+	factory := parser.NewDocumentParserFactory()
+	uploadDir := t.TempDir()
+	userID := uuid.New()
+
+	handler := NewDocumentHandler(repo, new(mockDocUserRepository), nil, nil, factory, newTestStorage(t, uploadDir), 1024, nil)
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("userID", userID)
+		return c.Next()
+	})
+	app.Post("/route", handler.Upload)
+
+	// Executable renamed to .pdf
+	var b bytes.Buffer
+	writer := multipart.NewWriter(&b)
+	part, err := writer.CreateFormFile("file", "lecture.pdf")
+	require.NoError(t, err)
+	_, err = part.Write([]byte("MZ\x90\x00\x03\x00\x00\x00"))
+	require.NoError(t, err)
+	writer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/route", &b)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+	var body dto.ErrorResponse
+	require.NoError(t, json.Unmarshal(getBodyBytes(t, resp), &body))
+	assert.Equal(t, dto.ErrCodeInvalidFileType, body.Error.Code)
+
+	// Rejected file is not kept on disk
+	entries, err := os.ReadDir(uploadDir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+	repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestDocumentUpload_DeduplicatesByContentHash(t *testing.T) {
+	factory := parser.NewDocumentParserFactory()
+	userID := uuid.New()
+	// SHA-256 of "content" written by createUploadRequest
+	contentHash := "ed7002b439e9ac845f22357d822bac1444730fbdb6016d3ec9432297b9ec9f73"
+
+	newApp := func(repo *mockDocumentRepository, uploadDir string) *fiber.App {
+		handler := NewDocumentHandler(repo, new(mockDocUserRepository), nil, nil, factory, newTestStorage(t, uploadDir), 1024, nil)
+		app := fiber.New()
+		app.Use(func(c *fiber.Ctx) error {
+			c.Locals("userID", userID)
+			return c.Next()
+		})
+		app.Post("/route", handler.Upload)
+		return app
+	}
+
+	t.Run("same user gets existing document", func(t *testing.T) {
+		existing := &entity.Document{ID: uuid.New(), UserID: userID, FileName: "sample.txt", FileType: entity.FileTypeTXT, ContentHash: contentHash}
+		repo := new(mockDocumentRepository)
+		repo.On("FindByContentHash", mock.Anything, contentHash).Return([]*entity.Document{existing}, nil)
+
+		req, err := createUploadRequest(t, "/route", "file", "sample.txt")
+		require.NoError(t, err)
+		resp, err := newApp(repo, t.TempDir()).Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+		var result dto.DocumentUploadResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+		assert.Equal(t, existing.ID.String(), result.ID)
+		assert.True(t, result.Duplicate)
+		repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("same user with on_duplicate=reject gets conflict", func(t *testing.T) {
+		existing := &entity.Document{ID: uuid.New(), UserID: userID, FileType: entity.FileTypeTXT, ContentHash: contentHash}
+		repo := new(mockDocumentRepository)
+		repo.On("FindByContentHash", mock.Anything, contentHash).Return([]*entity.Document{existing}, nil)
+
+		req, err := createUploadRequest(t, "/route?on_duplicate=reject", "file", "sample.txt")
+		require.NoError(t, err)
+		resp, err := newApp(repo, t.TempDir()).Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusConflict, resp.StatusCode)
+
+		var body dto.ErrorResponse
+		require.NoError(t, json.Unmarshal(getBodyBytes(t, resp), &body))
+		assert.Equal(t, dto.ErrCodeDocumentExists, body.Error.Code)
+	})
+
+	t.Run("other user's blob and parsed text are reused", func(t *testing.T) {
+		shared := &entity.Document{
+			ID:          uuid.New(),
+			UserID:      uuid.New(),
+			FilePath:    storage.DocumentKey("shared.txt"),
+			FileType:    entity.FileTypeTXT,
+			ContentHash: contentHash,
+			Status:      entity.StatusParsed,
+			ParsedText:  "content",
+			Encoding:    parser.EncodingUTF8,
+		}
+		repo := new(mockDocumentRepository)
+		repo.On("FindByContentHash", mock.Anything, contentHash).Return([]*entity.Document{shared}, nil)
+		repo.On("Create", mock.Anything, mock.MatchedBy(func(doc *entity.Document) bool {
+			return doc.UserID == userID && doc.FilePath == shared.FilePath && doc.IsParsed() &&
+				doc.ParsedText == "content" && doc.ContentHash == contentHash
+		})).Return(nil)
+
+		uploadDir := t.TempDir()
+		req, err := createUploadRequest(t, "/route", "file", "sample.txt")
+		require.NoError(t, err)
+		resp, err := newApp(repo, uploadDir).Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+
+		// The blob is not stored a second time
+		entries, err := os.ReadDir(uploadDir)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+		repo.AssertExpectations(t)
+	})
+}
+
 func TestDocumentList_HandlesErrors(t *testing.T) {
 	repo := new(mockDocumentRepository)
 	userRepo := new(mockDocUserRepository)
@@ -201,7 +409,7 @@ func TestDocumentList_HandlesErrors(t *testing.T) {
 	userRepo.On("FindByID", mock.Anything, userID).Return(teacherUser, nil)
 
 	repo.On("FindByUserID", mock.Anything, userID, 20, 0).Return(nil, assert.AnError)
-	handler := NewDocumentHandler(repo, userRepo, factory, t.TempDir(), 1024)
+	handler := NewDocumentHandler(repo, userRepo, nil, nil, factory, newTestStorage(t, t.TempDir()), 1024, nil)
 
 	app := fiber.New()
 	app.Use(func(c *fiber.Ctx) error {
@@ -227,7 +435,7 @@ func TestDocumentGetByID_ForbiddenAndNotFound(t *testing.T) {
 	doc := &entity.Document{ID: uuid.New(), UserID: otherUser}
 	repo.On("FindByID", mock.Anything, doc.ID).Return(doc, nil)
 
-	handler := NewDocumentHandler(repo, new(mockDocUserRepository), factory, t.TempDir(), 1024)
+	handler := NewDocumentHandler(repo, new(mockDocUserRepository), nil, nil, factory, newTestStorage(t, t.TempDir()), 1024, nil)
 	app := fiber.New()
 	app.Use(func(c *fiber.Ctx) error {
 		c.Locals("userID", userID)
@@ -248,7 +456,46 @@ func TestDocumentGetByID_ForbiddenAndNotFound(t *testing.T) {
 	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
 }
 
-func TestDocumentDelete_RemovesFileAndHandlesRepoFailure(t *testing.T) {
+func TestDocumentGetByID_IncludesTextStats(t *testing.T) {
+	repo := new(mockDocumentRepository)
+	factory := parser.NewDocumentParserFactory()
+	userID := uuid.New()
+
+	parsed := &entity.Document{ID: uuid.New(), UserID: userID}
+	parsed.MarkAsParsed("Фотосинтез — это процесс образования органических веществ из углекислого газа и воды.")
+	legacy := &entity.Document{ID: uuid.New(), UserID: userID, Status: entity.StatusParsed, ParsedText: "parsed before stats existed"}
+	repo.On("FindByID", mock.Anything, parsed.ID).Return(parsed, nil)
+	repo.On("FindByID", mock.Anything, legacy.ID).Return(legacy, nil)
+
+	handler := NewDocumentHandler(repo, new(mockDocUserRepository), nil, nil, factory, newTestStorage(t, t.TempDir()), 1024, nil)
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("userID", userID)
+		return c.Next()
+	})
+	app.Get("/route/:id", handler.GetByID)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/route/"+parsed.ID.String(), nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var body dto.DocumentUploadResponse
+	require.NoError(t, json.Unmarshal(getBodyBytes(t, resp), &body))
+	require.NotNil(t, body.Stats)
+	assert.Equal(t, 11, body.Stats.WordCount)
+	assert.Equal(t, "ru", body.Stats.Language)
+	assert.Equal(t, 1, body.Stats.SectionCount)
+	assert.Contains(t, body.Stats.TokenEstimates, "yandexgpt")
+
+	// Documents parsed before statistics were stored have none
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/route/"+legacy.ID.String(), nil))
+	require.NoError(t, err)
+	body = dto.DocumentUploadResponse{}
+	require.NoError(t, json.Unmarshal(getBodyBytes(t, resp), &body))
+	assert.Nil(t, body.Stats)
+}
+
+func TestDocumentDelete_KeepsFileForJanitor(t *testing.T) {
 	repo := new(mockDocumentRepository)
 	factory := parser.NewDocumentParserFactory()
 	userID := uuid.New()
@@ -259,9 +506,36 @@ func TestDocumentDelete_RemovesFileAndHandlesRepoFailure(t *testing.T) {
 
 	doc := &entity.Document{ID: uuid.New(), UserID: userID, FilePath: filePath}
 	repo.On("FindByID", mock.Anything, doc.ID).Return(doc, nil)
+	repo.On("Delete", mock.Anything, doc.ID).Return(nil)
+
+	handler := NewDocumentHandler(repo, new(mockDocUserRepository), nil, nil, factory, newTestStorage(t, tempDir), 1024, nil)
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("userID", userID)
+		return c.Next()
+	})
+	app.Delete("/route/:id", handler.Delete)
+
+	req := httptest.NewRequest(http.MethodDelete, "/route/"+doc.ID.String(), nil)
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	_, err = os.Stat(filePath)
+	assert.NoError(t, err, "file is kept until the retention period passes")
+	repo.AssertNotCalled(t, "CountByFilePath", mock.Anything, mock.Anything)
+}
+
+func TestDocumentDelete_RepoFailure(t *testing.T) {
+	repo := new(mockDocumentRepository)
+	factory := parser.NewDocumentParserFactory()
+	userID := uuid.New()
+
+	doc := &entity.Document{ID: uuid.New(), UserID: userID, FilePath: storage.DocumentKey("file.txt")}
+	repo.On("FindByID", mock.Anything, doc.ID).Return(doc, nil)
 	repo.On("Delete", mock.Anything, doc.ID).Return(assert.AnError)
 
-	handler := NewDocumentHandler(repo, new(mockDocUserRepository), factory, tempDir, 1024)
+	handler := NewDocumentHandler(repo, new(mockDocUserRepository), nil, nil, factory, newTestStorage(t, t.TempDir()), 1024, nil)
 
 	app := fiber.New()
 	app.Use(func(c *fiber.Ctx) error {
@@ -274,8 +548,6 @@ func TestDocumentDelete_RemovesFileAndHandlesRepoFailure(t *testing.T) {
 	resp, err := app.Test(req)
 	require.NoError(t, err)
 	assert.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
-	_, err = os.Stat(filePath)
-	assert.True(t, os.IsNotExist(err))
 }
 
 func TestDocumentParse_SuccessAndParserError(t *testing.T) {
@@ -293,7 +565,7 @@ func TestDocumentParse_SuccessAndParserError(t *testing.T) {
 	repo.On("FindByID", mock.Anything, doc.ID).Return(doc, nil)
 	repo.On("Update", mock.Anything, mock.AnythingOfType("*entity.Document")).Return(nil)
 
-	handler := NewDocumentHandler(repo, new(mockDocUserRepository), factory, tempDir, 1024)
+	handler := NewDocumentHandler(repo, new(mockDocUserRepository), nil, nil, factory, newTestStorage(t, tempDir), 1024, nil)
 
 	app := fiber.New()
 	app.Use(func(c *fiber.Ctx) error {
@@ -316,7 +588,7 @@ func TestDocumentParse_SuccessAndParserError(t *testing.T) {
 	doc.Status = entity.StatusUploaded
 	repo.On("FindByID", mock.Anything, doc.ID).Return(doc, nil)
 
-	handler = NewDocumentHandler(repo, new(mockDocUserRepository), factoryErr, tempDir, 1024)
+	handler = NewDocumentHandler(repo, new(mockDocUserRepository), nil, nil, factoryErr, newTestStorage(t, tempDir), 1024, nil)
 	app = fiber.New()
 	app.Use(func(c *fiber.Ctx) error {
 		c.Locals("userID", userID)
@@ -330,6 +602,280 @@ func TestDocumentParse_SuccessAndParserError(t *testing.T) {
 	assert.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
 }
 
+func TestDocumentParse_QueuesWhenParseQueueConfigured(t *testing.T) {
+	repo := new(mockDocumentRepository)
+	factory := parser.NewDocumentParserFactory()
+	userID := uuid.New()
+
+	doc := &entity.Document{ID: uuid.New(), UserID: userID, FileType: entity.FileTypeTXT, Status: entity.StatusError, ErrorMsg: "old failure"}
+	repo.On("FindByID", mock.Anything, doc.ID).Return(doc, nil)
+	repo.On("Update", mock.Anything, mock.AnythingOfType("*entity.Document")).Return(nil)
+
+	// Queue is not started, so the job stays buffered
+	queue := worker.NewParseQueue(repo, factory, newTestStorage(t, t.TempDir()), worker.ParseQueueConfig{QueueSize: 1}, nil)
+	handler := NewDocumentHandler(repo, new(mockDocUserRepository), nil, nil, factory, newTestStorage(t, t.TempDir()), 1024, queue)
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("userID", userID)
+		return c.Next()
+	})
+	app.Post("/route/:id/parse", handler.Parse)
+
+	req := httptest.NewRequest(http.MethodPost, "/route/"+doc.ID.String()+"/parse", nil)
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusAccepted, resp.StatusCode)
+
+	var body dto.ParseJobResponse
+	require.NoError(t, json.Unmarshal(getBodyBytes(t, resp), &body))
+	assert.Equal(t, string(entity.StatusUploaded), body.Status)
+	assert.Empty(t, doc.ErrorMsg)
+
+	// A document already being parsed is rejected
+	doc.Status = entity.StatusParsing
+	req = httptest.NewRequest(http.MethodPost, "/route/"+doc.ID.String()+"/parse", nil)
+	resp, err = app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusConflict, resp.StatusCode)
+}
+
+func TestDocumentUpdateText_SavesRevision(t *testing.T) {
+	repo := new(mockDocumentRepository)
+	revisionRepo := new(mockTextRevisionRepository)
+	factory := parser.NewDocumentParserFactory()
+	userID := uuid.New()
+
+	doc := &entity.Document{ID: uuid.New(), UserID: userID, Status: entity.StatusParsed, ParsedText: "Page 1\nbro-\nken"}
+	repo.On("FindByID", mock.Anything, doc.ID).Return(doc, nil)
+	revisionRepo.On("Create", mock.Anything, mock.MatchedBy(func(revision *entity.DocumentTextRevision) bool {
+		return revision.DocumentID == doc.ID && revision.UserID == userID && revision.Text == "broken\nline" &&
+			revision.Stats.WordCount == 2
+	})).Run(func(args mock.Arguments) {
+		args.Get(1).(*entity.DocumentTextRevision).Revision = 1
+	}).Return(nil)
+
+	handler := NewDocumentHandler(repo, new(mockDocUserRepository), revisionRepo, nil, factory, newTestStorage(t, t.TempDir()), 1024, nil)
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("userID", userID)
+		return c.Next()
+	})
+	app.Put("/route/:id/text", handler.UpdateText)
+
+	// Line endings are normalized like parser output
+	req := httptest.NewRequest(http.MethodPut, "/route/"+doc.ID.String()+"/text", strings.NewReader(`{"text":"broken\r\nline"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var body dto.DocumentTextRevisionResponse
+	require.NoError(t, json.Unmarshal(getBodyBytes(t, resp), &body))
+	assert.Equal(t, 1, body.Revision)
+	assert.Equal(t, "broken\nline", body.Text)
+
+	// Blank text is rejected
+	req = httptest.NewRequest(http.MethodPut, "/route/"+doc.ID.String()+"/text", strings.NewReader(`{"text":"  \n "}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err = app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+	// Asserted last: the mock inspects recorded request contexts, which fasthttp reuses
+	revisionRepo.AssertExpectations(t)
+}
+
+func TestDocumentUpdateText_RejectsUnparsedAndForeignDocuments(t *testing.T) {
+	repo := new(mockDocumentRepository)
+	revisionRepo := new(mockTextRevisionRepository)
+	factory := parser.NewDocumentParserFactory()
+	userID := uuid.New()
+
+	parsing := &entity.Document{ID: uuid.New(), UserID: userID, Status: entity.StatusParsing}
+	foreign := &entity.Document{ID: uuid.New(), UserID: uuid.New(), Status: entity.StatusParsed}
+	repo.On("FindByID", mock.Anything, parsing.ID).Return(parsing, nil)
+	repo.On("FindByID", mock.Anything, foreign.ID).Return(foreign, nil)
+
+	handler := NewDocumentHandler(repo, new(mockDocUserRepository), revisionRepo, nil, factory, newTestStorage(t, t.TempDir()), 1024, nil)
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("userID", userID)
+		return c.Next()
+	})
+	app.Put("/route/:id/text", handler.UpdateText)
+	app.Get("/route/:id/text/revisions", handler.ListTextRevisions)
+
+	req := httptest.NewRequest(http.MethodPut, "/route/"+parsing.ID.String()+"/text", strings.NewReader(`{"text":"fixed"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+	req = httptest.NewRequest(http.MethodPut, "/route/"+foreign.ID.String()+"/text", strings.NewReader(`{"text":"fixed"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err = app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+
+	req = httptest.NewRequest(http.MethodGet, "/route/"+foreign.ID.String()+"/text/revisions", nil)
+	resp, err = app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+
+	revisionRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestDocumentListTextRevisions(t *testing.T) {
+	repo := new(mockDocumentRepository)
+	revisionRepo := new(mockTextRevisionRepository)
+	factory := parser.NewDocumentParserFactory()
+	userID := uuid.New()
+
+	doc := &entity.Document{ID: uuid.New(), UserID: userID, Status: entity.StatusParsed}
+	repo.On("FindByID", mock.Anything, doc.ID).Return(doc, nil)
+	revisionRepo.On("FindByDocumentID", mock.Anything, doc.ID).Return([]*entity.DocumentTextRevision{
+		{ID: uuid.New(), DocumentID: doc.ID, UserID: userID, Revision: 2, Text: "second"},
+		{ID: uuid.New(), DocumentID: doc.ID, UserID: userID, Revision: 1, Text: "first"},
+	}, nil)
+
+	handler := NewDocumentHandler(repo, new(mockDocUserRepository), revisionRepo, nil, factory, newTestStorage(t, t.TempDir()), 1024, nil)
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("userID", userID)
+		return c.Next()
+	})
+	app.Get("/route/:id/text/revisions", handler.ListTextRevisions)
+
+	req := httptest.NewRequest(http.MethodGet, "/route/"+doc.ID.String()+"/text/revisions", nil)
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var body dto.DocumentTextRevisionListResponse
+	require.NoError(t, json.Unmarshal(getBodyBytes(t, resp), &body))
+	require.Equal(t, 2, body.Total)
+	assert.Equal(t, 2, body.Revisions[0].Revision)
+	assert.Equal(t, "first", body.Revisions[1].Text)
+}
+
+func TestDocumentDownloadFile_FullRangeAndInline(t *testing.T) {
+	repo := new(mockDocumentRepository)
+	factory := parser.NewDocumentParserFactory()
+	userID := uuid.New()
+
+	fileStorage := newTestStorage(t, t.TempDir())
+	key := storage.DocumentKey("notes.txt")
+	require.NoError(t, fileStorage.Put(context.Background(), key, strings.NewReader("0123456789"), 10, "text/plain"))
+
+	doc := &entity.Document{ID: uuid.New(), UserID: userID, FileName: "Конспект.txt", FilePath: key, FileType: entity.FileTypeTXT, Encoding: parser.EncodingUTF8}
+	foreign := &entity.Document{ID: uuid.New(), UserID: uuid.New(), FilePath: key, FileType: entity.FileTypeTXT}
+	repo.On("FindByID", mock.Anything, doc.ID).Return(doc, nil)
+	repo.On("FindByID", mock.Anything, foreign.ID).Return(foreign, nil)
+
+	handler := NewDocumentHandler(repo, new(mockDocUserRepository), nil, nil, factory, fileStorage, 1024, nil)
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("userID", userID)
+		return c.Next()
+	})
+	app.Get("/route/:id/file", handler.DownloadFile)
+
+	req := httptest.NewRequest(http.MethodGet, "/route/"+doc.ID.String()+"/file", nil)
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/plain; charset=utf-8", resp.Header.Get("Content-Type"))
+	assert.Equal(t, "bytes", resp.Header.Get("Accept-Ranges"))
+	assert.True(t, strings.HasPrefix(resp.Header.Get("Content-Disposition"), "attachment;"))
+	assert.Equal(t, "0123456789", string(getBodyBytes(t, resp)))
+
+	req = httptest.NewRequest(http.MethodGet, "/route/"+doc.ID.String()+"/file?inline=true", nil)
+	req.Header.Set("Range", "bytes=2-5")
+	resp, err = app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusPartialContent, resp.StatusCode)
+	assert.Equal(t, "bytes 2-5/10", resp.Header.Get("Content-Range"))
+	assert.True(t, strings.HasPrefix(resp.Header.Get("Content-Disposition"), "inline;"))
+	assert.Equal(t, "2345", string(getBodyBytes(t, resp)))
+
+	req = httptest.NewRequest(http.MethodGet, "/route/"+doc.ID.String()+"/file", nil)
+	req.Header.Set("Range", "bytes=10-")
+	resp, err = app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusRequestedRangeNotSatisfiable, resp.StatusCode)
+	assert.Equal(t, "bytes */10", resp.Header.Get("Content-Range"))
+
+	req = httptest.NewRequest(http.MethodGet, "/route/"+foreign.ID.String()+"/file", nil)
+	resp, err = app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+
+	// Missing blob is reported as not found
+	require.NoError(t, fileStorage.Delete(context.Background(), key))
+	req = httptest.NewRequest(http.MethodGet, "/route/"+doc.ID.String()+"/file", nil)
+	resp, err = app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+}
+
+func TestDocumentGetText_Pages(t *testing.T) {
+	repo := new(mockDocumentRepository)
+	factory := parser.NewDocumentParserFactory()
+	userID := uuid.New()
+
+	doc := &entity.Document{
+		ID:            uuid.New(),
+		UserID:        userID,
+		Status:        entity.StatusParsed,
+		ParsedText:    "Страница 1 Текст",
+		CorrectedText: "Текст лекции",
+		TextRevision:  1,
+	}
+	repo.On("FindByID", mock.Anything, doc.ID).Return(doc, nil)
+
+	handler := NewDocumentHandler(repo, new(mockDocUserRepository), nil, nil, factory, newTestStorage(t, t.TempDir()), 1024, nil)
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("userID", userID)
+		return c.Next()
+	})
+	app.Get("/route/:id/text", handler.GetText)
+
+	getPage := func(query string) (int, dto.DocumentTextPageResponse) {
+		req := httptest.NewRequest(http.MethodGet, "/route/"+doc.ID.String()+"/text"+query, nil)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		var page dto.DocumentTextPageResponse
+		if resp.StatusCode == fiber.StatusOK {
+			require.NoError(t, json.Unmarshal(getBodyBytes(t, resp), &page))
+		}
+		return resp.StatusCode, page
+	}
+
+	status, page := getPage("?limit=5")
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, "Текст", page.Text)
+	assert.Equal(t, 12, page.Total)
+	assert.Equal(t, 1, page.TextRevision)
+	assert.True(t, page.HasMore)
+
+	status, page = getPage("?offset=6&limit=100")
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, "лекции", page.Text)
+	assert.False(t, page.HasMore)
+
+	status, page = getPage("?original=true&limit=8")
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, "Страница", page.Text)
+	assert.Equal(t, 0, page.TextRevision)
+
+	status, _ = getPage("?offset=-1")
+	assert.Equal(t, fiber.StatusBadRequest, status)
+	status, _ = getPage("?limit=0")
+	assert.Equal(t, fiber.StatusBadRequest, status)
+}
+
 func getBodyBytes(t *testing.T, resp *http.Response) []byte {
 	body, err := io.ReadAll(resp.Body)
 	require.NoError(t, err)
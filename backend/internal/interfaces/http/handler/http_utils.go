@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// errRangeNotSatisfiable is returned for ranges lying entirely outside the file
+var errRangeNotSatisfiable = errors.New("requested range not satisfiable")
+
+// byteRange is a resolved single HTTP byte range
+type byteRange struct {
+	start  int64
+	length int64
+}
+
+// contentRange formats the Content-Range header value for the range
+func (r byteRange) contentRange(size int64) string {
+	return fmt.Sprintf("bytes %d-%d/%d", r.start, r.start+r.length-1, size)
+}
+
+// parseByteRange resolves a Range header against a file of the given size.
+// It returns nil when the whole file should be sent: no header, a unit other
+// than bytes, a malformed value, or multiple ranges (multipart responses are
+// not supported, and RFC 9110 allows ignoring Range in that case).
+func parseByteRange(header string, size int64) (*byteRange, error) {
+	spec, ok := strings.CutPrefix(strings.TrimSpace(header), "bytes=")
+	if !ok || strings.Contains(spec, ",") {
+		return nil, nil
+	}
+
+	first, last, ok := strings.Cut(strings.TrimSpace(spec), "-")
+	if !ok {
+		return nil, nil
+	}
+
+	// Suffix range: the last N bytes
+	if first == "" {
+		n, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || n < 0 {
+			return nil, nil
+		}
+		if n == 0 || size == 0 {
+			return nil, errRangeNotSatisfiable
+		}
+		if n > size {
+			n = size
+		}
+		return &byteRange{start: size - n, length: n}, nil
+	}
+
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil || start < 0 {
+		return nil, nil
+	}
+	end := size - 1
+	if last != "" {
+		end, err = strconv.ParseInt(last, 10, 64)
+		if err != nil || end < start {
+			return nil, nil
+		}
+		if end >= size {
+			end = size - 1
+		}
+	}
+	if start >= size {
+		return nil, errRangeNotSatisfiable
+	}
+	return &byteRange{start: start, length: end - start + 1}, nil
+}
+
+// contentDisposition builds a Content-Disposition value with an ASCII fallback
+// filename and the exact UTF-8 name in filename* (RFC 6266)
+func contentDisposition(dispositionType, filename string) string {
+	fallback := strings.Map(func(r rune) rune {
+		if r < 0x20 || r > 0x7e || r == '"' || r == '\\' {
+			return '_'
+		}
+		return r
+	}, filename)
+	return fmt.Sprintf(`%s; filename="%s"; filename*=UTF-8''%s`,
+		dispositionType, fallback, strings.ReplaceAll(url.QueryEscape(filename), "+", "%20"))
+}
+
+// sliceRunes returns up to limit characters of text starting at character offset,
+// along with the total number of characters, without converting the whole text to runes
+func sliceRunes(text string, offset, limit int) (string, int) {
+	total := utf8.RuneCountInString(text)
+	if offset >= total {
+		return "", total
+	}
+
+	start := len(text)
+	end := len(text)
+	index := 0
+	for i := range text {
+		if index == offset {
+			start = i
+		}
+		if index == offset+limit {
+			end = i
+			break
+		}
+		index++
+	}
+	return text[start:end], total
+}
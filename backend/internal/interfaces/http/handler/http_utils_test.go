@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseByteRange(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   *byteRange
+		err    error
+	}{
+		{name: "no header", header: ""},
+		{name: "closed range", header: "bytes=0-9", want: &byteRange{start: 0, length: 10}},
+		{name: "open range", header: "bytes=90-", want: &byteRange{start: 90, length: 10}},
+		{name: "suffix range", header: "bytes=-5", want: &byteRange{start: 95, length: 5}},
+		{name: "suffix longer than file", header: "bytes=-500", want: &byteRange{start: 0, length: 100}},
+		{name: "end clamped to size", header: "bytes=50-1000", want: &byteRange{start: 50, length: 50}},
+		{name: "multiple ranges ignored", header: "bytes=0-1,5-6"},
+		{name: "other unit ignored", header: "items=0-1"},
+		{name: "malformed ignored", header: "bytes=abc"},
+		{name: "reversed ignored", header: "bytes=9-1"},
+		{name: "start past end", header: "bytes=100-", err: errRangeNotSatisfiable},
+		{name: "empty suffix", header: "bytes=-0", err: errRangeNotSatisfiable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseByteRange(tt.header, 100)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestContentDisposition(t *testing.T) {
+	assert.Equal(t,
+		`attachment; filename="lecture.pdf"; filename*=UTF-8''lecture.pdf`,
+		contentDisposition("attachment", "lecture.pdf"))
+	assert.Equal(t,
+		`inline; filename="______ 1.pdf"; filename*=UTF-8''%D0%9B%D0%B5%D0%BA%D1%86%D0%B8%D1%8F%201.pdf`,
+		contentDisposition("inline", "Лекция 1.pdf"))
+	assert.Equal(t,
+		`attachment; filename="a_b_.txt"; filename*=UTF-8''a%22b%0A.txt`,
+		contentDisposition("attachment", "a\"b\n.txt"))
+}
+
+func TestSliceRunes(t *testing.T) {
+	text := "Привет, world"
+
+	page, total := sliceRunes(text, 0, 6)
+	assert.Equal(t, "Привет", page)
+	assert.Equal(t, 13, total)
+
+	page, _ = sliceRunes(text, 8, 100)
+	assert.Equal(t, "world", page)
+
+	page, _ = sliceRunes(text, 13, 10)
+	assert.Empty(t, page)
+}
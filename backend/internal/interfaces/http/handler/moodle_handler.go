@@ -6,7 +6,6 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"github.com/shester1kov/testgen-backend/internal/application/dto"
-	"github.com/shester1kov/testgen-backend/internal/domain/entity"
 	"github.com/shester1kov/testgen-backend/internal/domain/repository"
 	"github.com/shester1kov/testgen-backend/internal/infrastructure/moodle"
 	"github.com/shester1kov/testgen-backend/pkg/security"
@@ -16,7 +15,6 @@ import (
 type MoodleHandler struct {
 	testRepo     repository.TestRepository
 	questionRepo repository.QuestionRepository
-	answerRepo   repository.AnswerRepository
 	xmlExporter  *moodle.MoodleXMLExporter
 	moodleClient *moodle.Client
 }
@@ -25,14 +23,12 @@ type MoodleHandler struct {
 func NewMoodleHandler(
 	testRepo repository.TestRepository,
 	questionRepo repository.QuestionRepository,
-	answerRepo repository.AnswerRepository,
 	xmlExporter *moodle.MoodleXMLExporter,
 	moodleClient *moodle.Client,
 ) *MoodleHandler {
 	return &MoodleHandler{
 		testRepo:     testRepo,
 		questionRepo: questionRepo,
-		answerRepo:   answerRepo,
 		xmlExporter:  xmlExporter,
 		moodleClient: moodleClient,
 	}
@@ -74,7 +70,7 @@ func (h *MoodleHandler) ExportToXML(c *fiber.Ctx) error {
 	}
 
 	// Get questions for the test
-	questions, err := h.questionRepo.FindByTestID(c.Context(), testID)
+	questions, err := h.questionRepo.FindByTestIDWithAnswers(c.Context(), testID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(
 			dto.NewErrorResponse(dto.ErrCodeDatabaseError, "failed to retrieve questions"),
@@ -87,20 +83,8 @@ func (h *MoodleHandler) ExportToXML(c *fiber.Ctx) error {
 		)
 	}
 
-	// Get answers for each question
-	answersMap := make(map[string][]*entity.Answer)
-	for _, q := range questions {
-		answers, err := h.answerRepo.FindByQuestionID(c.Context(), q.ID)
-		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(
-				dto.NewErrorResponse(dto.ErrCodeDatabaseError, "failed to retrieve answers"),
-			)
-		}
-		answersMap[q.ID.String()] = answers
-	}
-
 	// Export to XML
-	xmlContent, err := h.xmlExporter.Export(test, questions, answersMap)
+	xmlContent, err := h.xmlExporter.Export(test, questions, moodle.AnswersByQuestion(questions))
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(
 			dto.NewErrorResponse(dto.ErrCodeExportFailed, "failed to export XML"),
@@ -162,7 +146,7 @@ func (h *MoodleHandler) SyncToMoodle(c *fiber.Ctx) error {
 	}
 
 	// Get questions
-	questions, err := h.questionRepo.FindByTestID(c.Context(), testID)
+	questions, err := h.questionRepo.FindByTestIDWithAnswers(c.Context(), testID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(
 			dto.NewErrorResponse(dto.ErrCodeDatabaseError, "failed to retrieve questions"),
@@ -175,20 +159,8 @@ func (h *MoodleHandler) SyncToMoodle(c *fiber.Ctx) error {
 		)
 	}
 
-	// Get answers
-	answersMap := make(map[string][]*entity.Answer)
-	for _, q := range questions {
-		answers, err := h.answerRepo.FindByQuestionID(c.Context(), q.ID)
-		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(
-				dto.NewErrorResponse(dto.ErrCodeDatabaseError, "failed to retrieve answers"),
-			)
-		}
-		answersMap[q.ID.String()] = answers
-	}
-
 	// Export to XML
-	xmlContent, err := h.xmlExporter.Export(test, questions, answersMap)
+	xmlContent, err := h.xmlExporter.Export(test, questions, moodle.AnswersByQuestion(questions))
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(
 			dto.NewErrorResponse(dto.ErrCodeExportFailed, "failed to export XML"),
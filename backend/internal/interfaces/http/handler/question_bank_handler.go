@@ -0,0 +1,1438 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/shester1kov/testgen-backend/internal/application/dto"
+	"github.com/shester1kov/testgen-backend/internal/domain/entity"
+	"github.com/shester1kov/testgen-backend/internal/domain/repository"
+	"github.com/shester1kov/testgen-backend/internal/infrastructure/llm"
+	"github.com/shester1kov/testgen-backend/pkg/security"
+	"gorm.io/gorm"
+)
+
+// maxCategoryNameLength limits a category name, in characters
+const maxCategoryNameLength = 255
+
+var (
+	// errInvalidCategoryID is returned when a request carries a malformed category ID
+	errInvalidCategoryID = errors.New("invalid category ID")
+	// errCategoryNotOwned is returned when a request refers to a missing or foreign category
+	errCategoryNotOwned = errors.New("category not found")
+)
+
+// QuestionBankHandler handles the reusable question bank of a teacher
+type QuestionBankHandler struct {
+	bankRepo     repository.BankQuestionRepository
+	categoryRepo repository.QuestionCategoryRepository
+	testRepo     repository.TestRepository
+	questionRepo repository.QuestionRepository
+	documentRepo repository.DocumentRepository
+	userRepo     repository.UserRepository
+	llmFactory   *llm.LLMFactory
+}
+
+// NewQuestionBankHandler creates a new question bank handler
+func NewQuestionBankHandler(
+	bankRepo repository.BankQuestionRepository,
+	categoryRepo repository.QuestionCategoryRepository,
+	testRepo repository.TestRepository,
+	questionRepo repository.QuestionRepository,
+	documentRepo repository.DocumentRepository,
+	userRepo repository.UserRepository,
+	llmFactory *llm.LLMFactory,
+) *QuestionBankHandler {
+	return &QuestionBankHandler{
+		bankRepo:     bankRepo,
+		categoryRepo: categoryRepo,
+		testRepo:     testRepo,
+		questionRepo: questionRepo,
+		documentRepo: documentRepo,
+		userRepo:     userRepo,
+		llmFactory:   llmFactory,
+	}
+}
+
+// ListCategories godoc
+// @Summary List question bank categories
+// @Description Get the category tree of the current user's question bank
+// @Tags question-bank
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.CategoryListResponse
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /bank/categories [get]
+func (h *QuestionBankHandler) ListCategories(c *fiber.Ctx) error {
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(
+			dto.NewErrorResponse(dto.ErrCodeUnauthorized, "Unauthorized"),
+		)
+	}
+
+	categories, err := h.categoryRepo.FindByUserID(c.Context(), userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(
+			dto.NewErrorResponse(dto.ErrCodeDatabaseError, "failed to fetch categories"),
+		)
+	}
+
+	return c.JSON(dto.CategoryListResponse{Categories: categoryTree(categories)})
+}
+
+// CreateCategory godoc
+// @Summary Create a question bank category
+// @Description Create a category, optionally nested in another category of the current user
+// @Tags question-bank
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.CreateCategoryRequest true "Create category request"
+// @Success 201 {object} dto.CategoryResponse
+// @Failure 400 {object} dto.ErrorResponse "Invalid request"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 404 {object} dto.ErrorResponse "Parent category not found"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /bank/categories [post]
+func (h *QuestionBankHandler) CreateCategory(c *fiber.Ctx) error {
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(
+			dto.NewErrorResponse(dto.ErrCodeUnauthorized, "Unauthorized"),
+		)
+	}
+
+	var req dto.CreateCategoryRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInvalidInput, "invalid request body"),
+		)
+	}
+
+	name := security.SanitizeInput(req.Name)
+	if msg := categoryNameError(name); msg != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInvalidInput, msg),
+		)
+	}
+
+	parentID, err := h.ownedCategoryID(c.Context(), userID, req.ParentID)
+	if err != nil {
+		status, errResp := categoryLookupError(err)
+		return c.Status(status).JSON(errResp)
+	}
+
+	category := &entity.QuestionCategory{
+		ID:        uuid.New(),
+		UserID:    userID,
+		ParentID:  parentID,
+		Name:      name,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := h.categoryRepo.Create(c.Context(), category); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(
+			dto.NewErrorResponse(dto.ErrCodeDatabaseError, "failed to create category"),
+		)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(categoryResponse(category))
+}
+
+// UpdateCategory godoc
+// @Summary Rename or move a question bank category
+// @Description Change the name or the parent of a category; a category cannot be moved into its own subtree
+// @Tags question-bank
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Category ID"
+// @Param request body dto.UpdateCategoryRequest true "Update category request"
+// @Success 200 {object} dto.CategoryResponse
+// @Failure 400 {object} dto.ErrorResponse "Invalid request"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 403 {object} dto.ErrorResponse "Access denied"
+// @Failure 404 {object} dto.ErrorResponse "Category not found"
+// @Failure 409 {object} dto.ErrorResponse "Move would create a cycle"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /bank/categories/{id} [put]
+func (h *QuestionBankHandler) UpdateCategory(c *fiber.Ctx) error {
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(
+			dto.NewErrorResponse(dto.ErrCodeUnauthorized, "Unauthorized"),
+		)
+	}
+
+	categoryID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInvalidUUID, "invalid category ID"),
+		)
+	}
+
+	category, err := h.categoryRepo.FindByID(c.Context(), categoryID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(
+			dto.NewErrorResponse(dto.ErrCodeCategoryNotFound, "category not found"),
+		)
+	}
+	if category.UserID != userID {
+		return c.Status(fiber.StatusForbidden).JSON(
+			dto.NewErrorResponse(dto.ErrCodeForbidden, "access denied"),
+		)
+	}
+
+	var req dto.UpdateCategoryRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInvalidInput, "invalid request body"),
+		)
+	}
+
+	if req.Name != "" {
+		name := security.SanitizeInput(req.Name)
+		if msg := categoryNameError(name); msg != "" {
+			return c.Status(fiber.StatusBadRequest).JSON(
+				dto.NewErrorResponse(dto.ErrCodeInvalidInput, msg),
+			)
+		}
+		category.Name = name
+	}
+
+	if req.ParentID != nil {
+		category.ParentID = nil
+		if *req.ParentID != "" {
+			parentID, err := h.ownedCategoryID(c.Context(), userID, req.ParentID)
+			if err != nil {
+				status, errResp := categoryLookupError(err)
+				return c.Status(status).JSON(errResp)
+			}
+
+			categories, err := h.categoryRepo.FindByUserID(c.Context(), userID)
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(
+					dto.NewErrorResponse(dto.ErrCodeDatabaseError, "failed to fetch categories"),
+				)
+			}
+			if isInCategorySubtree(categories, *parentID, category.ID) {
+				return c.Status(fiber.StatusConflict).JSON(
+					dto.NewErrorResponse(dto.ErrCodeConflict, "category cannot be moved into its own subtree"),
+				)
+			}
+			category.ParentID = parentID
+		}
+	}
+
+	category.UpdatedAt = time.Now()
+	if err := h.categoryRepo.Update(c.Context(), category); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(
+			dto.NewErrorResponse(dto.ErrCodeDatabaseError, "failed to update category"),
+		)
+	}
+
+	return c.JSON(categoryResponse(category))
+}
+
+// DeleteCategory godoc
+// @Summary Delete a question bank category
+// @Description Delete a category with its subcategories; their questions stay in the bank without a category
+// @Tags question-bank
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Category ID"
+// @Success 200 {object} dto.MessageResponse
+// @Failure 400 {object} dto.ErrorResponse "Invalid category ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 403 {object} dto.ErrorResponse "Access denied"
+// @Failure 404 {object} dto.ErrorResponse "Category not found"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /bank/categories/{id} [delete]
+func (h *QuestionBankHandler) DeleteCategory(c *fiber.Ctx) error {
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(
+			dto.NewErrorResponse(dto.ErrCodeUnauthorized, "Unauthorized"),
+		)
+	}
+
+	categoryID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInvalidUUID, "invalid category ID"),
+		)
+	}
+
+	category, err := h.categoryRepo.FindByID(c.Context(), categoryID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(
+			dto.NewErrorResponse(dto.ErrCodeCategoryNotFound, "category not found"),
+		)
+	}
+	if category.UserID != userID {
+		return c.Status(fiber.StatusForbidden).JSON(
+			dto.NewErrorResponse(dto.ErrCodeForbidden, "access denied"),
+		)
+	}
+
+	if err := h.categoryRepo.Delete(c.Context(), categoryID); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(
+			dto.NewErrorResponse(dto.ErrCodeDatabaseError, "failed to delete category"),
+		)
+	}
+
+	return c.JSON(dto.NewMessageResponse("category deleted"))
+}
+
+// ListTags godoc
+// @Summary List question bank tags
+// @Description Get the tags used in the current user's question bank
+// @Tags question-bank
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.TagListResponse
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /bank/tags [get]
+func (h *QuestionBankHandler) ListTags(c *fiber.Ctx) error {
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(
+			dto.NewErrorResponse(dto.ErrCodeUnauthorized, "Unauthorized"),
+		)
+	}
+
+	tags, err := h.bankRepo.FindTagsByUserID(c.Context(), userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(
+			dto.NewErrorResponse(dto.ErrCodeDatabaseError, "failed to fetch tags"),
+		)
+	}
+
+	names := make([]string, len(tags))
+	for i, tag := range tags {
+		names[i] = tag.Name
+	}
+	return c.JSON(dto.TagListResponse{Tags: names})
+}
+
+// ListQuestions godoc
+// @Summary Browse the question bank
+// @Description Get a page of the current user's bank questions, newest first, filtered by category, tags, type, difficulty and text
+// @Tags question-bank
+// @Produce json
+// @Security BearerAuth
+// @Param category_id query string false "Category ID"
+// @Param include_subcategories query bool false "Also match questions of nested categories" default(true)
+// @Param tags query string false "Comma-separated tags; questions must carry every tag"
+// @Param type query string false "Question type"
+// @Param difficulty query string false "Difficulty"
+// @Param q query string false "Text the question contains, case-insensitive"
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(20)
+// @Success 200 {object} dto.BankQuestionListResponse
+// @Failure 400 {object} dto.ErrorResponse "Invalid filter"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /bank/questions [get]
+func (h *QuestionBankHandler) ListQuestions(c *fiber.Ctx) error {
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(
+			dto.NewErrorResponse(dto.ErrCodeUnauthorized, "Unauthorized"),
+		)
+	}
+
+	filter := repository.BankQuestionFilter{
+		UserID:               userID,
+		IncludeSubcategories: c.QueryBool("include_subcategories", true),
+		QuestionType:         entity.QuestionType(c.Query("type")),
+		Difficulty:           entity.Difficulty(c.Query("difficulty")),
+		Text:                 strings.TrimSpace(c.Query("q")),
+	}
+
+	if rawCategoryID := c.Query("category_id"); rawCategoryID != "" {
+		categoryID, err := uuid.Parse(rawCategoryID)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(
+				dto.NewErrorResponse(dto.ErrCodeInvalidUUID, "invalid category ID"),
+			)
+		}
+		filter.CategoryID = &categoryID
+	}
+	if rawTags := c.Query("tags"); rawTags != "" {
+		tags, ok := entity.NormalizeTagNames(strings.Split(rawTags, ","))
+		if !ok {
+			return c.Status(fiber.StatusBadRequest).JSON(
+				dto.NewErrorResponse(dto.ErrCodeInvalidInput, fmt.Sprintf("tags must not exceed %d characters", entity.MaxTagLength)),
+			)
+		}
+		filter.Tags = tags
+	}
+	if filter.QuestionType != "" && !(&entity.Question{QuestionType: filter.QuestionType}).IsValidType() {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInvalidInput, "invalid question type"),
+		)
+	}
+	if filter.Difficulty != "" && !(&entity.Question{Difficulty: filter.Difficulty}).IsValidDifficulty() {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInvalidInput, "invalid difficulty"),
+		)
+	}
+	if utf8.RuneCountInString(filter.Text) > maxSearchQueryLength {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInvalidInput, fmt.Sprintf("search query must not exceed %d characters", maxSearchQueryLength)),
+		)
+	}
+
+	page, pageSize := c.QueryInt("page", 1), c.QueryInt("page_size", 20)
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+	filter.Limit = pageSize
+	filter.Offset = (page - 1) * pageSize
+
+	questions, total, err := h.bankRepo.List(c.Context(), filter)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(
+			dto.NewErrorResponse(dto.ErrCodeDatabaseError, "failed to fetch questions"),
+		)
+	}
+
+	language := labelLanguage(c)
+	result := make([]dto.BankQuestionResponse, len(questions))
+	for i, q := range questions {
+		result[i] = bankQuestionResponse(q, language)
+	}
+
+	return c.JSON(dto.BankQuestionListResponse{Questions: result, Total: total, Page: page, PageSize: pageSize})
+}
+
+// GetQuestion godoc
+// @Summary Get a bank question
+// @Description Get a bank question with its answers, tags and the tests it was added to
+// @Tags question-bank
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Bank question ID"
+// @Success 200 {object} dto.BankQuestionResponse
+// @Failure 400 {object} dto.ErrorResponse "Invalid question ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 403 {object} dto.ErrorResponse "Access denied"
+// @Failure 404 {object} dto.ErrorResponse "Question not found"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /bank/questions/{id} [get]
+func (h *QuestionBankHandler) GetQuestion(c *fiber.Ctx) error {
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(
+			dto.NewErrorResponse(dto.ErrCodeUnauthorized, "Unauthorized"),
+		)
+	}
+
+	questionID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInvalidUUID, "invalid question ID"),
+		)
+	}
+
+	question, err := h.bankRepo.FindByID(c.Context(), questionID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(
+			dto.NewErrorResponse(dto.ErrCodeBankQuestionNotFound, "question not found"),
+		)
+	}
+	if question.UserID != userID {
+		return c.Status(fiber.StatusForbidden).JSON(
+			dto.NewErrorResponse(dto.ErrCodeForbidden, "access denied"),
+		)
+	}
+
+	testIDs, err := h.bankRepo.FindTestIDs(c.Context(), questionID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(
+			dto.NewErrorResponse(dto.ErrCodeDatabaseError, "failed to fetch question usage"),
+		)
+	}
+
+	response := bankQuestionResponse(question, labelLanguage(c))
+	response.TestIDs = make([]string, len(testIDs))
+	for i, id := range testIDs {
+		response.TestIDs[i] = id.String()
+	}
+	return c.JSON(response)
+}
+
+// CreateQuestion godoc
+// @Summary Add a question to the bank
+// @Description Create a bank question with its answers; unknown tags are created
+// @Tags question-bank
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.BankQuestionRequest true "Bank question"
+// @Success 201 {object} dto.BankQuestionResponse
+// @Failure 400 {object} dto.ErrorResponse "Invalid request"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 404 {object} dto.ErrorResponse "Category not found"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /bank/questions [post]
+func (h *QuestionBankHandler) CreateQuestion(c *fiber.Ctx) error {
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(
+			dto.NewErrorResponse(dto.ErrCodeUnauthorized, "Unauthorized"),
+		)
+	}
+
+	var req dto.BankQuestionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInvalidInput, "invalid request body"),
+		)
+	}
+
+	question := &entity.BankQuestion{
+		ID:        uuid.New(),
+		UserID:    userID,
+		CreatedAt: time.Now(),
+	}
+	if status, errResp := h.applyBankQuestionRequest(c.Context(), question, &req); errResp != nil {
+		return c.Status(status).JSON(errResp)
+	}
+
+	if err := h.bankRepo.Create(c.Context(), question); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(
+			dto.NewErrorResponse(dto.ErrCodeDatabaseError, "failed to create question"),
+		)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(bankQuestionResponse(question, labelLanguage(c)))
+}
+
+// UpdateQuestion godoc
+// @Summary Update a bank question
+// @Description Replace a bank question with its answers and tags; copies already added to tests do not change
+// @Tags question-bank
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Bank question ID"
+// @Param request body dto.BankQuestionRequest true "Bank question"
+// @Success 200 {object} dto.BankQuestionResponse
+// @Failure 400 {object} dto.ErrorResponse "Invalid request"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 403 {object} dto.ErrorResponse "Access denied"
+// @Failure 404 {object} dto.ErrorResponse "Question or category not found"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /bank/questions/{id} [put]
+func (h *QuestionBankHandler) UpdateQuestion(c *fiber.Ctx) error {
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(
+			dto.NewErrorResponse(dto.ErrCodeUnauthorized, "Unauthorized"),
+		)
+	}
+
+	questionID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInvalidUUID, "invalid question ID"),
+		)
+	}
+
+	question, err := h.bankRepo.FindByID(c.Context(), questionID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(
+			dto.NewErrorResponse(dto.ErrCodeBankQuestionNotFound, "question not found"),
+		)
+	}
+	if question.UserID != userID {
+		return c.Status(fiber.StatusForbidden).JSON(
+			dto.NewErrorResponse(dto.ErrCodeForbidden, "access denied"),
+		)
+	}
+
+	var req dto.BankQuestionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInvalidInput, "invalid request body"),
+		)
+	}
+
+	if status, errResp := h.applyBankQuestionRequest(c.Context(), question, &req); errResp != nil {
+		return c.Status(status).JSON(errResp)
+	}
+
+	if err := h.bankRepo.Update(c.Context(), question); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(
+			dto.NewErrorResponse(dto.ErrCodeDatabaseError, "failed to update question"),
+		)
+	}
+
+	return c.JSON(bankQuestionResponse(question, labelLanguage(c)))
+}
+
+// DeleteQuestion godoc
+// @Summary Delete a bank question
+// @Description Delete a bank question; copies already added to tests are kept
+// @Tags question-bank
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Bank question ID"
+// @Success 200 {object} dto.MessageResponse
+// @Failure 400 {object} dto.ErrorResponse "Invalid question ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 403 {object} dto.ErrorResponse "Access denied"
+// @Failure 404 {object} dto.ErrorResponse "Question not found"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /bank/questions/{id} [delete]
+func (h *QuestionBankHandler) DeleteQuestion(c *fiber.Ctx) error {
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(
+			dto.NewErrorResponse(dto.ErrCodeUnauthorized, "Unauthorized"),
+		)
+	}
+
+	questionID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInvalidUUID, "invalid question ID"),
+		)
+	}
+
+	question, err := h.bankRepo.FindByID(c.Context(), questionID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(
+			dto.NewErrorResponse(dto.ErrCodeBankQuestionNotFound, "question not found"),
+		)
+	}
+	if question.UserID != userID {
+		return c.Status(fiber.StatusForbidden).JSON(
+			dto.NewErrorResponse(dto.ErrCodeForbidden, "access denied"),
+		)
+	}
+
+	if err := h.bankRepo.Delete(c.Context(), questionID); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(
+			dto.NewErrorResponse(dto.ErrCodeDatabaseError, "failed to delete question"),
+		)
+	}
+
+	return c.JSON(dto.NewMessageResponse("question deleted"))
+}
+
+// SaveFromTest godoc
+// @Summary Save test questions to the bank
+// @Description Copy questions of one of the current user's tests into the question bank, optionally into a category and with tags
+// @Tags question-bank
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.SaveToBankRequest true "Questions to save"
+// @Success 201 {object} dto.BankQuestionListResponse
+// @Failure 400 {object} dto.ErrorResponse "Invalid request"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 403 {object} dto.ErrorResponse "Access denied"
+// @Failure 404 {object} dto.ErrorResponse "Test, question or category not found"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /bank/questions/from-test [post]
+func (h *QuestionBankHandler) SaveFromTest(c *fiber.Ctx) error {
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(
+			dto.NewErrorResponse(dto.ErrCodeUnauthorized, "Unauthorized"),
+		)
+	}
+
+	var req dto.SaveToBankRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInvalidInput, "invalid request body"),
+		)
+	}
+
+	testID, err := uuid.Parse(req.TestID)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInvalidTestID, "invalid test ID"),
+		)
+	}
+	wanted := make(map[uuid.UUID]bool, len(req.QuestionIDs))
+	for _, raw := range req.QuestionIDs {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(
+				dto.NewErrorResponse(dto.ErrCodeInvalidInput, "invalid question ID"),
+			)
+		}
+		wanted[id] = true
+	}
+	tags, ok := entity.NormalizeTagNames(req.Tags)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInvalidInput, fmt.Sprintf("tags must not exceed %d characters", entity.MaxTagLength)),
+		)
+	}
+
+	// Check if test exists and belongs to user
+	test, err := h.testRepo.FindByID(c.Context(), testID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(
+			dto.NewErrorResponse(dto.ErrCodeTestNotFound, "test not found"),
+		)
+	}
+	if test.UserID != userID {
+		return c.Status(fiber.StatusForbidden).JSON(
+			dto.NewErrorResponse(dto.ErrCodeForbidden, "access denied"),
+		)
+	}
+
+	categoryID, err := h.ownedCategoryID(c.Context(), userID, req.CategoryID)
+	if err != nil {
+		status, errResp := categoryLookupError(err)
+		return c.Status(status).JSON(errResp)
+	}
+
+	questions, err := h.questionRepo.FindByTestIDWithAnswers(c.Context(), testID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(
+			dto.NewErrorResponse(dto.ErrCodeDatabaseError, "failed to load questions"),
+		)
+	}
+
+	selected := len(wanted) > 0
+	var bankQuestions []*entity.BankQuestion
+	for _, q := range questions {
+		if selected && !wanted[q.ID] {
+			continue
+		}
+		delete(wanted, q.ID)
+
+		bankQuestion := entity.NewBankQuestionFromQuestion(userID, q)
+		bankQuestion.ID = uuid.New()
+		bankQuestion.CategoryID = categoryID
+		bankQuestion.CreatedAt = time.Now()
+		bankQuestion.UpdatedAt = time.Now()
+		for _, name := range tags {
+			bankQuestion.Tags = append(bankQuestion.Tags, entity.QuestionTag{Name: name})
+		}
+		bankQuestions = append(bankQuestions, bankQuestion)
+	}
+	if len(wanted) > 0 {
+		return c.Status(fiber.StatusNotFound).JSON(
+			dto.NewErrorResponse(dto.ErrCodeNotFound, "question not found in the test"),
+		)
+	}
+	if len(bankQuestions) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			dto.NewErrorResponse(dto.ErrCodeTestHasNoQuestions, "test has no questions"),
+		)
+	}
+
+	if err := h.bankRepo.Create(c.Context(), bankQuestions...); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(
+			dto.NewErrorResponse(dto.ErrCodeDatabaseError, "failed to save questions"),
+		)
+	}
+
+	language := labelLanguage(c)
+	result := make([]dto.BankQuestionResponse, len(bankQuestions))
+	for i, q := range bankQuestions {
+		result[i] = bankQuestionResponse(q, language)
+	}
+	return c.Status(fiber.StatusCreated).JSON(dto.BankQuestionListResponse{
+		Questions: result,
+		Total:     int64(len(result)),
+		Page:      1,
+		PageSize:  len(result),
+	})
+}
+
+// AddToTest godoc
+// @Summary Add bank questions to a test
+// @Description Copy bank questions to the end of a test in the given order and link them to the test
+// @Tags tests
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param testId path string true "Test ID"
+// @Param request body dto.AddBankQuestionsRequest true "Bank questions to add"
+// @Success 201 {array} dto.QuestionDTO
+// @Failure 400 {object} dto.ErrorResponse "Invalid request"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 403 {object} dto.ErrorResponse "Access denied"
+// @Failure 404 {object} dto.ErrorResponse "Test or bank question not found"
+// @Failure 409 {object} dto.ErrorResponse "Bank question is already in the test"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /tests/{testId}/questions/from-bank [post]
+func (h *QuestionBankHandler) AddToTest(c *fiber.Ctx) error {
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(
+			dto.NewErrorResponse(dto.ErrCodeUnauthorized, "Unauthorized"),
+		)
+	}
+
+	testID, err := uuid.Parse(c.Params("testId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInvalidInput, "invalid test ID"),
+		)
+	}
+
+	// Check if test exists and belongs to user
+	test, err := h.testRepo.FindByID(c.Context(), testID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(
+			dto.NewErrorResponse(dto.ErrCodeTestNotFound, "test not found"),
+		)
+	}
+	if test.UserID != userID {
+		return c.Status(fiber.StatusForbidden).JSON(
+			dto.NewErrorResponse(dto.ErrCodeForbidden, "access denied"),
+		)
+	}
+	if !test.IsEditable() {
+		return testLockedError(c)
+	}
+
+	var req dto.AddBankQuestionsRequest
+	if err := c.BodyParser(&req); err != nil || len(req.BankQuestionIDs) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInvalidInput, "bank_question_ids is required"),
+		)
+	}
+
+	ids := make([]uuid.UUID, 0, len(req.BankQuestionIDs))
+	seen := make(map[uuid.UUID]bool, len(req.BankQuestionIDs))
+	for _, raw := range req.BankQuestionIDs {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(
+				dto.NewErrorResponse(dto.ErrCodeInvalidInput, "invalid bank question ID"),
+			)
+		}
+		if seen[id] {
+			return c.Status(fiber.StatusBadRequest).JSON(
+				dto.NewErrorResponse(dto.ErrCodeInvalidInput, "bank_question_ids must not repeat"),
+			)
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+
+	found, err := h.bankRepo.FindByIDs(c.Context(), ids)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(
+			dto.NewErrorResponse(dto.ErrCodeDatabaseError, "failed to fetch bank questions"),
+		)
+	}
+	byID := make(map[uuid.UUID]*entity.BankQuestion, len(found))
+	for _, q := range found {
+		if q.UserID == userID {
+			byID[q.ID] = q
+		}
+	}
+
+	// Keep the requested order; foreign questions are reported as missing
+	bankQuestions := make([]*entity.BankQuestion, len(ids))
+	for i, id := range ids {
+		q, ok := byID[id]
+		if !ok {
+			return c.Status(fiber.StatusNotFound).JSON(
+				dto.NewErrorResponse(dto.ErrCodeBankQuestionNotFound, fmt.Sprintf("bank question %s not found", id)),
+			)
+		}
+		bankQuestions[i] = q
+	}
+
+	questions, err := h.bankRepo.AddToTest(c.Context(), testID, bankQuestions)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrBankQuestionInTest):
+			return c.Status(fiber.StatusConflict).JSON(
+				dto.NewErrorResponse(dto.ErrCodeConflict, "bank question is already in the test"),
+			)
+		case errors.Is(err, repository.ErrTestLocked):
+			return testLockedError(c)
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(
+				dto.NewErrorResponse(dto.ErrCodeTestNotFound, "test not found"),
+			)
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInternalError, "failed to add questions"),
+		)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(questionDTOs(questions, labelLanguage(c)))
+}
+
+// Assemble godoc
+// @Summary Assemble a test from the question bank
+// @Description Create a test by drawing random bank questions for each blueprint rule without repeats. Rules that cannot be filled are reported with their shortfall; with top_up the missing questions are generated with LLM from the linked document.
+// @Tags tests
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.AssembleTestRequest true "Test blueprint"
+// @Success 201 {object} dto.AssembleTestResponse
+// @Failure 400 {object} dto.ErrorResponse "Invalid blueprint or no matching questions"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 403 {object} dto.ErrorResponse "Access denied to the document"
+// @Failure 404 {object} dto.ErrorResponse "Category or document not found"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /tests/assemble [post]
+func (h *QuestionBankHandler) Assemble(c *fiber.Ctx) error {
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(
+			dto.NewErrorResponse(dto.ErrCodeUnauthorized, "Unauthorized"),
+		)
+	}
+
+	var req dto.AssembleTestRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInvalidInput, "invalid request body"),
+		)
+	}
+
+	title := security.SanitizeInput(req.Title)
+	if utf8.RuneCountInString(title) < 3 {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInvalidInput, "title must be at least 3 characters"),
+		)
+	}
+
+	blueprint, status, errResp := h.blueprintFromRequest(c.Context(), userID, &req)
+	if errResp != nil {
+		return c.Status(status).JSON(errResp)
+	}
+
+	// The document is linked to the test and is the source for topping up
+	var document *entity.Document
+	if req.DocumentID != nil && *req.DocumentID != "" {
+		docID, err := uuid.Parse(*req.DocumentID)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(
+				dto.NewErrorResponse(dto.ErrCodeInvalidDocumentID, "invalid document ID"),
+			)
+		}
+		document, err = h.documentRepo.FindByID(c.Context(), docID)
+		if err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(
+				dto.NewErrorResponse(dto.ErrCodeDocumentNotFound, "document not found"),
+			)
+		}
+		user, err := h.userRepo.FindByID(c.Context(), userID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(
+				dto.NewErrorResponse(dto.ErrCodeDatabaseError, "failed to fetch user"),
+			)
+		}
+		// Non-admin users can only use their own documents
+		if !user.IsAdmin() && document.UserID != userID {
+			return c.Status(fiber.StatusForbidden).JSON(
+				dto.NewErrorResponse(dto.ErrCodeForbidden, "access denied to this document"),
+			)
+		}
+	}
+
+	var strategy llm.LLMStrategy
+	if req.TopUp {
+		if document == nil {
+			return c.Status(fiber.StatusBadRequest).JSON(
+				dto.NewErrorResponse(dto.ErrCodeInvalidInput, "document_id is required for top_up"),
+			)
+		}
+		if !document.IsParsed() {
+			return c.Status(fiber.StatusBadRequest).JSON(
+				dto.NewErrorResponse(dto.ErrCodeDocumentNotParsed, "document not parsed yet"),
+			)
+		}
+		provider := req.LLMProvider
+		if provider == "" {
+			provider = "perplexity"
+		}
+		var err error
+		strategy, err = h.llmFactory.CreateStrategy(provider)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(
+				dto.NewErrorResponse(dto.ErrCodeInvalidProvider, err.Error()),
+			)
+		}
+	}
+
+	var warnings []string
+	if strategy != nil {
+		tokens, limit := documentTokenEstimate(document, strategy.GetProviderName()), llm.InputTokenLimit(strategy.GetProviderName())
+		if limit > 0 && tokens > limit {
+			warnings = append(warnings, fmt.Sprintf(
+				"document is about %d tokens, more than the %d tokens %s accepts; generated questions may cover only part of it",
+				tokens, limit, strategy.GetProviderName(),
+			))
+		}
+	}
+
+	candidates := make([][]*entity.BankQuestion, len(blueprint.Rules))
+	for i := range blueprint.Rules {
+		matching, err := h.bankRepo.FindMatching(c.Context(), blueprintRuleFilter(userID, blueprint, i))
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(
+				dto.NewErrorResponse(dto.ErrCodeDatabaseError, "failed to fetch bank questions"),
+			)
+		}
+		candidates[i] = matching
+	}
+	drawn := blueprint.Draw(candidates, rand.Shuffle)
+
+	// Candidates come without answers; load the drawn questions in full
+	var drawnIDs []uuid.UUID
+	for _, questions := range drawn {
+		for _, q := range questions {
+			drawnIDs = append(drawnIDs, q.ID)
+		}
+	}
+	full, err := h.bankRepo.FindByIDs(c.Context(), drawnIDs)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(
+			dto.NewErrorResponse(dto.ErrCodeDatabaseError, "failed to fetch bank questions"),
+		)
+	}
+	byID := make(map[uuid.UUID]*entity.BankQuestion, len(full))
+	for _, q := range full {
+		byID[q.ID] = q
+	}
+
+	test := &entity.Test{
+		ID:          uuid.New(),
+		UserID:      userID,
+		Title:       title,
+		Description: security.SanitizeMultiline(req.Description),
+		Status:      entity.TestStatusDraft,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	if document != nil {
+		test.DocumentID = &document.ID
+	}
+
+	pickedTypes := make(map[entity.QuestionType]int)
+	for _, questions := range drawn {
+		for _, q := range questions {
+			pickedTypes[q.QuestionType]++
+		}
+	}
+
+	var links []entity.TestBankQuestion
+	reports := make([]dto.BlueprintRuleReport, len(blueprint.Rules))
+	shortfall := 0
+	for i, rule := range blueprint.Rules {
+		report := dto.BlueprintRuleReport{Rule: i + 1, Requested: rule.Count}
+		for _, q := range drawn[i] {
+			bankQuestion, ok := byID[q.ID]
+			if !ok {
+				// Deleted since it was drawn
+				continue
+			}
+			question := bankQuestion.ToQuestion(test.ID, 0)
+			question.ID = uuid.New()
+			test.Questions = append(test.Questions, *question)
+			links = append(links, entity.TestBankQuestion{BankQuestionID: bankQuestion.ID, QuestionID: question.ID})
+			report.Drawn++
+		}
+
+		if missing := rule.Count - report.Drawn; missing > 0 && strategy != nil {
+			generated, err := h.topUpRule(c.Context(), strategy, document, blueprint, rule, missing, pickedTypes)
+			if err != nil {
+				warnings = append(warnings, fmt.Sprintf("rule %d: failed to generate the missing questions", i+1))
+			}
+			test.Questions = append(test.Questions, generated...)
+			report.Generated = len(generated)
+		}
+
+		report.Shortfall = rule.Count - report.Drawn - report.Generated
+		shortfall += report.Shortfall
+		reports[i] = report
+	}
+	if len(test.Questions) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			dto.NewErrorResponse(dto.ErrCodeTestHasNoQuestions, "no bank questions match the blueprint"),
+		)
+	}
+
+	// Save the whole test at once so a failure leaves no partial test behind
+	if err := h.bankRepo.CreateTest(c.Context(), test, links); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInternalError, "failed to save test"),
+		)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dto.AssembleTestResponse{
+		Test: dto.TestResponse{
+			ID:             test.ID.String(),
+			UserID:         test.UserID.String(),
+			Title:          test.Title,
+			Description:    test.Description,
+			TotalQuestions: test.TotalQuestions,
+			Status:         string(test.Status),
+			MoodleSynced:   false,
+			Version:        test.Version,
+			CreatedAt:      test.CreatedAt.Format(time.RFC3339),
+			Questions:      questionDTOs(testQuestions(test), labelLanguage(c)),
+			Warnings:       warnings,
+		},
+		Rules:     reports,
+		Shortfall: shortfall,
+	})
+}
+
+// topUpRule generates up to missing questions for a blueprint rule. Generated
+// questions of another type than the rule asks for, over the MaxPerType limit
+// or with invalid answers are dropped; pickedTypes is updated with the rest.
+func (h *QuestionBankHandler) topUpRule(
+	ctx context.Context,
+	strategy llm.LLMStrategy,
+	document *entity.Document,
+	blueprint *entity.Blueprint,
+	rule entity.BlueprintRule,
+	missing int,
+	pickedTypes map[entity.QuestionType]int,
+) ([]entity.Question, error) {
+	difficulty := rule.Difficulty
+	if difficulty == "" {
+		difficulty = entity.DifficultyMedium
+	}
+	params := llm.GenerationParams{
+		Text:         document.EffectiveText(),
+		NumQuestions: missing,
+		Difficulty:   string(difficulty),
+	}
+	if rule.QuestionType != "" {
+		params.QuestionTypes = []llm.QuestionType{llm.QuestionType(rule.QuestionType)}
+	}
+
+	generated, err := llm.NewLLMContext(strategy).GenerateQuestions(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	var questions []entity.Question
+	for _, q := range generated {
+		if len(questions) == missing {
+			break
+		}
+		question := generatedQuestion(q)
+		question.ID = uuid.New()
+		if rule.Difficulty != "" || !question.IsValidDifficulty() {
+			question.Difficulty = difficulty
+		}
+		if !question.IsValidType() || (rule.QuestionType != "" && question.QuestionType != rule.QuestionType) {
+			continue
+		}
+		if !blueprint.TypeAllowed(question.QuestionType, pickedTypes) || question.ValidateAnswers() != nil {
+			continue
+		}
+		pickedTypes[question.QuestionType]++
+		questions = append(questions, question)
+	}
+	return questions, nil
+}
+
+// blueprintFromRequest validates an assemble request and builds its blueprint;
+// on failure it returns the status and error response to send
+func (h *QuestionBankHandler) blueprintFromRequest(ctx context.Context, userID uuid.UUID, req *dto.AssembleTestRequest) (*entity.Blueprint, int, *dto.ErrorResponse) {
+	invalid := func(msg string) (*entity.Blueprint, int, *dto.ErrorResponse) {
+		errResp := dto.NewErrorResponse(dto.ErrCodeInvalidInput, msg)
+		return nil, fiber.StatusBadRequest, &errResp
+	}
+	categoryError := func(err error) (*entity.Blueprint, int, *dto.ErrorResponse) {
+		status, errResp := categoryLookupError(err)
+		return nil, status, &errResp
+	}
+	tagsTooLong := fmt.Sprintf("tags must not exceed %d characters", entity.MaxTagLength)
+
+	blueprint := &entity.Blueprint{IncludeSubcategories: true}
+	if req.IncludeSubcategories != nil {
+		blueprint.IncludeSubcategories = *req.IncludeSubcategories
+	}
+
+	var err error
+	if blueprint.CategoryID, err = h.ownedCategoryID(ctx, userID, req.CategoryID); err != nil {
+		return categoryError(err)
+	}
+	tags, ok := entity.NormalizeTagNames(req.Tags)
+	if !ok {
+		return invalid(tagsTooLong)
+	}
+	blueprint.Tags = tags
+
+	for _, ruleReq := range req.Rules {
+		rule := entity.BlueprintRule{
+			Count:        ruleReq.Count,
+			Difficulty:   entity.Difficulty(ruleReq.Difficulty),
+			QuestionType: entity.QuestionType(ruleReq.QuestionType),
+		}
+		if rule.CategoryID, err = h.ownedCategoryID(ctx, userID, ruleReq.CategoryID); err != nil {
+			return categoryError(err)
+		}
+		if rule.Tags, ok = entity.NormalizeTagNames(ruleReq.Tags); !ok {
+			return invalid(tagsTooLong)
+		}
+		blueprint.Rules = append(blueprint.Rules, rule)
+	}
+
+	if len(req.MaxPerType) > 0 {
+		blueprint.MaxPerType = make(map[entity.QuestionType]int, len(req.MaxPerType))
+		for questionType, limit := range req.MaxPerType {
+			blueprint.MaxPerType[entity.QuestionType(questionType)] = limit
+		}
+	}
+
+	if err := blueprint.Validate(); err != nil {
+		return invalid(err.Error())
+	}
+	return blueprint, 0, nil
+}
+
+// blueprintRuleFilter builds the bank filter of a blueprint rule, falling back
+// to the blueprint's category and adding the blueprint's tags
+func blueprintRuleFilter(userID uuid.UUID, blueprint *entity.Blueprint, i int) repository.BankQuestionFilter {
+	rule := blueprint.Rules[i]
+	filter := repository.BankQuestionFilter{
+		UserID:               userID,
+		CategoryID:           blueprint.CategoryID,
+		IncludeSubcategories: blueprint.IncludeSubcategories,
+		QuestionType:         rule.QuestionType,
+		Difficulty:           rule.Difficulty,
+	}
+	if rule.CategoryID != nil {
+		filter.CategoryID = rule.CategoryID
+	}
+	filter.Tags, _ = entity.NormalizeTagNames(append(append([]string(nil), blueprint.Tags...), rule.Tags...))
+	return filter
+}
+
+// testQuestions returns pointers to the questions of a test
+func testQuestions(test *entity.Test) []*entity.Question {
+	questions := make([]*entity.Question, len(test.Questions))
+	for i := range test.Questions {
+		questions[i] = &test.Questions[i]
+	}
+	return questions
+}
+
+// applyBankQuestionRequest validates a create or update request and copies it
+// onto the question; on failure it returns the status and error response to send
+func (h *QuestionBankHandler) applyBankQuestionRequest(ctx context.Context, question *entity.BankQuestion, req *dto.BankQuestionRequest) (int, *dto.ErrorResponse) {
+	invalid := func(msg string) (int, *dto.ErrorResponse) {
+		errResp := dto.NewErrorResponse(dto.ErrCodeInvalidInput, msg)
+		return fiber.StatusBadRequest, &errResp
+	}
+
+	question.QuestionText = security.SanitizeMultiline(req.QuestionText)
+	question.QuestionType = entity.QuestionType(req.QuestionType)
+	question.Difficulty = entity.DifficultyMedium
+	if req.Difficulty != "" {
+		question.Difficulty = entity.Difficulty(req.Difficulty)
+	}
+	question.Points = 1.0
+	if req.Points != nil {
+		if *req.Points <= 0 {
+			return invalid("points must be positive")
+		}
+		question.Points = *req.Points
+	}
+	question.CaseSensitive = req.CaseSensitive
+
+	question.Answers = nil
+	for i, answerReq := range req.Answers {
+		question.Answers = append(question.Answers, entity.BankAnswer{
+			AnswerText: security.SanitizeInput(answerReq.AnswerText),
+			IsCorrect:  answerReq.IsCorrect,
+			MatchText:  security.SanitizeInput(answerReq.MatchText),
+			Tolerance:  answerReq.Tolerance,
+			Weight:     answerReq.Weight,
+			OrderNum:   i + 1,
+		})
+	}
+	question.NormalizeTrueFalseAnswers()
+	if msg := importQuestionError(question.ToQuestion(uuid.Nil, 0)); msg != "" {
+		return invalid(msg)
+	}
+
+	tags, ok := entity.NormalizeTagNames(req.Tags)
+	if !ok {
+		return invalid(fmt.Sprintf("tags must not exceed %d characters", entity.MaxTagLength))
+	}
+	question.Tags = nil
+	for _, name := range tags {
+		question.Tags = append(question.Tags, entity.QuestionTag{Name: name})
+	}
+
+	categoryID, err := h.ownedCategoryID(ctx, question.UserID, req.CategoryID)
+	if err != nil {
+		status, errResp := categoryLookupError(err)
+		return status, &errResp
+	}
+	question.CategoryID = categoryID
+	question.UpdatedAt = time.Now()
+	return 0, nil
+}
+
+// ownedCategoryID parses an optional category ID from a request and checks
+// that the category belongs to the user; nil and empty IDs yield nil
+func (h *QuestionBankHandler) ownedCategoryID(ctx context.Context, userID uuid.UUID, raw *string) (*uuid.UUID, error) {
+	if raw == nil || *raw == "" {
+		return nil, nil
+	}
+	id, err := uuid.Parse(*raw)
+	if err != nil {
+		return nil, errInvalidCategoryID
+	}
+	category, err := h.categoryRepo.FindByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && category.UserID != userID) {
+		return nil, errCategoryNotOwned
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+// categoryLookupError maps an ownedCategoryID error to a status and error response
+func categoryLookupError(err error) (int, dto.ErrorResponse) {
+	switch {
+	case errors.Is(err, errCategoryNotOwned):
+		return fiber.StatusNotFound, dto.NewErrorResponse(dto.ErrCodeCategoryNotFound, "category not found")
+	case errors.Is(err, errInvalidCategoryID):
+		return fiber.StatusBadRequest, dto.NewErrorResponse(dto.ErrCodeInvalidUUID, "invalid category ID")
+	}
+	return fiber.StatusInternalServerError, dto.NewErrorResponse(dto.ErrCodeDatabaseError, "failed to fetch category")
+}
+
+// categoryNameError describes why a category name is invalid, or returns an
+// empty string when it is valid
+func categoryNameError(name string) string {
+	switch {
+	case name == "":
+		return "category name is required"
+	case utf8.RuneCountInString(name) > maxCategoryNameLength:
+		return fmt.Sprintf("category name must not exceed %d characters", maxCategoryNameLength)
+	}
+	return ""
+}
+
+// isInCategorySubtree reports whether id is root or one of its nested categories
+func isInCategorySubtree(categories []*entity.QuestionCategory, id, root uuid.UUID) bool {
+	parents := make(map[uuid.UUID]*uuid.UUID, len(categories))
+	for _, category := range categories {
+		parents[category.ID] = category.ParentID
+	}
+	// Walk up from id; the depth bound guards against cycles in stored data
+	for depth := 0; depth <= len(categories); depth++ {
+		if id == root {
+			return true
+		}
+		parent := parents[id]
+		if parent == nil {
+			return false
+		}
+		id = *parent
+	}
+	return true
+}
+
+// categoryTree nests categories under their parents, keeping the given order
+func categoryTree(categories []*entity.QuestionCategory) []dto.CategoryResponse {
+	children := make(map[uuid.UUID][]*entity.QuestionCategory)
+	known := make(map[uuid.UUID]bool, len(categories))
+	for _, category := range categories {
+		known[category.ID] = true
+	}
+	var roots []*entity.QuestionCategory
+	for _, category := range categories {
+		if category.ParentID == nil || !known[*category.ParentID] {
+			roots = append(roots, category)
+			continue
+		}
+		children[*category.ParentID] = append(children[*category.ParentID], category)
+	}
+
+	var build func(level []*entity.QuestionCategory) []dto.CategoryResponse
+	build = func(level []*entity.QuestionCategory) []dto.CategoryResponse {
+		result := make([]dto.CategoryResponse, len(level))
+		for i, category := range level {
+			result[i] = categoryResponse(category)
+			result[i].Children = build(children[category.ID])
+		}
+		return result
+	}
+	return build(roots)
+}
+
+func categoryResponse(category *entity.QuestionCategory) dto.CategoryResponse {
+	var parentID *string
+	if category.ParentID != nil {
+		id := category.ParentID.String()
+		parentID = &id
+	}
+	return dto.CategoryResponse{
+		ID:        category.ID.String(),
+		ParentID:  parentID,
+		Name:      category.Name,
+		CreatedAt: category.CreatedAt.Format(time.RFC3339),
+		Children:  []dto.CategoryResponse{},
+	}
+}
+
+func bankQuestionResponse(question *entity.BankQuestion, language string) dto.BankQuestionResponse {
+	var categoryID *string
+	if question.CategoryID != nil {
+		id := question.CategoryID.String()
+		categoryID = &id
+	}
+
+	answers := make([]dto.AnswerDTO, len(question.Answers))
+	for i, a := range question.Answers {
+		answers[i] = dto.AnswerDTO{
+			ID:         a.ID.String(),
+			AnswerText: entity.AnswerLabel(question.QuestionType, a.AnswerText, language),
+			IsCorrect:  a.IsCorrect,
+			MatchText:  a.MatchText,
+			Tolerance:  a.Tolerance,
+			Weight:     a.Weight,
+			OrderNum:   a.OrderNum,
+		}
+	}
+
+	return dto.BankQuestionResponse{
+		ID:            question.ID.String(),
+		CategoryID:    categoryID,
+		QuestionText:  question.QuestionText,
+		QuestionType:  string(question.QuestionType),
+		Difficulty:    string(question.Difficulty),
+		Points:        question.Points,
+		CaseSensitive: question.CaseSensitive,
+		Tags:          question.TagNames(),
+		Answers:       answers,
+		CreatedAt:     question.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:     question.UpdatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,629 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/shester1kov/testgen-backend/internal/application/dto"
+	"github.com/shester1kov/testgen-backend/internal/domain/entity"
+	"github.com/shester1kov/testgen-backend/internal/domain/repository"
+	"github.com/shester1kov/testgen-backend/internal/infrastructure/llm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type mockBankQuestionRepository struct {
+	mock.Mock
+}
+
+func (m *mockBankQuestionRepository) Create(ctx context.Context, questions ...*entity.BankQuestion) error {
+	args := m.Called(ctx, questions)
+	return args.Error(0)
+}
+func (m *mockBankQuestionRepository) FindByID(ctx context.Context, id uuid.UUID) (*entity.BankQuestion, error) {
+	args := m.Called(ctx, id)
+	if res := args.Get(0); res != nil {
+		return res.(*entity.BankQuestion), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+func (m *mockBankQuestionRepository) FindByIDs(ctx context.Context, ids []uuid.UUID) ([]*entity.BankQuestion, error) {
+	args := m.Called(ctx, ids)
+	if res := args.Get(0); res != nil {
+		return res.([]*entity.BankQuestion), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+func (m *mockBankQuestionRepository) List(ctx context.Context, filter repository.BankQuestionFilter) ([]*entity.BankQuestion, int64, error) {
+	args := m.Called(ctx, filter)
+	if res := args.Get(0); res != nil {
+		return res.([]*entity.BankQuestion), args.Get(1).(int64), args.Error(2)
+	}
+	return nil, 0, args.Error(2)
+}
+func (m *mockBankQuestionRepository) FindMatching(ctx context.Context, filter repository.BankQuestionFilter) ([]*entity.BankQuestion, error) {
+	args := m.Called(ctx, filter)
+	if res := args.Get(0); res != nil {
+		return res.([]*entity.BankQuestion), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+func (m *mockBankQuestionRepository) Update(ctx context.Context, question *entity.BankQuestion) error {
+	args := m.Called(ctx, question)
+	return args.Error(0)
+}
+func (m *mockBankQuestionRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+func (m *mockBankQuestionRepository) FindTagsByUserID(ctx context.Context, userID uuid.UUID) ([]*entity.QuestionTag, error) {
+	args := m.Called(ctx, userID)
+	if res := args.Get(0); res != nil {
+		return res.([]*entity.QuestionTag), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+func (m *mockBankQuestionRepository) FindTestIDs(ctx context.Context, id uuid.UUID) ([]uuid.UUID, error) {
+	args := m.Called(ctx, id)
+	if res := args.Get(0); res != nil {
+		return res.([]uuid.UUID), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+func (m *mockBankQuestionRepository) AddToTest(ctx context.Context, testID uuid.UUID, questions []*entity.BankQuestion) ([]*entity.Question, error) {
+	args := m.Called(ctx, testID, questions)
+	if res := args.Get(0); res != nil {
+		return res.([]*entity.Question), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockBankQuestionRepository) CreateTest(ctx context.Context, test *entity.Test, links []entity.TestBankQuestion) error {
+	args := m.Called(ctx, test, links)
+	return args.Error(0)
+}
+
+type mockQuestionCategoryRepository struct {
+	mock.Mock
+}
+
+func (m *mockQuestionCategoryRepository) Create(ctx context.Context, category *entity.QuestionCategory) error {
+	args := m.Called(ctx, category)
+	return args.Error(0)
+}
+func (m *mockQuestionCategoryRepository) FindByID(ctx context.Context, id uuid.UUID) (*entity.QuestionCategory, error) {
+	args := m.Called(ctx, id)
+	if res := args.Get(0); res != nil {
+		return res.(*entity.QuestionCategory), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+func (m *mockQuestionCategoryRepository) FindByUserID(ctx context.Context, userID uuid.UUID) ([]*entity.QuestionCategory, error) {
+	args := m.Called(ctx, userID)
+	if res := args.Get(0); res != nil {
+		return res.([]*entity.QuestionCategory), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+func (m *mockQuestionCategoryRepository) Update(ctx context.Context, category *entity.QuestionCategory) error {
+	args := m.Called(ctx, category)
+	return args.Error(0)
+}
+func (m *mockQuestionCategoryRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+type questionBankMocks struct {
+	bank       *mockBankQuestionRepository
+	categories *mockQuestionCategoryRepository
+	tests      *mockTestUpdateRepository
+	questions  *mockQuestionRepository
+	documents  *mockTestDocRepository
+	users      *mockTestUserRepository
+}
+
+func newQuestionBankApp(userID uuid.UUID) (*fiber.App, *questionBankMocks) {
+	mocks := &questionBankMocks{
+		bank:       new(mockBankQuestionRepository),
+		categories: new(mockQuestionCategoryRepository),
+		tests:      new(mockTestUpdateRepository),
+		questions:  new(mockQuestionRepository),
+		documents:  new(mockTestDocRepository),
+		users:      new(mockTestUserRepository),
+	}
+	handler := NewQuestionBankHandler(mocks.bank, mocks.categories, mocks.tests, mocks.questions, mocks.documents, mocks.users, llm.NewLLMFactory("test-key", "", "", "", ""))
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("userID", userID)
+		return c.Next()
+	})
+	app.Get("/bank/categories", handler.ListCategories)
+	app.Put("/bank/categories/:id", handler.UpdateCategory)
+	app.Get("/bank/questions", handler.ListQuestions)
+	app.Post("/bank/questions", handler.CreateQuestion)
+	app.Post("/bank/questions/from-test", handler.SaveFromTest)
+	app.Get("/bank/questions/:id", handler.GetQuestion)
+	app.Post("/tests/:testId/questions/from-bank", handler.AddToTest)
+	app.Post("/tests/assemble", handler.Assemble)
+	return app, mocks
+}
+
+func putJSON(t *testing.T, app *fiber.App, path string, body interface{}) *http.Response {
+	payload, err := json.Marshal(body)
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPut, path, bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	return resp
+}
+
+func getPath(t *testing.T, app *fiber.App, path string) *http.Response {
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, path, nil))
+	require.NoError(t, err)
+	return resp
+}
+
+func TestQuestionBank_ListCategoriesBuildsTree(t *testing.T) {
+	userID := uuid.New()
+	app, mocks := newQuestionBankApp(userID)
+
+	root := &entity.QuestionCategory{ID: uuid.New(), UserID: userID, Name: "Math"}
+	child := &entity.QuestionCategory{ID: uuid.New(), UserID: userID, ParentID: &root.ID, Name: "Algebra"}
+	mocks.categories.On("FindByUserID", mock.Anything, userID).Return([]*entity.QuestionCategory{child, root}, nil)
+
+	resp := getPath(t, app, "/bank/categories")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var body dto.CategoryListResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	require.Len(t, body.Categories, 1)
+	assert.Equal(t, "Math", body.Categories[0].Name)
+	require.Len(t, body.Categories[0].Children, 1)
+	assert.Equal(t, "Algebra", body.Categories[0].Children[0].Name)
+}
+
+func TestQuestionBank_UpdateCategoryRejectsCycle(t *testing.T) {
+	userID := uuid.New()
+	app, mocks := newQuestionBankApp(userID)
+
+	root := &entity.QuestionCategory{ID: uuid.New(), UserID: userID, Name: "Root"}
+	child := &entity.QuestionCategory{ID: uuid.New(), UserID: userID, ParentID: &root.ID, Name: "Child"}
+	mocks.categories.On("FindByID", mock.Anything, root.ID).Return(root, nil)
+	mocks.categories.On("FindByID", mock.Anything, child.ID).Return(child, nil)
+	mocks.categories.On("FindByUserID", mock.Anything, userID).Return([]*entity.QuestionCategory{root, child}, nil)
+
+	parentID := child.ID.String()
+	resp := putJSON(t, app, "/bank/categories/"+root.ID.String(), dto.UpdateCategoryRequest{ParentID: &parentID})
+
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+	mocks.categories.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestQuestionBank_CreateQuestionNormalizesTags(t *testing.T) {
+	userID := uuid.New()
+	app, mocks := newQuestionBankApp(userID)
+
+	var saved *entity.BankQuestion
+	mocks.bank.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		saved = args.Get(1).([]*entity.BankQuestion)[0]
+	}).Return(nil)
+
+	resp := postJSON(t, app, "/bank/questions", dto.BankQuestionRequest{
+		QuestionText: "What is 2 + 2?",
+		QuestionType: "single_choice",
+		Tags:         []string{" Arithmetic ", "arithmetic", "Basics"},
+		Answers: []dto.CreateAnswerRequest{
+			{AnswerText: "4", IsCorrect: true},
+			{AnswerText: "5"},
+		},
+	})
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	require.NotNil(t, saved)
+	assert.Equal(t, userID, saved.UserID)
+	assert.Equal(t, entity.DifficultyMedium, saved.Difficulty)
+	assert.Equal(t, 1.0, saved.Points)
+	assert.Equal(t, []string{"arithmetic", "basics"}, saved.TagNames())
+	require.Len(t, saved.Answers, 2)
+	assert.Equal(t, 2, saved.Answers[1].OrderNum)
+}
+
+func TestQuestionBank_CreateQuestionValidation(t *testing.T) {
+	userID := uuid.New()
+	foreignCategory := &entity.QuestionCategory{ID: uuid.New(), UserID: uuid.New(), Name: "Other"}
+	foreignCategoryID := foreignCategory.ID.String()
+
+	tests := []struct {
+		name   string
+		req    dto.BankQuestionRequest
+		status int
+	}{
+		{
+			name: "no correct answer",
+			req: dto.BankQuestionRequest{QuestionText: "Pick one", QuestionType: "single_choice", Answers: []dto.CreateAnswerRequest{
+				{AnswerText: "A"}, {AnswerText: "B"},
+			}},
+			status: http.StatusBadRequest,
+		},
+		{
+			name: "foreign category",
+			req: dto.BankQuestionRequest{QuestionText: "Pick one", QuestionType: "single_choice", CategoryID: &foreignCategoryID, Answers: []dto.CreateAnswerRequest{
+				{AnswerText: "A", IsCorrect: true}, {AnswerText: "B"},
+			}},
+			status: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, mocks := newQuestionBankApp(userID)
+			mocks.categories.On("FindByID", mock.Anything, foreignCategory.ID).Return(foreignCategory, nil)
+
+			resp := postJSON(t, app, "/bank/questions", tt.req)
+
+			assert.Equal(t, tt.status, resp.StatusCode)
+			mocks.bank.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestQuestionBank_ListQuestionsBuildsFilter(t *testing.T) {
+	userID := uuid.New()
+	categoryID := uuid.New()
+	app, mocks := newQuestionBankApp(userID)
+
+	expected := repository.BankQuestionFilter{
+		UserID:               userID,
+		CategoryID:           &categoryID,
+		IncludeSubcategories: false,
+		Tags:                 []string{"go", "basics"},
+		Difficulty:           entity.DifficultyHard,
+		Text:                 "channel",
+		Limit:                10,
+		Offset:               10,
+	}
+	mocks.bank.On("List", mock.Anything, expected).Return([]*entity.BankQuestion{}, int64(11), nil)
+
+	resp := getPath(t, app, "/bank/questions?category_id="+categoryID.String()+
+		"&include_subcategories=false&tags=Go,basics&difficulty=hard&q=channel&page=2&page_size=10")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var body dto.BankQuestionListResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, int64(11), body.Total)
+	assert.Equal(t, 2, body.Page)
+	mocks.bank.AssertExpectations(t)
+}
+
+func TestQuestionBank_GetQuestionIncludesTests(t *testing.T) {
+	userID := uuid.New()
+	app, mocks := newQuestionBankApp(userID)
+
+	question := &entity.BankQuestion{ID: uuid.New(), UserID: userID, QuestionText: "Q"}
+	testID := uuid.New()
+	mocks.bank.On("FindByID", mock.Anything, question.ID).Return(question, nil)
+	mocks.bank.On("FindTestIDs", mock.Anything, question.ID).Return([]uuid.UUID{testID}, nil)
+
+	resp := getPath(t, app, "/bank/questions/"+question.ID.String())
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var body dto.BankQuestionResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, []string{testID.String()}, body.TestIDs)
+}
+
+func TestQuestionBank_GetQuestionForbidden(t *testing.T) {
+	app, mocks := newQuestionBankApp(uuid.New())
+
+	question := &entity.BankQuestion{ID: uuid.New(), UserID: uuid.New()}
+	mocks.bank.On("FindByID", mock.Anything, question.ID).Return(question, nil)
+
+	resp := getPath(t, app, "/bank/questions/"+question.ID.String())
+
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
+
+func TestQuestionBank_SaveFromTestCopiesSelectedQuestions(t *testing.T) {
+	userID := uuid.New()
+	testID := uuid.New()
+	app, mocks := newQuestionBankApp(userID)
+
+	picked := &entity.Question{ID: uuid.New(), TestID: testID, QuestionText: "Picked", QuestionType: entity.QuestionTypeTrueFalse,
+		Difficulty: entity.DifficultyEasy, Points: 2, Answers: []entity.Answer{{AnswerText: "True", IsCorrect: true}, {AnswerText: "False"}}}
+	skipped := &entity.Question{ID: uuid.New(), TestID: testID, QuestionText: "Skipped"}
+	mocks.tests.On("FindByID", mock.Anything, testID).Return(&entity.Test{ID: testID, UserID: userID}, nil)
+	mocks.questions.On("FindByTestIDWithAnswers", mock.Anything, testID).Return([]*entity.Question{picked, skipped}, nil)
+
+	var saved []*entity.BankQuestion
+	mocks.bank.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		saved = args.Get(1).([]*entity.BankQuestion)
+	}).Return(nil)
+
+	resp := postJSON(t, app, "/bank/questions/from-test", dto.SaveToBankRequest{
+		TestID:      testID.String(),
+		QuestionIDs: []string{picked.ID.String()},
+		Tags:        []string{"Imported"},
+	})
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	require.Len(t, saved, 1)
+	assert.Equal(t, "Picked", saved[0].QuestionText)
+	assert.Equal(t, 2.0, saved[0].Points)
+	assert.Equal(t, []string{"imported"}, saved[0].TagNames())
+	require.Len(t, saved[0].Answers, 2)
+	assert.True(t, saved[0].Answers[0].IsCorrect)
+}
+
+func TestQuestionBank_SaveFromTestUnknownQuestion(t *testing.T) {
+	userID := uuid.New()
+	testID := uuid.New()
+	app, mocks := newQuestionBankApp(userID)
+
+	mocks.tests.On("FindByID", mock.Anything, testID).Return(&entity.Test{ID: testID, UserID: userID}, nil)
+	mocks.questions.On("FindByTestIDWithAnswers", mock.Anything, testID).Return([]*entity.Question{}, nil)
+
+	resp := postJSON(t, app, "/bank/questions/from-test", dto.SaveToBankRequest{
+		TestID:      testID.String(),
+		QuestionIDs: []string{uuid.NewString()},
+	})
+
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	mocks.bank.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestQuestionBank_AddToTestKeepsRequestedOrder(t *testing.T) {
+	userID := uuid.New()
+	testID := uuid.New()
+	app, mocks := newQuestionBankApp(userID)
+
+	first := &entity.BankQuestion{ID: uuid.New(), UserID: userID, QuestionText: "First"}
+	second := &entity.BankQuestion{ID: uuid.New(), UserID: userID, QuestionText: "Second"}
+	mocks.tests.On("FindByID", mock.Anything, testID).Return(&entity.Test{ID: testID, UserID: userID}, nil)
+	mocks.bank.On("FindByIDs", mock.Anything, []uuid.UUID{second.ID, first.ID}).Return([]*entity.BankQuestion{first, second}, nil)
+	mocks.bank.On("AddToTest", mock.Anything, testID, []*entity.BankQuestion{second, first}).Return([]*entity.Question{
+		{ID: uuid.New(), TestID: testID, QuestionText: "Second", OrderNum: 1},
+		{ID: uuid.New(), TestID: testID, QuestionText: "First", OrderNum: 2},
+	}, nil)
+
+	resp := postJSON(t, app, "/tests/"+testID.String()+"/questions/from-bank", dto.AddBankQuestionsRequest{
+		BankQuestionIDs: []string{second.ID.String(), first.ID.String()},
+	})
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var body []dto.QuestionDTO
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	require.Len(t, body, 2)
+	assert.Equal(t, "Second", body[0].QuestionText)
+	mocks.bank.AssertExpectations(t)
+}
+
+func TestQuestionBank_AddToTestErrors(t *testing.T) {
+	userID := uuid.New()
+	testID := uuid.New()
+	own := &entity.BankQuestion{ID: uuid.New(), UserID: userID}
+	foreign := &entity.BankQuestion{ID: uuid.New(), UserID: uuid.New()}
+
+	tests := []struct {
+		name     string
+		ids      []uuid.UUID
+		addErr   error
+		status   int
+		addCalls bool
+	}{
+		{name: "foreign question", ids: []uuid.UUID{foreign.ID}, status: http.StatusNotFound},
+		{name: "repeated question", ids: []uuid.UUID{own.ID, own.ID}, status: http.StatusBadRequest},
+		{name: "already in test", ids: []uuid.UUID{own.ID}, addErr: repository.ErrBankQuestionInTest, status: http.StatusConflict, addCalls: true},
+		{name: "test deleted meanwhile", ids: []uuid.UUID{own.ID}, addErr: gorm.ErrRecordNotFound, status: http.StatusNotFound, addCalls: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, mocks := newQuestionBankApp(userID)
+			mocks.tests.On("FindByID", mock.Anything, testID).Return(&entity.Test{ID: testID, UserID: userID}, nil)
+			mocks.bank.On("FindByIDs", mock.Anything, mock.Anything).Return([]*entity.BankQuestion{own, foreign}, nil)
+			mocks.bank.On("AddToTest", mock.Anything, testID, mock.Anything).Return(nil, tt.addErr)
+
+			ids := make([]string, len(tt.ids))
+			for i, id := range tt.ids {
+				ids[i] = id.String()
+			}
+			resp := postJSON(t, app, "/tests/"+testID.String()+"/questions/from-bank", dto.AddBankQuestionsRequest{BankQuestionIDs: ids})
+
+			assert.Equal(t, tt.status, resp.StatusCode)
+			if !tt.addCalls {
+				mocks.bank.AssertNotCalled(t, "AddToTest", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestQuestionBank_AddToTestForbidden(t *testing.T) {
+	testID := uuid.New()
+	app, mocks := newQuestionBankApp(uuid.New())
+	mocks.tests.On("FindByID", mock.Anything, testID).Return(&entity.Test{ID: testID, UserID: uuid.New()}, nil)
+
+	resp := postJSON(t, app, "/tests/"+testID.String()+"/questions/from-bank", dto.AddBankQuestionsRequest{
+		BankQuestionIDs: []string{uuid.NewString()},
+	})
+
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	mocks.bank.AssertNotCalled(t, "FindByIDs", mock.Anything, mock.Anything)
+}
+
+func bankQuestionsOf(userID uuid.UUID, questionType entity.QuestionType, difficulty entity.Difficulty, n int) []*entity.BankQuestion {
+	questions := make([]*entity.BankQuestion, n)
+	for i := range questions {
+		questions[i] = &entity.BankQuestion{
+			ID:           uuid.New(),
+			UserID:       userID,
+			QuestionText: "Bank question",
+			QuestionType: questionType,
+			Difficulty:   difficulty,
+			Points:       1,
+			Answers:      []entity.BankAnswer{{AnswerText: "True", IsCorrect: true, OrderNum: 1}, {AnswerText: "False", OrderNum: 2}},
+		}
+	}
+	return questions
+}
+
+// expectFindByIDs returns the given questions whichever ones are asked for;
+// the handler only looks up the drawn ones
+func expectFindByIDs(mocks *questionBankMocks, questions ...*entity.BankQuestion) {
+	mocks.bank.On("FindByIDs", mock.Anything, mock.Anything).Return(append([]*entity.BankQuestion{}, questions...), nil)
+}
+
+func TestQuestionBank_AssembleDrawsByRules(t *testing.T) {
+	userID := uuid.New()
+	categoryID := uuid.New()
+	app, mocks := newQuestionBankApp(userID)
+
+	easy := bankQuestionsOf(userID, entity.QuestionTypeTrueFalse, entity.DifficultyEasy, 4)
+	hard := bankQuestionsOf(userID, entity.QuestionTypeTrueFalse, entity.DifficultyHard, 1)
+	mocks.categories.On("FindByID", mock.Anything, categoryID).Return(&entity.QuestionCategory{ID: categoryID, UserID: userID}, nil)
+	mocks.bank.On("FindMatching", mock.Anything, mock.MatchedBy(func(f repository.BankQuestionFilter) bool {
+		return f.Difficulty == entity.DifficultyEasy && *f.CategoryID == categoryID && f.IncludeSubcategories
+	})).Return(easy, nil)
+	mocks.bank.On("FindMatching", mock.Anything, mock.MatchedBy(func(f repository.BankQuestionFilter) bool {
+		return f.Difficulty == entity.DifficultyHard
+	})).Return(hard, nil)
+	expectFindByIDs(mocks, append(easy, hard...)...)
+
+	var saved *entity.Test
+	var links []entity.TestBankQuestion
+	mocks.bank.On("CreateTest", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		saved = args.Get(1).(*entity.Test)
+		links = args.Get(2).([]entity.TestBankQuestion)
+		saved.TotalQuestions = len(saved.Questions)
+	}).Return(nil)
+
+	categoryRef := categoryID.String()
+	resp := postJSON(t, app, "/tests/assemble", dto.AssembleTestRequest{
+		Title:      "Networking quiz",
+		CategoryID: &categoryRef,
+		Rules: []dto.BlueprintRuleRequest{
+			{Count: 2, Difficulty: "easy"},
+			{Count: 3, Difficulty: "hard"},
+		},
+		MaxPerType: map[string]int{"true_false": 3},
+	})
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var body dto.AssembleTestResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, []dto.BlueprintRuleReport{
+		{Rule: 1, Requested: 2, Drawn: 2, Shortfall: 0},
+		{Rule: 2, Requested: 3, Drawn: 1, Shortfall: 2},
+	}, body.Rules)
+	assert.Equal(t, 2, body.Shortfall)
+	assert.Equal(t, 3, body.Test.TotalQuestions)
+
+	require.NotNil(t, saved)
+	require.Len(t, saved.Questions, 3)
+	require.Len(t, links, 3)
+	seen := make(map[uuid.UUID]bool)
+	for i, link := range links {
+		assert.False(t, seen[link.BankQuestionID], "bank questions must not repeat")
+		seen[link.BankQuestionID] = true
+		assert.Equal(t, saved.Questions[i].ID, link.QuestionID)
+	}
+	assert.Equal(t, entity.DifficultyHard, saved.Questions[2].Difficulty)
+}
+
+func TestQuestionBank_AssembleTopsUpWithLLM(t *testing.T) {
+	userID := uuid.New()
+	docID := uuid.New()
+	app, mocks := newQuestionBankApp(userID)
+
+	mocks.documents.On("FindByID", mock.Anything, docID).Return(&entity.Document{
+		ID: docID, UserID: userID, Status: entity.StatusParsed, ParsedText: "Routers forward packets.",
+	}, nil)
+	mocks.users.On("FindByID", mock.Anything, userID).Return(&entity.User{
+		ID: userID, Role: &entity.Role{Name: entity.RoleNameTeacher},
+	}, nil)
+	mocks.bank.On("FindMatching", mock.Anything, mock.Anything).Return([]*entity.BankQuestion{}, nil)
+	expectFindByIDs(mocks)
+
+	var saved *entity.Test
+	mocks.bank.On("CreateTest", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		saved = args.Get(1).(*entity.Test)
+	}).Return(nil)
+
+	docRef := docID.String()
+	resp := postJSON(t, app, "/tests/assemble", dto.AssembleTestRequest{
+		Title: "Topped up",
+		Rules: []dto.BlueprintRuleRequest{
+			// The test LLM only produces single choice questions
+			{Count: 2, Difficulty: "hard", QuestionType: "single_choice"},
+			{Count: 1, QuestionType: "true_false"},
+		},
+		DocumentID:  &docRef,
+		TopUp:       true,
+		LLMProvider: "perplexity",
+	})
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var body dto.AssembleTestResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, []dto.BlueprintRuleReport{
+		{Rule: 1, Requested: 2, Generated: 2},
+		{Rule: 2, Requested: 1, Shortfall: 1},
+	}, body.Rules)
+
+	require.NotNil(t, saved)
+	require.NotNil(t, saved.DocumentID)
+	assert.Equal(t, docID, *saved.DocumentID)
+	require.Len(t, saved.Questions, 2)
+	assert.Equal(t, entity.DifficultyHard, saved.Questions[0].Difficulty)
+}
+
+func TestQuestionBank_AssembleValidation(t *testing.T) {
+	userID := uuid.New()
+
+	tests := []struct {
+		name   string
+		req    dto.AssembleTestRequest
+		status int
+	}{
+		{
+			name:   "no rules",
+			req:    dto.AssembleTestRequest{Title: "Quiz"},
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "invalid type limit",
+			req:    dto.AssembleTestRequest{Title: "Quiz", Rules: []dto.BlueprintRuleRequest{{Count: 1}}, MaxPerType: map[string]int{"essay_like": 1}},
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "top up without document",
+			req:    dto.AssembleTestRequest{Title: "Quiz", Rules: []dto.BlueprintRuleRequest{{Count: 1}}, TopUp: true},
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "nothing matches",
+			req:    dto.AssembleTestRequest{Title: "Quiz", Rules: []dto.BlueprintRuleRequest{{Count: 1}}},
+			status: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, mocks := newQuestionBankApp(userID)
+			mocks.bank.On("FindMatching", mock.Anything, mock.Anything).Return([]*entity.BankQuestion{}, nil)
+			expectFindByIDs(mocks)
+
+			resp := postJSON(t, app, "/tests/assemble", tt.req)
+
+			assert.Equal(t, tt.status, resp.StatusCode)
+			mocks.bank.AssertNotCalled(t, "CreateTest", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package handler
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/shester1kov/testgen-backend/internal/application/dto"
+	"github.com/shester1kov/testgen-backend/internal/domain/entity"
+	"github.com/shester1kov/testgen-backend/internal/domain/repository"
+)
+
+// maxSearchQueryLength limits the search string, in characters
+const maxSearchQueryLength = 200
+
+// SearchHandler handles full-text search across documents, tests and questions
+type SearchHandler struct {
+	searchRepo repository.SearchRepository
+	userRepo   repository.UserRepository
+}
+
+// NewSearchHandler creates a new search handler
+func NewSearchHandler(searchRepo repository.SearchRepository, userRepo repository.UserRepository) *SearchHandler {
+	return &SearchHandler{
+		searchRepo: searchRepo,
+		userRepo:   userRepo,
+	}
+}
+
+// Search godoc
+// @Summary Full-text search
+// @Description Search document titles and text, test titles and descriptions, and question texts (Russian and English word forms). Admin searches everything, others only their own documents and tests
+// @Tags search
+// @Produce json
+// @Security BearerAuth
+// @Param q query string true "Search query; supports quoted phrases, OR and -exclusion"
+// @Param type query string false "Comma-separated result types: document, test, question"
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(20)
+// @Success 200 {object} dto.SearchResponse
+// @Failure 400 {object} dto.ErrorResponse "Missing or too long query, or unknown type"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /search [get]
+func (h *SearchHandler) Search(c *fiber.Ctx) error {
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(
+			dto.NewErrorResponse(dto.ErrCodeUnauthorized, "Unauthorized"),
+		)
+	}
+
+	text := strings.TrimSpace(c.Query("q"))
+	if text == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInvalidInput, "search query is required"),
+		)
+	}
+	if utf8.RuneCountInString(text) > maxSearchQueryLength {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInvalidInput, fmt.Sprintf("search query must not exceed %d characters", maxSearchQueryLength)),
+		)
+	}
+
+	var types []entity.SearchResultType
+	if rawTypes := c.Query("type"); rawTypes != "" {
+		for _, rawType := range strings.Split(rawTypes, ",") {
+			resultType := entity.SearchResultType(strings.TrimSpace(rawType))
+			if !resultType.IsValid() {
+				return c.Status(fiber.StatusBadRequest).JSON(
+					dto.NewErrorResponse(dto.ErrCodeInvalidInput, fmt.Sprintf("unknown result type: %s", resultType)),
+				)
+			}
+			types = append(types, resultType)
+		}
+	}
+
+	// Get user to check role
+	user, err := h.userRepo.FindByID(c.Context(), userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(
+			dto.NewErrorResponse(dto.ErrCodeDatabaseError, "failed to fetch user"),
+		)
+	}
+
+	page := c.QueryInt("page", 1)
+	pageSize := c.QueryInt("page_size", 20)
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	query := repository.SearchQuery{
+		Text:   text,
+		Types:  types,
+		Limit:  pageSize,
+		Offset: (page - 1) * pageSize,
+	}
+
+	// Admin searches everything, others only their own documents and tests
+	if !user.IsAdmin() {
+		query.UserID = &userID
+	}
+
+	results, total, err := h.searchRepo.Search(c.Context(), query)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(
+			dto.NewErrorResponse(dto.ErrCodeDatabaseError, "search failed"),
+		)
+	}
+
+	response := make([]dto.SearchResultResponse, len(results))
+	for i, result := range results {
+		var testID *string
+		if result.TestID != nil {
+			id := result.TestID.String()
+			testID = &id
+		}
+
+		response[i] = dto.SearchResultResponse{
+			Type:      string(result.Type),
+			ID:        result.ID.String(),
+			UserID:    result.UserID.String(),
+			TestID:    testID,
+			Title:     result.Title,
+			Snippet:   result.Snippet,
+			Rank:      result.Rank,
+			CreatedAt: result.CreatedAt.Format("2006-01-02T15:04:05Z"),
+		}
+	}
+
+	return c.JSON(dto.SearchResponse{
+		Query:    text,
+		Results:  response,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	})
+}
@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/shester1kov/testgen-backend/internal/application/dto"
+	"github.com/shester1kov/testgen-backend/internal/domain/entity"
+	"github.com/shester1kov/testgen-backend/internal/domain/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockSearchRepository struct {
+	mock.Mock
+}
+
+func (m *mockSearchRepository) Search(ctx context.Context, query repository.SearchQuery) ([]*entity.SearchResult, int64, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*entity.SearchResult), args.Get(1).(int64), args.Error(2)
+}
+
+func newSearchApp(searchRepo *mockSearchRepository, userRepo *mockDocUserRepository, userID uuid.UUID) *fiber.App {
+	handler := NewSearchHandler(searchRepo, userRepo)
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("userID", userID)
+		return c.Next()
+	})
+	app.Get("/search", handler.Search)
+	return app
+}
+
+func TestSearch_ScopesNonAdminToOwnObjects(t *testing.T) {
+	searchRepo := new(mockSearchRepository)
+	userRepo := new(mockDocUserRepository)
+	userID := uuid.New()
+	testID := uuid.New()
+
+	teacherRole := &entity.Role{ID: uuid.New(), Name: "teacher"}
+	userRepo.On("FindByID", mock.Anything, userID).Return(&entity.User{ID: userID, RoleID: teacherRole.ID, Role: teacherRole}, nil)
+
+	searchRepo.On("Search", mock.Anything, mock.MatchedBy(func(query repository.SearchQuery) bool {
+		return query.Text == "горутины" && query.UserID != nil && *query.UserID == userID &&
+			len(query.Types) == 1 && query.Types[0] == entity.SearchResultQuestion &&
+			query.Limit == 10 && query.Offset == 10
+	})).Return([]*entity.SearchResult{{
+		Type:      entity.SearchResultQuestion,
+		ID:        uuid.New(),
+		UserID:    userID,
+		TestID:    &testID,
+		Title:     "Go basics",
+		Snippet:   "Что такое <mark>горутины</mark>?",
+		Rank:      0.6,
+		CreatedAt: time.Now(),
+	}}, int64(11), nil)
+
+	app := newSearchApp(searchRepo, userRepo, userID)
+	req := httptest.NewRequest(http.MethodGet, "/search?q="+url.QueryEscape("горутины")+"&type=question&page=2&page_size=10", nil)
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var body dto.SearchResponse
+	require.NoError(t, json.Unmarshal(getBodyBytes(t, resp), &body))
+	assert.EqualValues(t, 11, body.Total)
+	require.Len(t, body.Results, 1)
+	assert.Equal(t, "question", body.Results[0].Type)
+	require.NotNil(t, body.Results[0].TestID)
+	assert.Equal(t, testID.String(), *body.Results[0].TestID)
+
+	searchRepo.AssertExpectations(t)
+}
+
+func TestSearch_AdminSearchesEverything(t *testing.T) {
+	searchRepo := new(mockSearchRepository)
+	userRepo := new(mockDocUserRepository)
+	userID := uuid.New()
+
+	adminRole := &entity.Role{ID: uuid.New(), Name: "admin"}
+	userRepo.On("FindByID", mock.Anything, userID).Return(&entity.User{ID: userID, RoleID: adminRole.ID, Role: adminRole}, nil)
+	searchRepo.On("Search", mock.Anything, mock.MatchedBy(func(query repository.SearchQuery) bool {
+		return query.UserID == nil && len(query.Types) == 0
+	})).Return([]*entity.SearchResult{}, int64(0), nil)
+
+	app := newSearchApp(searchRepo, userRepo, userID)
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/search?q=syllabus", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	searchRepo.AssertExpectations(t)
+}
+
+func TestSearch_ValidatesQuery(t *testing.T) {
+	searchRepo := new(mockSearchRepository)
+	app := newSearchApp(searchRepo, new(mockDocUserRepository), uuid.New())
+
+	for _, query := range []string{"", "?q=%20%20", "?q=go&type=user"} {
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/search"+query, nil))
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode, query)
+	}
+
+	searchRepo.AssertNotCalled(t, "Search", mock.Anything, mock.Anything)
+}
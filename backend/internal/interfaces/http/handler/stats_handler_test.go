@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -24,9 +25,15 @@ type mockStatsTestRepository struct {
 func (m *mockStatsTestRepository) Create(ctx context.Context, test *entity.Test) error {
 	return nil
 }
+func (m *mockStatsTestRepository) CreateWithQuestions(ctx context.Context, test *entity.Test) error {
+	return nil
+}
 func (m *mockStatsTestRepository) FindByID(ctx context.Context, id uuid.UUID) (*entity.Test, error) {
 	return nil, nil
 }
+func (m *mockStatsTestRepository) FindByIDForUpdate(ctx context.Context, id uuid.UUID) (*entity.Test, error) {
+	return nil, nil
+}
 func (m *mockStatsTestRepository) FindByUserID(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*entity.Test, error) {
 	return nil, nil
 }
@@ -34,7 +41,10 @@ func (m *mockStatsTestRepository) FindAll(ctx context.Context, limit, offset int
 	return nil, nil
 }
 func (m *mockStatsTestRepository) Update(ctx context.Context, test *entity.Test) error { return nil }
-func (m *mockStatsTestRepository) Delete(ctx context.Context, id uuid.UUID) error      { return nil }
+func (m *mockStatsTestRepository) UpdateStatus(ctx context.Context, test *entity.Test) error {
+	return nil
+}
+func (m *mockStatsTestRepository) Delete(ctx context.Context, id uuid.UUID) error { return nil }
 func (m *mockStatsTestRepository) CountByUserID(ctx context.Context, userID uuid.UUID) (int64, error) {
 	args := m.Called(ctx, userID)
 	return args.Get(0).(int64), args.Error(1)
@@ -43,6 +53,21 @@ func (m *mockStatsTestRepository) CountAll(ctx context.Context) (int64, error) {
 	args := m.Called(ctx)
 	return args.Get(0).(int64), args.Error(1)
 }
+func (m *mockStatsTestRepository) FindPublishedByStudentID(ctx context.Context, studentID uuid.UUID, limit, offset int) ([]*entity.Test, error) {
+	return nil, nil
+}
+func (m *mockStatsTestRepository) CountPublishedByStudentID(ctx context.Context, studentID uuid.UUID) (int64, error) {
+	return 0, nil
+}
+func (m *mockStatsTestRepository) AssignStudents(ctx context.Context, assignments []entity.TestAssignment) error {
+	return nil
+}
+func (m *mockStatsTestRepository) UnassignStudent(ctx context.Context, testID, studentID uuid.UUID) error {
+	return nil
+}
+func (m *mockStatsTestRepository) FindAssignments(ctx context.Context, testID uuid.UUID) ([]*entity.TestAssignment, error) {
+	return nil, nil
+}
 
 type mockStatsDocumentRepository struct {
 	mock.Mock
@@ -73,6 +98,46 @@ func (m *mockStatsDocumentRepository) CountAll(ctx context.Context) (int64, erro
 	return args.Get(0).(int64), args.Error(1)
 }
 
+func (m *mockStatsDocumentRepository) FindByStatus(ctx context.Context, status entity.DocumentStatus, limit int) ([]*entity.Document, error) {
+	return nil, nil
+}
+
+func (m *mockStatsDocumentRepository) ClaimForParsing(ctx context.Context, id uuid.UUID) (bool, error) {
+	return false, nil
+}
+
+func (m *mockStatsDocumentRepository) UpdateParseState(ctx context.Context, document *entity.Document) error {
+	return nil
+}
+
+func (m *mockStatsDocumentRepository) ResetStaleParsing(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (m *mockStatsDocumentRepository) FindByContentHash(ctx context.Context, contentHash string) ([]*entity.Document, error) {
+	return nil, nil
+}
+
+func (m *mockStatsDocumentRepository) CountByFilePath(ctx context.Context, filePath string) (int64, error) {
+	return 0, nil
+}
+
+func (m *mockStatsDocumentRepository) FindDeletedBefore(ctx context.Context, before time.Time, limit, offset int) ([]*entity.Document, error) {
+	return nil, nil
+}
+
+func (m *mockStatsDocumentRepository) CountRetainedByFilePath(ctx context.Context, filePath string, deletedBefore time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (m *mockStatsDocumentRepository) MarkFilePurged(ctx context.Context, id uuid.UUID) error {
+	return nil
+}
+
+func (m *mockStatsDocumentRepository) FindFilePaths(ctx context.Context) ([]string, error) {
+	return nil, nil
+}
+
 type mockStatsQuestionRepository struct {
 	mock.Mock
 }
@@ -86,6 +151,9 @@ func (m *mockStatsQuestionRepository) FindByID(ctx context.Context, id uuid.UUID
 func (m *mockStatsQuestionRepository) FindByTestID(ctx context.Context, testID uuid.UUID) ([]*entity.Question, error) {
 	return nil, nil
 }
+func (m *mockStatsQuestionRepository) FindByTestIDWithAnswers(ctx context.Context, testID uuid.UUID) ([]*entity.Question, error) {
+	return nil, nil
+}
 func (m *mockStatsQuestionRepository) Update(ctx context.Context, question *entity.Question) error {
 	return nil
 }
@@ -93,6 +161,12 @@ func (m *mockStatsQuestionRepository) Delete(ctx context.Context, id uuid.UUID)
 func (m *mockStatsQuestionRepository) CountByTestID(ctx context.Context, testID uuid.UUID) (int, error) {
 	return 0, nil
 }
+func (m *mockStatsQuestionRepository) CreateInTest(ctx context.Context, question *entity.Question) error {
+	return nil
+}
+func (m *mockStatsQuestionRepository) DeleteFromTest(ctx context.Context, testID, questionID uuid.UUID) error {
+	return nil
+}
 func (m *mockStatsQuestionRepository) ReorderQuestions(ctx context.Context, testID uuid.UUID, questionIDs []uuid.UUID) error {
 	return nil
 }
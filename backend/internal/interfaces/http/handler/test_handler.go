@@ -1,6 +1,9 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
+	"sort"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -11,6 +14,8 @@ import (
 	"github.com/shester1kov/testgen-backend/internal/infrastructure/llm"
 	"github.com/shester1kov/testgen-backend/internal/infrastructure/moodle"
 	"github.com/shester1kov/testgen-backend/pkg/security"
+	"github.com/shester1kov/testgen-backend/pkg/textstats"
+	"gorm.io/gorm"
 )
 
 type TestHandler struct {
@@ -19,6 +24,7 @@ type TestHandler struct {
 	questionRepo repository.QuestionRepository
 	answerRepo   repository.AnswerRepository
 	userRepo     repository.UserRepository
+	uow          repository.UnitOfWork
 	llmFactory   *llm.LLMFactory
 	xmlExporter  *moodle.MoodleXMLExporter
 }
@@ -29,6 +35,7 @@ func NewTestHandler(
 	questionRepo repository.QuestionRepository,
 	answerRepo repository.AnswerRepository,
 	userRepo repository.UserRepository,
+	uow repository.UnitOfWork,
 	llmFactory *llm.LLMFactory,
 	xmlExporter *moodle.MoodleXMLExporter,
 ) *TestHandler {
@@ -38,6 +45,7 @@ func NewTestHandler(
 		questionRepo: questionRepo,
 		answerRepo:   answerRepo,
 		userRepo:     userRepo,
+		uow:          uow,
 		llmFactory:   llmFactory,
 		xmlExporter:  xmlExporter,
 	}
@@ -88,7 +96,7 @@ func (h *TestHandler) Create(c *fiber.Ctx) error {
 
 	return c.Status(fiber.StatusCreated).JSON(dto.TestResponse{
 		ID: test.ID.String(), UserID: test.UserID.String(), Title: test.Title, Description: test.Description,
-		TotalQuestions: 0, Status: string(test.Status), CreatedAt: test.CreatedAt.Format("2006-01-02T15:04:05Z"),
+		TotalQuestions: 0, Status: string(test.Status), Version: test.Version, CreatedAt: test.CreatedAt.Format("2006-01-02T15:04:05Z"),
 	})
 }
 
@@ -120,6 +128,16 @@ func (h *TestHandler) Generate(c *fiber.Ctx) error {
 		)
 	}
 
+	questionTypes := make([]llm.QuestionType, 0, len(req.QuestionTypes))
+	for _, questionType := range req.QuestionTypes {
+		if !(&entity.Question{QuestionType: entity.QuestionType(questionType)}).IsValidType() {
+			return c.Status(fiber.StatusBadRequest).JSON(
+				dto.NewErrorResponse(dto.ErrCodeInvalidInput, fmt.Sprintf("invalid question type %q", questionType)),
+			)
+		}
+		questionTypes = append(questionTypes, llm.QuestionType(questionType))
+	}
+
 	docID, _ := uuid.Parse(req.DocumentID)
 	document, err := h.documentRepo.FindByID(c.Context(), docID)
 	if err != nil {
@@ -161,81 +179,69 @@ func (h *TestHandler) Generate(c *fiber.Ctx) error {
 		)
 	}
 
+	// Oversized documents are still sent, the teacher is warned that generation may fail or miss content
+	var warnings []string
+	tokens, limit := documentTokenEstimate(document, strategy.GetProviderName()), llm.InputTokenLimit(strategy.GetProviderName())
+	tooLong := limit > 0 && tokens > limit
+	if tooLong {
+		warnings = append(warnings, fmt.Sprintf(
+			"document is about %d tokens, more than the %d tokens %s accepts; questions may cover only part of it",
+			tokens, limit, strategy.GetProviderName(),
+		))
+	}
+
 	llmContext := llm.NewLLMContext(strategy)
 	questions, err := llmContext.GenerateQuestions(c.Context(), llm.GenerationParams{
-		Text: document.ParsedText, NumQuestions: req.NumQuestions, Difficulty: req.Difficulty,
+		Text: document.EffectiveText(), NumQuestions: req.NumQuestions, Difficulty: req.Difficulty,
+		QuestionTypes: questionTypes,
 	})
 	if err != nil {
+		message := "failed to generate questions"
+		if tooLong {
+			message = fmt.Sprintf("failed to generate questions: document is too long for %s", strategy.GetProviderName())
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(
-			dto.NewErrorResponse(dto.ErrCodeGenerationFailed, "failed to generate questions"),
+			dto.NewErrorResponse(dto.ErrCodeGenerationFailed, message),
 		)
 	}
 
 	// Sanitize user input
 	sanitizedTitle := security.SanitizeInput(req.Title)
 
-	// Create Test entity
+	// Build the test with its questions and answers
 	test := &entity.Test{
-		ID:             uuid.New(),
-		UserID:         userID,
-		DocumentID:     &docID,
-		Title:          sanitizedTitle,
-		TotalQuestions: len(questions),
-		Status:         entity.TestStatusDraft,
-		CreatedAt:      time.Now(),
-		UpdatedAt:      time.Now(),
+		ID:         uuid.New(),
+		UserID:     userID,
+		DocumentID: &docID,
+		Title:      sanitizedTitle,
+		Status:     entity.TestStatusDraft,
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+	}
+	// Questions whose answers do not fit their type cannot be answered or exported
+	dropped := 0
+	for _, q := range questions {
+		question := generatedQuestion(q)
+		if !question.IsValidType() || question.ValidateAnswers() != nil {
+			dropped++
+			continue
+		}
+		test.Questions = append(test.Questions, question)
 	}
-
-	// Save test to database
-	if err := h.testRepo.Create(c.Context(), test); err != nil {
+	if len(test.Questions) == 0 && dropped > 0 {
 		return c.Status(fiber.StatusInternalServerError).JSON(
-			dto.NewErrorResponse(dto.ErrCodeInternalError, "failed to save test"),
+			dto.NewErrorResponse(dto.ErrCodeGenerationFailed, "failed to generate questions: answers did not fit the question types"),
 		)
 	}
+	if dropped > 0 {
+		warnings = append(warnings, fmt.Sprintf("%d generated questions were dropped because their answers did not fit the question type", dropped))
+	}
 
-	// Save questions and answers to database
-	for i, q := range questions {
-		// Sanitize question text from LLM output (defense in depth)
-		sanitizedQuestionText := security.SanitizeMultiline(q.QuestionText)
-
-		question := &entity.Question{
-			ID:           uuid.New(),
-			TestID:       test.ID,
-			QuestionText: sanitizedQuestionText,
-			QuestionType: entity.QuestionType(q.QuestionType),
-			Difficulty:   entity.Difficulty(q.Difficulty),
-			Points:       1.0, // Default points
-			OrderNum:     i + 1,
-			CreatedAt:    time.Now(),
-			UpdatedAt:    time.Now(),
-		}
-
-		if err := h.questionRepo.Create(c.Context(), question); err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(
-				dto.NewErrorResponse(dto.ErrCodeInternalError, "failed to save question"),
-			)
-		}
-
-		// Save answers
-		for j, a := range q.Answers {
-			// Sanitize answer text from LLM output
-			sanitizedAnswerText := security.SanitizeInput(a.Text)
-
-			answer := &entity.Answer{
-				ID:         uuid.New(),
-				QuestionID: question.ID,
-				AnswerText: sanitizedAnswerText,
-				IsCorrect:  a.IsCorrect,
-				OrderNum:   j + 1,
-				CreatedAt:  time.Now(),
-			}
-
-			if err := h.answerRepo.Create(c.Context(), answer); err != nil {
-				return c.Status(fiber.StatusInternalServerError).JSON(
-					dto.NewErrorResponse(dto.ErrCodeInternalError, "failed to save answer"),
-				)
-			}
-		}
+	// Save the whole test at once so a failure leaves no partial test behind
+	if err := h.testRepo.CreateWithQuestions(c.Context(), test); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInternalError, "failed to save test"),
+		)
 	}
 
 	// Return test response with ID
@@ -247,13 +253,73 @@ func (h *TestHandler) Generate(c *fiber.Ctx) error {
 		TotalQuestions: test.TotalQuestions,
 		Status:         string(test.Status),
 		MoodleSynced:   false,
+		Version:        test.Version,
 		CreatedAt:      test.CreatedAt.Format(time.RFC3339),
+		Warnings:       warnings,
 	})
 }
 
+// generatedQuestion converts a question generated by LLM into an unsaved test question
+func generatedQuestion(q llm.GeneratedQuestion) entity.Question {
+	question := entity.Question{
+		// Sanitize question text from LLM output (defense in depth)
+		QuestionText: security.SanitizeMultiline(q.QuestionText),
+		QuestionType: entity.QuestionType(q.QuestionType),
+		Difficulty:   entity.Difficulty(q.Difficulty),
+		Points:       1.0, // Default points
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
+	}
+	question.CaseSensitive = question.IsShortAnswer() && q.CaseSensitive
+	for _, a := range q.Answers {
+		answer := entity.Answer{
+			// Sanitize answer text from LLM output
+			AnswerText: security.SanitizeInput(a.Text),
+			IsCorrect:  a.IsCorrect,
+			MatchText:  security.SanitizeInput(a.Match),
+			Tolerance:  a.Tolerance,
+			CreatedAt:  time.Now(),
+		}
+		if question.IsShortAnswer() {
+			// Variant grades are rounded to a percentage Moodle accepts; other types keep computed fractions
+			if a.Weight != nil {
+				weight := entity.NearestAnswerWeight(*a.Weight)
+				answer.Weight = &weight
+			}
+			if shortAnswerVariantShadowed(question.Answers, answer.AnswerText, question.CaseSensitive) {
+				continue
+			}
+		}
+		question.Answers = append(question.Answers, answer)
+	}
+	question.NormalizeTrueFalseAnswers()
+	return question
+}
+
+// shortAnswerVariantShadowed reports whether an earlier variant already accepts
+// the variant, which LLMs produce when repeating a spelling or listing a
+// wildcard first
+func shortAnswerVariantShadowed(earlier []entity.Answer, variant string, caseSensitive bool) bool {
+	for _, a := range earlier {
+		if entity.ShortAnswerShadows(a.AnswerText, variant, caseSensitive) {
+			return true
+		}
+	}
+	return false
+}
+
+// documentTokenEstimate returns the stored token estimate for a provider, estimating on the fly
+// for documents parsed before statistics were stored
+func documentTokenEstimate(document *entity.Document, provider string) int {
+	if tokens, ok := document.TokenEstimates[provider]; ok {
+		return tokens
+	}
+	return textstats.EstimateTokens(document.EffectiveText(), provider)
+}
+
 // List godoc
 // @Summary List user's tests
-// @Description Get paginated list of tests created by the current user. Admin sees all tests with user info, others see only their own
+// @Description Get paginated list of tests created by the current user. Admin sees all tests with user info, students see published tests assigned to them, others see only their own
 // @Tags tests
 // @Produce json
 // @Security BearerAuth
@@ -291,8 +357,9 @@ func (h *TestHandler) List(c *fiber.Ctx) error {
 	var tests []*entity.Test
 	var total int64
 
-	// Admin sees all tests, others see only their own
-	if user.IsAdmin() {
+	// Admin sees all tests, students the published tests assigned to them, others only their own
+	switch {
+	case user.IsAdmin():
 		tests, err = h.testRepo.FindAll(c.Context(), pageSize, offset)
 		if err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(
@@ -306,7 +373,21 @@ func (h *TestHandler) List(c *fiber.Ctx) error {
 				dto.NewErrorResponse(dto.ErrCodeDatabaseError, "failed to count tests"),
 			)
 		}
-	} else {
+	case user.IsStudent():
+		tests, err = h.testRepo.FindPublishedByStudentID(c.Context(), userID, pageSize, offset)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(
+				dto.NewErrorResponse(dto.ErrCodeDatabaseError, "failed to fetch tests"),
+			)
+		}
+
+		total, err = h.testRepo.CountPublishedByStudentID(c.Context(), userID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(
+				dto.NewErrorResponse(dto.ErrCodeDatabaseError, "failed to count tests"),
+			)
+		}
+	default:
 		tests, err = h.testRepo.FindByUserID(c.Context(), userID, pageSize, offset)
 		if err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(
@@ -333,16 +414,18 @@ func (h *TestHandler) List(c *fiber.Ctx) error {
 		}
 
 		result[i] = dto.TestResponse{
-			ID:             t.ID.String(),
-			UserID:         t.UserID.String(),
-			UserName:       userName,
-			UserEmail:      userEmail,
-			Title:          t.Title,
-			Description:    t.Description,
-			TotalQuestions: t.TotalQuestions,
-			Status:         string(t.Status),
-			MoodleSynced:   t.MoodleSynced,
-			CreatedAt:      t.CreatedAt.Format(time.RFC3339),
+			ID:                t.ID.String(),
+			UserID:            t.UserID.String(),
+			UserName:          userName,
+			UserEmail:         userEmail,
+			Title:             t.Title,
+			Description:       t.Description,
+			TotalQuestions:    t.TotalQuestions,
+			Status:            string(t.Status),
+			MoodleSynced:      t.MoodleSynced,
+			Version:           t.Version,
+			PreviousVersionID: previousVersionID(t),
+			CreatedAt:         t.CreatedAt.Format(time.RFC3339),
 		}
 	}
 
@@ -377,56 +460,17 @@ func (h *TestHandler) GetByID(c *fiber.Ctx) error {
 		)
 	}
 
-	// Load questions with answers
-	questions, err := h.questionRepo.FindByTestID(c.Context(), testID)
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(
-			dto.NewErrorResponse(dto.ErrCodeDatabaseError, "failed to load questions"),
-		)
-	}
-
-	// Build questions DTO with answers
-	questionsDTO := make([]dto.QuestionDTO, len(questions))
-	for i, q := range questions {
-		// Load answers for each question
-		answers, err := h.answerRepo.FindByQuestionID(c.Context(), q.ID)
-		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(
-				dto.NewErrorResponse(dto.ErrCodeDatabaseError, "failed to load answers"),
-			)
-		}
-
-		// Build answers DTO
-		answersDTO := make([]dto.AnswerDTO, len(answers))
-		for j, a := range answers {
-			answersDTO[j] = dto.AnswerDTO{
-				ID:         a.ID.String(),
-				AnswerText: a.AnswerText,
-				IsCorrect:  a.IsCorrect,
-				OrderNum:   a.OrderNum,
-			}
-		}
-
-		questionsDTO[i] = dto.QuestionDTO{
-			ID:           q.ID.String(),
-			QuestionText: q.QuestionText,
-			QuestionType: string(q.QuestionType),
-			Difficulty:   string(q.Difficulty),
-			Points:       q.Points,
-			OrderNum:     q.OrderNum,
-			Answers:      answersDTO,
-		}
-	}
-
 	return c.JSON(dto.TestResponse{
-		ID:             test.ID.String(),
-		Title:          test.Title,
-		Description:    test.Description,
-		TotalQuestions: test.TotalQuestions,
-		Status:         string(test.Status),
-		MoodleSynced:   test.MoodleSynced,
-		CreatedAt:      test.CreatedAt.Format(time.RFC3339),
-		Questions:      questionsDTO,
+		ID:                test.ID.String(),
+		Title:             test.Title,
+		Description:       test.Description,
+		TotalQuestions:    test.TotalQuestions,
+		Status:            string(test.Status),
+		MoodleSynced:      test.MoodleSynced,
+		Version:           test.Version,
+		PreviousVersionID: previousVersionID(test),
+		CreatedAt:         test.CreatedAt.Format(time.RFC3339),
+		Questions:         questionDTOs(testQuestions(test), labelLanguage(c)),
 	})
 }
 
@@ -507,6 +551,9 @@ func (h *TestHandler) Update(c *fiber.Ctx) error {
 			dto.NewErrorResponse(dto.ErrCodeForbidden, "access denied"),
 		)
 	}
+	if !test.IsEditable() {
+		return testLockedError(c)
+	}
 
 	// Parse request
 	var req dto.UpdateTestRequest
@@ -549,6 +596,7 @@ func (h *TestHandler) Update(c *fiber.Ctx) error {
 		TotalQuestions: test.TotalQuestions,
 		Status:         string(test.Status),
 		MoodleSynced:   test.MoodleSynced,
+		Version:        test.Version,
 		CreatedAt:      test.CreatedAt.Format(time.RFC3339),
 	})
 }
@@ -605,6 +653,9 @@ func (h *TestHandler) UpdateQuestion(c *fiber.Ctx) error {
 			dto.NewErrorResponse(dto.ErrCodeForbidden, "access denied"),
 		)
 	}
+	if !test.IsEditable() {
+		return testLockedError(c)
+	}
 
 	// Check if question exists and belongs to test
 	question, err := h.questionRepo.FindByID(c.Context(), questionID)
@@ -645,42 +696,109 @@ func (h *TestHandler) UpdateQuestion(c *fiber.Ctx) error {
 	if req.Points != nil {
 		question.Points = *req.Points
 	}
-	question.UpdatedAt = time.Now()
-
-	// Save question
-	if err := h.questionRepo.Update(c.Context(), question); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(
-			dto.NewErrorResponse(dto.ErrCodeInternalError, "failed to update question"),
+	if req.CaseSensitive != nil {
+		question.CaseSensitive = *req.CaseSensitive
+	} else if !question.IsShortAnswer() {
+		// Case sensitivity does not carry over when the type changes
+		question.CaseSensitive = false
+	}
+	if !question.IsValidType() {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInvalidInput, "invalid question type"),
+		)
+	}
+	if !question.IsValidDifficulty() {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInvalidInput, "invalid difficulty"),
 		)
 	}
 
-	// Update answers if provided
-	if len(req.Answers) > 0 {
+	// Cloze answers are embedded in the text, so a cloze question keeps no answer list
+	dropAnswers := question.IsCloze() && len(req.Answers) == 0
+
+	// Answers must fit the resulting type, whether they, the type, the case sensitivity or the cloze text changed
+	if len(req.Answers) > 0 || req.QuestionType != "" || req.CaseSensitive != nil || (question.IsCloze() && req.QuestionText != "") {
+		candidate := *question
+		candidate.Answers = nil
+		if len(req.Answers) > 0 {
+			for _, answerReq := range req.Answers {
+				candidate.Answers = append(candidate.Answers, entity.Answer{
+					AnswerText: security.SanitizeInput(answerReq.AnswerText),
+					IsCorrect:  answerReq.IsCorrect,
+					MatchText:  security.SanitizeInput(answerReq.MatchText),
+					Tolerance:  answerReq.Tolerance,
+					Weight:     answerReq.Weight,
+				})
+			}
+		} else if !dropAnswers {
+			current, err := h.answerRepo.FindByQuestionID(c.Context(), questionID)
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(
+					dto.NewErrorResponse(dto.ErrCodeDatabaseError, "failed to load answers"),
+				)
+			}
+			for _, a := range current {
+				candidate.Answers = append(candidate.Answers, *a)
+			}
+		}
+		candidate.NormalizeTrueFalseAnswers()
+		if err := candidate.ValidateAnswers(); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(
+				dto.NewErrorResponse(dto.ErrCodeInvalidInput, err.Error()),
+			)
+		}
+	}
+	question.UpdatedAt = time.Now()
+
+	// Save the question and replace its answers atomically
+	err = h.uow.Do(c.Context(), func(repos repository.TxRepositories) error {
+		if err := lockEditableTest(c.Context(), repos, testID); err != nil {
+			return err
+		}
+		if err := repos.Questions.Update(c.Context(), question); err != nil {
+			return err
+		}
+		if len(req.Answers) == 0 && !dropAnswers {
+			return nil
+		}
+
 		// Delete old answers
-		oldAnswers, _ := h.answerRepo.FindByQuestionID(c.Context(), questionID)
+		oldAnswers, err := repos.Answers.FindByQuestionID(c.Context(), questionID)
+		if err != nil {
+			return err
+		}
 		for _, oldAnswer := range oldAnswers {
-			h.answerRepo.Delete(c.Context(), oldAnswer.ID)
+			if err := repos.Answers.Delete(c.Context(), oldAnswer.ID); err != nil {
+				return err
+			}
 		}
 
 		// Create new answers
 		for _, answerReq := range req.Answers {
-			// Sanitize answer text
-			sanitizedAnswerText := security.SanitizeInput(answerReq.AnswerText)
-
 			answer := &entity.Answer{
 				ID:         uuid.New(),
 				QuestionID: questionID,
-				AnswerText: sanitizedAnswerText,
+				AnswerText: entity.NormalizeAnswerText(question.QuestionType, security.SanitizeInput(answerReq.AnswerText)),
 				IsCorrect:  answerReq.IsCorrect,
+				MatchText:  security.SanitizeInput(answerReq.MatchText),
+				Tolerance:  answerReq.Tolerance,
+				Weight:     answerReq.Weight,
 				OrderNum:   answerReq.OrderNum,
 				CreatedAt:  time.Now(),
 			}
-			if err := h.answerRepo.Create(c.Context(), answer); err != nil {
-				return c.Status(fiber.StatusInternalServerError).JSON(
-					dto.NewErrorResponse(dto.ErrCodeInternalError, "failed to create answer"),
-				)
+			if err := repos.Answers.Create(c.Context(), answer); err != nil {
+				return err
 			}
 		}
+		return nil
+	})
+	if errors.Is(err, repository.ErrTestLocked) {
+		return testLockedError(c)
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInternalError, "failed to update question"),
+		)
 	}
 
 	// Load updated answers
@@ -692,42 +810,49 @@ func (h *TestHandler) UpdateQuestion(c *fiber.Ctx) error {
 	}
 
 	// Build answers DTO
+	language := labelLanguage(c)
 	answersDTO := make([]dto.AnswerDTO, len(answers))
 	for i, a := range answers {
 		answersDTO[i] = dto.AnswerDTO{
 			ID:         a.ID.String(),
-			AnswerText: a.AnswerText,
+			AnswerText: entity.AnswerLabel(question.QuestionType, a.AnswerText, language),
 			IsCorrect:  a.IsCorrect,
+			MatchText:  a.MatchText,
+			Tolerance:  a.Tolerance,
+			Weight:     a.Weight,
 			OrderNum:   a.OrderNum,
 		}
 	}
 
 	return c.JSON(dto.QuestionDTO{
-		ID:           question.ID.String(),
-		QuestionText: question.QuestionText,
-		QuestionType: string(question.QuestionType),
-		Difficulty:   string(question.Difficulty),
-		Points:       question.Points,
-		OrderNum:     question.OrderNum,
-		Answers:      answersDTO,
+		ID:            question.ID.String(),
+		QuestionText:  question.QuestionText,
+		QuestionType:  string(question.QuestionType),
+		Difficulty:    string(question.Difficulty),
+		Points:        question.Points,
+		CaseSensitive: question.CaseSensitive,
+		OrderNum:      question.OrderNum,
+		Answers:       answersDTO,
 	})
 }
 
-// ExportToJSON godoc
-// @Summary Export test to JSON format
-// @Description Export a test and its questions to JSON format for download
+// CreateQuestion godoc
+// @Summary Add a question to a test
+// @Description Create a question with its answers at the given position; later questions shift down and the test's question count is updated
 // @Tags tests
+// @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param id path string true "Test ID"
-// @Success 200 {object} dto.TestResponse
-// @Failure 400 {object} dto.ErrorResponse "Invalid test ID"
+// @Param testId path string true "Test ID"
+// @Param request body dto.CreateQuestionRequest true "Create question request"
+// @Success 201 {object} dto.QuestionDTO
+// @Failure 400 {object} dto.ErrorResponse "Invalid request"
 // @Failure 401 {object} dto.ErrorResponse "Unauthorized"
 // @Failure 403 {object} dto.ErrorResponse "Access denied"
 // @Failure 404 {object} dto.ErrorResponse "Test not found"
 // @Failure 500 {object} dto.ErrorResponse "Internal server error"
-// @Router /tests/{id}/export/json [get]
-func (h *TestHandler) ExportToJSON(c *fiber.Ctx) error {
+// @Router /tests/{testId}/questions [post]
+func (h *TestHandler) CreateQuestion(c *fiber.Ctx) error {
 	userID, ok := getUserIDFromContext(c)
 	if !ok {
 		return c.Status(fiber.StatusUnauthorized).JSON(
@@ -735,10 +860,10 @@ func (h *TestHandler) ExportToJSON(c *fiber.Ctx) error {
 		)
 	}
 
-	testID, err := uuid.Parse(c.Params("id"))
+	testID, err := uuid.Parse(c.Params("testId"))
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(
-			dto.NewErrorResponse(dto.ErrCodeInvalidTestID, "invalid test ID"),
+			dto.NewErrorResponse(dto.ErrCodeInvalidInput, "invalid test ID"),
 		)
 	}
 
@@ -755,53 +880,624 @@ func (h *TestHandler) ExportToJSON(c *fiber.Ctx) error {
 			dto.NewErrorResponse(dto.ErrCodeForbidden, "access denied"),
 		)
 	}
+	if !test.IsEditable() {
+		return testLockedError(c)
+	}
 
-	// Load questions with answers
-	questions, err := h.questionRepo.FindByTestID(c.Context(), testID)
+	var req dto.CreateQuestionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInvalidInput, "invalid request body"),
+		)
+	}
+
+	sanitizedQuestionText := security.SanitizeMultiline(req.QuestionText)
+	if len(sanitizedQuestionText) < 3 {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInvalidInput, "question text must be at least 3 characters"),
+		)
+	}
+
+	question := &entity.Question{
+		ID:            uuid.New(),
+		TestID:        testID,
+		QuestionText:  sanitizedQuestionText,
+		QuestionType:  entity.QuestionType(req.QuestionType),
+		Difficulty:    entity.DifficultyMedium,
+		Points:        1.0,
+		CaseSensitive: req.CaseSensitive,
+		OrderNum:      req.OrderNum,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+	if req.Difficulty != "" {
+		question.Difficulty = entity.Difficulty(req.Difficulty)
+	}
+	if req.Points != nil {
+		if *req.Points <= 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(
+				dto.NewErrorResponse(dto.ErrCodeInvalidInput, "points must be positive"),
+			)
+		}
+		question.Points = *req.Points
+	}
+	if !question.IsValidType() {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInvalidInput, "invalid question type"),
+		)
+	}
+	if !question.IsValidDifficulty() {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInvalidInput, "invalid difficulty"),
+		)
+	}
+
+	for i, answerReq := range req.Answers {
+		question.Answers = append(question.Answers, entity.Answer{
+			ID:         uuid.New(),
+			QuestionID: question.ID,
+			AnswerText: security.SanitizeInput(answerReq.AnswerText),
+			IsCorrect:  answerReq.IsCorrect,
+			MatchText:  security.SanitizeInput(answerReq.MatchText),
+			Tolerance:  answerReq.Tolerance,
+			Weight:     answerReq.Weight,
+			OrderNum:   i + 1,
+			CreatedAt:  time.Now(),
+		})
+	}
+	question.NormalizeTrueFalseAnswers()
+	if err := question.ValidateAnswers(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInvalidInput, err.Error()),
+		)
+	}
+
+	// Insert, shift later questions and refresh the count in one transaction
+	if err := h.questionRepo.CreateInTest(c.Context(), question); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(
+				dto.NewErrorResponse(dto.ErrCodeTestNotFound, "test not found"),
+			)
+		}
+		if errors.Is(err, repository.ErrTestLocked) {
+			return testLockedError(c)
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInternalError, "failed to create question"),
+		)
+	}
+
+	language := labelLanguage(c)
+	answersDTO := make([]dto.AnswerDTO, len(question.Answers))
+	for i, a := range question.Answers {
+		answersDTO[i] = dto.AnswerDTO{
+			ID:         a.ID.String(),
+			AnswerText: entity.AnswerLabel(question.QuestionType, a.AnswerText, language),
+			IsCorrect:  a.IsCorrect,
+			MatchText:  a.MatchText,
+			Tolerance:  a.Tolerance,
+			Weight:     a.Weight,
+			OrderNum:   a.OrderNum,
+		}
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dto.QuestionDTO{
+		ID:            question.ID.String(),
+		QuestionText:  question.QuestionText,
+		QuestionType:  string(question.QuestionType),
+		Difficulty:    string(question.Difficulty),
+		Points:        question.Points,
+		CaseSensitive: question.CaseSensitive,
+		OrderNum:      question.OrderNum,
+		Answers:       answersDTO,
+	})
+}
+
+// DeleteQuestion godoc
+// @Summary Delete a question
+// @Description Delete a question with its answers; later questions move up and the test's question count is updated
+// @Tags tests
+// @Produce json
+// @Security BearerAuth
+// @Param testId path string true "Test ID"
+// @Param questionId path string true "Question ID"
+// @Success 200 {object} dto.MessageResponse
+// @Failure 400 {object} dto.ErrorResponse "Invalid ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 403 {object} dto.ErrorResponse "Access denied"
+// @Failure 404 {object} dto.ErrorResponse "Question not found"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /tests/{testId}/questions/{questionId} [delete]
+func (h *TestHandler) DeleteQuestion(c *fiber.Ctx) error {
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(
+			dto.NewErrorResponse(dto.ErrCodeUnauthorized, "Unauthorized"),
+		)
+	}
+
+	testID, err := uuid.Parse(c.Params("testId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInvalidInput, "invalid test ID"),
+		)
+	}
+
+	questionID, err := uuid.Parse(c.Params("questionId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInvalidInput, "invalid question ID"),
+		)
+	}
+
+	// Check if test exists and belongs to user
+	test, err := h.testRepo.FindByID(c.Context(), testID)
 	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(
+			dto.NewErrorResponse(dto.ErrCodeTestNotFound, "test not found"),
+		)
+	}
+
+	if test.UserID != userID {
+		return c.Status(fiber.StatusForbidden).JSON(
+			dto.NewErrorResponse(dto.ErrCodeForbidden, "access denied"),
+		)
+	}
+	if !test.IsEditable() {
+		return testLockedError(c)
+	}
+
+	if err := h.questionRepo.DeleteFromTest(c.Context(), testID, questionID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(
+				dto.NewErrorResponse(dto.ErrCodeNotFound, "question not found"),
+			)
+		}
+		if errors.Is(err, repository.ErrTestLocked) {
+			return testLockedError(c)
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(
-			dto.NewErrorResponse(dto.ErrCodeDatabaseError, "failed to load questions"),
+			dto.NewErrorResponse(dto.ErrCodeInternalError, "failed to delete question"),
 		)
 	}
 
-	if len(questions) == 0 {
+	return c.JSON(dto.NewMessageResponse("question deleted"))
+}
+
+// ReorderQuestions godoc
+// @Summary Reorder questions of a test
+// @Description Set the order of all questions of a test at once
+// @Tags tests
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param testId path string true "Test ID"
+// @Param request body dto.ReorderQuestionsRequest true "Question IDs in the new order"
+// @Success 200 {object} dto.MessageResponse
+// @Failure 400 {object} dto.ErrorResponse "Invalid request"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 403 {object} dto.ErrorResponse "Access denied"
+// @Failure 404 {object} dto.ErrorResponse "Test not found"
+// @Failure 409 {object} dto.ErrorResponse "Question list does not match the test"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /tests/{testId}/questions/order [put]
+func (h *TestHandler) ReorderQuestions(c *fiber.Ctx) error {
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(
+			dto.NewErrorResponse(dto.ErrCodeUnauthorized, "Unauthorized"),
+		)
+	}
+
+	testID, err := uuid.Parse(c.Params("testId"))
+	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(
-			dto.NewErrorResponse(dto.ErrCodeTestHasNoQuestions, "test has no questions"),
+			dto.NewErrorResponse(dto.ErrCodeInvalidInput, "invalid test ID"),
 		)
 	}
 
-	// Build questions DTO with answers
-	questionsDTO := make([]dto.QuestionDTO, len(questions))
-	for i, q := range questions {
-		// Load answers for each question
-		answers, err := h.answerRepo.FindByQuestionID(c.Context(), q.ID)
+	// Check if test exists and belongs to user
+	test, err := h.testRepo.FindByID(c.Context(), testID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(
+			dto.NewErrorResponse(dto.ErrCodeTestNotFound, "test not found"),
+		)
+	}
+
+	if test.UserID != userID {
+		return c.Status(fiber.StatusForbidden).JSON(
+			dto.NewErrorResponse(dto.ErrCodeForbidden, "access denied"),
+		)
+	}
+	if !test.IsEditable() {
+		return testLockedError(c)
+	}
+
+	var req dto.ReorderQuestionsRequest
+	if err := c.BodyParser(&req); err != nil || len(req.QuestionIDs) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInvalidInput, "question_ids is required"),
+		)
+	}
+
+	questionIDs := make([]uuid.UUID, len(req.QuestionIDs))
+	for i, raw := range req.QuestionIDs {
+		id, err := uuid.Parse(raw)
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(
-				dto.NewErrorResponse(dto.ErrCodeDatabaseError, "failed to load answers"),
+			return c.Status(fiber.StatusBadRequest).JSON(
+				dto.NewErrorResponse(dto.ErrCodeInvalidInput, "invalid question ID"),
+			)
+		}
+		questionIDs[i] = id
+	}
+
+	if err := h.questionRepo.ReorderQuestions(c.Context(), testID, questionIDs); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrQuestionSetMismatch):
+			return c.Status(fiber.StatusConflict).JSON(
+				dto.NewErrorResponse(dto.ErrCodeConflict, "question_ids must list every question of the test exactly once"),
+			)
+		case errors.Is(err, repository.ErrTestLocked):
+			return testLockedError(c)
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(
+				dto.NewErrorResponse(dto.ErrCodeTestNotFound, "test not found"),
+			)
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInternalError, "failed to reorder questions"),
+		)
+	}
+
+	return c.JSON(dto.NewMessageResponse("questions reordered"))
+}
+
+// Clone godoc
+// @Summary Clone a test
+// @Description Copy a test with all its questions and answers into a new draft
+// @Tags tests
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Test ID"
+// @Param request body dto.CloneTestRequest false "Clone options"
+// @Success 201 {object} dto.TestResponse
+// @Failure 400 {object} dto.ErrorResponse "Invalid request"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 403 {object} dto.ErrorResponse "Access denied"
+// @Failure 404 {object} dto.ErrorResponse "Test not found"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /tests/{id}/clone [post]
+func (h *TestHandler) Clone(c *fiber.Ctx) error {
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(
+			dto.NewErrorResponse(dto.ErrCodeUnauthorized, "Unauthorized"),
+		)
+	}
+
+	testID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInvalidTestID, "invalid test ID"),
+		)
+	}
+
+	// Check if test exists and belongs to user
+	source, err := h.testRepo.FindByID(c.Context(), testID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(
+			dto.NewErrorResponse(dto.ErrCodeTestNotFound, "test not found"),
+		)
+	}
+
+	if source.UserID != userID {
+		return c.Status(fiber.StatusForbidden).JSON(
+			dto.NewErrorResponse(dto.ErrCodeForbidden, "access denied"),
+		)
+	}
+
+	// The body is optional
+	var req dto.CloneTestRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(
+				dto.NewErrorResponse(dto.ErrCodeInvalidInput, "invalid request body"),
+			)
+		}
+	}
+
+	title := source.Title + " (copy)"
+	if req.Title != "" {
+		title = security.SanitizeInput(req.Title)
+		if len(title) < 3 {
+			return c.Status(fiber.StatusBadRequest).JSON(
+				dto.NewErrorResponse(dto.ErrCodeInvalidInput, "title must be at least 3 characters"),
 			)
 		}
+	}
+
+	test := &entity.Test{
+		ID:          uuid.New(),
+		UserID:      userID,
+		DocumentID:  source.DocumentID,
+		Title:       title,
+		Description: source.Description,
+		Status:      entity.TestStatusDraft,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+
+	test.Questions = copyQuestions(source.Questions)
+
+	if err := h.testRepo.CreateWithQuestions(c.Context(), test); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(
+			dto.NewErrorResponse(dto.ErrCodeDatabaseError, "failed to clone test"),
+		)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dto.TestResponse{
+		ID:             test.ID.String(),
+		UserID:         test.UserID.String(),
+		Title:          test.Title,
+		Description:    test.Description,
+		TotalQuestions: test.TotalQuestions,
+		Status:         string(test.Status),
+		MoodleSynced:   false,
+		Version:        test.Version,
+		CreatedAt:      test.CreatedAt.Format(time.RFC3339),
+	})
+}
+
+// copyQuestions copies questions and answers in their current order, without
+// IDs, for a new test
+func copyQuestions(source []entity.Question) []entity.Question {
+	sourceQuestions := append([]entity.Question(nil), source...)
+	sort.SliceStable(sourceQuestions, func(i, j int) bool {
+		return sourceQuestions[i].OrderNum < sourceQuestions[j].OrderNum
+	})
+	questions := make([]entity.Question, 0, len(sourceQuestions))
+	for _, q := range sourceQuestions {
+		question := entity.Question{
+			QuestionText:  q.QuestionText,
+			QuestionType:  q.QuestionType,
+			Difficulty:    q.Difficulty,
+			Points:        q.Points,
+			CaseSensitive: q.CaseSensitive,
+			CreatedAt:     time.Now(),
+			UpdatedAt:     time.Now(),
+		}
+		sourceAnswers := append([]entity.Answer(nil), q.Answers...)
+		sort.SliceStable(sourceAnswers, func(i, j int) bool {
+			return sourceAnswers[i].OrderNum < sourceAnswers[j].OrderNum
+		})
+		for _, a := range sourceAnswers {
+			question.Answers = append(question.Answers, entity.Answer{
+				AnswerText: a.AnswerText,
+				IsCorrect:  a.IsCorrect,
+				MatchText:  a.MatchText,
+				Tolerance:  a.Tolerance,
+				Weight:     a.Weight,
+				CreatedAt:  time.Now(),
+			})
+		}
+		questions = append(questions, question)
+	}
+	return questions
+}
 
-		// Build answers DTO
-		answersDTO := make([]dto.AnswerDTO, len(answers))
-		for j, a := range answers {
+// Import godoc
+// @Summary Import a test
+// @Description Create a test from the JSON export format; every question is validated before anything is saved
+// @Tags tests
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.ImportTestRequest true "Test in the JSON export format"
+// @Success 201 {object} dto.TestResponse
+// @Failure 400 {object} dto.ErrorResponse "Invalid test"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /tests/import [post]
+func (h *TestHandler) Import(c *fiber.Ctx) error {
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(
+			dto.NewErrorResponse(dto.ErrCodeUnauthorized, "Unauthorized"),
+		)
+	}
+
+	var req dto.ImportTestRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInvalidInput, "invalid request body"),
+		)
+	}
+
+	title := security.SanitizeInput(req.Title)
+	if len(title) < 3 {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInvalidInput, "title must be at least 3 characters"),
+		)
+	}
+	if len(req.Questions) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			dto.NewErrorResponse(dto.ErrCodeTestHasNoQuestions, "test has no questions"),
+		)
+	}
+
+	test := &entity.Test{
+		ID:          uuid.New(),
+		UserID:      userID,
+		Title:       title,
+		Description: security.SanitizeMultiline(req.Description),
+		Status:      entity.TestStatusDraft,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+
+	for i, q := range req.Questions {
+		question := entity.Question{
+			QuestionText:  security.SanitizeMultiline(q.QuestionText),
+			QuestionType:  entity.QuestionType(q.QuestionType),
+			Difficulty:    entity.Difficulty(q.Difficulty),
+			Points:        q.Points,
+			CaseSensitive: q.CaseSensitive,
+			CreatedAt:     time.Now(),
+			UpdatedAt:     time.Now(),
+		}
+		if question.Difficulty == "" {
+			question.Difficulty = entity.DifficultyMedium
+		}
+		if question.Points == 0 {
+			question.Points = 1.0
+		}
+		for _, a := range q.Answers {
+			question.Answers = append(question.Answers, entity.Answer{
+				AnswerText: security.SanitizeInput(a.AnswerText),
+				IsCorrect:  a.IsCorrect,
+				MatchText:  security.SanitizeInput(a.MatchText),
+				Tolerance:  a.Tolerance,
+				Weight:     a.Weight,
+				CreatedAt:  time.Now(),
+			})
+		}
+
+		question.NormalizeTrueFalseAnswers()
+		if msg := importQuestionError(&question); msg != "" {
+			return c.Status(fiber.StatusBadRequest).JSON(
+				dto.NewErrorResponse(dto.ErrCodeInvalidInput, fmt.Sprintf("question %d: %s", i+1, msg)),
+			)
+		}
+		test.Questions = append(test.Questions, question)
+	}
+
+	if err := h.testRepo.CreateWithQuestions(c.Context(), test); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(
+			dto.NewErrorResponse(dto.ErrCodeDatabaseError, "failed to import test"),
+		)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dto.TestResponse{
+		ID:             test.ID.String(),
+		UserID:         test.UserID.String(),
+		Title:          test.Title,
+		Description:    test.Description,
+		TotalQuestions: test.TotalQuestions,
+		Status:         string(test.Status),
+		MoodleSynced:   false,
+		Version:        test.Version,
+		CreatedAt:      test.CreatedAt.Format(time.RFC3339),
+	})
+}
+
+// importQuestionError describes why an imported question cannot be saved,
+// or returns an empty string when it is valid
+func importQuestionError(question *entity.Question) string {
+	switch {
+	case len(question.QuestionText) < 3:
+		return "question text must be at least 3 characters"
+	case !question.IsValidType():
+		return "invalid question type"
+	case !question.IsValidDifficulty():
+		return "invalid difficulty"
+	case question.Points < 0:
+		return "points must be positive"
+	}
+	if err := question.ValidateAnswers(); err != nil {
+		return err.Error()
+	}
+	return ""
+}
+
+// questionDTOs converts questions with preloaded answers to response DTOs,
+// showing true/false answers in the given language
+func questionDTOs(questions []*entity.Question, language string) []dto.QuestionDTO {
+	questionsDTO := make([]dto.QuestionDTO, len(questions))
+	for i, q := range questions {
+		answersDTO := make([]dto.AnswerDTO, len(q.Answers))
+		for j, a := range q.Answers {
 			answersDTO[j] = dto.AnswerDTO{
 				ID:         a.ID.String(),
-				AnswerText: a.AnswerText,
+				AnswerText: entity.AnswerLabel(q.QuestionType, a.AnswerText, language),
 				IsCorrect:  a.IsCorrect,
+				MatchText:  a.MatchText,
+				Tolerance:  a.Tolerance,
+				Weight:     a.Weight,
 				OrderNum:   a.OrderNum,
 			}
 		}
 
 		questionsDTO[i] = dto.QuestionDTO{
-			ID:           q.ID.String(),
-			QuestionText: q.QuestionText,
-			QuestionType: string(q.QuestionType),
-			Difficulty:   string(q.Difficulty),
-			Points:       q.Points,
-			OrderNum:     q.OrderNum,
-			Answers:      answersDTO,
+			ID:            q.ID.String(),
+			QuestionText:  q.QuestionText,
+			QuestionType:  string(q.QuestionType),
+			Difficulty:    string(q.Difficulty),
+			Points:        q.Points,
+			CaseSensitive: q.CaseSensitive,
+			OrderNum:      q.OrderNum,
+			Answers:       answersDTO,
 		}
 	}
+	return questionsDTO
+}
+
+// ExportToJSON godoc
+// @Summary Export test to JSON format
+// @Description Export a test and its questions to JSON format for download
+// @Tags tests
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Test ID"
+// @Success 200 {object} dto.TestResponse
+// @Failure 400 {object} dto.ErrorResponse "Invalid test ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 403 {object} dto.ErrorResponse "Access denied"
+// @Failure 404 {object} dto.ErrorResponse "Test not found"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /tests/{id}/export/json [get]
+func (h *TestHandler) ExportToJSON(c *fiber.Ctx) error {
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(
+			dto.NewErrorResponse(dto.ErrCodeUnauthorized, "Unauthorized"),
+		)
+	}
+
+	testID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInvalidTestID, "invalid test ID"),
+		)
+	}
+
+	// Check if test exists and belongs to user
+	test, err := h.testRepo.FindByID(c.Context(), testID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(
+			dto.NewErrorResponse(dto.ErrCodeTestNotFound, "test not found"),
+		)
+	}
+
+	if test.UserID != userID {
+		return c.Status(fiber.StatusForbidden).JSON(
+			dto.NewErrorResponse(dto.ErrCodeForbidden, "access denied"),
+		)
+	}
+
+	// Load questions with answers
+	questions, err := h.questionRepo.FindByTestIDWithAnswers(c.Context(), testID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(
+			dto.NewErrorResponse(dto.ErrCodeDatabaseError, "failed to load questions"),
+		)
+	}
+
+	if len(questions) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			dto.NewErrorResponse(dto.ErrCodeTestHasNoQuestions, "test has no questions"),
+		)
+	}
 
 	testResponse := dto.TestResponse{
 		ID:             test.ID.String(),
@@ -811,8 +1507,9 @@ func (h *TestHandler) ExportToJSON(c *fiber.Ctx) error {
 		TotalQuestions: test.TotalQuestions,
 		Status:         string(test.Status),
 		MoodleSynced:   test.MoodleSynced,
+		Version:        test.Version,
 		CreatedAt:      test.CreatedAt.Format(time.RFC3339),
-		Questions:      questionsDTO,
+		Questions:      questionDTOs(questions, labelLanguage(c)),
 	}
 
 	// Set content disposition header for file download
@@ -867,7 +1564,7 @@ func (h *TestHandler) ExportToXML(c *fiber.Ctx) error {
 	}
 
 	// Get questions for the test
-	questions, err := h.questionRepo.FindByTestID(c.Context(), testID)
+	questions, err := h.questionRepo.FindByTestIDWithAnswers(c.Context(), testID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(
 			dto.NewErrorResponse(dto.ErrCodeDatabaseError, "failed to retrieve questions"),
@@ -880,20 +1577,8 @@ func (h *TestHandler) ExportToXML(c *fiber.Ctx) error {
 		)
 	}
 
-	// Get answers for each question
-	answersMap := make(map[string][]*entity.Answer)
-	for _, q := range questions {
-		answers, err := h.answerRepo.FindByQuestionID(c.Context(), q.ID)
-		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(
-				dto.NewErrorResponse(dto.ErrCodeDatabaseError, "failed to retrieve answers"),
-			)
-		}
-		answersMap[q.ID.String()] = answers
-	}
-
 	// Export to XML
-	xmlContent, err := h.xmlExporter.Export(test, questions, answersMap)
+	xmlContent, err := h.xmlExporter.Export(test, questions, moodle.AnswersByQuestion(questions))
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(
 			dto.NewErrorResponse(dto.ErrCodeExportFailed, "failed to export XML"),
@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/shester1kov/testgen-backend/internal/application/dto"
+	"github.com/shester1kov/testgen-backend/internal/domain/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newCloneImportApp(userID uuid.UUID, testRepo *mockTestUpdateRepository) *fiber.App {
+	handler := NewTestHandler(testRepo, new(mockDocumentUpdateRepository), new(mockQuestionUpdateRepository), new(mockAnswerUpdateRepository), new(mockUserUpdateRepository), nil, nil, nil)
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("userID", userID)
+		return c.Next()
+	})
+	app.Post("/tests/import", handler.Import)
+	app.Post("/tests/:id/clone", handler.Clone)
+	return app
+}
+
+func postJSON(t *testing.T, app *fiber.App, path string, body interface{}) *http.Response {
+	payload, err := json.Marshal(body)
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	return resp
+}
+
+func TestClone_CopiesQuestionsInOrder(t *testing.T) {
+	userID := uuid.New()
+	sourceID := uuid.New()
+	docID := uuid.New()
+	testRepo := new(mockTestUpdateRepository)
+	testRepo.On("FindByID", mock.Anything, sourceID).Return(&entity.Test{
+		ID:         sourceID,
+		UserID:     userID,
+		DocumentID: &docID,
+		Title:      "Графы",
+		Status:     entity.TestStatusPublished,
+		Questions: []entity.Question{
+			{ID: uuid.New(), QuestionText: "Второй", QuestionType: entity.QuestionTypeShortAnswer, OrderNum: 2,
+				Answers: []entity.Answer{{ID: uuid.New(), AnswerText: "BFS", IsCorrect: true, OrderNum: 1}}},
+			{ID: uuid.New(), QuestionText: "Первый", QuestionType: entity.QuestionTypeTrueFalse, OrderNum: 1,
+				Answers: []entity.Answer{
+					{ID: uuid.New(), AnswerText: "False", OrderNum: 2},
+					{ID: uuid.New(), AnswerText: "True", IsCorrect: true, OrderNum: 1},
+				}},
+		},
+	}, nil)
+
+	var saved *entity.Test
+	testRepo.On("CreateWithQuestions", mock.Anything, mock.AnythingOfType("*entity.Test")).Run(func(args mock.Arguments) {
+		saved = args.Get(1).(*entity.Test)
+		saved.TotalQuestions = len(saved.Questions)
+	}).Return(nil)
+
+	resp := postJSON(t, newCloneImportApp(userID, testRepo), "/tests/"+sourceID.String()+"/clone", map[string]string{})
+
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	var response dto.TestResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	assert.Equal(t, "Графы (copy)", response.Title)
+	assert.Equal(t, "draft", response.Status)
+	assert.Equal(t, 2, response.TotalQuestions)
+
+	require.NotNil(t, saved)
+	assert.NotEqual(t, sourceID, saved.ID)
+	assert.Equal(t, &docID, saved.DocumentID)
+	require.Len(t, saved.Questions, 2)
+	assert.Equal(t, "Первый", saved.Questions[0].QuestionText)
+	assert.Equal(t, uuid.Nil, saved.Questions[0].ID, "copies get fresh IDs")
+	assert.Equal(t, "True", saved.Questions[0].Answers[0].AnswerText)
+	assert.Equal(t, "Второй", saved.Questions[1].QuestionText)
+}
+
+func TestClone_ForeignTest(t *testing.T) {
+	sourceID := uuid.New()
+	testRepo := new(mockTestUpdateRepository)
+	testRepo.On("FindByID", mock.Anything, sourceID).Return(&entity.Test{ID: sourceID, UserID: uuid.New()}, nil)
+
+	resp := postJSON(t, newCloneImportApp(uuid.New(), testRepo), "/tests/"+sourceID.String()+"/clone", map[string]string{})
+
+	assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+	testRepo.AssertNotCalled(t, "CreateWithQuestions", mock.Anything, mock.Anything)
+}
+
+func TestImport(t *testing.T) {
+	exported := dto.TestResponse{
+		ID:    uuid.New().String(),
+		Title: "Сортировки",
+		Questions: []dto.QuestionDTO{
+			{
+				ID:           uuid.New().String(),
+				QuestionText: "Сложность быстрой сортировки в среднем?",
+				QuestionType: "single_choice",
+				Difficulty:   "hard",
+				Points:       2,
+				Answers: []dto.AnswerDTO{
+					{AnswerText: "O(n log n)", IsCorrect: true},
+					{AnswerText: "O(n^2)"},
+				},
+			},
+			{
+				QuestionText: "Сортировка слиянием устойчива",
+				QuestionType: "true_false",
+				Answers: []dto.AnswerDTO{
+					{AnswerText: "True", IsCorrect: true},
+					{AnswerText: "False"},
+				},
+			},
+		},
+	}
+
+	t.Run("accepts the JSON export format", func(t *testing.T) {
+		userID := uuid.New()
+		testRepo := new(mockTestUpdateRepository)
+		testRepo.On("CreateWithQuestions", mock.Anything, mock.MatchedBy(func(test *entity.Test) bool {
+			return test.UserID == userID && len(test.Questions) == 2 &&
+				test.Questions[1].Difficulty == entity.DifficultyMedium && test.Questions[1].Points == 1.0
+		})).Return(nil)
+
+		resp := postJSON(t, newCloneImportApp(userID, testRepo), "/tests/import", exported)
+
+		assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+		testRepo.AssertExpectations(t)
+	})
+
+	t.Run("rejects the whole test when one question is invalid", func(t *testing.T) {
+		invalid := exported
+		invalid.Questions = append([]dto.QuestionDTO{}, exported.Questions...)
+		invalid.Questions[1].Answers = []dto.AnswerDTO{{AnswerText: "True", IsCorrect: true}}
+		testRepo := new(mockTestUpdateRepository)
+
+		resp := postJSON(t, newCloneImportApp(uuid.New(), testRepo), "/tests/import", invalid)
+
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+		var response dto.ErrorResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		assert.Contains(t, response.Error.Message, "question 2")
+		testRepo.AssertNotCalled(t, "CreateWithQuestions", mock.Anything, mock.Anything)
+	})
+
+	t.Run("reports save failures", func(t *testing.T) {
+		testRepo := new(mockTestUpdateRepository)
+		testRepo.On("CreateWithQuestions", mock.Anything, mock.Anything).Return(errors.New("connection reset"))
+
+		resp := postJSON(t, newCloneImportApp(uuid.New(), testRepo), "/tests/import", exported)
+
+		assert.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
+	})
+}
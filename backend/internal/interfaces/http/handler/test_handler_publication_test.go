@@ -0,0 +1,387 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/shester1kov/testgen-backend/internal/application/dto"
+	"github.com/shester1kov/testgen-backend/internal/domain/entity"
+	"github.com/shester1kov/testgen-backend/internal/domain/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type publicationFixture struct {
+	app          *fiber.App
+	userID       uuid.UUID
+	test         *entity.Test
+	testRepo     *mockTestUpdateRepository
+	questionRepo *mockQuestionRepository
+	userRepo     *mockUserUpdateRepository
+}
+
+func newPublicationFixture(t *testing.T, status entity.TestStatus) *publicationFixture {
+	f := &publicationFixture{
+		userID:       uuid.New(),
+		testRepo:     new(mockTestUpdateRepository),
+		questionRepo: new(mockQuestionRepository),
+		userRepo:     new(mockUserUpdateRepository),
+	}
+	f.test = &entity.Test{ID: uuid.New(), UserID: f.userID, Title: "Networks", Status: status, Version: 1}
+	f.testRepo.On("FindByID", mock.Anything, f.test.ID).Return(f.test, nil)
+
+	handler := NewTestHandler(f.testRepo, new(mockDocumentUpdateRepository), f.questionRepo, new(mockAnswerUpdateRepository), f.userRepo, newPassThroughUnitOfWork(f.testRepo, f.questionRepo, new(mockAnswerUpdateRepository)), nil, nil)
+	f.app = fiber.New()
+	f.app.Use(func(c *fiber.Ctx) error {
+		c.Locals("userID", f.userID)
+		return c.Next()
+	})
+	f.app.Post("/tests/:id/publish", handler.Publish)
+	f.app.Post("/tests/:id/unpublish", handler.Unpublish)
+	f.app.Post("/tests/:id/archive", handler.Archive)
+	f.app.Post("/tests/:id/versions", handler.CreateVersion)
+	f.app.Get("/tests/:id/assignments", handler.ListAssignments)
+	f.app.Post("/tests/:id/assignments", handler.AssignStudents)
+	f.app.Delete("/tests/:id/assignments/:studentId", handler.UnassignStudent)
+	f.app.Put("/tests/:id", handler.Update)
+	f.app.Post("/tests/:testId/questions", handler.CreateQuestion)
+	f.app.Put("/tests/:testId/questions/order", handler.ReorderQuestions)
+	f.app.Put("/tests/:testId/questions/:questionId", handler.UpdateQuestion)
+	f.app.Delete("/tests/:testId/questions/:questionId", handler.DeleteQuestion)
+	return f
+}
+
+func (f *publicationFixture) do(t *testing.T, method, path string, body interface{}) *http.Response {
+	var reader *bytes.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		require.NoError(t, err)
+		reader = bytes.NewReader(payload)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	req := httptest.NewRequest(method, "/tests/"+f.test.ID.String()+path, reader)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := f.app.Test(req)
+	require.NoError(t, err)
+	return resp
+}
+
+func publishableQuestions() []*entity.Question {
+	return []*entity.Question{{
+		ID:           uuid.New(),
+		QuestionText: "TCP guarantees delivery order",
+		QuestionType: entity.QuestionTypeTrueFalse,
+		Points:       1,
+		OrderNum:     1,
+		Answers: []entity.Answer{
+			{AnswerText: entity.TrueFalseKeyTrue, IsCorrect: true},
+			{AnswerText: entity.TrueFalseKeyFalse},
+		},
+	}}
+}
+
+func TestPublish_Success(t *testing.T) {
+	f := newPublicationFixture(t, entity.TestStatusDraft)
+	f.questionRepo.On("FindByTestIDWithAnswers", mock.Anything, f.test.ID).Return(publishableQuestions(), nil)
+	f.testRepo.On("UpdateStatus", mock.Anything, mock.MatchedBy(func(test *entity.Test) bool {
+		return test.ID == f.test.ID && test.Status == entity.TestStatusPublished
+	})).Return(nil).Once()
+
+	resp := f.do(t, http.MethodPost, "/publish", nil)
+
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	var response dto.TestResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	assert.Equal(t, "published", response.Status)
+	f.testRepo.AssertExpectations(t)
+}
+
+func TestPublish_ReportsFailedChecks(t *testing.T) {
+	f := newPublicationFixture(t, entity.TestStatusDraft)
+	questions := publishableQuestions()
+	questions = append(questions, &entity.Question{
+		ID:           uuid.New(),
+		QuestionText: "  ",
+		QuestionType: entity.QuestionTypeSingleChoice,
+		Points:       0,
+		OrderNum:     2,
+		Answers:      []entity.Answer{{AnswerText: "a", IsCorrect: true}},
+	})
+	f.questionRepo.On("FindByTestIDWithAnswers", mock.Anything, f.test.ID).Return(questions, nil)
+
+	resp := f.do(t, http.MethodPost, "/publish", nil)
+
+	require.Equal(t, fiber.StatusUnprocessableEntity, resp.StatusCode)
+	var response dto.PublishCheckResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	assert.Equal(t, dto.ErrCodeTestNotPublishable, response.Error.Code)
+	require.Len(t, response.Problems, 3, "empty text, no points and too few answers")
+	for _, p := range response.Problems {
+		assert.Equal(t, questions[1].ID.String(), p.QuestionID)
+		assert.Equal(t, 2, p.OrderNum)
+	}
+	f.testRepo.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything)
+	assert.Equal(t, entity.TestStatusDraft, f.test.Status)
+}
+
+func TestPublish_RejectsTestWithoutQuestions(t *testing.T) {
+	f := newPublicationFixture(t, entity.TestStatusDraft)
+	f.questionRepo.On("FindByTestIDWithAnswers", mock.Anything, f.test.ID).Return([]*entity.Question{}, nil)
+
+	resp := f.do(t, http.MethodPost, "/publish", nil)
+
+	require.Equal(t, fiber.StatusUnprocessableEntity, resp.StatusCode)
+	var response dto.PublishCheckResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	require.Len(t, response.Problems, 1)
+	assert.Empty(t, response.Problems[0].QuestionID)
+	assert.Equal(t, "test has no questions", response.Problems[0].Message)
+}
+
+func TestPublish_ArchivesPreviousVersion(t *testing.T) {
+	f := newPublicationFixture(t, entity.TestStatusDraft)
+	previous := &entity.Test{ID: uuid.New(), UserID: f.userID, Status: entity.TestStatusPublished, Version: 1}
+	f.test.Version, f.test.PreviousVersionID = 2, &previous.ID
+	f.testRepo.On("FindByID", mock.Anything, previous.ID).Return(previous, nil)
+	f.questionRepo.On("FindByTestIDWithAnswers", mock.Anything, f.test.ID).Return(publishableQuestions(), nil)
+	f.testRepo.On("UpdateStatus", mock.Anything, f.test).Return(nil).Once()
+	f.testRepo.On("UpdateStatus", mock.Anything, previous).Return(nil).Once()
+
+	resp := f.do(t, http.MethodPost, "/publish", nil)
+
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Equal(t, entity.TestStatusPublished, f.test.Status)
+	assert.Equal(t, entity.TestStatusArchived, previous.Status)
+	f.testRepo.AssertExpectations(t)
+}
+
+func TestPublish_OnlyDrafts(t *testing.T) {
+	for _, status := range []entity.TestStatus{entity.TestStatusPublished, entity.TestStatusArchived} {
+		f := newPublicationFixture(t, status)
+
+		resp := f.do(t, http.MethodPost, "/publish", nil)
+
+		assert.Equal(t, fiber.StatusConflict, resp.StatusCode, status)
+		f.questionRepo.AssertNotCalled(t, "FindByTestIDWithAnswers", mock.Anything, mock.Anything)
+	}
+}
+
+func TestStatusTransitions(t *testing.T) {
+	tests := []struct {
+		name   string
+		from   entity.TestStatus
+		path   string
+		status int
+		to     entity.TestStatus
+	}{
+		{"unpublish published", entity.TestStatusPublished, "/unpublish", fiber.StatusOK, entity.TestStatusDraft},
+		{"unpublish draft", entity.TestStatusDraft, "/unpublish", fiber.StatusConflict, entity.TestStatusDraft},
+		{"archive draft", entity.TestStatusDraft, "/archive", fiber.StatusOK, entity.TestStatusArchived},
+		{"archive published", entity.TestStatusPublished, "/archive", fiber.StatusOK, entity.TestStatusArchived},
+		{"archive archived", entity.TestStatusArchived, "/archive", fiber.StatusConflict, entity.TestStatusArchived},
+		{"unpublish archived", entity.TestStatusArchived, "/unpublish", fiber.StatusConflict, entity.TestStatusArchived},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newPublicationFixture(t, tt.from)
+			f.testRepo.On("UpdateStatus", mock.Anything, f.test).Return(nil).Maybe()
+			f.testRepo.On("FindAssignments", mock.Anything, f.test.ID).Return([]*entity.TestAssignment{}, nil).Maybe()
+
+			resp := f.do(t, http.MethodPost, tt.path, nil)
+
+			assert.Equal(t, tt.status, resp.StatusCode)
+			assert.Equal(t, tt.to, f.test.Status)
+			f.testRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestUnpublish_RefusedWhileAssigned(t *testing.T) {
+	f := newPublicationFixture(t, entity.TestStatusPublished)
+	f.testRepo.On("FindAssignments", mock.Anything, f.test.ID).Return([]*entity.TestAssignment{{TestID: f.test.ID, StudentID: uuid.New()}}, nil)
+
+	resp := f.do(t, http.MethodPost, "/unpublish", nil)
+
+	assert.Equal(t, fiber.StatusConflict, resp.StatusCode)
+	assert.Equal(t, entity.TestStatusPublished, f.test.Status)
+	f.testRepo.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything)
+}
+
+func TestUnpublish_AssignmentsLoadError(t *testing.T) {
+	f := newPublicationFixture(t, entity.TestStatusPublished)
+	f.testRepo.On("FindAssignments", mock.Anything, f.test.ID).Return(nil, assert.AnError)
+
+	resp := f.do(t, http.MethodPost, "/unpublish", nil)
+
+	assert.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
+	assert.Equal(t, entity.TestStatusPublished, f.test.Status)
+	f.testRepo.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything)
+}
+
+func TestPublish_RepublishBumpsVersion(t *testing.T) {
+	f := newPublicationFixture(t, entity.TestStatusDraft)
+	publishedAt := time.Now().Add(-time.Hour)
+	f.test.PublishedAt = &publishedAt
+	f.questionRepo.On("FindByTestIDWithAnswers", mock.Anything, f.test.ID).Return(publishableQuestions(), nil)
+	f.testRepo.On("UpdateStatus", mock.Anything, f.test).Return(nil)
+
+	resp := f.do(t, http.MethodPost, "/publish", nil)
+
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	var response dto.TestResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	assert.Equal(t, 2, response.Version)
+	assert.True(t, f.test.PublishedAt.After(publishedAt))
+}
+
+func TestLockEditableTest(t *testing.T) {
+	testRepo := new(mockTestUpdateRepository)
+	draft := &entity.Test{ID: uuid.New(), Status: entity.TestStatusDraft}
+	published := &entity.Test{ID: uuid.New(), Status: entity.TestStatusPublished}
+	testRepo.On("FindByID", mock.Anything, draft.ID).Return(draft, nil)
+	testRepo.On("FindByID", mock.Anything, published.ID).Return(published, nil)
+	repos := repository.TxRepositories{Tests: testRepo}
+
+	assert.NoError(t, lockEditableTest(context.Background(), repos, draft.ID))
+	assert.ErrorIs(t, lockEditableTest(context.Background(), repos, published.ID), repository.ErrTestLocked)
+}
+
+func TestCreateVersion(t *testing.T) {
+	f := newPublicationFixture(t, entity.TestStatusPublished)
+	f.test.Version = 2
+	f.test.Questions = []entity.Question{{ID: uuid.New(), QuestionText: "Q", QuestionType: entity.QuestionTypeEssay, Points: 1}}
+	studentID := uuid.New()
+	f.testRepo.On("FindAssignments", mock.Anything, f.test.ID).Return([]*entity.TestAssignment{{TestID: f.test.ID, StudentID: studentID, AssignedBy: f.userID}}, nil)
+
+	var created *entity.Test
+	f.testRepo.On("CreateWithQuestions", mock.Anything, mock.MatchedBy(func(test *entity.Test) bool {
+		created = test
+		return test.Status == entity.TestStatusDraft && test.Version == 3 &&
+			test.PreviousVersionID != nil && *test.PreviousVersionID == f.test.ID &&
+			test.Title == f.test.Title && len(test.Questions) == 1 && test.Questions[0].ID == uuid.Nil
+	})).Return(nil)
+	f.testRepo.On("AssignStudents", mock.Anything, mock.MatchedBy(func(assignments []entity.TestAssignment) bool {
+		return len(assignments) == 1 && assignments[0].TestID == created.ID && assignments[0].StudentID == studentID
+	})).Return(nil)
+
+	resp := f.do(t, http.MethodPost, "/versions", nil)
+
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	var response dto.TestResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	assert.Equal(t, 3, response.Version)
+	require.NotNil(t, response.PreviousVersionID)
+	assert.Equal(t, f.test.ID.String(), *response.PreviousVersionID)
+	assert.Equal(t, entity.TestStatusPublished, f.test.Status, "the source stays published until the version is")
+	f.testRepo.AssertExpectations(t)
+}
+
+func TestCreateVersion_DraftIsEditedDirectly(t *testing.T) {
+	f := newPublicationFixture(t, entity.TestStatusDraft)
+
+	resp := f.do(t, http.MethodPost, "/versions", nil)
+
+	assert.Equal(t, fiber.StatusConflict, resp.StatusCode)
+	f.testRepo.AssertNotCalled(t, "CreateWithQuestions", mock.Anything, mock.Anything)
+}
+
+func TestPublishedTestIsLocked(t *testing.T) {
+	questionID := uuid.New().String()
+	requests := []struct {
+		method string
+		path   string
+		body   interface{}
+	}{
+		{http.MethodPut, "", dto.UpdateTestRequest{Title: "Renamed test"}},
+		{http.MethodPost, "/questions", dto.CreateQuestionRequest{QuestionText: "Q?", QuestionType: "essay"}},
+		{http.MethodPut, "/questions/order", dto.ReorderQuestionsRequest{QuestionIDs: []string{questionID}}},
+		{http.MethodPut, "/questions/" + questionID, dto.UpdateQuestionRequest{QuestionText: "Changed"}},
+		{http.MethodDelete, "/questions/" + questionID, nil},
+	}
+
+	for _, status := range []entity.TestStatus{entity.TestStatusPublished, entity.TestStatusArchived} {
+		for _, r := range requests {
+			f := newPublicationFixture(t, status)
+
+			resp := f.do(t, r.method, r.path, r.body)
+
+			require.Equal(t, fiber.StatusConflict, resp.StatusCode, "%s %s on %s test", r.method, r.path, status)
+			var response dto.ErrorResponse
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+			assert.Equal(t, dto.ErrCodeTestLocked, response.Error.Code)
+			f.testRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+		}
+	}
+}
+
+func TestAssignStudents(t *testing.T) {
+	f := newPublicationFixture(t, entity.TestStatusDraft)
+	student := &entity.User{ID: uuid.New(), FullName: "Student", Email: "student@example.com", Role: &entity.Role{Name: entity.RoleNameStudent}}
+	teacher := &entity.User{ID: uuid.New(), Role: &entity.Role{Name: entity.RoleNameTeacher}}
+	f.userRepo.On("FindByID", mock.Anything, student.ID).Return(student, nil)
+	f.userRepo.On("FindByID", mock.Anything, teacher.ID).Return(teacher, nil)
+
+	resp := f.do(t, http.MethodPost, "/assignments", dto.AssignStudentsRequest{StudentIDs: []string{student.ID.String(), teacher.ID.String()}})
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode, "only students are assigned")
+	f.testRepo.AssertNotCalled(t, "AssignStudents", mock.Anything, mock.Anything)
+
+	f.testRepo.On("AssignStudents", mock.Anything, []entity.TestAssignment{{TestID: f.test.ID, StudentID: student.ID, AssignedBy: f.userID}}).Return(nil).Once()
+	f.testRepo.On("FindAssignments", mock.Anything, f.test.ID).Return([]*entity.TestAssignment{{TestID: f.test.ID, StudentID: student.ID, Student: *student}}, nil)
+
+	resp = f.do(t, http.MethodPost, "/assignments", dto.AssignStudentsRequest{StudentIDs: []string{student.ID.String(), student.ID.String()}})
+
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	var response dto.TestAssignmentListResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	require.Len(t, response.Assignments, 1)
+	assert.Equal(t, "Student", response.Assignments[0].StudentName)
+	f.testRepo.AssertExpectations(t)
+}
+
+func TestUnassignStudent(t *testing.T) {
+	f := newPublicationFixture(t, entity.TestStatusPublished)
+	studentID, strangerID := uuid.New(), uuid.New()
+	f.testRepo.On("UnassignStudent", mock.Anything, f.test.ID, studentID).Return(nil)
+	f.testRepo.On("UnassignStudent", mock.Anything, f.test.ID, strangerID).Return(gorm.ErrRecordNotFound)
+
+	assert.Equal(t, fiber.StatusOK, f.do(t, http.MethodDelete, "/assignments/"+studentID.String(), nil).StatusCode)
+	assert.Equal(t, fiber.StatusNotFound, f.do(t, http.MethodDelete, "/assignments/"+strangerID.String(), nil).StatusCode)
+	assert.Equal(t, fiber.StatusBadRequest, f.do(t, http.MethodDelete, "/assignments/not-a-uuid", nil).StatusCode)
+}
+
+func TestListTests_StudentSeesAssignedPublishedTests(t *testing.T) {
+	studentID := uuid.New()
+	testRepo := new(mockTestRepository)
+	userRepo := new(mockTestUserRepository)
+	userRepo.On("FindByID", mock.Anything, studentID).Return(&entity.User{ID: studentID, Role: &entity.Role{Name: entity.RoleNameStudent}}, nil)
+	testRepo.On("FindPublishedByStudentID", mock.Anything, studentID, 20, 0).Return([]*entity.Test{{ID: uuid.New(), Title: "Assigned", Status: entity.TestStatusPublished}}, nil)
+	testRepo.On("CountPublishedByStudentID", mock.Anything, studentID).Return(int64(1), nil)
+
+	handler := NewTestHandler(testRepo, new(mockTestDocRepository), new(mockQuestionRepository), new(mockAnswerRepository), userRepo, nil, nil, nil)
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error { c.Locals("userID", studentID); return c.Next() })
+	app.Get("/tests", handler.List)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/tests", nil))
+	require.NoError(t, err)
+
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	var response dto.TestListResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	require.Len(t, response.Tests, 1)
+	assert.Equal(t, "Assigned", response.Tests[0].Title)
+	testRepo.AssertNotCalled(t, "FindByUserID", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	testRepo.AssertExpectations(t)
+}
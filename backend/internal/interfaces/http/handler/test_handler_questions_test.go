@@ -0,0 +1,535 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/shester1kov/testgen-backend/internal/application/dto"
+	"github.com/shester1kov/testgen-backend/internal/domain/entity"
+	"github.com/shester1kov/testgen-backend/internal/domain/repository"
+	"github.com/shester1kov/testgen-backend/internal/infrastructure/llm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type questionEndpointsFixture struct {
+	userID       uuid.UUID
+	testID       uuid.UUID
+	testRepo     *mockTestUpdateRepository
+	questionRepo *mockQuestionUpdateRepository
+	answerRepo   *mockAnswerUpdateRepository
+	app          *fiber.App
+}
+
+func newQuestionEndpointsFixture(t *testing.T) *questionEndpointsFixture {
+	f := &questionEndpointsFixture{
+		userID:       uuid.New(),
+		testID:       uuid.New(),
+		testRepo:     new(mockTestUpdateRepository),
+		questionRepo: new(mockQuestionUpdateRepository),
+		answerRepo:   new(mockAnswerUpdateRepository),
+	}
+	f.testRepo.On("FindByID", mock.Anything, f.testID).Return(&entity.Test{ID: f.testID, UserID: f.userID}, nil)
+
+	handler := NewTestHandler(f.testRepo, new(mockDocumentUpdateRepository), f.questionRepo, f.answerRepo, new(mockUserUpdateRepository), newPassThroughUnitOfWork(f.testRepo, f.questionRepo, f.answerRepo), nil, nil)
+	f.app = fiber.New()
+	f.app.Use(func(c *fiber.Ctx) error {
+		c.Locals("userID", f.userID)
+		return c.Next()
+	})
+	f.app.Post("/tests/:testId/questions", handler.CreateQuestion)
+	f.app.Put("/tests/:testId/questions/order", handler.ReorderQuestions)
+	f.app.Put("/tests/:testId/questions/:questionId", handler.UpdateQuestion)
+	f.app.Delete("/tests/:testId/questions/:questionId", handler.DeleteQuestion)
+	return f
+}
+
+func (f *questionEndpointsFixture) do(t *testing.T, method, path string, body interface{}) *http.Response {
+	var reader *bytes.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		require.NoError(t, err)
+		reader = bytes.NewReader(payload)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	req := httptest.NewRequest(method, "/tests/"+f.testID.String()+path, reader)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := f.app.Test(req)
+	require.NoError(t, err)
+	return resp
+}
+
+func TestCreateQuestion_Success(t *testing.T) {
+	f := newQuestionEndpointsFixture(t)
+	f.questionRepo.On("CreateInTest", mock.Anything, mock.MatchedBy(func(q *entity.Question) bool {
+		return q.TestID == f.testID && q.OrderNum == 2 && len(q.Answers) == 3 &&
+			q.Answers[2].OrderNum == 3 && q.Difficulty == entity.DifficultyMedium
+	})).Return(nil)
+
+	resp := f.do(t, http.MethodPost, "/questions", dto.CreateQuestionRequest{
+		QuestionText: "Какие структуры данных линейные?",
+		QuestionType: "multiple_choice",
+		OrderNum:     2,
+		Answers: []dto.CreateAnswerRequest{
+			{AnswerText: "Стек", IsCorrect: true},
+			{AnswerText: "Очередь", IsCorrect: true},
+			{AnswerText: "Дерево"},
+		},
+	})
+
+	assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	var response dto.QuestionDTO
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	assert.Equal(t, "multiple_choice", response.QuestionType)
+	assert.Equal(t, 1.0, response.Points)
+	assert.Len(t, response.Answers, 3)
+	f.questionRepo.AssertExpectations(t)
+}
+
+func TestCreateQuestion_NewTypes(t *testing.T) {
+	tolerance := 0.01
+	tests := []struct {
+		name    string
+		req     dto.CreateQuestionRequest
+		answers int
+	}{
+		{"matching", dto.CreateQuestionRequest{
+			QuestionText: "Match languages and their authors",
+			QuestionType: "matching",
+			Answers: []dto.CreateAnswerRequest{
+				{AnswerText: "Go", MatchText: "Google"},
+				{AnswerText: "C#", MatchText: "Microsoft"},
+			},
+		}, 2},
+		{"ordering", dto.CreateQuestionRequest{
+			QuestionText: "Order the OSI layers from the bottom",
+			QuestionType: "ordering",
+			Answers:      []dto.CreateAnswerRequest{{AnswerText: "Physical"}, {AnswerText: "Data link"}, {AnswerText: "Network"}},
+		}, 3},
+		{"numerical", dto.CreateQuestionRequest{
+			QuestionText: "What is pi to two decimals?",
+			QuestionType: "numerical",
+			Answers:      []dto.CreateAnswerRequest{{AnswerText: "3.14", IsCorrect: true, Tolerance: &tolerance}},
+		}, 1},
+		{"cloze", dto.CreateQuestionRequest{
+			QuestionText: "The capital of France is {1:SHORTANSWER:=Paris}",
+			QuestionType: "cloze",
+		}, 0},
+		{"essay", dto.CreateQuestionRequest{
+			QuestionText: "Explain how garbage collection works",
+			QuestionType: "essay",
+		}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newQuestionEndpointsFixture(t)
+			f.questionRepo.On("CreateInTest", mock.Anything, mock.Anything).Return(nil)
+
+			resp := f.do(t, http.MethodPost, "/questions", tt.req)
+
+			assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+			var response dto.QuestionDTO
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+			assert.Equal(t, tt.req.QuestionType, response.QuestionType)
+			require.Len(t, response.Answers, tt.answers)
+			for i, answer := range tt.req.Answers {
+				assert.Equal(t, answer.MatchText, response.Answers[i].MatchText)
+				assert.Equal(t, answer.Tolerance, response.Answers[i].Tolerance)
+			}
+		})
+	}
+}
+
+func TestCreateQuestion_KeepsAnswerWeights(t *testing.T) {
+	f := newQuestionEndpointsFixture(t)
+	f.questionRepo.On("CreateInTest", mock.Anything, mock.Anything).Return(nil)
+	seventy, thirty, penalty := 70.0, 30.0, -50.0
+
+	resp := f.do(t, http.MethodPost, "/questions", dto.CreateQuestionRequest{
+		QuestionText: "Which of these are compiled languages?",
+		QuestionType: "multiple_choice",
+		Answers: []dto.CreateAnswerRequest{
+			{AnswerText: "Go", IsCorrect: true, Weight: &seventy},
+			{AnswerText: "Rust", IsCorrect: true, Weight: &thirty},
+			{AnswerText: "Python", Weight: &penalty},
+		},
+	})
+
+	assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	var response dto.QuestionDTO
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	require.Len(t, response.Answers, 3)
+	assert.Equal(t, &seventy, response.Answers[0].Weight)
+	assert.Equal(t, &penalty, response.Answers[2].Weight)
+}
+
+func TestCreateQuestion_ShortAnswerVariants(t *testing.T) {
+	f := newQuestionEndpointsFixture(t)
+	f.questionRepo.On("CreateInTest", mock.Anything, mock.MatchedBy(func(q *entity.Question) bool {
+		return q.CaseSensitive
+	})).Return(nil)
+	half := 50.0
+
+	resp := f.do(t, http.MethodPost, "/questions", dto.CreateQuestionRequest{
+		QuestionText:  "Which element has the symbol Na?",
+		QuestionType:  "short_answer",
+		CaseSensitive: true,
+		Answers: []dto.CreateAnswerRequest{
+			{AnswerText: "Sodium", IsCorrect: true},
+			{AnswerText: "Натрий", IsCorrect: true},
+			{AnswerText: "Natri*", IsCorrect: true, Weight: &half},
+		},
+	})
+
+	assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	var response dto.QuestionDTO
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	assert.True(t, response.CaseSensitive)
+	require.Len(t, response.Answers, 3)
+	assert.Equal(t, "Natri*", response.Answers[2].AnswerText)
+	assert.Equal(t, &half, response.Answers[2].Weight)
+}
+
+func TestCreateQuestion_TrueFalseLabels(t *testing.T) {
+	f := newQuestionEndpointsFixture(t)
+	f.questionRepo.On("CreateInTest", mock.Anything, mock.MatchedBy(func(q *entity.Question) bool {
+		return q.Answers[0].AnswerText == entity.TrueFalseKeyTrue && q.Answers[1].AnswerText == entity.TrueFalseKeyFalse
+	})).Return(nil)
+
+	for _, tc := range []struct {
+		acceptLanguage string
+		labels         []string
+	}{
+		{"", []string{"Верно", "Неверно"}},
+		{"en-US,en;q=0.9", []string{"True", "False"}},
+		{"de", []string{"Верно", "Неверно"}},
+	} {
+		payload, err := json.Marshal(dto.CreateQuestionRequest{
+			QuestionText: "Go is statically typed",
+			QuestionType: "true_false",
+			Answers:      []dto.CreateAnswerRequest{{AnswerText: " верно ", IsCorrect: true}, {AnswerText: "False"}},
+		})
+		require.NoError(t, err)
+		req := httptest.NewRequest(http.MethodPost, "/tests/"+f.testID.String()+"/questions", bytes.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept-Language", tc.acceptLanguage)
+		resp, err := f.app.Test(req)
+		require.NoError(t, err)
+
+		require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+		var response dto.QuestionDTO
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		require.Len(t, response.Answers, 2)
+		assert.Equal(t, tc.labels, []string{response.Answers[0].AnswerText, response.Answers[1].AnswerText}, tc.acceptLanguage)
+	}
+	f.questionRepo.AssertExpectations(t)
+}
+
+func TestCreateQuestion_RejectsAnswersNotFittingType(t *testing.T) {
+	fifty := 50.0
+	tests := []struct {
+		name string
+		req  dto.CreateQuestionRequest
+	}{
+		{"two correct single choice", dto.CreateQuestionRequest{
+			QuestionText: "Question?",
+			QuestionType: "single_choice",
+			Answers:      []dto.CreateAnswerRequest{{AnswerText: "a", IsCorrect: true}, {AnswerText: "b", IsCorrect: true}},
+		}},
+		{"true false with one option", dto.CreateQuestionRequest{
+			QuestionText: "Question?",
+			QuestionType: "true_false",
+			Answers:      []dto.CreateAnswerRequest{{AnswerText: "True", IsCorrect: true}},
+		}},
+		{"true false with an answer that is neither", dto.CreateQuestionRequest{
+			QuestionText: "Question?",
+			QuestionType: "true_false",
+			Answers:      []dto.CreateAnswerRequest{{AnswerText: "Верно", IsCorrect: true}, {AnswerText: "Иногда"}},
+		}},
+		{"unknown type", dto.CreateQuestionRequest{
+			QuestionText: "Question?",
+			QuestionType: "drag_and_drop",
+			Answers:      []dto.CreateAnswerRequest{{AnswerText: "a", IsCorrect: true}},
+		}},
+		{"cloze without embedded answers", dto.CreateQuestionRequest{
+			QuestionText: "The capital of France is Paris",
+			QuestionType: "cloze",
+		}},
+		{"numerical with text answer", dto.CreateQuestionRequest{
+			QuestionText: "What is pi?",
+			QuestionType: "numerical",
+			Answers:      []dto.CreateAnswerRequest{{AnswerText: "pi", IsCorrect: true}},
+		}},
+		{"short answer variant shadowed by a wildcard", dto.CreateQuestionRequest{
+			QuestionText: "Who discovered gravity?",
+			QuestionType: "short_answer",
+			Answers:      []dto.CreateAnswerRequest{{AnswerText: "Newt*", IsCorrect: true}, {AnswerText: "Newton", IsCorrect: true}},
+		}},
+		{"weights of correct answers not adding up to 100", dto.CreateQuestionRequest{
+			QuestionText: "Question?",
+			QuestionType: "multiple_choice",
+			Answers: []dto.CreateAnswerRequest{
+				{AnswerText: "a", IsCorrect: true, Weight: &fifty},
+				{AnswerText: "b", IsCorrect: true, Weight: &fifty},
+				{AnswerText: "c", IsCorrect: true, Weight: &fifty},
+			},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newQuestionEndpointsFixture(t)
+
+			resp := f.do(t, http.MethodPost, "/questions", tt.req)
+
+			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+			f.questionRepo.AssertNotCalled(t, "CreateInTest", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestCreateQuestion_ForeignTest(t *testing.T) {
+	f := newQuestionEndpointsFixture(t)
+	otherTestID := uuid.New()
+	f.testRepo.On("FindByID", mock.Anything, otherTestID).Return(&entity.Test{ID: otherTestID, UserID: uuid.New()}, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/tests/"+otherTestID.String()+"/questions", bytes.NewReader([]byte(`{}`)))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := f.app.Test(req)
+	require.NoError(t, err)
+
+	assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+}
+
+func TestDeleteQuestion(t *testing.T) {
+	t.Run("deletes the question", func(t *testing.T) {
+		f := newQuestionEndpointsFixture(t)
+		questionID := uuid.New()
+		f.questionRepo.On("DeleteFromTest", mock.Anything, f.testID, questionID).Return(nil)
+
+		resp := f.do(t, http.MethodDelete, "/questions/"+questionID.String(), nil)
+
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		f.questionRepo.AssertExpectations(t)
+	})
+
+	t.Run("question of another test", func(t *testing.T) {
+		f := newQuestionEndpointsFixture(t)
+		questionID := uuid.New()
+		f.questionRepo.On("DeleteFromTest", mock.Anything, f.testID, questionID).Return(gorm.ErrRecordNotFound)
+
+		resp := f.do(t, http.MethodDelete, "/questions/"+questionID.String(), nil)
+
+		assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+	})
+}
+
+func TestQuestionEdits_TestPublishedMeanwhile(t *testing.T) {
+	f := newQuestionEndpointsFixture(t)
+	questionID := uuid.New()
+	f.questionRepo.On("CreateInTest", mock.Anything, mock.Anything).Return(repository.ErrTestLocked)
+	f.questionRepo.On("DeleteFromTest", mock.Anything, f.testID, questionID).Return(repository.ErrTestLocked)
+
+	for _, resp := range []*http.Response{
+		f.do(t, http.MethodPost, "/questions", dto.CreateQuestionRequest{QuestionText: "Explain TCP slow start", QuestionType: "essay"}),
+		f.do(t, http.MethodDelete, "/questions/"+questionID.String(), nil),
+	} {
+		require.Equal(t, fiber.StatusConflict, resp.StatusCode)
+		var response dto.ErrorResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		assert.Equal(t, dto.ErrCodeTestLocked, response.Error.Code)
+	}
+}
+
+func TestReorderQuestions(t *testing.T) {
+	first, second := uuid.New(), uuid.New()
+
+	t.Run("applies the new order", func(t *testing.T) {
+		f := newQuestionEndpointsFixture(t)
+		f.questionRepo.On("ReorderQuestions", mock.Anything, f.testID, []uuid.UUID{second, first}).Return(nil)
+
+		resp := f.do(t, http.MethodPut, "/questions/order", dto.ReorderQuestionsRequest{
+			QuestionIDs: []string{second.String(), first.String()},
+		})
+
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		f.questionRepo.AssertExpectations(t)
+	})
+
+	t.Run("incomplete list", func(t *testing.T) {
+		f := newQuestionEndpointsFixture(t)
+		f.questionRepo.On("ReorderQuestions", mock.Anything, f.testID, []uuid.UUID{first}).Return(repository.ErrQuestionSetMismatch)
+
+		resp := f.do(t, http.MethodPut, "/questions/order", dto.ReorderQuestionsRequest{
+			QuestionIDs: []string{first.String()},
+		})
+
+		assert.Equal(t, fiber.StatusConflict, resp.StatusCode)
+	})
+
+	t.Run("invalid ID", func(t *testing.T) {
+		f := newQuestionEndpointsFixture(t)
+
+		resp := f.do(t, http.MethodPut, "/questions/order", dto.ReorderQuestionsRequest{
+			QuestionIDs: []string{"not-a-uuid"},
+		})
+
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+		f.questionRepo.AssertNotCalled(t, "ReorderQuestions", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestUpdateQuestion_TypeChangeValidatesExistingAnswers(t *testing.T) {
+	f := newQuestionEndpointsFixture(t)
+	questionID := uuid.New()
+	f.questionRepo.On("FindByID", mock.Anything, questionID).Return(&entity.Question{
+		ID:           questionID,
+		TestID:       f.testID,
+		QuestionText: "Question",
+		QuestionType: entity.QuestionTypeMultipleChoice,
+		Difficulty:   entity.DifficultyMedium,
+	}, nil)
+	f.answerRepo.On("FindByQuestionID", mock.Anything, questionID).Return([]*entity.Answer{
+		{AnswerText: "a", IsCorrect: true},
+		{AnswerText: "b", IsCorrect: true},
+		{AnswerText: "c"},
+	}, nil)
+
+	resp := f.do(t, http.MethodPut, "/questions/"+questionID.String(), dto.UpdateQuestionRequest{
+		QuestionType: "single_choice",
+	})
+
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	f.questionRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestUpdateQuestion_CaseSensitivityOnlyForShortAnswer(t *testing.T) {
+	f := newQuestionEndpointsFixture(t)
+	questionID := uuid.New()
+	caseSensitive := true
+	f.questionRepo.On("FindByID", mock.Anything, questionID).Return(&entity.Question{
+		ID:           questionID,
+		TestID:       f.testID,
+		QuestionText: "Question",
+		QuestionType: entity.QuestionTypeSingleChoice,
+		Difficulty:   entity.DifficultyMedium,
+	}, nil)
+	f.answerRepo.On("FindByQuestionID", mock.Anything, questionID).Return([]*entity.Answer{
+		{AnswerText: "a", IsCorrect: true},
+		{AnswerText: "b"},
+	}, nil)
+
+	resp := f.do(t, http.MethodPut, "/questions/"+questionID.String(), dto.UpdateQuestionRequest{
+		CaseSensitive: &caseSensitive,
+	})
+
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	f.questionRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestGeneratedQuestion_ShortAnswerVariants(t *testing.T) {
+	weight := 48.0
+	question := generatedQuestion(llm.GeneratedQuestion{
+		QuestionText:  "Who formulated the law of universal gravitation?",
+		QuestionType:  llm.ShortAnswer,
+		Difficulty:    "easy",
+		CaseSensitive: true,
+		Answers: []llm.GeneratedAnswer{
+			{Text: "Ньютон*", IsCorrect: true},
+			{Text: "Ньютон", IsCorrect: true},
+			{Text: "Newton", IsCorrect: true, Weight: &weight},
+		},
+	})
+
+	assert.True(t, question.CaseSensitive)
+	require.Len(t, question.Answers, 2)
+	assert.Equal(t, "Newton", question.Answers[1].AnswerText)
+	require.NotNil(t, question.Answers[1].Weight)
+	assert.Equal(t, 50.0, *question.Answers[1].Weight)
+	assert.NoError(t, question.ValidateAnswers())
+
+	choice := generatedQuestion(llm.GeneratedQuestion{
+		QuestionText:  "Pick one",
+		QuestionType:  llm.SingleChoice,
+		CaseSensitive: true,
+		Answers:       []llm.GeneratedAnswer{{Text: "a", IsCorrect: true, Weight: &weight}, {Text: "b"}},
+	})
+	assert.False(t, choice.CaseSensitive)
+	assert.Nil(t, choice.Answers[0].Weight)
+}
+
+func TestGeneratedQuestion_TrueFalseKeys(t *testing.T) {
+	question := generatedQuestion(llm.GeneratedQuestion{
+		QuestionText: "Go has generics",
+		QuestionType: llm.TrueFalse,
+		Answers:      []llm.GeneratedAnswer{{Text: "Верно.", IsCorrect: true}, {Text: "Неверно."}},
+	})
+
+	require.Len(t, question.Answers, 2)
+	assert.Equal(t, entity.TrueFalseKeyTrue, question.Answers[0].AnswerText)
+	assert.Equal(t, entity.TrueFalseKeyFalse, question.Answers[1].AnswerText)
+	assert.NoError(t, question.ValidateAnswers())
+}
+
+func TestUpdateQuestion_SwitchToClozeDropsAnswers(t *testing.T) {
+	f := newQuestionEndpointsFixture(t)
+	questionID := uuid.New()
+	oldAnswers := []*entity.Answer{
+		{ID: uuid.New(), AnswerText: "Paris", IsCorrect: true},
+		{ID: uuid.New(), AnswerText: "Berlin"},
+	}
+	f.questionRepo.On("FindByID", mock.Anything, questionID).Return(&entity.Question{
+		ID:           questionID,
+		TestID:       f.testID,
+		QuestionText: "What is the capital of France?",
+		QuestionType: entity.QuestionTypeSingleChoice,
+		Difficulty:   entity.DifficultyMedium,
+	}, nil)
+	f.questionRepo.On("Update", mock.Anything, mock.MatchedBy(func(q *entity.Question) bool {
+		return q.QuestionType == entity.QuestionTypeCloze
+	})).Return(nil)
+	f.answerRepo.On("FindByQuestionID", mock.Anything, questionID).Return(oldAnswers, nil).Once()
+	f.answerRepo.On("FindByQuestionID", mock.Anything, questionID).Return([]*entity.Answer{}, nil)
+	f.answerRepo.On("Delete", mock.Anything, mock.Anything).Return(nil)
+
+	resp := f.do(t, http.MethodPut, "/questions/"+questionID.String(), dto.UpdateQuestionRequest{
+		QuestionText: "The capital of France is {1:SHORTANSWER:=Paris}",
+		QuestionType: "cloze",
+	})
+
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	var response dto.QuestionDTO
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	assert.Empty(t, response.Answers)
+	f.answerRepo.AssertNumberOfCalls(t, "Delete", 2)
+	f.answerRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestUpdateQuestion_ClozeTextIsValidated(t *testing.T) {
+	f := newQuestionEndpointsFixture(t)
+	questionID := uuid.New()
+	f.questionRepo.On("FindByID", mock.Anything, questionID).Return(&entity.Question{
+		ID:           questionID,
+		TestID:       f.testID,
+		QuestionText: "The capital of France is {1:SHORTANSWER:=Paris}",
+		QuestionType: entity.QuestionTypeCloze,
+		Difficulty:   entity.DifficultyMedium,
+	}, nil)
+
+	resp := f.do(t, http.MethodPut, "/questions/"+questionID.String(), dto.UpdateQuestionRequest{
+		QuestionText: "The capital of France is Paris",
+	})
+
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	f.questionRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -25,6 +26,11 @@ func (m *mockTestRepository) Create(ctx context.Context, test *entity.Test) erro
 	return args.Error(0)
 }
 
+func (m *mockTestRepository) CreateWithQuestions(ctx context.Context, test *entity.Test) error {
+	args := m.Called(ctx, test)
+	return args.Error(0)
+}
+
 func (m *mockTestRepository) FindByID(ctx context.Context, id uuid.UUID) (*entity.Test, error) {
 	args := m.Called(ctx, id)
 	if res := args.Get(0); res != nil {
@@ -33,6 +39,11 @@ func (m *mockTestRepository) FindByID(ctx context.Context, id uuid.UUID) (*entit
 	return nil, args.Error(1)
 }
 
+// FindByIDForUpdate reads like FindByID; row locks only exist in the database
+func (m *mockTestRepository) FindByIDForUpdate(ctx context.Context, id uuid.UUID) (*entity.Test, error) {
+	return m.FindByID(ctx, id)
+}
+
 func (m *mockTestRepository) FindByUserID(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*entity.Test, error) {
 	args := m.Called(ctx, userID, limit, offset)
 	if res := args.Get(0); res != nil {
@@ -43,6 +54,8 @@ func (m *mockTestRepository) FindByUserID(ctx context.Context, userID uuid.UUID,
 
 func (m *mockTestRepository) Update(ctx context.Context, test *entity.Test) error { return nil }
 
+func (m *mockTestRepository) UpdateStatus(ctx context.Context, test *entity.Test) error { return nil }
+
 func (m *mockTestRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
@@ -61,6 +74,31 @@ func (m *mockTestRepository) CountAll(ctx context.Context) (int64, error) {
 	return 0, nil
 }
 
+func (m *mockTestRepository) FindPublishedByStudentID(ctx context.Context, studentID uuid.UUID, limit, offset int) ([]*entity.Test, error) {
+	args := m.Called(ctx, studentID, limit, offset)
+	if res := args.Get(0); res != nil {
+		return res.([]*entity.Test), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockTestRepository) CountPublishedByStudentID(ctx context.Context, studentID uuid.UUID) (int64, error) {
+	args := m.Called(ctx, studentID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *mockTestRepository) AssignStudents(ctx context.Context, assignments []entity.TestAssignment) error {
+	return nil
+}
+
+func (m *mockTestRepository) UnassignStudent(ctx context.Context, testID, studentID uuid.UUID) error {
+	return nil
+}
+
+func (m *mockTestRepository) FindAssignments(ctx context.Context, testID uuid.UUID) ([]*entity.TestAssignment, error) {
+	return nil, nil
+}
+
 type mockTestDocRepository struct{ mock.Mock }
 
 func (m *mockTestDocRepository) Create(ctx context.Context, doc *entity.Document) error { return nil }
@@ -99,6 +137,46 @@ func (m *mockTestDocRepository) CountAll(ctx context.Context) (int64, error) {
 	return 0, nil
 }
 
+func (m *mockTestDocRepository) FindByStatus(ctx context.Context, status entity.DocumentStatus, limit int) ([]*entity.Document, error) {
+	return nil, nil
+}
+
+func (m *mockTestDocRepository) ClaimForParsing(ctx context.Context, id uuid.UUID) (bool, error) {
+	return false, nil
+}
+
+func (m *mockTestDocRepository) UpdateParseState(ctx context.Context, document *entity.Document) error {
+	return nil
+}
+
+func (m *mockTestDocRepository) ResetStaleParsing(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (m *mockTestDocRepository) FindByContentHash(ctx context.Context, contentHash string) ([]*entity.Document, error) {
+	return nil, nil
+}
+
+func (m *mockTestDocRepository) CountByFilePath(ctx context.Context, filePath string) (int64, error) {
+	return 0, nil
+}
+
+func (m *mockTestDocRepository) FindDeletedBefore(ctx context.Context, before time.Time, limit, offset int) ([]*entity.Document, error) {
+	return nil, nil
+}
+
+func (m *mockTestDocRepository) CountRetainedByFilePath(ctx context.Context, filePath string, deletedBefore time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (m *mockTestDocRepository) MarkFilePurged(ctx context.Context, id uuid.UUID) error {
+	return nil
+}
+
+func (m *mockTestDocRepository) FindFilePaths(ctx context.Context) ([]string, error) {
+	return nil, nil
+}
+
 type mockQuestionRepository struct{ mock.Mock }
 
 func (m *mockQuestionRepository) Create(ctx context.Context, question *entity.Question) error {
@@ -115,11 +193,25 @@ func (m *mockQuestionRepository) FindByTestID(ctx context.Context, testID uuid.U
 	}
 	return nil, args.Error(1)
 }
+
+func (m *mockQuestionRepository) FindByTestIDWithAnswers(ctx context.Context, testID uuid.UUID) ([]*entity.Question, error) {
+	args := m.Called(ctx, testID)
+	if res := args.Get(0); res != nil {
+		return res.([]*entity.Question), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
 func (m *mockQuestionRepository) Update(ctx context.Context, question *entity.Question) error { return nil }
 func (m *mockQuestionRepository) Delete(ctx context.Context, id uuid.UUID) error              { return nil }
 func (m *mockQuestionRepository) CountByTestID(ctx context.Context, testID uuid.UUID) (int, error) {
 	return 0, nil
 }
+func (m *mockQuestionRepository) CreateInTest(ctx context.Context, question *entity.Question) error {
+	return nil
+}
+func (m *mockQuestionRepository) DeleteFromTest(ctx context.Context, testID, questionID uuid.UUID) error {
+	return nil
+}
 func (m *mockQuestionRepository) ReorderQuestions(ctx context.Context, testID uuid.UUID, questionIDs []uuid.UUID) error {
 	return nil
 }
@@ -184,7 +276,7 @@ func TestCreateTest_Success(t *testing.T) {
 		test.ID = uuid.New()
 	}).Return(nil)
 
-	handler := NewTestHandler(testRepo, docRepo, new(mockQuestionRepository), new(mockAnswerRepository), new(mockTestUserRepository), nil, nil)
+	handler := NewTestHandler(testRepo, docRepo, new(mockQuestionRepository), new(mockAnswerRepository), new(mockTestUserRepository), nil, nil, nil)
 	app := fiber.New()
 	app.Use(func(c *fiber.Ctx) error { c.Locals("userID", userID); return c.Next() })
 	app.Post("/tests", handler.Create)
@@ -200,7 +292,7 @@ func TestCreateTest_Success(t *testing.T) {
 }
 
 func TestCreateTest_InvalidBody(t *testing.T) {
-	handler := NewTestHandler(new(mockTestRepository), new(mockTestDocRepository), new(mockQuestionRepository), new(mockAnswerRepository), new(mockTestUserRepository), nil, nil)
+	handler := NewTestHandler(new(mockTestRepository), new(mockTestDocRepository), new(mockQuestionRepository), new(mockAnswerRepository), new(mockTestUserRepository), nil, nil, nil)
 	app := fiber.New()
 	app.Use(func(c *fiber.Ctx) error { c.Locals("userID", uuid.New()); return c.Next() })
 	app.Post("/tests", handler.Create)
@@ -244,19 +336,18 @@ func TestGenerate_Success(t *testing.T) {
 	}
 	userRepo.On("FindByID", mock.Anything, userID).Return(user, nil)
 
-	// Mock test creation
-	testRepo.On("Create", mock.Anything, mock.AnythingOfType("*entity.Test")).Return(nil)
-
-	// Mock question creation
-	questionRepo.On("Create", mock.Anything, mock.AnythingOfType("*entity.Question")).Return(nil)
-
-	// Mock answer creation
-	answerRepo.On("Create", mock.Anything, mock.AnythingOfType("*entity.Answer")).Return(nil)
+	// The test is saved with its questions and answers in one call
+	testRepo.On("CreateWithQuestions", mock.Anything, mock.MatchedBy(func(test *entity.Test) bool {
+		return len(test.Questions) == 2 && len(test.Questions[0].Answers) > 0
+	})).Run(func(args mock.Arguments) {
+		test := args.Get(1).(*entity.Test)
+		test.TotalQuestions = len(test.Questions)
+	}).Return(nil)
 
 	// Use perplexity provider which returns mock data in tests
 	mockFactory := llm.NewLLMFactory("test-key", "", "", "", "")
 
-	handler := NewTestHandler(testRepo, docRepo, questionRepo, answerRepo, userRepo, mockFactory, nil)
+	handler := NewTestHandler(testRepo, docRepo, questionRepo, answerRepo, userRepo, nil, mockFactory, nil)
 	app := fiber.New()
 	app.Use(func(c *fiber.Ctx) error { c.Locals("userID", userID); return c.Next() })
 	app.Post("/tests/generate", handler.Generate)
@@ -281,6 +372,55 @@ func TestGenerate_Success(t *testing.T) {
 	assert.NotEmpty(t, response.ID)
 	assert.Equal(t, "Generated Test", response.Title)
 	assert.Equal(t, "draft", response.Status)
+	assert.Equal(t, 2, response.TotalQuestions)
+	assert.Empty(t, response.Warnings)
+	testRepo.AssertExpectations(t)
+}
+
+func TestGenerate_WarnsWhenDocumentExceedsTokenLimit(t *testing.T) {
+	userID := uuid.New()
+	docID := uuid.New()
+
+	testRepo := new(mockTestRepository)
+	docRepo := new(mockTestDocRepository)
+	questionRepo := new(mockQuestionRepository)
+	answerRepo := new(mockAnswerRepository)
+
+	document := &entity.Document{ID: docID, UserID: userID, Title: "Long Document"}
+	document.MarkAsParsed("Short text")
+	document.TokenEstimates = map[string]int{"perplexity": llm.InputTokenLimit("perplexity") + 1}
+	docRepo.On("FindByID", mock.Anything, docID).Return(document, nil)
+
+	userRepo := new(mockTestUserRepository)
+	userRepo.On("FindByID", mock.Anything, userID).Return(&entity.User{
+		ID:   userID,
+		Role: &entity.Role{ID: uuid.New(), Name: entity.RoleNameTeacher},
+	}, nil)
+	testRepo.On("CreateWithQuestions", mock.Anything, mock.AnythingOfType("*entity.Test")).Return(nil)
+
+	handler := NewTestHandler(testRepo, docRepo, questionRepo, answerRepo, userRepo, nil, llm.NewLLMFactory("test-key", "", "", "", ""), nil)
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error { c.Locals("userID", userID); return c.Next() })
+	app.Post("/tests/generate", handler.Generate)
+
+	body, _ := json.Marshal(dto.GenerateTestRequest{
+		DocumentID:   docID.String(),
+		Title:        "Generated Test",
+		NumQuestions: 1,
+		Difficulty:   "easy",
+		LLMProvider:  "perplexity",
+	})
+	req := httptest.NewRequest(http.MethodPost, "/tests/generate", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+
+	var response dto.TestResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	require.Len(t, response.Warnings, 1)
+	assert.Contains(t, response.Warnings[0], "perplexity")
 }
 
 func TestGenerate_DocumentNotFound(t *testing.T) {
@@ -288,7 +428,7 @@ func TestGenerate_DocumentNotFound(t *testing.T) {
 	docRepo := new(mockTestDocRepository)
 	docRepo.On("FindByID", mock.Anything, mock.AnythingOfType("uuid.UUID")).Return(nil, assert.AnError)
 
-	handler := NewTestHandler(new(mockTestRepository), docRepo, new(mockQuestionRepository), new(mockAnswerRepository), new(mockTestUserRepository), nil, nil)
+	handler := NewTestHandler(new(mockTestRepository), docRepo, new(mockQuestionRepository), new(mockAnswerRepository), new(mockTestUserRepository), nil, nil, nil)
 	app := fiber.New()
 	app.Use(func(c *fiber.Ctx) error { c.Locals("userID", userID); return c.Next() })
 	app.Post("/tests/generate", handler.Generate)
@@ -330,7 +470,7 @@ func TestGenerate_DocumentNotParsed(t *testing.T) {
 	}
 	userRepo.On("FindByID", mock.Anything, userID).Return(user, nil)
 
-	handler := NewTestHandler(new(mockTestRepository), docRepo, new(mockQuestionRepository), new(mockAnswerRepository), userRepo, nil, nil)
+	handler := NewTestHandler(new(mockTestRepository), docRepo, new(mockQuestionRepository), new(mockAnswerRepository), userRepo, nil, nil, nil)
 	app := fiber.New()
 	app.Use(func(c *fiber.Ctx) error { c.Locals("userID", userID); return c.Next() })
 	app.Post("/tests/generate", handler.Generate)
@@ -376,7 +516,7 @@ func TestGenerate_InvalidProvider(t *testing.T) {
 	// Factory will return error for invalid provider (empty factory)
 	mockFactory := llm.NewLLMFactory("", "", "", "", "")
 
-	handler := NewTestHandler(new(mockTestRepository), docRepo, new(mockQuestionRepository), new(mockAnswerRepository), userRepo, mockFactory, nil)
+	handler := NewTestHandler(new(mockTestRepository), docRepo, new(mockQuestionRepository), new(mockAnswerRepository), userRepo, nil, mockFactory, nil)
 	app := fiber.New()
 	app.Use(func(c *fiber.Ctx) error { c.Locals("userID", userID); return c.Next() })
 	app.Post("/tests/generate", handler.Generate)
@@ -396,6 +536,31 @@ func TestGenerate_InvalidProvider(t *testing.T) {
 	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
 }
 
+func TestGenerate_InvalidQuestionType(t *testing.T) {
+	userID := uuid.New()
+	docRepo := new(mockTestDocRepository)
+
+	handler := NewTestHandler(new(mockTestRepository), docRepo, new(mockQuestionRepository), new(mockAnswerRepository), new(mockTestUserRepository), nil, llm.NewLLMFactory("test-key", "", "", "", ""), nil)
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error { c.Locals("userID", userID); return c.Next() })
+	app.Post("/tests/generate", handler.Generate)
+
+	body, _ := json.Marshal(dto.GenerateTestRequest{
+		DocumentID:    uuid.New().String(),
+		Title:         "Test",
+		NumQuestions:  1,
+		QuestionTypes: []string{"matching", "drag_and_drop"},
+		Difficulty:    "easy",
+	})
+	req := httptest.NewRequest(http.MethodPost, "/tests/generate", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	docRepo.AssertNotCalled(t, "FindByID", mock.Anything, mock.Anything)
+}
+
 func TestListTests_Success(t *testing.T) {
 	userID := uuid.New()
 	testRepo := new(mockTestRepository)
@@ -410,7 +575,7 @@ func TestListTests_Success(t *testing.T) {
 	testRepo.On("FindByUserID", mock.Anything, userID, 20, 0).Return([]*entity.Test{{ID: uuid.New(), Title: "T1", UserID: userID}}, nil)
 	testRepo.On("CountByUserID", mock.Anything, userID).Return(int64(1), nil)
 
-	handler := NewTestHandler(testRepo, docRepo, new(mockQuestionRepository), new(mockAnswerRepository), userRepo, nil, nil)
+	handler := NewTestHandler(testRepo, docRepo, new(mockQuestionRepository), new(mockAnswerRepository), userRepo, nil, nil, nil)
 	app := fiber.New()
 	app.Use(func(c *fiber.Ctx) error { c.Locals("userID", userID); return c.Next() })
 	app.Get("/tests", handler.List)
@@ -426,7 +591,7 @@ func TestGetByID_NotFound(t *testing.T) {
 	testRepo := new(mockTestRepository)
 	testRepo.On("FindByID", mock.Anything, mock.AnythingOfType("uuid.UUID")).Return(nil, assert.AnError)
 
-	handler := NewTestHandler(testRepo, new(mockTestDocRepository), new(mockQuestionRepository), new(mockAnswerRepository), new(mockTestUserRepository), nil, nil)
+	handler := NewTestHandler(testRepo, new(mockTestDocRepository), new(mockQuestionRepository), new(mockAnswerRepository), new(mockTestUserRepository), nil, nil, nil)
 	app := fiber.New()
 	app.Use(func(c *fiber.Ctx) error { c.Locals("userID", userID); return c.Next() })
 	app.Get("/tests/:id", handler.GetByID)
@@ -445,7 +610,7 @@ func TestDeleteTest_Success(t *testing.T) {
 	testRepo.On("FindByID", mock.Anything, testID).Return(&entity.Test{ID: testID, UserID: userID}, nil)
 	testRepo.On("Delete", mock.Anything, testID).Return(nil)
 
-	handler := NewTestHandler(testRepo, new(mockTestDocRepository), new(mockQuestionRepository), new(mockAnswerRepository), new(mockTestUserRepository), nil, nil)
+	handler := NewTestHandler(testRepo, new(mockTestDocRepository), new(mockQuestionRepository), new(mockAnswerRepository), new(mockTestUserRepository), nil, nil, nil)
 	app := fiber.New()
 	app.Use(func(c *fiber.Ctx) error { c.Locals("userID", userID); return c.Next() })
 	app.Delete("/tests/:id", handler.Delete)
@@ -481,10 +646,8 @@ func TestGetByID_Success_WithQuestionsAndAnswers(t *testing.T) {
 		Status:         entity.TestStatusDraft,
 		MoodleSynced:   false,
 	}
-	testRepo.On("FindByID", mock.Anything, testID).Return(test, nil)
-
-	// Mock questions
-	questions := []*entity.Question{
+	// Questions and answers come preloaded with the test
+	test.Questions = []entity.Question{
 		{
 			ID:           questionID1,
 			TestID:       testID,
@@ -504,25 +667,19 @@ func TestGetByID_Success_WithQuestionsAndAnswers(t *testing.T) {
 			OrderNum:     2,
 		},
 	}
-	questionRepo.On("FindByTestID", mock.Anything, testID).Return(questions, nil)
-
-	// Mock answers for question 1
-	answers1 := []*entity.Answer{
+	test.Questions[0].Answers = []entity.Answer{
 		{ID: uuid.New(), QuestionID: questionID1, AnswerText: "A programming language", IsCorrect: true, OrderNum: 1},
 		{ID: uuid.New(), QuestionID: questionID1, AnswerText: "A database", IsCorrect: false, OrderNum: 2},
 		{ID: uuid.New(), QuestionID: questionID1, AnswerText: "A framework", IsCorrect: false, OrderNum: 3},
 		{ID: uuid.New(), QuestionID: questionID1, AnswerText: "An IDE", IsCorrect: false, OrderNum: 4},
 	}
-	answerRepo.On("FindByQuestionID", mock.Anything, questionID1).Return(answers1, nil)
-
-	// Mock answers for question 2
-	answers2 := []*entity.Answer{
+	test.Questions[1].Answers = []entity.Answer{
 		{ID: uuid.New(), QuestionID: questionID2, AnswerText: "True", IsCorrect: true, OrderNum: 1},
 		{ID: uuid.New(), QuestionID: questionID2, AnswerText: "False", IsCorrect: false, OrderNum: 2},
 	}
-	answerRepo.On("FindByQuestionID", mock.Anything, questionID2).Return(answers2, nil)
+	testRepo.On("FindByID", mock.Anything, testID).Return(test, nil)
 
-	handler := NewTestHandler(testRepo, new(mockTestDocRepository), questionRepo, answerRepo, new(mockTestUserRepository), nil, nil)
+	handler := NewTestHandler(testRepo, new(mockTestDocRepository), questionRepo, answerRepo, new(mockTestUserRepository), nil, nil, nil)
 	app := fiber.New()
 	app.Use(func(c *fiber.Ctx) error { c.Locals("userID", userID); return c.Next() })
 	app.Get("/tests/:id", handler.GetByID)
@@ -570,69 +727,8 @@ func TestGetByID_Success_WithQuestionsAndAnswers(t *testing.T) {
 	require.Len(t, q2.Answers, 2)
 
 	testRepo.AssertExpectations(t)
-	questionRepo.AssertExpectations(t)
-	answerRepo.AssertExpectations(t)
-}
-
-// TestGetByID_QuestionsLoadError tests error handling when loading questions fails
-func TestGetByID_QuestionsLoadError(t *testing.T) {
-	userID := uuid.New()
-	testID := uuid.New()
-
-	testRepo := new(mockTestRepository)
-	questionRepo := new(mockQuestionRepository)
-
-	test := &entity.Test{ID: testID, UserID: userID, Title: "Test"}
-	testRepo.On("FindByID", mock.Anything, testID).Return(test, nil)
-	questionRepo.On("FindByTestID", mock.Anything, testID).Return(nil, assert.AnError)
-
-	handler := NewTestHandler(testRepo, new(mockTestDocRepository), questionRepo, new(mockAnswerRepository), new(mockTestUserRepository), nil, nil)
-	app := fiber.New()
-	app.Use(func(c *fiber.Ctx) error { c.Locals("userID", userID); return c.Next() })
-	app.Get("/tests/:id", handler.GetByID)
-
-	req := httptest.NewRequest(http.MethodGet, "/tests/"+testID.String(), nil)
-	resp, err := app.Test(req)
-	require.NoError(t, err)
-	assert.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
-
-	var response dto.ErrorResponse
-	json.NewDecoder(resp.Body).Decode(&response)
-	assert.Contains(t, response.Error.Message, "failed to load questions")
-}
-
-// TestGetByID_AnswersLoadError tests error handling when loading answers fails
-func TestGetByID_AnswersLoadError(t *testing.T) {
-	userID := uuid.New()
-	testID := uuid.New()
-	questionID := uuid.New()
-
-	testRepo := new(mockTestRepository)
-	questionRepo := new(mockQuestionRepository)
-	answerRepo := new(mockAnswerRepository)
-
-	test := &entity.Test{ID: testID, UserID: userID, Title: "Test"}
-	testRepo.On("FindByID", mock.Anything, testID).Return(test, nil)
-
-	questions := []*entity.Question{
-		{ID: questionID, TestID: testID, QuestionText: "Question 1", OrderNum: 1},
-	}
-	questionRepo.On("FindByTestID", mock.Anything, testID).Return(questions, nil)
-	answerRepo.On("FindByQuestionID", mock.Anything, questionID).Return(nil, assert.AnError)
-
-	handler := NewTestHandler(testRepo, new(mockTestDocRepository), questionRepo, answerRepo, new(mockTestUserRepository), nil, nil)
-	app := fiber.New()
-	app.Use(func(c *fiber.Ctx) error { c.Locals("userID", userID); return c.Next() })
-	app.Get("/tests/:id", handler.GetByID)
-
-	req := httptest.NewRequest(http.MethodGet, "/tests/"+testID.String(), nil)
-	resp, err := app.Test(req)
-	require.NoError(t, err)
-	assert.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
-
-	var response dto.ErrorResponse
-	json.NewDecoder(resp.Body).Decode(&response)
-	assert.Contains(t, response.Error.Message, "failed to load answers")
+	questionRepo.AssertNotCalled(t, "FindByTestIDWithAnswers", mock.Anything, mock.Anything)
+	answerRepo.AssertNotCalled(t, "FindByQuestionID", mock.Anything, mock.Anything)
 }
 
 // TestListTests_ReturnsCompleteData tests the updated List handler
@@ -674,7 +770,7 @@ func TestListTests_ReturnsCompleteData(t *testing.T) {
 	testRepo.On("FindByUserID", mock.Anything, userID, 20, 0).Return(tests, nil)
 	testRepo.On("CountByUserID", mock.Anything, userID).Return(int64(2), nil)
 
-	handler := NewTestHandler(testRepo, new(mockTestDocRepository), new(mockQuestionRepository), new(mockAnswerRepository), userRepo, nil, nil)
+	handler := NewTestHandler(testRepo, new(mockTestDocRepository), new(mockQuestionRepository), new(mockAnswerRepository), userRepo, nil, nil, nil)
 	app := fiber.New()
 	app.Use(func(c *fiber.Ctx) error { c.Locals("userID", userID); return c.Next() })
 	app.Get("/tests", handler.List)
@@ -732,7 +828,7 @@ func TestListTests_WithPagination(t *testing.T) {
 	testRepo.On("FindByUserID", mock.Anything, userID, 10, 10).Return([]*entity.Test{}, nil)
 	testRepo.On("CountByUserID", mock.Anything, userID).Return(int64(25), nil)
 
-	handler := NewTestHandler(testRepo, new(mockTestDocRepository), new(mockQuestionRepository), new(mockAnswerRepository), userRepo, nil, nil)
+	handler := NewTestHandler(testRepo, new(mockTestDocRepository), new(mockQuestionRepository), new(mockAnswerRepository), userRepo, nil, nil, nil)
 	app := fiber.New()
 	app.Use(func(c *fiber.Ctx) error { c.Locals("userID", userID); return c.Next() })
 	app.Get("/tests", handler.List)
@@ -7,11 +7,13 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"github.com/shester1kov/testgen-backend/internal/application/dto"
 	"github.com/shester1kov/testgen-backend/internal/domain/entity"
+	"github.com/shester1kov/testgen-backend/internal/domain/repository"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -25,6 +27,10 @@ type mockTestUpdateRepository struct {
 func (m *mockTestUpdateRepository) Create(ctx context.Context, test *entity.Test) error {
 	return nil
 }
+func (m *mockTestUpdateRepository) CreateWithQuestions(ctx context.Context, test *entity.Test) error {
+	args := m.Called(ctx, test)
+	return args.Error(0)
+}
 func (m *mockTestUpdateRepository) FindByID(ctx context.Context, id uuid.UUID) (*entity.Test, error) {
 	args := m.Called(ctx, id)
 	if res := args.Get(0); res != nil {
@@ -32,6 +38,11 @@ func (m *mockTestUpdateRepository) FindByID(ctx context.Context, id uuid.UUID) (
 	}
 	return nil, args.Error(1)
 }
+
+// FindByIDForUpdate reads like FindByID; row locks only exist in the database
+func (m *mockTestUpdateRepository) FindByIDForUpdate(ctx context.Context, id uuid.UUID) (*entity.Test, error) {
+	return m.FindByID(ctx, id)
+}
 func (m *mockTestUpdateRepository) FindByUserID(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*entity.Test, error) {
 	return nil, nil
 }
@@ -42,6 +53,10 @@ func (m *mockTestUpdateRepository) Update(ctx context.Context, test *entity.Test
 	args := m.Called(ctx, test)
 	return args.Error(0)
 }
+func (m *mockTestUpdateRepository) UpdateStatus(ctx context.Context, test *entity.Test) error {
+	args := m.Called(ctx, test)
+	return args.Error(0)
+}
 func (m *mockTestUpdateRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
@@ -51,6 +66,27 @@ func (m *mockTestUpdateRepository) CountByUserID(ctx context.Context, userID uui
 func (m *mockTestUpdateRepository) CountAll(ctx context.Context) (int64, error) {
 	return 0, nil
 }
+func (m *mockTestUpdateRepository) FindPublishedByStudentID(ctx context.Context, studentID uuid.UUID, limit, offset int) ([]*entity.Test, error) {
+	return nil, nil
+}
+func (m *mockTestUpdateRepository) CountPublishedByStudentID(ctx context.Context, studentID uuid.UUID) (int64, error) {
+	return 0, nil
+}
+func (m *mockTestUpdateRepository) AssignStudents(ctx context.Context, assignments []entity.TestAssignment) error {
+	args := m.Called(ctx, assignments)
+	return args.Error(0)
+}
+func (m *mockTestUpdateRepository) UnassignStudent(ctx context.Context, testID, studentID uuid.UUID) error {
+	args := m.Called(ctx, testID, studentID)
+	return args.Error(0)
+}
+func (m *mockTestUpdateRepository) FindAssignments(ctx context.Context, testID uuid.UUID) ([]*entity.TestAssignment, error) {
+	args := m.Called(ctx, testID)
+	if res := args.Get(0); res != nil {
+		return res.([]*entity.TestAssignment), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
 
 type mockDocumentUpdateRepository struct {
 	mock.Mock
@@ -81,6 +117,46 @@ func (m *mockDocumentUpdateRepository) CountAll(ctx context.Context) (int64, err
 	return 0, nil
 }
 
+func (m *mockDocumentUpdateRepository) FindByStatus(ctx context.Context, status entity.DocumentStatus, limit int) ([]*entity.Document, error) {
+	return nil, nil
+}
+
+func (m *mockDocumentUpdateRepository) ClaimForParsing(ctx context.Context, id uuid.UUID) (bool, error) {
+	return false, nil
+}
+
+func (m *mockDocumentUpdateRepository) UpdateParseState(ctx context.Context, document *entity.Document) error {
+	return nil
+}
+
+func (m *mockDocumentUpdateRepository) ResetStaleParsing(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (m *mockDocumentUpdateRepository) FindByContentHash(ctx context.Context, contentHash string) ([]*entity.Document, error) {
+	return nil, nil
+}
+
+func (m *mockDocumentUpdateRepository) CountByFilePath(ctx context.Context, filePath string) (int64, error) {
+	return 0, nil
+}
+
+func (m *mockDocumentUpdateRepository) FindDeletedBefore(ctx context.Context, before time.Time, limit, offset int) ([]*entity.Document, error) {
+	return nil, nil
+}
+
+func (m *mockDocumentUpdateRepository) CountRetainedByFilePath(ctx context.Context, filePath string, deletedBefore time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (m *mockDocumentUpdateRepository) MarkFilePurged(ctx context.Context, id uuid.UUID) error {
+	return nil
+}
+
+func (m *mockDocumentUpdateRepository) FindFilePaths(ctx context.Context) ([]string, error) {
+	return nil, nil
+}
+
 type mockQuestionUpdateRepository struct {
 	mock.Mock
 }
@@ -98,6 +174,9 @@ func (m *mockQuestionUpdateRepository) FindByID(ctx context.Context, id uuid.UUI
 func (m *mockQuestionUpdateRepository) FindByTestID(ctx context.Context, testID uuid.UUID) ([]*entity.Question, error) {
 	return nil, nil
 }
+func (m *mockQuestionUpdateRepository) FindByTestIDWithAnswers(ctx context.Context, testID uuid.UUID) ([]*entity.Question, error) {
+	return nil, nil
+}
 func (m *mockQuestionUpdateRepository) Update(ctx context.Context, question *entity.Question) error {
 	args := m.Called(ctx, question)
 	return args.Error(0)
@@ -108,8 +187,17 @@ func (m *mockQuestionUpdateRepository) Delete(ctx context.Context, id uuid.UUID)
 func (m *mockQuestionUpdateRepository) CountByTestID(ctx context.Context, testID uuid.UUID) (int, error) {
 	return 0, nil
 }
+func (m *mockQuestionUpdateRepository) CreateInTest(ctx context.Context, question *entity.Question) error {
+	args := m.Called(ctx, question)
+	return args.Error(0)
+}
+func (m *mockQuestionUpdateRepository) DeleteFromTest(ctx context.Context, testID, questionID uuid.UUID) error {
+	args := m.Called(ctx, testID, questionID)
+	return args.Error(0)
+}
 func (m *mockQuestionUpdateRepository) ReorderQuestions(ctx context.Context, testID uuid.UUID, questionIDs []uuid.UUID) error {
-	return nil
+	args := m.Called(ctx, testID, questionIDs)
+	return args.Error(0)
 }
 func (m *mockQuestionUpdateRepository) CountByUserID(ctx context.Context, userID uuid.UUID) (int64, error) {
 	return 0, nil
@@ -148,6 +236,19 @@ func (m *mockAnswerUpdateRepository) DeleteByQuestionID(ctx context.Context, que
 	return nil
 }
 
+// passThroughUnitOfWork runs the function directly against the mocks
+type passThroughUnitOfWork struct {
+	repos repository.TxRepositories
+}
+
+func newPassThroughUnitOfWork(tests repository.TestRepository, questions repository.QuestionRepository, answers repository.AnswerRepository) *passThroughUnitOfWork {
+	return &passThroughUnitOfWork{repos: repository.TxRepositories{Tests: tests, Questions: questions, Answers: answers}}
+}
+
+func (u *passThroughUnitOfWork) Do(ctx context.Context, fn func(repos repository.TxRepositories) error) error {
+	return fn(u.repos)
+}
+
 type mockUserUpdateRepository struct {
 	mock.Mock
 }
@@ -201,7 +302,7 @@ func TestUpdate_Success(t *testing.T) {
 		return t.Title == "New Title" && t.Description == "New Description"
 	})).Return(nil)
 
-	handler := NewTestHandler(testRepo, documentRepo, questionRepo, answerRepo, userRepo, nil, nil)
+	handler := NewTestHandler(testRepo, documentRepo, questionRepo, answerRepo, userRepo, nil, nil, nil)
 	app := fiber.New()
 	app.Use(func(c *fiber.Ctx) error {
 		c.Locals("userID", userID)
@@ -244,7 +345,7 @@ func TestUpdate_NotFound(t *testing.T) {
 
 	testRepo.On("FindByID", mock.Anything, testID).Return(nil, assert.AnError)
 
-	handler := NewTestHandler(testRepo, documentRepo, questionRepo, answerRepo, userRepo, nil, nil)
+	handler := NewTestHandler(testRepo, documentRepo, questionRepo, answerRepo, userRepo, nil, nil, nil)
 	app := fiber.New()
 	app.Use(func(c *fiber.Ctx) error {
 		c.Locals("userID", userID)
@@ -288,7 +389,7 @@ func TestUpdate_Unauthorized(t *testing.T) {
 
 	testRepo.On("FindByID", mock.Anything, testID).Return(existingTest, nil)
 
-	handler := NewTestHandler(testRepo, documentRepo, questionRepo, answerRepo, userRepo, nil, nil)
+	handler := NewTestHandler(testRepo, documentRepo, questionRepo, answerRepo, userRepo, nil, nil, nil)
 	app := fiber.New()
 	app.Use(func(c *fiber.Ctx) error {
 		c.Locals("userID", userID)
@@ -368,7 +469,7 @@ func TestUpdateQuestion_Success(t *testing.T) {
 	// Mock Create for new answers
 	answerRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
 
-	handler := NewTestHandler(testRepo, documentRepo, questionRepo, answerRepo, userRepo, nil, nil)
+	handler := NewTestHandler(testRepo, documentRepo, questionRepo, answerRepo, userRepo, newPassThroughUnitOfWork(testRepo, questionRepo, answerRepo), nil, nil)
 	app := fiber.New()
 	app.Use(func(c *fiber.Ctx) error {
 		c.Locals("userID", userID)
@@ -442,7 +543,7 @@ func TestUpdateQuestion_NotFound(t *testing.T) {
 	testRepo.On("FindByID", mock.Anything, testID).Return(existingTest, nil)
 	questionRepo.On("FindByID", mock.Anything, questionID).Return(nil, assert.AnError)
 
-	handler := NewTestHandler(testRepo, documentRepo, questionRepo, answerRepo, userRepo, nil, nil)
+	handler := NewTestHandler(testRepo, documentRepo, questionRepo, answerRepo, userRepo, newPassThroughUnitOfWork(testRepo, questionRepo, answerRepo), nil, nil)
 	app := fiber.New()
 	app.Use(func(c *fiber.Ctx) error {
 		c.Locals("userID", userID)
@@ -505,7 +606,7 @@ func TestUpdateQuestion_AddNewAnswer(t *testing.T) {
 	questionRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
 	answerRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
 
-	handler := NewTestHandler(testRepo, documentRepo, questionRepo, answerRepo, userRepo, nil, nil)
+	handler := NewTestHandler(testRepo, documentRepo, questionRepo, answerRepo, userRepo, newPassThroughUnitOfWork(testRepo, questionRepo, answerRepo), nil, nil)
 	app := fiber.New()
 	app.Use(func(c *fiber.Ctx) error {
 		c.Locals("userID", userID)
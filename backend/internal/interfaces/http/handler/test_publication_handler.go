@@ -0,0 +1,505 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/shester1kov/testgen-backend/internal/application/dto"
+	"github.com/shester1kov/testgen-backend/internal/domain/entity"
+	"github.com/shester1kov/testgen-backend/internal/domain/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	// errPublishChecksFailed rolls back a publish whose pre-publish checks found problems
+	errPublishChecksFailed = errors.New("pre-publish checks failed")
+	// errTestAssigned refuses to unpublish a test students are assigned to
+	errTestAssigned = errors.New("test is assigned to students")
+)
+
+// Publish godoc
+// @Summary Publish a test
+// @Description Run the pre-publish checks and make a draft visible to the students it is assigned to. The test needs questions, and every question needs text, points above 0 and answers that fit its type. Publishing a new version archives the published version it was created from.
+// @Tags tests
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Test ID"
+// @Success 200 {object} dto.TestResponse
+// @Failure 400 {object} dto.ErrorResponse "Invalid test ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 403 {object} dto.ErrorResponse "Access denied"
+// @Failure 404 {object} dto.ErrorResponse "Test not found"
+// @Failure 409 {object} dto.ErrorResponse "Test is not a draft"
+// @Failure 422 {object} dto.PublishCheckResponse "Pre-publish checks failed"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /tests/{id}/publish [post]
+func (h *TestHandler) Publish(c *fiber.Ctx) error {
+	owned, status, errResp := h.ownedTest(c)
+	if errResp != nil {
+		return c.Status(status).JSON(errResp)
+	}
+
+	var (
+		test     *entity.Test
+		problems []entity.PublishProblem
+	)
+	err := h.uow.Do(c.Context(), func(repos repository.TxRepositories) error {
+		// Question changes lock the same row, so none can land between the checks and the status change
+		locked, err := repos.Tests.FindByIDForUpdate(c.Context(), owned.ID)
+		if err != nil {
+			return err
+		}
+		test = locked
+		if !test.CanTransitionTo(entity.TestStatusPublished) {
+			return entity.ErrInvalidStatusTransition
+		}
+
+		questions, err := repos.Questions.FindByTestIDWithAnswers(c.Context(), test.ID)
+		if err != nil {
+			return err
+		}
+		if problems = entity.PublishProblems(questions); len(problems) > 0 {
+			return errPublishChecksFailed
+		}
+
+		if err := test.Publish(); err != nil {
+			return err
+		}
+		test.UpdatedAt = time.Now()
+		if err := repos.Tests.UpdateStatus(c.Context(), test); err != nil {
+			return err
+		}
+
+		// The version this one replaces stops being shown to students
+		if test.PreviousVersionID == nil {
+			return nil
+		}
+		previous, err := repos.Tests.FindByIDForUpdate(c.Context(), *test.PreviousVersionID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if previous.UserID != test.UserID || !previous.IsPublished() {
+			return nil
+		}
+		if err := previous.Archive(); err != nil {
+			return err
+		}
+		previous.UpdatedAt = time.Now()
+		return repos.Tests.UpdateStatus(c.Context(), previous)
+	})
+	switch {
+	case err == nil:
+		return c.JSON(testSummaryResponse(test))
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(
+			dto.NewErrorResponse(dto.ErrCodeTestNotFound, "test not found"),
+		)
+	case errors.Is(err, entity.ErrInvalidStatusTransition):
+		return c.Status(fiber.StatusConflict).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInvalidTestStatus, fmt.Sprintf("%s test cannot be published", test.Status)),
+		)
+	case errors.Is(err, errPublishChecksFailed):
+		response := dto.PublishCheckResponse{
+			ErrorResponse: dto.NewErrorResponse(dto.ErrCodeTestNotPublishable, "test does not pass the pre-publish checks"),
+			Problems:      make([]dto.PublishProblemDTO, len(problems)),
+		}
+		for i, p := range problems {
+			response.Problems[i] = dto.PublishProblemDTO{OrderNum: p.OrderNum, Message: p.Message}
+			if p.QuestionID != nil {
+				response.Problems[i].QuestionID = p.QuestionID.String()
+			}
+		}
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(response)
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(
+		dto.NewErrorResponse(dto.ErrCodeDatabaseError, "failed to publish test"),
+	)
+}
+
+// Unpublish godoc
+// @Summary Unpublish a test
+// @Description Take a published test back to draft, hiding it from students and unlocking it for edits. Only tests nobody is assigned to can be unpublished; publishing again bumps the version.
+// @Tags tests
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Test ID"
+// @Success 200 {object} dto.TestResponse
+// @Failure 400 {object} dto.ErrorResponse "Invalid test ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 403 {object} dto.ErrorResponse "Access denied"
+// @Failure 404 {object} dto.ErrorResponse "Test not found"
+// @Failure 409 {object} dto.ErrorResponse "Test is not published or is assigned to students"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /tests/{id}/unpublish [post]
+func (h *TestHandler) Unpublish(c *fiber.Ctx) error {
+	return h.transitionTest(c, func(ctx context.Context, repos repository.TxRepositories, test *entity.Test) error {
+		// Assigned students may already have seen the test; changing it in place would rewrite that
+		if test.IsPublished() {
+			assignments, err := repos.Tests.FindAssignments(ctx, test.ID)
+			if err != nil {
+				return err
+			}
+			if len(assignments) > 0 {
+				return errTestAssigned
+			}
+		}
+		return test.Unpublish()
+	})
+}
+
+// Archive godoc
+// @Summary Archive a test
+// @Description Archive a draft or published test; an archived test is hidden from students and only changes through a new version
+// @Tags tests
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Test ID"
+// @Success 200 {object} dto.TestResponse
+// @Failure 400 {object} dto.ErrorResponse "Invalid test ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 403 {object} dto.ErrorResponse "Access denied"
+// @Failure 404 {object} dto.ErrorResponse "Test not found"
+// @Failure 409 {object} dto.ErrorResponse "Test is already archived"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /tests/{id}/archive [post]
+func (h *TestHandler) Archive(c *fiber.Ctx) error {
+	return h.transitionTest(c, func(_ context.Context, _ repository.TxRepositories, test *entity.Test) error {
+		return test.Archive()
+	})
+}
+
+// transitionTest moves an owned test to another status. The transition runs
+// against the row-locked test inside a transaction, so its checks and the
+// status change cannot interleave with publishing or question edits, and only
+// the publication columns are written back.
+func (h *TestHandler) transitionTest(c *fiber.Ctx, transition func(ctx context.Context, repos repository.TxRepositories, test *entity.Test) error) error {
+	owned, code, errResp := h.ownedTest(c)
+	if errResp != nil {
+		return c.Status(code).JSON(errResp)
+	}
+
+	var test *entity.Test
+	err := h.uow.Do(c.Context(), func(repos repository.TxRepositories) error {
+		locked, err := repos.Tests.FindByIDForUpdate(c.Context(), owned.ID)
+		if err != nil {
+			return err
+		}
+		test = locked
+		if err := transition(c.Context(), repos, test); err != nil {
+			return err
+		}
+		test.UpdatedAt = time.Now()
+		return repos.Tests.UpdateStatus(c.Context(), test)
+	})
+	switch {
+	case err == nil:
+		return c.JSON(testSummaryResponse(test))
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(
+			dto.NewErrorResponse(dto.ErrCodeTestNotFound, "test not found"),
+		)
+	case errors.Is(err, entity.ErrInvalidStatusTransition):
+		return c.Status(fiber.StatusConflict).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInvalidTestStatus, err.Error()),
+		)
+	case errors.Is(err, errTestAssigned):
+		return c.Status(fiber.StatusConflict).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInvalidTestStatus, "test is assigned to students, create a new version to change it"),
+		)
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(
+		dto.NewErrorResponse(dto.ErrCodeDatabaseError, "failed to update test status"),
+	)
+}
+
+// CreateVersion godoc
+// @Summary Create a new version of a test
+// @Description Copy a published or archived test into a draft with the next version number, its questions and its student assignments. Publishing the new version archives this one.
+// @Tags tests
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Test ID"
+// @Success 201 {object} dto.TestResponse
+// @Failure 400 {object} dto.ErrorResponse "Invalid test ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 403 {object} dto.ErrorResponse "Access denied"
+// @Failure 404 {object} dto.ErrorResponse "Test not found"
+// @Failure 409 {object} dto.ErrorResponse "Test is a draft"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /tests/{id}/versions [post]
+func (h *TestHandler) CreateVersion(c *fiber.Ctx) error {
+	source, status, errResp := h.ownedTest(c)
+	if errResp != nil {
+		return c.Status(status).JSON(errResp)
+	}
+	if source.IsEditable() {
+		return c.Status(fiber.StatusConflict).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInvalidTestStatus, "draft tests are edited directly"),
+		)
+	}
+
+	assignments, err := h.testRepo.FindAssignments(c.Context(), source.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(
+			dto.NewErrorResponse(dto.ErrCodeDatabaseError, "failed to load assignments"),
+		)
+	}
+
+	test := &entity.Test{
+		ID:                uuid.New(),
+		UserID:            source.UserID,
+		DocumentID:        source.DocumentID,
+		Title:             source.Title,
+		Description:       source.Description,
+		Status:            entity.TestStatusDraft,
+		Version:           source.NextVersion(),
+		PreviousVersionID: &source.ID,
+		Questions:         copyQuestions(source.Questions),
+		CreatedAt:         time.Now(),
+		UpdatedAt:         time.Now(),
+	}
+
+	err = h.uow.Do(c.Context(), func(repos repository.TxRepositories) error {
+		if err := repos.Tests.CreateWithQuestions(c.Context(), test); err != nil {
+			return err
+		}
+		copied := make([]entity.TestAssignment, len(assignments))
+		for i, a := range assignments {
+			copied[i] = entity.TestAssignment{TestID: test.ID, StudentID: a.StudentID, AssignedBy: a.AssignedBy}
+		}
+		return repos.Tests.AssignStudents(c.Context(), copied)
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(
+			dto.NewErrorResponse(dto.ErrCodeDatabaseError, "failed to create test version"),
+		)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(testSummaryResponse(test))
+}
+
+// ListAssignments godoc
+// @Summary List test assignments
+// @Description List the students a test is assigned to
+// @Tags tests
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Test ID"
+// @Success 200 {object} dto.TestAssignmentListResponse
+// @Failure 400 {object} dto.ErrorResponse "Invalid test ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 403 {object} dto.ErrorResponse "Access denied"
+// @Failure 404 {object} dto.ErrorResponse "Test not found"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /tests/{id}/assignments [get]
+func (h *TestHandler) ListAssignments(c *fiber.Ctx) error {
+	test, status, errResp := h.ownedTest(c)
+	if errResp != nil {
+		return c.Status(status).JSON(errResp)
+	}
+	return h.sendAssignments(c, test.ID)
+}
+
+// AssignStudents godoc
+// @Summary Assign a test to students
+// @Description Assign a test to students; they see it in their test list while it is published. Students already assigned are kept.
+// @Tags tests
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Test ID"
+// @Param request body dto.AssignStudentsRequest true "Students to assign"
+// @Success 200 {object} dto.TestAssignmentListResponse
+// @Failure 400 {object} dto.ErrorResponse "Invalid request or user is not a student"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 403 {object} dto.ErrorResponse "Access denied"
+// @Failure 404 {object} dto.ErrorResponse "Test not found"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /tests/{id}/assignments [post]
+func (h *TestHandler) AssignStudents(c *fiber.Ctx) error {
+	test, status, errResp := h.ownedTest(c)
+	if errResp != nil {
+		return c.Status(status).JSON(errResp)
+	}
+
+	var req dto.AssignStudentsRequest
+	if err := c.BodyParser(&req); err != nil || len(req.StudentIDs) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInvalidInput, "student_ids must list at least 1 student"),
+		)
+	}
+
+	seen := make(map[uuid.UUID]bool, len(req.StudentIDs))
+	var assignments []entity.TestAssignment
+	for _, raw := range req.StudentIDs {
+		studentID, err := uuid.Parse(raw)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(
+				dto.NewErrorResponse(dto.ErrCodeInvalidUUID, fmt.Sprintf("invalid student ID %q", raw)),
+			)
+		}
+		if seen[studentID] {
+			continue
+		}
+		seen[studentID] = true
+
+		student, err := h.userRepo.FindByID(c.Context(), studentID)
+		if err != nil || !student.IsStudent() {
+			return c.Status(fiber.StatusBadRequest).JSON(
+				dto.NewErrorResponse(dto.ErrCodeInvalidInput, fmt.Sprintf("user %s is not a student", studentID)),
+			)
+		}
+		assignments = append(assignments, entity.TestAssignment{TestID: test.ID, StudentID: studentID, AssignedBy: test.UserID})
+	}
+
+	if err := h.testRepo.AssignStudents(c.Context(), assignments); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(
+			dto.NewErrorResponse(dto.ErrCodeDatabaseError, "failed to assign test"),
+		)
+	}
+
+	return h.sendAssignments(c, test.ID)
+}
+
+// UnassignStudent godoc
+// @Summary Unassign a test from a student
+// @Description Remove a student's access to a test
+// @Tags tests
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Test ID"
+// @Param studentId path string true "Student ID"
+// @Success 200 {object} dto.MessageResponse
+// @Failure 400 {object} dto.ErrorResponse "Invalid ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 403 {object} dto.ErrorResponse "Access denied"
+// @Failure 404 {object} dto.ErrorResponse "Test or assignment not found"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /tests/{id}/assignments/{studentId} [delete]
+func (h *TestHandler) UnassignStudent(c *fiber.Ctx) error {
+	test, status, errResp := h.ownedTest(c)
+	if errResp != nil {
+		return c.Status(status).JSON(errResp)
+	}
+
+	studentID, err := uuid.Parse(c.Params("studentId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInvalidUUID, "invalid student ID"),
+		)
+	}
+
+	if err := h.testRepo.UnassignStudent(c.Context(), test.ID, studentID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(
+				dto.NewErrorResponse(dto.ErrCodeNotFound, "assignment not found"),
+			)
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(
+			dto.NewErrorResponse(dto.ErrCodeDatabaseError, "failed to unassign test"),
+		)
+	}
+
+	return c.JSON(dto.NewMessageResponse("student unassigned"))
+}
+
+// sendAssignments answers with the students a test is assigned to
+func (h *TestHandler) sendAssignments(c *fiber.Ctx, testID uuid.UUID) error {
+	assignments, err := h.testRepo.FindAssignments(c.Context(), testID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(
+			dto.NewErrorResponse(dto.ErrCodeDatabaseError, "failed to load assignments"),
+		)
+	}
+
+	result := make([]dto.TestAssignmentResponse, len(assignments))
+	for i, a := range assignments {
+		result[i] = dto.TestAssignmentResponse{
+			StudentID:   a.StudentID.String(),
+			StudentName: a.Student.FullName,
+			Email:       a.Student.Email,
+			AssignedAt:  a.CreatedAt.Format(time.RFC3339),
+		}
+	}
+	return c.JSON(dto.TestAssignmentListResponse{Assignments: result})
+}
+
+// ownedTest loads the test named by the id parameter if it belongs to the
+// current user
+func (h *TestHandler) ownedTest(c *fiber.Ctx) (*entity.Test, int, *dto.ErrorResponse) {
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		errResp := dto.NewErrorResponse(dto.ErrCodeUnauthorized, "Unauthorized")
+		return nil, fiber.StatusUnauthorized, &errResp
+	}
+
+	testID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		errResp := dto.NewErrorResponse(dto.ErrCodeInvalidTestID, "invalid test ID")
+		return nil, fiber.StatusBadRequest, &errResp
+	}
+
+	test, err := h.testRepo.FindByID(c.Context(), testID)
+	if err != nil {
+		errResp := dto.NewErrorResponse(dto.ErrCodeTestNotFound, "test not found")
+		return nil, fiber.StatusNotFound, &errResp
+	}
+	if test.UserID != userID {
+		errResp := dto.NewErrorResponse(dto.ErrCodeForbidden, "access denied")
+		return nil, fiber.StatusForbidden, &errResp
+	}
+	return test, 0, nil
+}
+
+// testLockedError answers an edit of a published or archived test, which only
+// changes through a new version
+func testLockedError(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusConflict).JSON(
+		dto.NewErrorResponse(dto.ErrCodeTestLocked, "only draft tests can be edited, create a new version"),
+	)
+}
+
+// lockEditableTest row-locks a test inside a unit of work, failing with
+// repository.ErrTestLocked unless it is still a draft
+func lockEditableTest(ctx context.Context, repos repository.TxRepositories, testID uuid.UUID) error {
+	test, err := repos.Tests.FindByIDForUpdate(ctx, testID)
+	if err != nil {
+		return err
+	}
+	if !test.IsEditable() {
+		return repository.ErrTestLocked
+	}
+	return nil
+}
+
+// testSummaryResponse describes a test without its questions
+func testSummaryResponse(test *entity.Test) dto.TestResponse {
+	return dto.TestResponse{
+		ID:                test.ID.String(),
+		UserID:            test.UserID.String(),
+		Title:             test.Title,
+		Description:       test.Description,
+		TotalQuestions:    test.TotalQuestions,
+		Status:            string(test.Status),
+		MoodleSynced:      test.MoodleSynced,
+		Version:           test.Version,
+		PreviousVersionID: previousVersionID(test),
+		CreatedAt:         test.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// previousVersionID returns the ID of the test a version was created from
+func previousVersionID(test *entity.Test) *string {
+	if test.PreviousVersionID == nil {
+		return nil
+	}
+	id := test.PreviousVersionID.String()
+	return &id
+}
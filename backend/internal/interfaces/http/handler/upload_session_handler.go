@@ -0,0 +1,467 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/shester1kov/testgen-backend/internal/application/dto"
+	"github.com/shester1kov/testgen-backend/internal/domain/entity"
+	"github.com/shester1kov/testgen-backend/internal/infrastructure/storage"
+)
+
+// Resumable upload defaults; chunks travel as raw request bodies, so they must fit the HTTP body limit
+const (
+	defaultMaxChunkSize     = 4 << 20
+	defaultUploadSessionTTL = 24 * time.Hour
+	minChunkSize            = 64 << 10
+)
+
+// uploadOffsetHeader carries the byte offset of a chunk, as in the tus protocol
+const uploadOffsetHeader = "Upload-Offset"
+
+var checksumPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// CreateUpload godoc
+// @Summary Start a resumable upload
+// @Description Create an upload session for a large file. The file is then sent in chunks of chunk_size bytes with PATCH /documents/uploads/{id} and turned into a document with POST /documents/uploads/{id}/complete
+// @Tags documents
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.CreateUploadSessionRequest true "Upload session"
+// @Success 201 {object} dto.UploadSessionResponse
+// @Failure 400 {object} dto.ErrorResponse "Invalid input, unsupported file type or file too large"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /documents/uploads [post]
+func (h *DocumentHandler) CreateUpload(c *fiber.Ctx) error {
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(
+			dto.NewErrorResponse(dto.ErrCodeUnauthorized, "Unauthorized"),
+		)
+	}
+
+	var req dto.CreateUploadSessionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInvalidInput, "invalid request body"),
+		)
+	}
+	if req.FileName == "" || req.Size <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInvalidInput, "file_name and a positive size are required"),
+		)
+	}
+
+	// Reject oversized and unsupported files before any chunk is sent
+	if _, err := h.validateUploadFile(req.FileName, req.Size); err != nil {
+		return err.send(c)
+	}
+
+	chunkSize := req.ChunkSize
+	if chunkSize == 0 {
+		chunkSize = h.maxChunkSize
+	}
+	if chunkSize > h.maxChunkSize || (chunkSize < minChunkSize && chunkSize < req.Size) {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInvalidInput, fmt.Sprintf("chunk_size must be between %d and %d bytes", minChunkSize, h.maxChunkSize)),
+		)
+	}
+	if chunkSize > req.Size {
+		chunkSize = req.Size
+	}
+
+	checksum := strings.ToLower(req.Checksum)
+	if checksum != "" && !checksumPattern.MatchString(checksum) {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInvalidInput, "checksum must be a hex-encoded SHA-256"),
+		)
+	}
+
+	session := &entity.UploadSession{
+		ID:          uuid.New(),
+		UserID:      userID,
+		FileName:    req.FileName,
+		Title:       req.Title,
+		ContentType: req.ContentType,
+		TotalSize:   req.Size,
+		ChunkSize:   chunkSize,
+		Checksum:    checksum,
+		Status:      entity.UploadSessionActive,
+		ExpiresAt:   time.Now().Add(h.uploadSessionTTL),
+	}
+	if err := h.uploadRepo.Create(c.Context(), session); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(
+			dto.NewErrorResponse(dto.ErrCodeDatabaseError, "failed to create upload session"),
+		)
+	}
+
+	c.Set(uploadOffsetHeader, "0")
+	return c.Status(fiber.StatusCreated).JSON(uploadSessionResponse(session))
+}
+
+// GetUpload godoc
+// @Summary Get resumable upload status
+// @Description Get the offset to resume a resumable upload from
+// @Tags documents
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Upload session ID"
+// @Success 200 {object} dto.UploadSessionResponse
+// @Failure 400 {object} dto.ErrorResponse "Invalid upload session ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 403 {object} dto.ErrorResponse "Access denied"
+// @Failure 404 {object} dto.ErrorResponse "Upload session not found"
+// @Router /documents/uploads/{id} [get]
+func (h *DocumentHandler) GetUpload(c *fiber.Ctx) error {
+	session, uploadErr := h.findUploadSession(c)
+	if uploadErr != nil {
+		return uploadErr.send(c)
+	}
+
+	c.Set(uploadOffsetHeader, strconv.FormatInt(session.ReceivedSize, 10))
+	return c.JSON(uploadSessionResponse(session))
+}
+
+// UploadChunk godoc
+// @Summary Upload a chunk
+// @Description Append the next chunk of a resumable upload. The raw request body is the chunk; the Upload-Offset header must equal the current offset. Every chunk but the last must be exactly chunk_size bytes. Retrying a chunk after a lost response is safe: a stale offset is answered with 409 and the current offset
+// @Tags documents
+// @Accept application/offset+octet-stream
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Upload session ID"
+// @Param Upload-Offset header int true "Byte offset of the chunk"
+// @Success 200 {object} dto.UploadSessionResponse
+// @Failure 400 {object} dto.ErrorResponse "Invalid offset or chunk size"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 403 {object} dto.ErrorResponse "Access denied"
+// @Failure 404 {object} dto.ErrorResponse "Upload session not found"
+// @Failure 409 {object} dto.ErrorResponse "Offset does not match or upload already completed"
+// @Failure 410 {object} dto.ErrorResponse "Upload session expired"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /documents/uploads/{id} [patch]
+func (h *DocumentHandler) UploadChunk(c *fiber.Ctx) error {
+	offset, err := strconv.ParseInt(c.Get(uploadOffsetHeader), 10, 64)
+	if err != nil || offset < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInvalidOffset, "Upload-Offset header must be a non-negative integer"),
+		)
+	}
+
+	session, uploadErr := h.findActiveUploadSession(c)
+	if uploadErr != nil {
+		return uploadErr.send(c)
+	}
+
+	if offset != session.ReceivedSize || session.IsFullyReceived() {
+		return offsetMismatch(c, session.ReceivedSize)
+	}
+
+	chunk := c.Body()
+	if expected := session.ExpectedChunkSize(); int64(len(chunk)) != expected {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInvalidInput, fmt.Sprintf("chunk at offset %d must be %d bytes, got %d", offset, expected, len(chunk))),
+		)
+	}
+
+	// A chunk is stored under its index, so a retried chunk overwrites itself
+	index := int(offset / session.ChunkSize)
+	key := storage.UploadChunkKey(session.ID.String(), index)
+	if err := h.fileStorage.Put(c.Context(), key, bytes.NewReader(chunk), int64(len(chunk)), "application/octet-stream"); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInternalError, "failed to store chunk"),
+		)
+	}
+
+	next := offset + int64(len(chunk))
+	advanced, err := h.uploadRepo.AdvanceOffset(c.Context(), session.ID, offset, next)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(
+			dto.NewErrorResponse(dto.ErrCodeDatabaseError, "failed to update upload session"),
+		)
+	}
+	if !advanced {
+		// A concurrent request stored this chunk first
+		current, err := h.uploadRepo.FindByID(c.Context(), session.ID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(
+				dto.NewErrorResponse(dto.ErrCodeDatabaseError, "failed to fetch upload session"),
+			)
+		}
+		return offsetMismatch(c, current.ReceivedSize)
+	}
+	session.ReceivedSize = next
+
+	c.Set(uploadOffsetHeader, strconv.FormatInt(next, 10))
+	return c.JSON(uploadSessionResponse(session))
+}
+
+// CompleteUpload godoc
+// @Summary Complete a resumable upload
+// @Description Assemble the uploaded chunks, verify the SHA-256 checksum and create the document exactly like POST /documents. A checksum mismatch discards the upload
+// @Tags documents
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Upload session ID"
+// @Param request body dto.CompleteUploadSessionRequest false "Checksum, unless given when the session was created"
+// @Success 200 {object} dto.DocumentUploadResponse "Same file already uploaded by this user; existing document returned"
+// @Success 201 {object} dto.DocumentUploadResponse
+// @Failure 400 {object} dto.ErrorResponse "Missing or mismatching checksum, content not matching extension or unsafe archive"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 403 {object} dto.ErrorResponse "Access denied"
+// @Failure 404 {object} dto.ErrorResponse "Upload session not found"
+// @Failure 409 {object} dto.ErrorResponse "Chunks missing, upload already completed or same file already uploaded (on_duplicate=reject)"
+// @Failure 410 {object} dto.ErrorResponse "Upload session expired"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /documents/uploads/{id}/complete [post]
+func (h *DocumentHandler) CompleteUpload(c *fiber.Ctx) error {
+	var req dto.CompleteUploadSessionRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(
+				dto.NewErrorResponse(dto.ErrCodeInvalidInput, "invalid request body"),
+			)
+		}
+	}
+
+	session, uploadErr := h.findActiveUploadSession(c)
+	if uploadErr != nil {
+		return uploadErr.send(c)
+	}
+
+	if !session.IsFullyReceived() {
+		return c.Status(fiber.StatusConflict).JSON(
+			dto.NewErrorResponse(dto.ErrCodeUploadIncomplete, fmt.Sprintf("received %d of %d bytes", session.ReceivedSize, session.TotalSize)),
+		)
+	}
+
+	checksum := strings.ToLower(req.Checksum)
+	switch {
+	case checksum == "":
+		checksum = session.Checksum
+	case session.Checksum != "" && checksum != session.Checksum:
+		return c.Status(fiber.StatusBadRequest).JSON(
+			dto.NewErrorResponse(dto.ErrCodeChecksumMismatch, "checksum differs from the one given when the upload started"),
+		)
+	}
+	if !checksumPattern.MatchString(checksum) {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInvalidInput, "a hex-encoded SHA-256 checksum of the file is required"),
+		)
+	}
+
+	assembled, actual, err := h.assembleUpload(c.Context(), session)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(
+			dto.NewErrorResponse(dto.ErrCodeInternalError, "failed to assemble uploaded chunks"),
+		)
+	}
+	defer func() {
+		assembled.Close()
+		os.Remove(assembled.Name())
+	}()
+
+	// Corrupted chunks cannot be told apart, so the whole upload has to be repeated
+	if actual != checksum {
+		h.discardUpload(c.Context(), session)
+		return c.Status(fiber.StatusBadRequest).JSON(
+			dto.NewErrorResponse(dto.ErrCodeChecksumMismatch, "checksum of the uploaded file does not match; the upload was discarded, start a new one"),
+		)
+	}
+
+	document, duplicate, uploadErr := h.storeUpload(c.Context(), session.UserID, incomingFile{
+		Source:      assembled,
+		FileName:    session.FileName,
+		Size:        session.TotalSize,
+		ContentType: session.ContentType,
+		Title:       session.Title,
+		OnDuplicate: c.Query("on_duplicate", req.OnDuplicate),
+	})
+	if uploadErr != nil {
+		// The content itself was rejected; keep the session only when retrying can help
+		if uploadErr.status < fiber.StatusInternalServerError && uploadErr.status != fiber.StatusConflict {
+			h.discardUpload(c.Context(), session)
+		}
+		return uploadErr.send(c)
+	}
+
+	session.MarkAsCompleted(document.ID)
+	if err := h.uploadRepo.Update(c.Context(), session); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(
+			dto.NewErrorResponse(dto.ErrCodeDatabaseError, "failed to update upload session"),
+		)
+	}
+	h.deleteUploadChunks(c.Context(), session)
+
+	response := documentResponse(document)
+	if duplicate {
+		response.Duplicate = true
+		return c.JSON(response)
+	}
+	return c.Status(fiber.StatusCreated).JSON(response)
+}
+
+// CancelUpload godoc
+// @Summary Cancel a resumable upload
+// @Description Delete an upload session and its staged chunks
+// @Tags documents
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Upload session ID"
+// @Success 200 {object} dto.MessageResponse
+// @Failure 400 {object} dto.ErrorResponse "Invalid upload session ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 403 {object} dto.ErrorResponse "Access denied"
+// @Failure 404 {object} dto.ErrorResponse "Upload session not found"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /documents/uploads/{id} [delete]
+func (h *DocumentHandler) CancelUpload(c *fiber.Ctx) error {
+	session, uploadErr := h.findUploadSession(c)
+	if uploadErr != nil {
+		return uploadErr.send(c)
+	}
+
+	if err := h.discardUpload(c.Context(), session); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(
+			dto.NewErrorResponse(dto.ErrCodeDatabaseError, "failed to delete upload session"),
+		)
+	}
+
+	return c.JSON(dto.NewMessageResponse("upload cancelled"))
+}
+
+// findUploadSession loads the upload session from the :id parameter, owner only
+func (h *DocumentHandler) findUploadSession(c *fiber.Ctx) (*entity.UploadSession, *uploadError) {
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return nil, &uploadError{fiber.StatusUnauthorized, dto.ErrCodeUnauthorized, "Unauthorized"}
+	}
+	sessionID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return nil, &uploadError{fiber.StatusBadRequest, dto.ErrCodeInvalidUUID, "invalid upload session ID"}
+	}
+
+	session, err := h.uploadRepo.FindByID(c.Context(), sessionID)
+	if err != nil {
+		return nil, &uploadError{fiber.StatusNotFound, dto.ErrCodeNotFound, "upload session not found"}
+	}
+	if session.UserID != userID {
+		return nil, &uploadError{fiber.StatusForbidden, dto.ErrCodeForbidden, "access denied"}
+	}
+	return session, nil
+}
+
+// findActiveUploadSession loads an upload session that still accepts chunks or completion
+func (h *DocumentHandler) findActiveUploadSession(c *fiber.Ctx) (*entity.UploadSession, *uploadError) {
+	session, uploadErr := h.findUploadSession(c)
+	if uploadErr != nil {
+		return nil, uploadErr
+	}
+	if !session.IsActive() {
+		return nil, &uploadError{fiber.StatusConflict, dto.ErrCodeConflict, "upload already completed"}
+	}
+	if session.IsExpired(time.Now()) {
+		return nil, &uploadError{fiber.StatusGone, dto.ErrCodeUploadExpired, "upload session expired, start a new one"}
+	}
+	return session, nil
+}
+
+// assembleUpload concatenates staged chunks into a temporary file and returns it with its SHA-256
+func (h *DocumentHandler) assembleUpload(ctx context.Context, session *entity.UploadSession) (*os.File, string, error) {
+	assembled, err := os.CreateTemp("", "upload-*")
+	if err != nil {
+		return nil, "", err
+	}
+	fail := func(err error) (*os.File, string, error) {
+		assembled.Close()
+		os.Remove(assembled.Name())
+		return nil, "", err
+	}
+
+	hash := sha256.New()
+	writer := io.MultiWriter(assembled, hash)
+	for index := 0; index < session.ChunkCount(); index++ {
+		chunk, err := h.fileStorage.Get(ctx, storage.UploadChunkKey(session.ID.String(), index))
+		if err != nil {
+			return fail(fmt.Errorf("chunk %d: %w", index, err))
+		}
+		_, err = io.Copy(writer, chunk)
+		chunk.Close()
+		if err != nil {
+			return fail(fmt.Errorf("chunk %d: %w", index, err))
+		}
+	}
+
+	info, err := assembled.Stat()
+	if err != nil {
+		return fail(err)
+	}
+	if info.Size() != session.TotalSize {
+		return fail(fmt.Errorf("assembled %d of %d bytes", info.Size(), session.TotalSize))
+	}
+	if _, err := assembled.Seek(0, io.SeekStart); err != nil {
+		return fail(err)
+	}
+	return assembled, hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// discardUpload deletes an upload session together with its staged chunks
+func (h *DocumentHandler) discardUpload(ctx context.Context, session *entity.UploadSession) error {
+	if session.IsActive() {
+		h.deleteUploadChunks(ctx, session)
+	}
+	return h.uploadRepo.Delete(ctx, session.ID)
+}
+
+// deleteUploadChunks removes staged chunks on a best-effort basis
+func (h *DocumentHandler) deleteUploadChunks(ctx context.Context, session *entity.UploadSession) {
+	for index := 0; index < session.ReceivedChunks(); index++ {
+		h.fileStorage.Delete(ctx, storage.UploadChunkKey(session.ID.String(), index))
+	}
+}
+
+// offsetMismatch reports the offset the client has to resume from
+func offsetMismatch(c *fiber.Ctx, current int64) error {
+	c.Set(uploadOffsetHeader, strconv.FormatInt(current, 10))
+	return c.Status(fiber.StatusConflict).JSON(
+		dto.NewErrorResponse(dto.ErrCodeUploadOffsetMismatch, fmt.Sprintf("upload is at offset %d", current)),
+	)
+}
+
+// uploadSessionResponse converts an upload session to its API response
+func uploadSessionResponse(session *entity.UploadSession) dto.UploadSessionResponse {
+	var documentID *string
+	if session.DocumentID != nil {
+		id := session.DocumentID.String()
+		documentID = &id
+	}
+
+	return dto.UploadSessionResponse{
+		ID:             session.ID.String(),
+		FileName:       session.FileName,
+		Title:          session.Title,
+		Size:           session.TotalSize,
+		ChunkSize:      session.ChunkSize,
+		Offset:         session.ReceivedSize,
+		ChunkCount:     session.ChunkCount(),
+		ReceivedChunks: session.ReceivedChunks(),
+		Status:         string(session.Status),
+		DocumentID:     documentID,
+		ExpiresAt:      session.ExpiresAt.Format("2006-01-02T15:04:05Z"),
+		CreatedAt:      session.CreatedAt.Format("2006-01-02T15:04:05Z"),
+	}
+}
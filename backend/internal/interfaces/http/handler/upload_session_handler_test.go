@@ -0,0 +1,330 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/shester1kov/testgen-backend/internal/application/dto"
+	"github.com/shester1kov/testgen-backend/internal/domain/entity"
+	"github.com/shester1kov/testgen-backend/internal/infrastructure/parser"
+	"github.com/shester1kov/testgen-backend/internal/infrastructure/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// memoryUploadSessionRepository keeps upload sessions in memory
+type memoryUploadSessionRepository struct {
+	mu       sync.Mutex
+	sessions map[uuid.UUID]entity.UploadSession
+}
+
+func newMemoryUploadSessionRepository() *memoryUploadSessionRepository {
+	return &memoryUploadSessionRepository{sessions: make(map[uuid.UUID]entity.UploadSession)}
+}
+
+func (r *memoryUploadSessionRepository) Create(ctx context.Context, session *entity.UploadSession) error {
+	return r.Update(ctx, session)
+}
+
+func (r *memoryUploadSessionRepository) FindByID(ctx context.Context, id uuid.UUID) (*entity.UploadSession, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	session, ok := r.sessions[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &session, nil
+}
+
+func (r *memoryUploadSessionRepository) Update(ctx context.Context, session *entity.UploadSession) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sessions[session.ID] = *session
+	return nil
+}
+
+func (r *memoryUploadSessionRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.sessions, id)
+	return nil
+}
+
+func (r *memoryUploadSessionRepository) AdvanceOffset(ctx context.Context, id uuid.UUID, from, to int64) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	session, ok := r.sessions[id]
+	if !ok || !session.IsActive() || session.ReceivedSize != from {
+		return false, nil
+	}
+	session.ReceivedSize = to
+	r.sessions[id] = session
+	return true, nil
+}
+
+func (r *memoryUploadSessionRepository) FindExpired(ctx context.Context, before time.Time, limit, offset int) ([]*entity.UploadSession, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var sessions []*entity.UploadSession
+	for _, session := range r.sessions {
+		if session.ExpiresAt.Before(before) {
+			s := session
+			sessions = append(sessions, &s)
+		}
+	}
+	if offset >= len(sessions) {
+		return nil, nil
+	}
+	sessions = sessions[offset:]
+	if len(sessions) > limit {
+		sessions = sessions[:limit]
+	}
+	return sessions, nil
+}
+
+type uploadTestEnv struct {
+	app         *fiber.App
+	repo        *mockDocumentRepository
+	uploadRepo  *memoryUploadSessionRepository
+	fileStorage storage.FileStorage
+}
+
+func newUploadTestEnv(t *testing.T, userID uuid.UUID) *uploadTestEnv {
+	env := &uploadTestEnv{
+		repo:        new(mockDocumentRepository),
+		uploadRepo:  newMemoryUploadSessionRepository(),
+		fileStorage: newTestStorage(t, t.TempDir()),
+	}
+
+	handler := NewDocumentHandler(env.repo, new(mockDocUserRepository), nil, env.uploadRepo, parser.NewDocumentParserFactory(), env.fileStorage, 1<<20, nil)
+	handler.SetUploadLimits(minChunkSize, time.Hour)
+
+	env.app = fiber.New()
+	env.app.Use(func(c *fiber.Ctx) error {
+		c.Locals("userID", userID)
+		return c.Next()
+	})
+	env.app.Post("/uploads", handler.CreateUpload)
+	env.app.Get("/uploads/:id", handler.GetUpload)
+	env.app.Patch("/uploads/:id", handler.UploadChunk)
+	env.app.Post("/uploads/:id/complete", handler.CompleteUpload)
+	env.app.Delete("/uploads/:id", handler.CancelUpload)
+	return env
+}
+
+func (env *uploadTestEnv) createSession(t *testing.T, req dto.CreateUploadSessionRequest) dto.UploadSessionResponse {
+	body, _ := json.Marshal(req)
+	httpReq := httptest.NewRequest(http.MethodPost, "/uploads", bytes.NewReader(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+	resp, err := env.app.Test(httpReq)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+
+	var session dto.UploadSessionResponse
+	require.NoError(t, json.Unmarshal(getBodyBytes(t, resp), &session))
+	return session
+}
+
+func (env *uploadTestEnv) sendChunk(t *testing.T, sessionID string, offset int64, chunk []byte) *http.Response {
+	req := httptest.NewRequest(http.MethodPatch, "/uploads/"+sessionID, bytes.NewReader(chunk))
+	req.Header.Set("Content-Type", "application/offset+octet-stream")
+	req.Header.Set("Upload-Offset", strconv.FormatInt(offset, 10))
+	resp, err := env.app.Test(req)
+	require.NoError(t, err)
+	return resp
+}
+
+func (env *uploadTestEnv) complete(t *testing.T, sessionID, checksum string) *http.Response {
+	body, _ := json.Marshal(dto.CompleteUploadSessionRequest{Checksum: checksum})
+	req := httptest.NewRequest(http.MethodPost, "/uploads/"+sessionID+"/complete", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := env.app.Test(req)
+	require.NoError(t, err)
+	return resp
+}
+
+func sha256Hex(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestUploadSession_ResumesAndCreatesDocument(t *testing.T) {
+	userID := uuid.New()
+	env := newUploadTestEnv(t, userID)
+	content := []byte(strings.Repeat("Lecture notes on photosynthesis.\n", 5000))
+
+	env.repo.On("FindByContentHash", mock.Anything, sha256Hex(content)).Return([]*entity.Document{}, nil)
+	env.repo.On("Create", mock.Anything, mock.MatchedBy(func(doc *entity.Document) bool {
+		return doc.UserID == userID && doc.Title == "Lecture" && doc.FileSize == int64(len(content)) &&
+			doc.ContentHash == sha256Hex(content) && doc.FileType == entity.FileTypeTXT
+	})).Run(func(args mock.Arguments) {
+		args.Get(1).(*entity.Document).ID = uuid.New()
+	}).Return(nil)
+
+	session := env.createSession(t, dto.CreateUploadSessionRequest{
+		FileName: "notes.txt", Title: "Lecture", Size: int64(len(content)),
+	})
+	assert.Equal(t, int64(minChunkSize), session.ChunkSize)
+	assert.Equal(t, 3, session.ChunkCount)
+
+	// First chunk
+	resp := env.sendChunk(t, session.ID, 0, content[:minChunkSize])
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Equal(t, strconv.Itoa(minChunkSize), resp.Header.Get("Upload-Offset"))
+
+	// A retried chunk whose response was lost is answered with the offset to resume from
+	resp = env.sendChunk(t, session.ID, 0, content[:minChunkSize])
+	assert.Equal(t, fiber.StatusConflict, resp.StatusCode)
+	assert.Equal(t, strconv.Itoa(minChunkSize), resp.Header.Get("Upload-Offset"))
+
+	// Chunks must have the agreed size
+	resp = env.sendChunk(t, session.ID, minChunkSize, content[minChunkSize:minChunkSize+10])
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+	// Completing early is refused
+	resp = env.complete(t, session.ID, sha256Hex(content))
+	assert.Equal(t, fiber.StatusConflict, resp.StatusCode)
+
+	// The client asks where to resume and sends the rest
+	resp, err := env.app.Test(httptest.NewRequest(http.MethodGet, "/uploads/"+session.ID, nil))
+	require.NoError(t, err)
+	var status dto.UploadSessionResponse
+	require.NoError(t, json.Unmarshal(getBodyBytes(t, resp), &status))
+	assert.Equal(t, int64(minChunkSize), status.Offset)
+	assert.Equal(t, 1, status.ReceivedChunks)
+
+	for offset := status.Offset; offset < int64(len(content)); offset += minChunkSize {
+		end := min(offset+minChunkSize, int64(len(content)))
+		resp = env.sendChunk(t, session.ID, offset, content[offset:end])
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	}
+
+	resp = env.complete(t, session.ID, strings.ToUpper(sha256Hex(content)))
+	assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	var document dto.DocumentUploadResponse
+	require.NoError(t, json.Unmarshal(getBodyBytes(t, resp), &document))
+	assert.Equal(t, "notes.txt", document.FileName)
+
+	// The assembled file is stored like a regular upload and the staged chunks are gone
+	stored, err := env.uploadRepo.FindByID(context.Background(), uuid.MustParse(session.ID))
+	require.NoError(t, err)
+	assert.Equal(t, entity.UploadSessionCompleted, stored.Status)
+	assert.Equal(t, document.ID, stored.DocumentID.String())
+	for index := 0; index < 3; index++ {
+		_, err := env.fileStorage.Stat(context.Background(), storage.UploadChunkKey(session.ID, index))
+		assert.ErrorIs(t, err, storage.ErrNotFound)
+	}
+
+	// A completed upload takes no more chunks
+	resp = env.sendChunk(t, session.ID, int64(len(content)), []byte("x"))
+	assert.Equal(t, fiber.StatusConflict, resp.StatusCode)
+
+	// Asserted last: the mock inspects recorded request contexts, which fasthttp reuses
+	env.repo.AssertExpectations(t)
+}
+
+func TestUploadSession_ChecksumMismatchDiscardsUpload(t *testing.T) {
+	userID := uuid.New()
+	env := newUploadTestEnv(t, userID)
+	content := []byte("short lecture text")
+
+	session := env.createSession(t, dto.CreateUploadSessionRequest{FileName: "notes.txt", Size: int64(len(content))})
+	assert.Equal(t, int64(len(content)), session.ChunkSize)
+
+	resp := env.sendChunk(t, session.ID, 0, content)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	// A checksum is required
+	resp = env.complete(t, session.ID, "")
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+	resp = env.complete(t, session.ID, sha256Hex([]byte("other content")))
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	var body dto.ErrorResponse
+	require.NoError(t, json.Unmarshal(getBodyBytes(t, resp), &body))
+	assert.Equal(t, dto.ErrCodeChecksumMismatch, body.Error.Code)
+
+	_, err := env.uploadRepo.FindByID(context.Background(), uuid.MustParse(session.ID))
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	_, err = env.fileStorage.Stat(context.Background(), storage.UploadChunkKey(session.ID, 0))
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+}
+
+func TestUploadSession_CreateValidation(t *testing.T) {
+	env := newUploadTestEnv(t, uuid.New())
+
+	tests := []struct {
+		name string
+		req  dto.CreateUploadSessionRequest
+		code string
+	}{
+		{"missing size", dto.CreateUploadSessionRequest{FileName: "a.txt"}, dto.ErrCodeInvalidInput},
+		{"too large", dto.CreateUploadSessionRequest{FileName: "a.txt", Size: 2 << 20}, dto.ErrCodeFileTooLarge},
+		{"unsupported type", dto.CreateUploadSessionRequest{FileName: "a.exe", Size: 10}, dto.ErrCodeInvalidFileType},
+		{"chunk too large", dto.CreateUploadSessionRequest{FileName: "a.txt", Size: 1 << 20, ChunkSize: 1 << 20}, dto.ErrCodeInvalidInput},
+		{"chunk too small", dto.CreateUploadSessionRequest{FileName: "a.txt", Size: 1 << 20, ChunkSize: 1024}, dto.ErrCodeInvalidInput},
+		{"bad checksum", dto.CreateUploadSessionRequest{FileName: "a.txt", Size: 10, Checksum: "abc"}, dto.ErrCodeInvalidInput},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, _ := json.Marshal(tt.req)
+			req := httptest.NewRequest(http.MethodPost, "/uploads", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := env.app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+			var errResp dto.ErrorResponse
+			require.NoError(t, json.Unmarshal(getBodyBytes(t, resp), &errResp))
+			assert.Equal(t, tt.code, errResp.Error.Code)
+		})
+	}
+}
+
+func TestUploadSession_ForeignExpiredAndCancelled(t *testing.T) {
+	userID := uuid.New()
+	env := newUploadTestEnv(t, userID)
+	ctx := context.Background()
+
+	foreign := &entity.UploadSession{
+		ID: uuid.New(), UserID: uuid.New(), FileName: "a.txt", TotalSize: 10, ChunkSize: 10,
+		Status: entity.UploadSessionActive, ExpiresAt: time.Now().Add(time.Hour),
+	}
+	expired := &entity.UploadSession{
+		ID: uuid.New(), UserID: userID, FileName: "a.txt", TotalSize: 10, ChunkSize: 10,
+		Status: entity.UploadSessionActive, ExpiresAt: time.Now().Add(-time.Minute),
+	}
+	require.NoError(t, env.uploadRepo.Create(ctx, foreign))
+	require.NoError(t, env.uploadRepo.Create(ctx, expired))
+
+	resp := env.sendChunk(t, foreign.ID.String(), 0, []byte("0123456789"))
+	assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+
+	resp = env.sendChunk(t, expired.ID.String(), 0, []byte("0123456789"))
+	assert.Equal(t, fiber.StatusGone, resp.StatusCode)
+
+	resp = env.sendChunk(t, expired.ID.String(), -1, []byte("0123456789"))
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+	resp, err := env.app.Test(httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/uploads/%s", expired.ID), nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	_, err = env.uploadRepo.FindByID(ctx, expired.ID)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
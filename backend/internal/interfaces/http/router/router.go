@@ -16,6 +16,9 @@ func SetupRoutes(
 	testHandler *handler.TestHandler,
 	moodleHandler *handler.MoodleHandler,
 	statsHandler *handler.StatsHandler,
+	searchHandler *handler.SearchHandler,
+	cleanupHandler *handler.CleanupHandler,
+	questionBankHandler *handler.QuestionBankHandler,
 	jwtManager *utils.JWTManager,
 	cookieName string,
 ) {
@@ -39,10 +42,24 @@ func SetupRoutes(
 	// Document routes (protected - teacher and admin only for upload)
 	documents := api.Group("/documents", middleware.AuthMiddleware(jwtManager, cookieName))
 	documents.Post("/", middleware.RequireTeacherOrAdmin(), documentHandler.Upload)     // Only teachers/admin can upload
+	documents.Post("/bulk", middleware.RequireTeacherOrAdmin(), documentHandler.BulkUpload) // ZIP bundle, one document per file
+
+	// Resumable chunked uploads (owner of the upload session only)
+	uploads := documents.Group("/uploads", middleware.RequireTeacherOrAdmin())
+	uploads.Post("/", documentHandler.CreateUpload)
+	uploads.Get("/:id", documentHandler.GetUpload)
+	uploads.Patch("/:id", documentHandler.UploadChunk)
+	uploads.Post("/:id/complete", documentHandler.CompleteUpload)
+	uploads.Delete("/:id", documentHandler.CancelUpload)
+
 	documents.Get("/", documentHandler.List)                                             // All can list
 	documents.Get("/:id", documentHandler.GetByID)                                       // All can view
 	documents.Delete("/:id", middleware.RequireTeacherOrAdmin(), documentHandler.Delete) // Only teachers/admin can delete
 	documents.Post("/:id/parse", middleware.RequireTeacherOrAdmin(), documentHandler.Parse) // Only teachers/admin can parse
+	documents.Get("/:id/file", documentHandler.DownloadFile)                                  // Owner can download the original file
+	documents.Get("/:id/text", documentHandler.GetText)                                       // Owner can page through text
+	documents.Put("/:id/text", middleware.RequireTeacherOrAdmin(), documentHandler.UpdateText)        // Only teachers/admin can correct text
+	documents.Get("/:id/text/revisions", middleware.RequireTeacherOrAdmin(), documentHandler.ListTextRevisions)
 
 	// Test routes (protected - teacher and admin only for creation/editing)
 	tests := api.Group("/tests", middleware.AuthMiddleware(jwtManager, cookieName))
@@ -52,7 +69,21 @@ func SetupRoutes(
 	tests.Put("/:id", middleware.RequireTeacherOrAdmin(), testHandler.Update)                                   // Only teachers/admin can update
 	tests.Delete("/:id", middleware.RequireTeacherOrAdmin(), testHandler.Delete)                                // Only teachers/admin can delete
 	tests.Post("/generate", middleware.RequireTeacherOrAdmin(), testHandler.Generate)                           // Only teachers/admin can generate
+	tests.Post("/assemble", middleware.RequireTeacherOrAdmin(), questionBankHandler.Assemble)                   // Only teachers/admin can assemble from the bank
+	tests.Post("/import", middleware.RequireTeacherOrAdmin(), testHandler.Import)                               // Only teachers/admin can import
+	tests.Post("/:id/clone", middleware.RequireTeacherOrAdmin(), testHandler.Clone)                             // Only teachers/admin can clone
+	tests.Post("/:id/publish", middleware.RequireTeacherOrAdmin(), testHandler.Publish)                         // Runs the pre-publish checks
+	tests.Post("/:id/unpublish", middleware.RequireTeacherOrAdmin(), testHandler.Unpublish)                     // Back to draft, hidden from students
+	tests.Post("/:id/archive", middleware.RequireTeacherOrAdmin(), testHandler.Archive)                         // Hidden from students, locked for good
+	tests.Post("/:id/versions", middleware.RequireTeacherOrAdmin(), testHandler.CreateVersion)                  // Editable copy of a published or archived test
+	tests.Get("/:id/assignments", middleware.RequireTeacherOrAdmin(), testHandler.ListAssignments)              // Students the test is assigned to
+	tests.Post("/:id/assignments", middleware.RequireTeacherOrAdmin(), testHandler.AssignStudents)              // Only teachers/admin can assign tests
+	tests.Delete("/:id/assignments/:studentId", middleware.RequireTeacherOrAdmin(), testHandler.UnassignStudent) // Only teachers/admin can unassign tests
+	tests.Post("/:testId/questions", middleware.RequireTeacherOrAdmin(), testHandler.CreateQuestion)            // Only teachers/admin can add questions
+	tests.Put("/:testId/questions/order", middleware.RequireTeacherOrAdmin(), testHandler.ReorderQuestions)     // Registered before :questionId
 	tests.Put("/:testId/questions/:questionId", middleware.RequireTeacherOrAdmin(), testHandler.UpdateQuestion) // Only teachers/admin can update questions
+	tests.Delete("/:testId/questions/:questionId", middleware.RequireTeacherOrAdmin(), testHandler.DeleteQuestion) // Only teachers/admin can delete questions
+	tests.Post("/:testId/questions/from-bank", middleware.RequireTeacherOrAdmin(), questionBankHandler.AddToTest) // Only teachers/admin can add bank questions
 	tests.Get("/:id/export/json", testHandler.ExportToJSON)                                                     // Export test to JSON
 	tests.Get("/:id/export/xml", testHandler.ExportToXML)                                                       // Export test to Moodle XML
 
@@ -63,7 +94,29 @@ func SetupRoutes(
 	moodle.Get("/tests/:id/export", moodleHandler.ExportToXML)
 	moodle.Post("/tests/:id/sync", moodleHandler.SyncToMoodle)
 
+	// Question bank routes (protected - teacher and admin only, scoped to the current user)
+	bank := api.Group("/bank", middleware.AuthMiddleware(jwtManager, cookieName), middleware.RequireTeacherOrAdmin())
+	bank.Get("/categories", questionBankHandler.ListCategories)
+	bank.Post("/categories", questionBankHandler.CreateCategory)
+	bank.Put("/categories/:id", questionBankHandler.UpdateCategory)
+	bank.Delete("/categories/:id", questionBankHandler.DeleteCategory)
+	bank.Get("/tags", questionBankHandler.ListTags)
+	bank.Get("/questions", questionBankHandler.ListQuestions)
+	bank.Post("/questions", questionBankHandler.CreateQuestion)
+	bank.Post("/questions/from-test", questionBankHandler.SaveFromTest)
+	bank.Get("/questions/:id", questionBankHandler.GetQuestion)
+	bank.Put("/questions/:id", questionBankHandler.UpdateQuestion)
+	bank.Delete("/questions/:id", questionBankHandler.DeleteQuestion)
+
 	// Stats routes (protected - all authenticated users)
 	stats := api.Group("/stats", middleware.AuthMiddleware(jwtManager, cookieName))
 	stats.Get("/dashboard", statsHandler.GetDashboardStats)
+
+	// Search routes (protected - all authenticated users, results filtered by ownership)
+	api.Get("/search", middleware.AuthMiddleware(jwtManager, cookieName), searchHandler.Search)
+
+	// Admin routes (protected - admin only)
+	admin := api.Group("/admin", middleware.AuthMiddleware(jwtManager, cookieName), middleware.RequireAdmin())
+	admin.Get("/cleanup", cleanupHandler.GetStatus)
+	admin.Post("/cleanup/run", cleanupHandler.Run)
 }
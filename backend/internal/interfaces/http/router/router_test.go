@@ -23,6 +23,9 @@ func TestSetupRoutes_RegistersExpectedEndpoints(t *testing.T) {
 		&handler.TestHandler{},
 		&handler.MoodleHandler{},
 		&handler.StatsHandler{},
+		&handler.SearchHandler{},
+		&handler.CleanupHandler{},
+		&handler.QuestionBankHandler{},
 		jwtManager,
 		"token",
 	)
@@ -30,26 +33,53 @@ func TestSetupRoutes_RegistersExpectedEndpoints(t *testing.T) {
 	routes := app.GetRoutes()
 
 	expected := map[string]bool{
-		"POST /api/v1/auth/register":          true,
-		"POST /api/v1/auth/login":             true,
-		"POST /api/v1/auth/logout":            true,
-		"GET /api/v1/auth/me":                 true,
-		"GET /api/v1/users/":                  true,
-		"PUT /api/v1/users/:id/role":          true,
-		"POST /api/v1/documents/":             true,
-		"GET /api/v1/documents/":              true,
-		"GET /api/v1/documents/:id":           true,
-		"DELETE /api/v1/documents/:id":        true,
-		"POST /api/v1/documents/:id/parse":    true,
-		"POST /api/v1/tests/":                 true,
-		"GET /api/v1/tests/":                  true,
-		"GET /api/v1/tests/:id":               true,
-		"DELETE /api/v1/tests/:id":            true,
-		"POST /api/v1/tests/generate":         true,
-		"GET /api/v1/moodle/connection":       true,
-		"GET /api/v1/moodle/courses":          true,
-		"GET /api/v1/moodle/tests/:id/export": true,
-		"POST /api/v1/moodle/tests/:id/sync":  true,
+		"POST /api/v1/auth/register":                      true,
+		"POST /api/v1/auth/login":                         true,
+		"POST /api/v1/auth/logout":                        true,
+		"GET /api/v1/auth/me":                             true,
+		"GET /api/v1/users/":                              true,
+		"PUT /api/v1/users/:id/role":                      true,
+		"POST /api/v1/documents/":                         true,
+		"POST /api/v1/documents/bulk":                     true,
+		"GET /api/v1/documents/":                          true,
+		"GET /api/v1/documents/:id":                       true,
+		"DELETE /api/v1/documents/:id":                    true,
+		"POST /api/v1/documents/:id/parse":                true,
+		"POST /api/v1/documents/uploads/":                 true,
+		"PATCH /api/v1/documents/uploads/:id":             true,
+		"POST /api/v1/documents/uploads/:id/complete":     true,
+		"POST /api/v1/tests/":                             true,
+		"GET /api/v1/tests/":                              true,
+		"GET /api/v1/tests/:id":                           true,
+		"DELETE /api/v1/tests/:id":                        true,
+		"POST /api/v1/tests/generate":                     true,
+		"POST /api/v1/tests/assemble":                     true,
+		"POST /api/v1/tests/:id/publish":                  true,
+		"POST /api/v1/tests/:id/unpublish":                true,
+		"POST /api/v1/tests/:id/archive":                  true,
+		"POST /api/v1/tests/:id/versions":                 true,
+		"GET /api/v1/tests/:id/assignments":               true,
+		"POST /api/v1/tests/:id/assignments":              true,
+		"DELETE /api/v1/tests/:id/assignments/:studentId": true,
+		"POST /api/v1/tests/:testId/questions/from-bank":  true,
+		"GET /api/v1/moodle/connection":                   true,
+		"GET /api/v1/moodle/courses":                      true,
+		"GET /api/v1/moodle/tests/:id/export":             true,
+		"POST /api/v1/moodle/tests/:id/sync":              true,
+		"GET /api/v1/bank/categories":                     true,
+		"POST /api/v1/bank/categories":                    true,
+		"PUT /api/v1/bank/categories/:id":                 true,
+		"DELETE /api/v1/bank/categories/:id":              true,
+		"GET /api/v1/bank/tags":                           true,
+		"GET /api/v1/bank/questions":                      true,
+		"POST /api/v1/bank/questions":                     true,
+		"POST /api/v1/bank/questions/from-test":           true,
+		"GET /api/v1/bank/questions/:id":                  true,
+		"PUT /api/v1/bank/questions/:id":                  true,
+		"DELETE /api/v1/bank/questions/:id":               true,
+		"GET /api/v1/search":                              true,
+		"GET /api/v1/admin/cleanup":                       true,
+		"POST /api/v1/admin/cleanup/run":                  true,
 	}
 
 	for _, route := range routes {
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"strconv"
+	"time"
 )
 
 // Config holds all application configuration
@@ -12,6 +13,9 @@ type Config struct {
 	JWT      JWTConfig
 	Cookie   CookieConfig
 	File     FileConfig
+	Storage  StorageConfig
+	Parser   ParserConfig
+	Cleanup  CleanupConfig
 	LLM      LLMConfig
 	Moodle   MoodleConfig
 	Logger   LoggerConfig
@@ -55,6 +59,52 @@ type CookieConfig struct {
 type FileConfig struct {
 	MaxFileSize int64
 	UploadDir   string
+
+	// Resumable chunked uploads; chunks must fit the HTTP body limit (4MB by default)
+	UploadChunkMaxSize int64
+	UploadSessionTTL   time.Duration
+
+	// ZIP bundle uploads (POST /documents/bulk)
+	BulkUploadMaxFiles int
+
+	// Archive bomb protection for ZIP-based formats (DOCX, PPTX, ODT, ODP, EPUB)
+	MaxArchiveEntries   int
+	MaxUncompressedSize int64
+	MaxCompressionRatio int
+}
+
+// StorageConfig holds uploaded file storage configuration
+type StorageConfig struct {
+	Backend        string // local or s3
+	S3Endpoint     string
+	S3AccessKey    string
+	S3SecretKey    string
+	S3Bucket       string
+	S3Region       string
+	S3UseSSL       bool
+	S3CreateBucket bool
+}
+
+// ParserConfig holds background document parsing configuration
+type ParserConfig struct {
+	Workers      int
+	QueueSize    int
+	JobTimeout   time.Duration
+	MaxRetries   int
+	RetryBackoff time.Duration
+	PollInterval time.Duration
+	StaleAfter   time.Duration
+
+	// Extract text outputs of Jupyter notebook code cells along with the code
+	NotebookOutputs bool
+}
+
+// CleanupConfig holds storage cleanup (janitor) configuration
+type CleanupConfig struct {
+	Interval          time.Duration // 0 disables scheduled cleanup
+	Retention         time.Duration // How long files of deleted documents are kept
+	OrphanGracePeriod time.Duration
+	DryRun            bool // Scheduled runs only report what would be removed
 }
 
 // LLMConfig holds LLM API configuration
@@ -69,9 +119,10 @@ type LLMConfig struct {
 
 // MoodleConfig holds Moodle integration configuration
 type MoodleConfig struct {
-	URL         string
-	Token       string
-	ImportToken string
+	URL             string
+	Token           string
+	ImportToken     string
+	NegativeMarking bool // Wrong choices of multiple choice questions take points away in exports
 }
 
 // LoggerConfig holds logger configuration
@@ -118,6 +169,42 @@ func Load() *Config {
 		File: FileConfig{
 			MaxFileSize: getEnvInt64("MAX_FILE_SIZE", 52428800), // 50MB
 			UploadDir:   getEnv("UPLOAD_DIR", "./uploads"),
+
+			UploadChunkMaxSize: getEnvInt64("UPLOAD_CHUNK_MAX_SIZE", 4194304), // 4MB
+			UploadSessionTTL:   getEnvDuration("UPLOAD_SESSION_TTL", 24*time.Hour),
+
+			BulkUploadMaxFiles: getEnvInt("BULK_UPLOAD_MAX_FILES", 100),
+
+			MaxArchiveEntries:   getEnvInt("ARCHIVE_MAX_ENTRIES", 1000),
+			MaxUncompressedSize: getEnvInt64("ARCHIVE_MAX_UNCOMPRESSED_SIZE", 209715200), // 200MB
+			MaxCompressionRatio: getEnvInt("ARCHIVE_MAX_COMPRESSION_RATIO", 100),
+		},
+		Storage: StorageConfig{
+			Backend:        getEnv("STORAGE_BACKEND", "local"),
+			S3Endpoint:     getEnv("S3_ENDPOINT", ""),
+			S3AccessKey:    getEnv("S3_ACCESS_KEY", ""),
+			S3SecretKey:    getEnv("S3_SECRET_KEY", ""),
+			S3Bucket:       getEnv("S3_BUCKET", "testgen-documents"),
+			S3Region:       getEnv("S3_REGION", "us-east-1"),
+			S3UseSSL:       getEnvBool("S3_USE_SSL", false),
+			S3CreateBucket: getEnvBool("S3_CREATE_BUCKET", false),
+		},
+		Parser: ParserConfig{
+			Workers:      getEnvInt("PARSE_WORKERS", 2),
+			QueueSize:    getEnvInt("PARSE_QUEUE_SIZE", 100),
+			JobTimeout:   getEnvDuration("PARSE_JOB_TIMEOUT", 2*time.Minute),
+			MaxRetries:   getEnvInt("PARSE_MAX_RETRIES", 3),
+			RetryBackoff: getEnvDuration("PARSE_RETRY_BACKOFF", 2*time.Second),
+			PollInterval: getEnvDuration("PARSE_POLL_INTERVAL", 30*time.Second),
+			StaleAfter:   getEnvDuration("PARSE_STALE_AFTER", 15*time.Minute),
+
+			NotebookOutputs: getEnvBool("PARSE_NOTEBOOK_OUTPUTS", true),
+		},
+		Cleanup: CleanupConfig{
+			Interval:          getEnvDuration("CLEANUP_INTERVAL", 6*time.Hour),
+			Retention:         getEnvDuration("CLEANUP_RETENTION", 30*24*time.Hour),
+			OrphanGracePeriod: getEnvDuration("CLEANUP_ORPHAN_GRACE_PERIOD", 24*time.Hour),
+			DryRun:            getEnvBool("CLEANUP_DRY_RUN", false),
 		},
 		LLM: LLMConfig{
 			Provider:         getEnv("LLM_PROVIDER", "yandexgpt"),
@@ -128,9 +215,10 @@ func Load() *Config {
 			YandexModel:      getEnv("YANDEX_GPT_MODEL", "yandexgpt-lite"),
 		},
 		Moodle: MoodleConfig{
-			URL:         getEnv("MOODLE_URL", ""),
-			Token:       getEnv("MOODLE_TOKEN", ""),
-			ImportToken: getEnv("MOODLE_IMPORT_TOKEN", "testgen_secret_token_change_in_production"),
+			URL:             getEnv("MOODLE_URL", ""),
+			Token:           getEnv("MOODLE_TOKEN", ""),
+			ImportToken:     getEnv("MOODLE_IMPORT_TOKEN", "testgen_secret_token_change_in_production"),
+			NegativeMarking: getEnvBool("MOODLE_NEGATIVE_MARKING", true),
 		},
 		Logger: LoggerConfig{
 			Level:  getEnv("LOG_LEVEL", "info"),
@@ -176,3 +264,27 @@ func getEnvInt64(key string, defaultValue int64) int64 {
 	}
 	return intValue
 }
+
+func getEnvInt(key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	intValue, err := strconv.Atoi(value)
+	if err != nil {
+		return defaultValue
+	}
+	return intValue
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return defaultValue
+	}
+	return duration
+}
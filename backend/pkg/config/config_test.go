@@ -2,6 +2,7 @@ package config
 
 import (
 	"testing"
+	"time"
 )
 
 func TestLoadDefaults(t *testing.T) {
@@ -51,4 +52,16 @@ func TestGetEnvHelpers(t *testing.T) {
 	if val := getEnvInt64("INT_VALUE", 42); val != 42 {
 		t.Fatalf("invalid int should return default")
 	}
+	if val := getEnvInt("INT_VALUE", 7); val != 7 {
+		t.Fatalf("invalid int should return default")
+	}
+
+	t.Setenv("DURATION_VALUE", "5m")
+	if val := getEnvDuration("DURATION_VALUE", time.Second); val != 5*time.Minute {
+		t.Fatalf("expected parsed duration, got %s", val)
+	}
+	t.Setenv("DURATION_VALUE", "soon")
+	if val := getEnvDuration("DURATION_VALUE", time.Second); val != time.Second {
+		t.Fatalf("invalid duration should return default")
+	}
 }
@@ -0,0 +1,251 @@
+package textstats
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// Detected document languages
+const (
+	LanguageRussian = "ru"
+	LanguageEnglish = "en"
+	LanguageMixed   = "mixed"
+	LanguageUnknown = "unknown"
+)
+
+// minLettersForLanguage is the amount of letters below which language is not guessed
+const minLettersForLanguage = 20
+
+// dominantScriptShare is the share of letters one script needs for the text to count as that language
+const dominantScriptShare = 0.8
+
+// tokenizerRatio describes how many characters of each script an LLM tokenizer packs into one token
+type tokenizerRatio struct {
+	latin    float64
+	cyrillic float64
+	other    float64
+}
+
+// tokenizerRatios holds rough per-provider averages measured on course materials.
+// Estimates are meant for "will it fit" warnings, not for billing.
+var tokenizerRatios = map[string]tokenizerRatio{
+	"perplexity": {latin: 3.8, cyrillic: 2.2, other: 1.5},
+	"openai":     {latin: 4.0, cyrillic: 2.8, other: 1.5},
+	"yandexgpt":  {latin: 3.2, cyrillic: 4.0, other: 1.5},
+}
+
+// Providers returns the LLM providers tokens are estimated for
+func Providers() []string {
+	return []string{"perplexity", "openai", "yandexgpt"}
+}
+
+// Stats holds statistics of a document text
+type Stats struct {
+	Words       int
+	Characters  int // Unicode characters, not bytes
+	Sentences   int
+	Sections    int
+	Language    string
+	Readability float64        // Flesch reading ease (Oborneva's variant for Russian), 0-100, higher is easier
+	Tokens      map[string]int // Estimated prompt tokens per LLM provider
+}
+
+// scriptCounts holds per-script character counts of a text
+type scriptCounts struct {
+	latin    int
+	cyrillic int
+	other    int // Digits, punctuation and other non-space characters
+}
+
+// Analyze computes statistics of text
+func Analyze(text string) Stats {
+	stats := Stats{
+		Characters: len([]rune(text)),
+		Sections:   countSections(text),
+		Tokens:     make(map[string]int, len(tokenizerRatios)),
+	}
+
+	counts := countScripts(text)
+	stats.Language = detectLanguage(counts)
+
+	syllables := 0
+	for _, word := range strings.FieldsFunc(text, isWordSeparator) {
+		if !containsLetter(word) {
+			continue
+		}
+		stats.Words++
+		syllables += countSyllables(word)
+	}
+	stats.Sentences = countSentences(text)
+	stats.Readability = readability(stats.Language, stats.Words, stats.Sentences, syllables)
+
+	for provider := range tokenizerRatios {
+		stats.Tokens[provider] = estimateTokens(counts, provider)
+	}
+	return stats
+}
+
+// EstimateTokens estimates how many tokens text takes in the given provider's prompt.
+// Unknown providers are estimated with the most conservative ratio.
+func EstimateTokens(text, provider string) int {
+	return estimateTokens(countScripts(text), provider)
+}
+
+func estimateTokens(counts scriptCounts, provider string) int {
+	ratio, ok := tokenizerRatios[provider]
+	if !ok {
+		ratio = tokenizerRatio{latin: 3.2, cyrillic: 2.2, other: 1.5}
+	}
+	tokens := float64(counts.latin)/ratio.latin +
+		float64(counts.cyrillic)/ratio.cyrillic +
+		float64(counts.other)/ratio.other
+	return int(math.Ceil(tokens))
+}
+
+func countScripts(text string) scriptCounts {
+	var counts scriptCounts
+	for _, r := range text {
+		switch {
+		case unicode.IsSpace(r):
+		case unicode.In(r, unicode.Latin):
+			counts.latin++
+		case unicode.In(r, unicode.Cyrillic):
+			counts.cyrillic++
+		default:
+			counts.other++
+		}
+	}
+	return counts
+}
+
+func detectLanguage(counts scriptCounts) string {
+	letters := counts.latin + counts.cyrillic
+	if letters < minLettersForLanguage {
+		return LanguageUnknown
+	}
+	switch {
+	case float64(counts.cyrillic) >= dominantScriptShare*float64(letters):
+		return LanguageRussian
+	case float64(counts.latin) >= dominantScriptShare*float64(letters):
+		return LanguageEnglish
+	default:
+		return LanguageMixed
+	}
+}
+
+// readability returns the Flesch reading ease score clamped to 0-100
+func readability(language string, words, sentences, syllables int) float64 {
+	if words == 0 {
+		return 0
+	}
+	if sentences == 0 {
+		sentences = 1
+	}
+	wordsPerSentence := float64(words) / float64(sentences)
+	syllablesPerWord := float64(syllables) / float64(words)
+
+	var score float64
+	if language == LanguageRussian {
+		score = 206.835 - 1.3*wordsPerSentence - 60.1*syllablesPerWord
+	} else {
+		score = 206.835 - 1.015*wordsPerSentence - 84.6*syllablesPerWord
+	}
+	score = math.Max(0, math.Min(100, score))
+	return math.Round(score*10) / 10
+}
+
+func isWordSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '\''
+}
+
+func containsLetter(word string) bool {
+	return strings.IndexFunc(word, unicode.IsLetter) >= 0
+}
+
+// countSyllables approximates syllables as groups of consecutive vowels, with at least one per word
+func countSyllables(word string) int {
+	word = strings.ToLower(word)
+	syllables := 0
+	previousVowel := false
+	for _, r := range word {
+		vowel := strings.ContainsRune("aeiouyаеёиоуыэюя", r)
+		if vowel && !previousVowel {
+			syllables++
+		}
+		previousVowel = vowel
+	}
+	// A trailing silent "e" in English words does not form a syllable
+	if syllables > 1 && strings.HasSuffix(word, "e") && !strings.HasSuffix(word, "le") {
+		syllables--
+	}
+	if syllables == 0 {
+		syllables = 1
+	}
+	return syllables
+}
+
+// countSentences counts runs of terminal punctuation and paragraph breaks, so headings count as sentences
+func countSentences(text string) int {
+	sentences := 0
+	inTerminator := false
+	hasContent := false
+	var previous rune
+	for _, r := range text {
+		terminal := r == '.' || r == '!' || r == '?' || r == '…' || (r == '\n' && previous == '\n')
+		previous = r
+		if terminal && !inTerminator && hasContent {
+			sentences++
+			hasContent = false
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			hasContent = true
+		}
+		inTerminator = terminal
+	}
+	if hasContent {
+		sentences++
+	}
+	return sentences
+}
+
+// countSections counts headings: markdown headings and short standalone lines
+// without terminal punctuation that start a paragraph. A non-empty text has at least one section.
+func countSections(text string) int {
+	if strings.TrimSpace(text) == "" {
+		return 0
+	}
+
+	lines := strings.Split(text, "\n")
+	sections := 0
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			sections++
+			continue
+		}
+		previousBlank := i == 0 || strings.TrimSpace(lines[i-1]) == ""
+		nextBlank := i+1 < len(lines) && strings.TrimSpace(lines[i+1]) == ""
+		if previousBlank && nextBlank && isHeadingLine(line) {
+			sections++
+		}
+	}
+	if sections == 0 {
+		sections = 1
+	}
+	return sections
+}
+
+func isHeadingLine(line string) bool {
+	runes := []rune(line)
+	if len(runes) > 80 || len(strings.Fields(line)) > 10 {
+		return false
+	}
+	if !unicode.IsUpper(runes[0]) && !unicode.IsDigit(runes[0]) {
+		return false
+	}
+	return !strings.ContainsRune(".,;!?…", runes[len(runes)-1])
+}
@@ -0,0 +1,78 @@
+package textstats
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnalyze_Russian(t *testing.T) {
+	text := "Введение\n\nФотосинтез — это процесс образования органических веществ. Он идёт в листьях растений.\n\nВыводы\n\nРастения дают кислород."
+
+	stats := Analyze(text)
+
+	assert.Equal(t, LanguageRussian, stats.Language)
+	assert.Equal(t, 16, stats.Words)
+	assert.Equal(t, len([]rune(text)), stats.Characters)
+	assert.Equal(t, 5, stats.Sentences)
+	assert.Equal(t, 2, stats.Sections)
+	assert.Greater(t, stats.Readability, 0.0)
+	assert.LessOrEqual(t, stats.Readability, 100.0)
+	for _, provider := range Providers() {
+		assert.Greater(t, stats.Tokens[provider], 0, provider)
+	}
+	// YandexGPT's tokenizer is tuned for Russian
+	assert.Less(t, stats.Tokens["yandexgpt"], stats.Tokens["openai"])
+}
+
+func TestAnalyze_English(t *testing.T) {
+	text := "# Photosynthesis\n\nPlants convert light into chemical energy. The process takes place in chloroplasts.\n\n## Summary\n\nPlants release oxygen."
+
+	stats := Analyze(text)
+
+	assert.Equal(t, LanguageEnglish, stats.Language)
+	assert.Equal(t, 2, stats.Sections)
+	assert.Equal(t, 5, stats.Sentences)
+	assert.Less(t, stats.Tokens["openai"], stats.Tokens["yandexgpt"])
+}
+
+func TestAnalyze_Empty(t *testing.T) {
+	stats := Analyze("")
+
+	assert.Equal(t, 0, stats.Words)
+	assert.Equal(t, 0, stats.Sections)
+	assert.Equal(t, LanguageUnknown, stats.Language)
+	assert.Equal(t, 0.0, stats.Readability)
+}
+
+func TestAnalyze_MixedLanguage(t *testing.T) {
+	stats := Analyze("Лекция про machine learning и neural networks для студентов первого курса")
+
+	assert.Equal(t, LanguageMixed, stats.Language)
+	assert.Equal(t, 1, stats.Sections)
+}
+
+func TestReadability_LongSentencesAreHarder(t *testing.T) {
+	short := Analyze(strings.Repeat("The cat sat. ", 20))
+	long := Analyze("The considerably complicated administrative regulations necessitated extraordinarily comprehensive documentation, unfortunately delaying implementation indefinitely.")
+
+	assert.Greater(t, short.Readability, long.Readability)
+}
+
+func TestEstimateTokens(t *testing.T) {
+	text := strings.Repeat("слово ", 1000)
+
+	assert.Equal(t, Analyze(text).Tokens["openai"], EstimateTokens(text, "openai"))
+	// Unknown providers fall back to a conservative ratio
+	assert.GreaterOrEqual(t, EstimateTokens(text, "unknown"), EstimateTokens(text, "openai"))
+	assert.Equal(t, 0, EstimateTokens("", "openai"))
+}
+
+func TestCountSyllables(t *testing.T) {
+	assert.Equal(t, 1, countSyllables("cat"))
+	assert.Equal(t, 2, countSyllables("table"))
+	assert.Equal(t, 1, countSyllables("make"))
+	assert.Equal(t, 5, countSyllables("молокозавод"))
+	assert.Equal(t, 1, countSyllables("вдр"))
+}
@@ -4,12 +4,16 @@
 package main
 
 import (
+	"context"
+
 	"github.com/google/wire"
 	"github.com/shester1kov/testgen-backend/internal/domain/repository"
 	"github.com/shester1kov/testgen-backend/internal/infrastructure/llm"
 	"github.com/shester1kov/testgen-backend/internal/infrastructure/moodle"
 	"github.com/shester1kov/testgen-backend/internal/infrastructure/parser"
 	"github.com/shester1kov/testgen-backend/internal/infrastructure/persistence/postgres"
+	"github.com/shester1kov/testgen-backend/internal/infrastructure/storage"
+	"github.com/shester1kov/testgen-backend/internal/infrastructure/worker"
 	"github.com/shester1kov/testgen-backend/internal/interfaces/http/handler"
 	"github.com/shester1kov/testgen-backend/pkg/config"
 	"github.com/shester1kov/testgen-backend/pkg/utils"
@@ -18,13 +22,18 @@ import (
 
 // ApplicationContainer holds all application dependencies
 type ApplicationContainer struct {
-	AuthHandler     *handler.AuthHandler
-	UserHandler     *handler.UserHandler
-	DocumentHandler *handler.DocumentHandler
-	TestHandler     *handler.TestHandler
-	MoodleHandler   *handler.MoodleHandler
-	StatsHandler    *handler.StatsHandler
-	JWTManager      *utils.JWTManager
+	AuthHandler         *handler.AuthHandler
+	UserHandler         *handler.UserHandler
+	DocumentHandler     *handler.DocumentHandler
+	TestHandler         *handler.TestHandler
+	MoodleHandler       *handler.MoodleHandler
+	StatsHandler        *handler.StatsHandler
+	SearchHandler       *handler.SearchHandler
+	CleanupHandler      *handler.CleanupHandler
+	QuestionBankHandler *handler.QuestionBankHandler
+	ParseQueue          *worker.ParseQueue
+	Janitor             *worker.Janitor
+	JWTManager          *utils.JWTManager
 }
 
 // InitializeApplication sets up all dependencies using Wire
@@ -34,34 +43,48 @@ func InitializeApplication(cfg *config.Config, db *gorm.DB) (*ApplicationContain
 		postgres.NewUserRepository,
 		postgres.NewRoleRepository,
 		postgres.NewDocumentRepository,
+		postgres.NewDocumentTextRevisionRepository,
+		postgres.NewUploadSessionRepository,
+		postgres.NewSearchRepository,
 		postgres.NewTestRepository,
 		postgres.NewQuestionRepository,
 		postgres.NewAnswerRepository,
+		postgres.NewQuestionCategoryRepository,
+		postgres.NewBankQuestionRepository,
+		postgres.NewUnitOfWork,
 
 		// JWT Manager
 		provideJWTManager,
 
 		// Document Parser Factory
-		parser.NewDocumentParserFactory,
+		provideParserFactory,
+
+		// File storage
+		provideFileStorage,
+
+		// Background parse queue
+		provideParseQueue,
+
+		// Storage janitor
+		provideJanitor,
 
 		// LLM Factory
 		provideLLMFactory,
 
 		// Moodle components
-		moodle.NewMoodleXMLExporter,
+		provideMoodleXMLExporter,
 		provideMoodleClient,
 
 		// Handlers
 		provideAuthHandler,
 		handler.NewUserHandler,
-		handler.NewDocumentHandler,
+		provideDocumentHandler,
 		handler.NewTestHandler,
 		handler.NewMoodleHandler,
 		handler.NewStatsHandler,
-
-		// File config providers
-		provideUploadDir,
-		provideMaxFileSize,
+		handler.NewSearchHandler,
+		handler.NewCleanupHandler,
+		handler.NewQuestionBankHandler,
 
 		// Wire the ApplicationContainer
 		wire.Struct(new(ApplicationContainer), "*"),
@@ -86,6 +109,12 @@ func provideLLMFactory(cfg *config.Config) *llm.LLMFactory {
 	)
 }
 
+func provideMoodleXMLExporter(cfg *config.Config) *moodle.MoodleXMLExporter {
+	exporter := moodle.NewMoodleXMLExporter()
+	exporter.SetNegativeMarking(cfg.Moodle.NegativeMarking)
+	return exporter
+}
+
 func provideMoodleClient(cfg *config.Config) *moodle.Client {
 	if cfg.Moodle.URL != "" && cfg.Moodle.Token != "" {
 		return moodle.NewClient(cfg.Moodle.URL, cfg.Moodle.Token, cfg.Moodle.ImportToken)
@@ -93,12 +122,88 @@ func provideMoodleClient(cfg *config.Config) *moodle.Client {
 	return nil
 }
 
-func provideUploadDir(cfg *config.Config) string {
-	return cfg.File.UploadDir
+func provideParserFactory(cfg *config.Config) *parser.DocumentParserFactory {
+	factory := parser.NewDocumentParserFactory()
+	factory.SetArchiveLimits(parser.ArchiveLimits{
+		MaxEntries:          cfg.File.MaxArchiveEntries,
+		MaxUncompressedSize: cfg.File.MaxUncompressedSize,
+		MaxCompressionRatio: float64(cfg.File.MaxCompressionRatio),
+	})
+	factory.SetNotebookOutputs(cfg.Parser.NotebookOutputs)
+	return factory
 }
 
-func provideMaxFileSize(cfg *config.Config) int64 {
-	return cfg.File.MaxFileSize
+func provideFileStorage(cfg *config.Config) (storage.FileStorage, error) {
+	return storage.New(context.Background(), storage.Config{
+		Backend:  cfg.Storage.Backend,
+		LocalDir: cfg.File.UploadDir,
+		S3: storage.S3Config{
+			Endpoint:     cfg.Storage.S3Endpoint,
+			AccessKey:    cfg.Storage.S3AccessKey,
+			SecretKey:    cfg.Storage.S3SecretKey,
+			Bucket:       cfg.Storage.S3Bucket,
+			Region:       cfg.Storage.S3Region,
+			UseSSL:       cfg.Storage.S3UseSSL,
+			CreateBucket: cfg.Storage.S3CreateBucket,
+		},
+	})
+}
+
+func provideParseQueue(
+	cfg *config.Config,
+	documentRepo repository.DocumentRepository,
+	parserFactory *parser.DocumentParserFactory,
+	fileStorage storage.FileStorage,
+) *worker.ParseQueue {
+	return worker.NewParseQueue(documentRepo, parserFactory, fileStorage, worker.ParseQueueConfig{
+		Workers:      cfg.Parser.Workers,
+		QueueSize:    cfg.Parser.QueueSize,
+		JobTimeout:   cfg.Parser.JobTimeout,
+		MaxRetries:   cfg.Parser.MaxRetries,
+		RetryBackoff: cfg.Parser.RetryBackoff,
+		PollInterval: cfg.Parser.PollInterval,
+		StaleAfter:   cfg.Parser.StaleAfter,
+	}, nil)
+}
+
+func provideJanitor(
+	cfg *config.Config,
+	documentRepo repository.DocumentRepository,
+	uploadRepo repository.UploadSessionRepository,
+	fileStorage storage.FileStorage,
+) *worker.Janitor {
+	return worker.NewJanitor(documentRepo, uploadRepo, fileStorage, worker.JanitorConfig{
+		Interval:          cfg.Cleanup.Interval,
+		Retention:         cfg.Cleanup.Retention,
+		OrphanGracePeriod: cfg.Cleanup.OrphanGracePeriod,
+		UploadSessionTTL:  cfg.File.UploadSessionTTL,
+		DryRun:            cfg.Cleanup.DryRun,
+	}, nil)
+}
+
+func provideDocumentHandler(
+	cfg *config.Config,
+	documentRepo repository.DocumentRepository,
+	userRepo repository.UserRepository,
+	revisionRepo repository.DocumentTextRevisionRepository,
+	uploadRepo repository.UploadSessionRepository,
+	parserFactory *parser.DocumentParserFactory,
+	fileStorage storage.FileStorage,
+	parseQueue *worker.ParseQueue,
+) *handler.DocumentHandler {
+	documentHandler := handler.NewDocumentHandler(
+		documentRepo,
+		userRepo,
+		revisionRepo,
+		uploadRepo,
+		parserFactory,
+		fileStorage,
+		cfg.File.MaxFileSize,
+		parseQueue,
+	)
+	documentHandler.SetUploadLimits(cfg.File.UploadChunkMaxSize, cfg.File.UploadSessionTTL)
+	documentHandler.SetBulkUploadLimit(cfg.File.BulkUploadMaxFiles)
+	return documentHandler
 }
 
 func provideAuthHandler(
@@ -21,6 +21,17 @@ export const FileType = {
   PPTX: 'pptx',
   TXT: 'txt',
   MD: 'md',
+  SRT: 'srt',
+  VTT: 'vtt',
+  IPYNB: 'ipynb',
+  GO: 'go',
+  PY: 'py',
+  JAVA: 'java',
+  ODT: 'odt',
+  ODP: 'odp',
+  RTF: 'rtf',
+  HTML: 'html',
+  EPUB: 'epub',
 } as const
 
 export type FileType = (typeof FileType)[keyof typeof FileType]